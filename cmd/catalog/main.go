@@ -0,0 +1,78 @@
+// Command catalog regenerates the offline quota catalog bundled with the
+// dashboard from the live Service Quotas API.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/catalog"
+)
+
+func main() {
+	region := flag.String("region", "us-east-1", "region to list default quotas in")
+	out := flag.String("out", "internal/catalog/catalog.json", "output file")
+	diff := flag.Bool("diff", false, "print the quota codes added or removed since the bundled catalog instead of writing it")
+	check := flag.Bool("check", false, "fail unless the bundled catalog has been generated, without calling AWS")
+	flag.Parse()
+
+	if *check {
+		bundled := catalog.Bundled()
+		if !bundled.Generated() {
+			log.Fatal(catalog.ErrNotGenerated)
+		}
+		bundle := bundled.Bundle()
+		log.Printf("Bundled catalog has %d services, generated %s", len(bundle.Services), bundle.GeneratedAt.Format("2006-01-02"))
+		return
+	}
+
+	if *diff && !catalog.Bundled().Generated() {
+		log.Fatal(catalog.ErrNotGenerated)
+	}
+
+	fetcher := aws.NewQuotaFetcher(1)
+	services, err := fetcher.ListCatalog(context.Background(), *region)
+	if err != nil {
+		log.Fatalf("Failed to list quota catalog: %v", err)
+	}
+
+	if *diff {
+		printDiff(catalog.Bundled().Compare(services, *region, time.Now()))
+		return
+	}
+
+	data, err := json.MarshalIndent(catalog.Bundle{
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+		Services:    services,
+	}, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode catalog: %v", err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatalf("Failed to write catalog: %v", err)
+	}
+	log.Printf("Wrote %d services to %s", len(services), *out)
+}
+
+// printDiff prints one line per quota code added (+) or removed (-)
+func printDiff(diff catalog.Diff) {
+	for _, svc := range diff.Services {
+		if svc.NewService {
+			fmt.Printf("+ %s (new service: %s)\n", svc.ServiceCode, svc.ServiceName)
+		}
+		for _, q := range svc.Added {
+			fmt.Printf("+ %s/%s %s\n", svc.ServiceCode, q.QuotaCode, q.QuotaName)
+		}
+		for _, q := range svc.Removed {
+			fmt.Printf("- %s/%s %s\n", svc.ServiceCode, q.QuotaCode, q.QuotaName)
+		}
+	}
+	log.Printf("%d quota codes added and %d removed since the bundled catalog of %s",
+		diff.Added, diff.Removed, diff.BundledAt.Format("2006-01-02"))
+}
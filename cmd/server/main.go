@@ -1,41 +1,432 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+	"github.com/yuxishi/aws-quota-dashboard/internal/accesslog"
+	"github.com/yuxishi/aws-quota-dashboard/internal/alert"
+	"github.com/yuxishi/aws-quota-dashboard/internal/auth"
 	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
 	"github.com/yuxishi/aws-quota-dashboard/internal/cache"
+	"github.com/yuxishi/aws-quota-dashboard/internal/composite"
 	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/format"
 	"github.com/yuxishi/aws-quota-dashboard/internal/handler"
+	"github.com/yuxishi/aws-quota-dashboard/internal/increase"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+	"github.com/yuxishi/aws-quota-dashboard/internal/org"
+	"github.com/yuxishi/aws-quota-dashboard/internal/report"
+	"github.com/yuxishi/aws-quota-dashboard/internal/sink"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/threshold"
+	"github.com/yuxishi/aws-quota-dashboard/internal/version"
 )
 
 func main() {
+	preflight := flag.Bool("preflight", false, "check credentials, IAM permissions, AWS endpoints, config and storage, print a report and exit")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.Load("config.yaml")
+	if *preflight {
+		if err != nil {
+			cfg = config.Default()
+		}
+		if cfg.EndpointURL != "" || len(cfg.Endpoints) > 0 {
+			aws.SetEndpoints(cfg.EndpointURL, cfg.Endpoints)
+		}
+		os.Exit(runPreflight(os.Stdout, cfg, err))
+	}
 	if err != nil {
 		log.Printf("Warning: failed to load config.yaml, using defaults: %v", err)
 		cfg = config.Default()
 	}
 	log.Printf("Configuration loaded: default_region=%s, default_service=%s", cfg.DefaultRegion, cfg.DefaultService)
 
+	if cfg.EndpointURL != "" || len(cfg.Endpoints) > 0 {
+		aws.SetEndpoints(cfg.EndpointURL, cfg.Endpoints)
+		log.Printf("Using custom AWS endpoints: endpoint_url=%q, per-service=%d", cfg.EndpointURL, len(cfg.Endpoints))
+	}
+
 	port := cfg.GetPort()
 	cacheTTL := cfg.GetCacheTTL()
-	c := cache.New(cacheTTL)
+	var c *cache.Cache
+	if cfg.Cache.Path == "" {
+		c = cache.New(cacheTTL)
+	} else {
+		registerCacheTypes()
+		c, err = cache.NewPersistent(cacheTTL, cfg.Cache.Path, cfg.GetCacheStaleMaxAge())
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Persisting cached quotas in %s (%d entries loaded)", cfg.Cache.Path, c.StaleCount())
+	}
+	defer func() {
+		if err := c.Close(); err != nil {
+			log.Printf("Failed to close cache file: %v", err)
+		}
+	}()
 	fetcher := aws.NewQuotaFetcher(cfg.MaxConcurrency)
-	h := handler.New(fetcher, c)
+	fetcher.SetDefaultRegion(cfg.DefaultRegion)
+	fetcher.SetOwnerTagKey(cfg.Ownership.TagKey)
+	fetcher.SetUsageHandlerTimeout(cfg.GetUsageHandlerTimeout())
+	fetcher.SetRateLimits(cfg.ScanRate.Initial, cfg.ScanRate.Min, cfg.ScanRate.Max)
+	var history store.Store = store.NewMemoryStore()
+	if cfg.History.SQLitePath != "" {
+		history, err = store.NewSQLiteStore(context.Background(), cfg.History.SQLitePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Recording quota history in %s", cfg.History.SQLitePath)
+	}
+	defer func() {
+		if err := history.Close(); err != nil {
+			log.Printf("Failed to close history store: %v", err)
+		}
+	}()
+	h := handler.New(fetcher, c, history)
+	if err := h.PersistState(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	h.SetRetireAfter(cfg.GetRetireAfter())
+	if err := threshold.Validate(cfg.Thresholds); err != nil {
+		log.Fatal(err)
+	}
+	h.SetThresholds(threshold.New(cfg.Thresholds))
+	h.SetWebhooks(cfg.Webhooks)
 
+	h.SetDefaultRegion(cfg.DefaultRegion)
 	// Set config for API access
 	h.SetConfig(map[string]interface{}{
 		"default_region":  cfg.DefaultRegion,
 		"default_service": cfg.DefaultService,
+		"support_cases":   cfg.SupportCases.Enabled,
 	})
 
+	templates, err := increase.NewTemplates(cfg.Increase.Templates)
+	if err != nil {
+		log.Fatal(err)
+	}
+	h.SetJustificationTemplates(templates)
+	h.SetIncreaseRegions(cfg.Regions)
+	h.SetAttributionConfig(cfg.Attribution)
+	h.SetSupportCases(cfg.SupportCases)
+	h.SetCostEnabled(cfg.Cost.Enabled)
+	if err := alert.Validate(cfg.Alerts.Rules); err != nil {
+		log.Fatal(err)
+	}
+	h.SetAlertRules(cfg.Alerts.Rules)
+	alertTemplates, err := alert.NewTemplates(cfg.Alerts.Templates)
+	if err != nil {
+		log.Fatal(err)
+	}
+	h.SetAlertTemplates(alertTemplates)
+	h.SetSnoozeReminderLead(cfg.GetReminderLead())
+	composites, err := composite.Compile(cfg.Composites)
+	if err != nil {
+		log.Fatal(err)
+	}
+	h.SetComposites(composites)
+	h.SetScanProfiles(cfg.ScanProfiles)
+	if err := h.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal(err)
+	}
+	if err := validateSlack(cfg.Slack); err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Slack.BotToken != "" {
+		h.SetSlack(notify.NewSlackBot(cfg.Slack.BotToken, cfg.Slack.Channel), cfg.Slack)
+	} else if cfg.Slack.WebhookURL != "" {
+		h.SetSlack(notify.NewSlack(cfg.Slack.WebhookURL), cfg.Slack)
+	}
+	if cfg.Teams.WebhookURL != "" {
+		h.SetTeams(notify.NewTeams(cfg.Teams.WebhookURL), cfg.Teams)
+	}
+	if len(cfg.Email.Recipients) > 0 {
+		if err := validateEmail(cfg.Email); err != nil {
+			log.Fatal(err)
+		}
+		h.SetEmail(cfg.Email, cfg.GetEmailRegion())
+	}
+	if cfg.SNS.TopicARN != "" {
+		if _, err := aws.TopicRegion(cfg.SNS.TopicARN); err != nil {
+			log.Fatal(err)
+		}
+		for _, event := range cfg.SNS.Events {
+			if event != notify.EventThresholdCrossed && event != notify.EventAlertResolved &&
+				event != notify.EventLimitAnomaly && event != notify.EventDigest {
+				log.Fatalf("invalid sns event %q", event)
+			}
+		}
+		h.SetSNS(cfg.SNS)
+	}
+	if cfg.Digest.Period != handler.DigestDaily && cfg.Digest.Period != handler.DigestWeekly {
+		log.Fatalf("invalid digest period %q", cfg.Digest.Period)
+	}
+	if cfg.Digest.Top < 0 {
+		log.Fatalf("invalid digest top %d", cfg.Digest.Top)
+	}
+	if cfg.Digest.ReplaceRealtime && cfg.Digest.Schedule == "" {
+		log.Fatal("digest.replace_realtime requires digest.schedule")
+	}
+	h.SetDigest(cfg.Digest)
+	var postgres *sink.Postgres
+	if cfg.PostgresSink.DSN != "" {
+		postgres, err = sink.NewPostgres(context.Background(), cfg.PostgresSink.DSN, cfg.PostgresSink.Schema, cfg.GetPostgresRetention())
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer postgres.Close()
+		h.SetSink(postgres)
+	}
+	if cfg.AccessLog.Enabled {
+		accesses := accesslog.New(cfg.AccessLog.MaxEntries)
+		h.SetAccessLog(accesses)
+		if postgres != nil && cfg.GetAccessLogFlushInterval() > 0 {
+			accesses.SetWriter(postgres)
+			flushes := cron.New()
+			if _, err := flushes.AddFunc(fmt.Sprintf("@every %s", cfg.GetAccessLogFlushInterval()), func() {
+				if err := accesses.Flush(context.Background()); err != nil {
+					log.Printf("Failed to write the access log to postgres: %v", err)
+				}
+			}); err != nil {
+				log.Fatal(err)
+			}
+			flushes.Start()
+			defer func() {
+				<-flushes.Stop().Done()
+				if err := accesses.Flush(context.Background()); err != nil {
+					log.Printf("Failed to write the access log to postgres: %v", err)
+				}
+			}()
+		}
+	}
+	h.SetReviewConfig(cfg.Review)
+	h.SetFeatures(cfg.Features())
+
+	// Start the scheduled org-wide scan when running as a delegated admin, or
+	// over a simulated organization in demo mode
+	if cfg.OrgScan.Enabled || cfg.Demo.Enabled {
+		scanCfg := cfg.OrgScan
+		var scanner *org.Scanner
+		if cfg.Demo.Enabled {
+			scanCfg.Schedule = cfg.Demo.Schedule
+			scanCfg.ScanOnStart = true
+			scanner = org.NewDemoScanner(org.NewDemo(cfg.Demo, cfg.GetOrgScanRegions()), scanCfg, cfg.MaxConcurrency)
+		} else {
+			scanner = org.NewScanner(fetcher, scanCfg, cfg.GetOrgScanRegions(), cfg.MaxConcurrency)
+		}
+		scanner.SetComposites(composites)
+		monitor := alert.NewMonitor(cfg.GetScanBudget(), cfg.Alerts.SelfMonitoring.CoverageDropPercent)
+		h.SetMonitor(monitor)
+		scanner.OnFailure(func(err error) {
+			h.NotifyMetaAlerts(context.Background(), []alert.MetaAlert{monitor.ScanFailed(err, time.Now())})
+			startedAt, _ := scanner.RunningSince()
+			h.RecordOrgScanFailure(startedAt, cfg.GetOrgScanRegions(), scanCfg.Service, err)
+		})
+		scanner.OnComplete(func(inv *org.Inventory) {
+			h.NotifyMetaAlerts(context.Background(), monitor.ScanCompleted(inv.StartedAt, inv.CompletedAt, inv.Quotas))
+			h.RecordOrgScanRun(inv, cfg.GetOrgScanRegions(), scanCfg.Service)
+		})
+		budgets := cron.New()
+		if _, err := budgets.AddFunc("@every 1m", func() {
+			if startedAt, running := scanner.RunningSince(); running {
+				if a, ok := monitor.CheckRunning(startedAt, time.Now()); ok {
+					h.NotifyMetaAlerts(context.Background(), []alert.MetaAlert{a})
+				}
+			}
+		}); err != nil {
+			log.Fatal(err)
+		}
+		budgets.Start()
+		defer budgets.Stop()
+		scanner.OnComplete(func(inv *org.Inventory) {
+			if postgres != nil {
+				if err := postgres.WriteSnapshot(context.Background(), inv.CompletedAt, sink.SourceOrgScan, inv.Quotas, inv.Warnings); err != nil {
+					log.Printf("Failed to mirror org quota snapshot: %v", err)
+				}
+			}
+			if err := history.RecordWarnings(context.Background(), inv.CompletedAt, store.WarningSourceOrgScan, inv.Warnings); err != nil {
+				log.Printf("Failed to record org scan warnings: %v", err)
+			}
+			h.TrackLimitChanges(context.Background(), inv.CompletedAt, inv.Quotas)
+			h.NotifyThresholdCrossings(context.Background(), inv.CompletedAt, inv.Quotas)
+			if err := history.Record(context.Background(), inv.CompletedAt, inv.Quotas); err != nil {
+				log.Printf("Failed to record org quota history: %v", err)
+				return
+			}
+			scope := store.Scope{
+				AccountIDs:  make([]string, 0, len(inv.Accounts)),
+				Regions:     append([]string{"global"}, cfg.GetOrgScanRegions()...),
+				ServiceCode: scanCfg.Service,
+			}
+			for _, account := range inv.Accounts {
+				scope.AccountIDs = append(scope.AccountIDs, account.ID)
+			}
+			retired, err := store.Reconcile(context.Background(), history, inv.CompletedAt, scope, inv.Quotas, cfg.GetRetireAfter())
+			if err != nil {
+				log.Printf("Failed to reconcile org quota history: %v", err)
+			} else if retired > 0 {
+				log.Printf("Retired %d org quotas missing from the latest scan", retired)
+			}
+		})
+		if hosting := cfg.ReportHosting; hosting.Bucket != "" {
+			region := hosting.Region
+			if region == "" {
+				region = cfg.DefaultRegion
+			}
+			locale, err := format.ParseLocale(hosting.Locale)
+			if err != nil {
+				log.Fatal(err)
+			}
+			opts := format.Options{Locale: locale, ScaleUnits: true}
+			scanner.OnComplete(func(inv *org.Inventory) {
+				trends, err := report.Trends(context.Background(), history, inv.Quotas)
+				if err != nil {
+					log.Printf("Failed to read usage trends for the HTML report: %v", err)
+				}
+				page := report.HTML(inv.Quotas, trends, opts)
+				url, err := aws.PublishHTML(context.Background(), region, hosting.Bucket, hosting.Key, []byte(page))
+				if err != nil {
+					log.Printf("Failed to publish HTML report: %v", err)
+					return
+				}
+				log.Printf("Published HTML report to %s", url)
+			})
+		}
+		if err := scanner.Start(context.Background()); err != nil {
+			log.Fatal(err)
+		}
+		defer scanner.Stop()
+		h.SetOrgScanner(scanner)
+		if cfg.Demo.Enabled {
+			log.Printf("Demo mode: org scan over a simulated organization in %s, schedule=%q",
+				strings.Join(cfg.GetOrgScanRegions(), ","), scanCfg.Schedule)
+		} else {
+			log.Printf("Org scan enabled: schedule=%q, role=%s", scanCfg.Schedule, scanCfg.RoleName)
+		}
+	}
+
+	// Compact old history, so the store does not grow without bound
+	if cfg.History.CompactionSchedule != "" {
+		bucket, err := store.BucketSize(cfg.History.RollupResolution)
+		if err != nil {
+			log.Fatalf("invalid history.rollup_resolution: %v", err)
+		}
+		retention := store.Retention{Raw: cfg.GetRawRetention(), Rollups: cfg.GetRollupRetention(), Bucket: bucket}
+		compactions := cron.New()
+		if _, err := compactions.AddFunc(cfg.History.CompactionSchedule, func() {
+			removed, err := history.Compact(context.Background(), time.Now(), retention)
+			if err != nil {
+				log.Printf("Failed to compact quota history: %v", err)
+				return
+			}
+			log.Printf("Compacted quota history, removed %d observations", removed)
+		}); err != nil {
+			log.Fatalf("invalid history compaction schedule %q: %v", cfg.History.CompactionSchedule, err)
+		}
+		compactions.Start()
+		defer compactions.Stop()
+	}
+
+	// Poll open increase requests so decisions are announced and the new
+	// limits shown without waiting for the cache to expire
+	if interval := cfg.GetIncreaseWatchInterval(); interval > 0 {
+		watches := cron.New()
+		if _, err := watches.AddFunc(fmt.Sprintf("@every %s", interval), func() {
+			h.WatchIncreaseRequests(context.Background())
+		}); err != nil {
+			log.Fatal(err)
+		}
+		watches.Start()
+		defer watches.Stop()
+	}
+
+	// Open quota reviews on schedule, e.g. at the start of each quarter
+	if cfg.Review.Schedule != "" {
+		reviews := cron.New()
+		if _, err := reviews.AddFunc(cfg.Review.Schedule, func() {
+			rev, err := h.OpenReview(context.Background())
+			if err != nil {
+				log.Printf("Failed to open scheduled quota review: %v", err)
+				return
+			}
+			log.Printf("Opened quota review %s with %d quotas", rev.ID, rev.Progress.Total)
+		}); err != nil {
+			log.Fatalf("invalid review schedule %q: %v", cfg.Review.Schedule, err)
+		}
+		reviews.Start()
+		defer reviews.Stop()
+	}
+
+	// Compare the live quota catalog against the bundled one, so new quota
+	// codes AWS introduced get noticed
+	if cfg.CatalogDiff.Schedule != "" {
+		region := cfg.CatalogDiff.Region
+		if region == "" {
+			region = cfg.DefaultRegion
+		}
+		diffs := cron.New()
+		if _, err := diffs.AddFunc(cfg.CatalogDiff.Schedule, func() {
+			diff, err := h.RunCatalogDiff(context.Background(), region)
+			if err != nil {
+				log.Printf("Failed to compare the quota catalog: %v", err)
+				return
+			}
+			for _, svc := range diff.Services {
+				for _, q := range svc.Added {
+					log.Printf("New quota in the live catalog: %s/%s %q", svc.ServiceCode, q.QuotaCode, q.QuotaName)
+				}
+			}
+			log.Printf("Quota catalog comparison: %d quota codes added and %d removed since the bundled catalog", diff.Added, diff.Removed)
+		}); err != nil {
+			log.Fatalf("invalid catalog_diff schedule %q: %v", cfg.CatalogDiff.Schedule, err)
+		}
+		diffs.Start()
+		defer diffs.Stop()
+	}
+
+	// Send the quota digest, e.g. every morning or on Mondays
+	if cfg.Digest.Schedule != "" {
+		digests := cron.New()
+		if _, err := digests.AddFunc(cfg.Digest.Schedule, func() {
+			if err := h.SendDigest(context.Background()); err != nil {
+				log.Printf("Failed to send the quota digest: %v", err)
+			}
+		}); err != nil {
+			log.Fatalf("invalid digest schedule %q: %v", cfg.Digest.Schedule, err)
+		}
+		digests.Start()
+		defer digests.Stop()
+	}
+
+	// Remind about snoozed alerts shortly before they resume
+	reminders := cron.New()
+	if _, err := reminders.AddFunc("@every 1m", func() { h.SendSnoozeReminders(context.Background()) }); err != nil {
+		log.Fatal(err)
+	}
+	reminders.Start()
+	defer reminders.Stop()
+
+	// Refresh the quotas persisted by the previous run before they are asked for
+	go h.RefreshStaleQuotas(context.Background())
+
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal(err)
+	}
 
 	// Find templates directory
 	templateDir := findTemplateDir()
@@ -45,18 +436,118 @@ func main() {
 		c.HTML(http.StatusOK, "index.html", nil)
 	})
 
-	api := r.Group("/api")
+	// Synchronous scans are bounded; the fetch job API has no limit
+	timeout := handler.RequestTimeout(cfg.GetRequestTimeout())
+
+	var verifier *auth.Verifier
+	if cfg.OIDC.Enabled {
+		verifier, err = auth.NewVerifier(context.Background(), cfg.OIDC)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("OIDC authentication enabled: issuer=%s", cfg.OIDC.IssuerURL)
+	}
+	access := handler.NewAccess(verifier)
+	operator := access.Require(auth.RoleOperator)
+	admin := access.Require(auth.RoleAdmin)
+
+	// Slack and the Service Quotas proxy authenticate their callers
+	// themselves
+	public := r.Group("/api", h.LogAccess)
+	{
+		public.POST("/slack/interactions", h.HandleSlackInteraction)
+
+		if cfg.Proxy.Enabled {
+			if len(cfg.Proxy.Tokens) == 0 || cfg.Proxy.RequestsPerMinute <= 0 {
+				log.Fatal("proxy requires at least one token and a positive requests_per_minute")
+			}
+			proxy := public.Group("/aws/servicequotas", handler.ProxyMiddleware(cfg.Proxy))
+			proxy.GET("/services", h.ProxyListServices)
+			proxy.GET("/services/:service/quotas", h.ProxyListServiceQuotas)
+			proxy.GET("/services/:service/quotas/:quota", h.ProxyGetServiceQuota)
+			proxy.GET("/services/:service/default-quotas", h.ProxyListDefaultServiceQuotas)
+		}
+	}
+
+	api := r.Group("/api", h.LogAccess, access.Authenticate)
 	{
+		api.GET("/auth/me", access.GetIdentity)
 		api.GET("/config", h.GetConfig)
+		api.GET("/version", h.GetVersion)
 		api.GET("/regions", h.GetRegions)
 		api.GET("/services", h.GetServices)
-		api.GET("/quotas", h.GetQuotas)
-		api.POST("/refresh", h.Refresh)
+		api.GET("/catalog", h.GetCatalog)
+		api.GET("/catalog/diff", h.GetCatalogDiff)
+		api.POST("/catalog/diff", operator, h.StartCatalogDiff)
+		api.GET("/coverage", h.GetCoverage)
+		api.GET("/coverage/requests", h.GetCoverageRequests)
+		api.POST("/coverage/requests", operator, h.RequestCoverage)
+		api.GET("/annotations", h.GetAnnotations)
+		api.POST("/annotations", admin, h.SetAnnotation)
+		api.DELETE("/annotations", admin, h.DeleteAnnotation)
+		api.GET("/annotations/export", h.ExportAnnotations)
+		api.POST("/annotations/import", admin, h.ImportAnnotations)
+		api.GET("/quotas", timeout, h.GetQuotas)
+		api.GET("/summary/services", timeout, h.GetServiceSummaries)
+		api.GET("/heatmap", timeout, h.GetHeatmap)
+		api.POST("/refresh", operator, h.Refresh)
+		api.POST("/fetch", operator, h.StartFetch)
+		api.GET("/fetch/:id", h.GetFetchJob)
+		api.GET("/fetch/:id/logs", h.StreamFetchLogs)
 		api.GET("/export/json", h.ExportJSON)
 		api.GET("/export/html", h.ExportHTML)
+		api.GET("/export/pdf", h.ExportPDF)
+		api.GET("/export/csv", h.ExportCSV)
+		api.GET("/export/xlsx", h.ExportXLSX)
+		api.GET("/export/snippets", h.ExportSnippets)
+		api.GET("/snapshot/export", h.ExportSnapshot)
+		api.POST("/snapshot/import", admin, h.ImportSnapshot)
+		api.GET("/snapshot/import", h.GetImportedSnapshot)
+		api.DELETE("/snapshot/import", admin, h.ClearImportedSnapshot)
+		api.POST("/snapshot/diff", h.DiffSnapshot)
+		api.GET("/org/accounts", h.GetOrgAccounts)
+		api.GET("/org/quotas", h.GetOrgQuotas)
+		api.POST("/org/scan", operator, h.TriggerOrgScan)
+		api.GET("/status/accounts", h.GetAccountStatuses)
+		api.GET("/status/rates", h.GetRateStatus)
+		api.GET("/increase/templates", h.GetJustificationTemplates)
+		api.POST("/increase/justification", h.RenderJustification)
+		api.POST("/increase/preview", h.PreviewIncrease)
+		api.GET("/increase/requests", h.GetIncreaseRequests)
+		api.POST("/increase/requests", operator, h.SubmitIncreaseRequest)
+		api.POST("/increase/bulk/preview", timeout, h.PreviewBulkIncrease)
+		api.POST("/increase/bulk", operator, timeout, h.SubmitBulkIncrease)
+		api.GET("/increase/proposals", h.GetIncreaseProposals)
+		api.POST("/increase/proposals", operator, h.ProposeIncrease)
+		api.GET("/attribution", h.GetAttribution)
+		api.GET("/support/cases", h.GetSupportCases)
+		api.POST("/support/cases", operator, h.OpenSupportCase)
+		api.GET("/history", h.GetHistory)
+		api.GET("/runs", h.GetRuns)
+		api.GET("/sparklines", h.GetSparklines)
+		api.GET("/quota-changes", h.GetQuotaChanges)
+		api.GET("/history/retired", h.GetRetiredQuotas)
+		api.GET("/warnings", h.GetWarnings)
+		api.POST("/alerts/test", timeout, h.TestAlertRules)
+		api.GET("/alerts/snoozes", h.GetSnoozes)
+		api.POST("/alerts/snoozes", operator, h.SnoozeAlert)
+		api.DELETE("/alerts/snoozes/:id", operator, h.DeleteSnooze)
+		api.GET("/alerts/meta", h.GetMetaAlerts)
+		api.GET("/alerts", h.GetAlerts)
+		api.GET("/digest", h.GetDigest)
+		api.POST("/alerts/:id/ack", operator, h.AcknowledgeAlert)
+		api.POST("/alerts/:id/snooze", operator, h.SnoozeActiveAlert)
+		api.GET("/reviews", h.GetReviews)
+		api.POST("/reviews", operator, timeout, h.CreateReview)
+		api.GET("/reviews/:id", h.GetReview)
+		api.PATCH("/reviews/:id/items/:item", operator, h.UpdateReviewItem)
+		api.POST("/reviews/:id/signoff", operator, h.SignOffReview)
+		api.POST("/preflight/terraform", h.PreflightTerraform)
+		api.GET("/analytics/usage", operator, h.GetUsageAnalytics)
+		api.GET("/analytics/access-log", admin, h.ExportAccessLog)
 	}
 
-	log.Printf("Starting server on http://localhost:%s", port)
+	log.Printf("Starting aws-quota-dashboard %s on http://localhost:%s", version.String(), port)
 	if err := r.Run(":" + port); err != nil {
 		log.Fatal(err)
 	}
@@ -79,3 +570,10 @@ func findTemplateDir() string {
 	// Default
 	return "web/templates"
 }
+
+// registerCacheTypes makes cached quotas, and the regions skipped with them,
+// persistable
+func registerCacheTypes() {
+	cache.Register([]model.Quota{})
+	cache.Register([]string{})
+}
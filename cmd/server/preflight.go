@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/auth"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/cache"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/sink"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+// preflightTimeout bounds each preflight check
+const preflightTimeout = 20 * time.Second
+
+// errSkipped marks checks that do not apply to the configuration
+var errSkipped = errors.New("skipped")
+
+// preflightCheck is one line of the preflight report; run returns a detail
+// shown on success
+type preflightCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// runPreflight checks that the deployment can work before it goes live:
+// config validity, credentials, a sample of the IAM permissions, reachability
+// of the AWS endpoints and writability of the PostgreSQL mirror. It prints a
+// pass/fail report and returns the process exit code.
+func runPreflight(w io.Writer, cfg *config.Config, loadErr error) int {
+	region := cfg.DefaultRegion
+	fetcher := aws.NewQuotaFetcher(1)
+	fetcher.SetDefaultRegion(region)
+
+	checks := []preflightCheck{
+		{"config file", func(context.Context) (string, error) {
+			if loadErr != nil {
+				return "", loadErr
+			}
+			if _, err := os.Stat("config.yaml"); os.IsNotExist(err) {
+				return "no config.yaml, using defaults", nil
+			}
+			return fmt.Sprintf("config.yaml loaded, features: %s", strings.Join(cfg.Features(), ", ")), nil
+		}},
+		{"config validity", func(context.Context) (string, error) {
+			return "templates, alert rules, thresholds, composites and schedules are valid", validateConfig(cfg)
+		}},
+		{"web templates", func(context.Context) (string, error) {
+			matches, err := filepath.Glob(filepath.Join(findTemplateDir(), "*.html"))
+			if err != nil || len(matches) == 0 {
+				return "", fmt.Errorf("no HTML templates found in %s", findTemplateDir())
+			}
+			return findTemplateDir(), nil
+		}},
+		{"credentials", func(ctx context.Context) (string, error) {
+			id, err := fetcher.Identity(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("account %s as %s", id.AccountID, id.Principal()), nil
+		}},
+	}
+	for _, probe := range aws.Probes(cfg.Features()) {
+		name := "iam " + probe.Action
+		if probe.Feature != "" {
+			name += " (" + probe.Feature + ")"
+		}
+		checks = append(checks, preflightCheck{name, func(ctx context.Context) (string, error) {
+			return "allowed, endpoint reachable", fetcher.RunProbe(ctx, region, probe)
+		}})
+	}
+
+	checks = append(checks,
+		preflightCheck{"oidc issuer", func(ctx context.Context) (string, error) {
+			if !cfg.OIDC.Enabled {
+				return "", fmt.Errorf("%w: oidc is disabled", errSkipped)
+			}
+			if _, err := auth.NewVerifier(ctx, cfg.OIDC); err != nil {
+				return "", err
+			}
+			return cfg.OIDC.IssuerURL + " discovered", nil
+		}},
+		preflightCheck{"postgres sink", func(ctx context.Context) (string, error) {
+			if cfg.PostgresSink.DSN == "" {
+				return "", fmt.Errorf("%w: no postgres_sink.dsn configured", errSkipped)
+			}
+			// Connecting creates the schema and its tables, proving the
+			// dashboard can write there
+			p, err := sink.NewPostgres(ctx, cfg.PostgresSink.DSN, cfg.PostgresSink.Schema, cfg.GetPostgresRetention())
+			if err != nil {
+				return "", err
+			}
+			defer p.Close()
+			return "connected and schema writable", nil
+		}},
+		preflightCheck{"sqlite history", func(ctx context.Context) (string, error) {
+			if cfg.History.SQLitePath == "" {
+				return "", fmt.Errorf("%w: history is kept in memory", errSkipped)
+			}
+			s, err := store.NewSQLiteStore(ctx, cfg.History.SQLitePath)
+			if err != nil {
+				return "", err
+			}
+			defer s.Close()
+			return cfg.History.SQLitePath + " opened and writable", nil
+		}},
+		preflightCheck{"persistent cache", func(ctx context.Context) (string, error) {
+			if cfg.Cache.Path == "" {
+				return "", fmt.Errorf("%w: cache is kept in memory", errSkipped)
+			}
+			registerCacheTypes()
+			c, err := cache.NewPersistent(cfg.GetCacheTTL(), cfg.Cache.Path, cfg.GetCacheStaleMaxAge())
+			if err != nil {
+				return "", err
+			}
+			defer c.Close()
+			return fmt.Sprintf("%s opened, %d entries persisted", cfg.Cache.Path, c.StaleCount()), nil
+		}},
+	)
+
+	failed := 0
+	for _, check := range checks {
+		ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+		detail, err := check.run(ctx)
+		cancel()
+		status := "PASS"
+		switch {
+		case errors.Is(err, errSkipped):
+			status, detail = "SKIP", strings.TrimPrefix(err.Error(), errSkipped.Error()+": ")
+		case err != nil:
+			status, detail = "FAIL", strings.ReplaceAll(err.Error(), "\n", "; ")
+			failed++
+		}
+		fmt.Fprintf(w, "%-4s  %-45s %s\n", status, check.name, detail)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(w, "\nPreflight failed: %d of %d checks failed\n", failed, len(checks))
+		return 1
+	}
+	fmt.Fprintf(w, "\nPreflight passed\n")
+	return 0
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	"github.com/yuxishi/aws-quota-dashboard/internal/alert"
+	"github.com/yuxishi/aws-quota-dashboard/internal/composite"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/format"
+	"github.com/yuxishi/aws-quota-dashboard/internal/increase"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/threshold"
+)
+
+// validateConfig runs the validations the server does at startup, without
+// exiting on the first problem
+func validateConfig(cfg *config.Config) error {
+	var errs []error
+	if _, err := increase.NewTemplates(cfg.Increase.Templates); err != nil {
+		errs = append(errs, err)
+	}
+	if err := alert.Validate(cfg.Alerts.Rules); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := alert.NewTemplates(cfg.Alerts.Templates); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := composite.Compile(cfg.Composites); err != nil {
+		errs = append(errs, err)
+	}
+	if err := threshold.Validate(cfg.Thresholds); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.ReportHosting.Bucket != "" {
+		if _, err := format.ParseLocale(cfg.ReportHosting.Locale); err != nil {
+			errs = append(errs, fmt.Errorf("report_hosting.locale: %w", err))
+		}
+	}
+	if cfg.History.CompactionSchedule != "" {
+		if _, err := store.BucketSize(cfg.History.RollupResolution); err != nil {
+			errs = append(errs, fmt.Errorf("history.rollup_resolution: %w", err))
+		}
+	}
+	if err := validateSlack(cfg.Slack); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateEmail(cfg.Email); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Proxy.Enabled && (len(cfg.Proxy.Tokens) == 0 || cfg.Proxy.RequestsPerMinute <= 0) {
+		errs = append(errs, errors.New("proxy requires at least one token and a positive requests_per_minute"))
+	}
+	schedules := [][2]string{
+		{"review.schedule", cfg.Review.Schedule},
+		{"catalog_diff.schedule", cfg.CatalogDiff.Schedule},
+		{"history.compaction_schedule", cfg.History.CompactionSchedule},
+	}
+	if cfg.OrgScan.Enabled {
+		schedules = append(schedules, [2]string{"org_scan.schedule", cfg.OrgScan.Schedule})
+	}
+	if cfg.Demo.Enabled {
+		schedules = append(schedules, [2]string{"demo.schedule", cfg.Demo.Schedule})
+	}
+	for _, s := range schedules {
+		if s[1] == "" {
+			continue
+		}
+		if _, err := cron.ParseStandard(s[1]); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", s[0], s[1], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateSlack checks that bot tokens have a default channel and that
+// interactive approvals are restricted to named approvers
+func validateSlack(cfg config.SlackConfig) error {
+	var errs []error
+	if cfg.BotToken != "" && cfg.Channel == "" {
+		errs = append(errs, errors.New("slack.bot_token requires slack.channel"))
+	}
+	if cfg.SigningSecret != "" && len(cfg.Approvers) == 0 {
+		errs = append(errs, errors.New("slack.signing_secret requires slack.approvers, the Slack user IDs allowed to approve increases"))
+	}
+	return errors.Join(errs...)
+}
+
+// validateEmail checks that email recipients have a sender and valid
+// severity filters
+func validateEmail(cfg config.EmailConfig) error {
+	if len(cfg.Recipients) == 0 {
+		return nil
+	}
+	var errs []error
+	if cfg.From == "" {
+		errs = append(errs, errors.New("email.recipients requires email.from"))
+	}
+	for _, r := range cfg.Recipients {
+		for _, severity := range r.Severities {
+			if severity != threshold.StatusWarning && severity != threshold.StatusCritical {
+				errs = append(errs, fmt.Errorf("invalid severity %q of email recipient %s", severity, r.Address))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+// Package accesslog records API requests and summarizes who uses which
+// endpoints, so operators can justify the service and spot polling clients
+package accesslog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries is the number of requests kept in memory when none is
+// configured
+const DefaultMaxEntries = 100000
+
+// maxPending bounds the entries waiting for the writer, so an unreachable
+// database does not grow memory without limit
+const maxPending = 10000
+
+// Entry is a single API request. Route is the route pattern, such as
+// /api/fetch/:id, so requests to the same endpoint group together; User is
+// the OIDC subject or email, or empty for anonymous callers.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Route      string    `json:"route"`
+	Status     int       `json:"status"`
+	User       string    `json:"user,omitempty"`
+	ClientIP   string    `json:"client_ip"`
+	DurationMS float64   `json:"duration_ms"`
+	Bytes      int       `json:"bytes"`
+}
+
+// Writer persists access log entries
+type Writer interface {
+	WriteAccessLog(ctx context.Context, entries []Entry) error
+}
+
+// Log keeps the most recent requests in memory and hands them to an optional
+// writer in batches
+type Log struct {
+	mu      sync.Mutex
+	max     int
+	entries []Entry
+	pending []Entry
+	writer  Writer
+}
+
+// New creates an access log keeping up to max requests in memory
+func New(max int) *Log {
+	if max <= 0 {
+		max = DefaultMaxEntries
+	}
+	return &Log{max: max}
+}
+
+// SetWriter sets where Flush persists the recorded requests
+func (l *Log) SetWriter(w Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.writer = w
+}
+
+// Record adds a request. The oldest requests are dropped in batches once a
+// quarter past the limit, so recording stays cheap.
+func (l *Log) Record(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+	if len(l.entries) > l.max+l.max/4 {
+		l.entries = append(l.entries[:0:0], l.entries[len(l.entries)-l.max:]...)
+	}
+	if l.writer != nil {
+		l.pending = append(l.pending, e)
+		if over := len(l.pending) - maxPending; over > 0 {
+			l.pending = l.pending[over:]
+		}
+	}
+}
+
+// Entries returns the requests started in [since, until), in the order they
+// completed
+func (l *Log) Entries(since, until time.Time) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := []Entry{}
+	for _, e := range l.entries {
+		if !e.Time.Before(since) && e.Time.Before(until) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Flush writes the requests recorded since the last flush. Failed batches are
+// retried on the next flush.
+func (l *Log) Flush(ctx context.Context) error {
+	l.mu.Lock()
+	writer, batch := l.writer, l.pending
+	l.pending = nil
+	l.mu.Unlock()
+	if writer == nil || len(batch) == 0 {
+		return nil
+	}
+	if err := writer.WriteAccessLog(ctx, batch); err != nil {
+		l.mu.Lock()
+		l.pending = append(batch, l.pending...)
+		if over := len(l.pending) - maxPending; over > 0 {
+			l.pending = l.pending[over:]
+		}
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
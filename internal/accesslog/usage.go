@@ -0,0 +1,158 @@
+package accesslog
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// AnonymousUser groups requests without an authenticated caller; they are
+// told apart by client IP
+const AnonymousUser = "anonymous"
+
+// Usage summarizes the requests of a period by endpoint and by caller
+type Usage struct {
+	Since     time.Time       `json:"since"`
+	Until     time.Time       `json:"until"`
+	Requests  int             `json:"requests"`
+	Endpoints []EndpointUsage `json:"endpoints"`
+	Users     []UserUsage     `json:"users"`
+}
+
+// EndpointUsage summarizes the requests to one route
+type EndpointUsage struct {
+	Method   string  `json:"method"`
+	Route    string  `json:"route"`
+	Requests int     `json:"requests"`
+	Users    int     `json:"users"`
+	Errors   int     `json:"errors"`
+	AvgMS    float64 `json:"avg_ms"`
+	P95MS    float64 `json:"p95_ms"`
+	Bytes    int64   `json:"bytes"`
+}
+
+// UserUsage summarizes the requests of one caller. PeakPerMinute is the most
+// requests the caller made within a calendar minute, which exposes clients
+// polling much more often than the data changes.
+type UserUsage struct {
+	User          string    `json:"user"`
+	ClientIP      string    `json:"client_ip,omitempty"`
+	Requests      int       `json:"requests"`
+	Endpoints     int       `json:"endpoints"`
+	PeakPerMinute int       `json:"peak_per_minute"`
+	Bytes         int64     `json:"bytes"`
+	LastSeen      time.Time `json:"last_seen"`
+}
+
+// Summarize groups requests by endpoint and caller, busiest first. Anonymous
+// callers are keyed by client IP.
+func Summarize(entries []Entry, since, until time.Time) Usage {
+	type endpointKey struct{ method, route string }
+	type endpointAcc struct {
+		durations []float64
+		users     map[string]bool
+		usage     EndpointUsage
+	}
+	type userAcc struct {
+		endpoints map[endpointKey]bool
+		minutes   map[int64]int
+		usage     UserUsage
+	}
+
+	endpoints := make(map[endpointKey]*endpointAcc)
+	users := make(map[string]*userAcc)
+	for _, e := range entries {
+		ek := endpointKey{e.Method, e.Route}
+		ea, ok := endpoints[ek]
+		if !ok {
+			ea = &endpointAcc{users: make(map[string]bool), usage: EndpointUsage{Method: e.Method, Route: e.Route}}
+			endpoints[ek] = ea
+		}
+		user, ip := e.User, ""
+		if user == "" {
+			user, ip = AnonymousUser, e.ClientIP
+		}
+		uk := user + "\x00" + ip
+		ea.usage.Requests++
+		ea.usage.Bytes += int64(e.Bytes)
+		if e.Status >= 400 {
+			ea.usage.Errors++
+		}
+		ea.durations = append(ea.durations, e.DurationMS)
+		ea.users[uk] = true
+
+		ua, ok := users[uk]
+		if !ok {
+			ua = &userAcc{
+				endpoints: make(map[endpointKey]bool),
+				minutes:   make(map[int64]int),
+				usage:     UserUsage{User: user, ClientIP: ip},
+			}
+			users[uk] = ua
+		}
+		ua.usage.Requests++
+		ua.usage.Bytes += int64(e.Bytes)
+		ua.endpoints[ek] = true
+		minute := e.Time.Unix() / 60
+		ua.minutes[minute]++
+		if n := ua.minutes[minute]; n > ua.usage.PeakPerMinute {
+			ua.usage.PeakPerMinute = n
+		}
+		if e.Time.After(ua.usage.LastSeen) {
+			ua.usage.LastSeen = e.Time
+		}
+	}
+
+	usage := Usage{
+		Since:     since,
+		Until:     until,
+		Requests:  len(entries),
+		Endpoints: make([]EndpointUsage, 0, len(endpoints)),
+		Users:     make([]UserUsage, 0, len(users)),
+	}
+	for _, ea := range endpoints {
+		ea.usage.Users = len(ea.users)
+		ea.usage.AvgMS, ea.usage.P95MS = durationStats(ea.durations)
+		usage.Endpoints = append(usage.Endpoints, ea.usage)
+	}
+	for _, ua := range users {
+		ua.usage.Endpoints = len(ua.endpoints)
+		usage.Users = append(usage.Users, ua.usage)
+	}
+	sort.Slice(usage.Endpoints, func(i, j int) bool {
+		a, b := usage.Endpoints[i], usage.Endpoints[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		return a.Method < b.Method
+	})
+	sort.Slice(usage.Users, func(i, j int) bool {
+		a, b := usage.Users[i], usage.Users[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		return a.ClientIP < b.ClientIP
+	})
+	return usage
+}
+
+// durationStats returns the mean and the 95th percentile, rounded to 0.1 ms
+func durationStats(durations []float64) (avg, p95 float64) {
+	if len(durations) == 0 {
+		return 0, 0
+	}
+	sorted := append([]float64(nil), durations...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, d := range sorted {
+		sum += d
+	}
+	idx := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	return math.Round(sum/float64(len(sorted))*10) / 10, math.Round(sorted[idx]*10) / 10
+}
@@ -0,0 +1,139 @@
+package alert
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// Meta alert kinds
+const (
+	MetaScanFailed     = "scan_failed"
+	MetaScanOverBudget = "scan_over_budget"
+	MetaCoverageDrop   = "coverage_drop"
+)
+
+// maxMetaAlerts bounds the number of meta alerts kept; the oldest are
+// dropped first
+const maxMetaAlerts = 100
+
+// MetaAlert is raised by the dashboard about its own scans, so stale data
+// does not go unnoticed
+type MetaAlert struct {
+	Kind     string    `json:"kind"`
+	Message  string    `json:"message"`
+	RaisedAt time.Time `json:"raised_at"`
+}
+
+// coverage summarizes what a completed scan returned
+type coverage struct {
+	quotas    int
+	withUsage int
+}
+
+// Monitor watches the scheduled scans for failures, scans running over their
+// duration budget and drops in coverage against the previous snapshot
+type Monitor struct {
+	budget      time.Duration
+	dropPercent float64
+
+	mu       sync.Mutex
+	previous *coverage
+	// reported is the start of the running scan already reported as over
+	// budget
+	reported time.Time
+	alerts   []MetaAlert
+}
+
+// NewMonitor creates a monitor. A zero budget or drop percentage disables
+// the respective check.
+func NewMonitor(budget time.Duration, dropPercent float64) *Monitor {
+	return &Monitor{budget: budget, dropPercent: dropPercent}
+}
+
+// ScanFailed records a scan that failed entirely
+func (m *Monitor) ScanFailed(err error, now time.Time) MetaAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.raise(MetaScanFailed, fmt.Sprintf("Scheduled scan failed: %v", err), now)
+}
+
+// CheckRunning reports a scan still running past the duration budget, once
+// per scan
+func (m *Monitor) CheckRunning(startedAt, now time.Time) (MetaAlert, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.budget <= 0 || now.Sub(startedAt) <= m.budget || m.reported.Equal(startedAt) {
+		return MetaAlert{}, false
+	}
+	m.reported = startedAt
+	return m.raise(MetaScanOverBudget, fmt.Sprintf("Scan started at %s is still running after %s (budget %s)",
+		startedAt.Format(time.RFC3339), now.Sub(startedAt).Round(time.Second), m.budget), now), true
+}
+
+// ScanCompleted checks a completed scan: one without any quota counts as
+// failed, one over the duration budget and one whose quota or usage coverage
+// dropped by more than the configured percentage against the previous scan
+// are reported
+func (m *Monitor) ScanCompleted(startedAt, completedAt time.Time, quotas []model.Quota) []MetaAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var alerts []MetaAlert
+	if len(quotas) == 0 {
+		return append(alerts, m.raise(MetaScanFailed, "Scheduled scan completed without any quota", completedAt))
+	}
+	if took := completedAt.Sub(startedAt); m.budget > 0 && took > m.budget && !m.reported.Equal(startedAt) {
+		alerts = append(alerts, m.raise(MetaScanOverBudget, fmt.Sprintf("Scan took %s (budget %s)",
+			took.Round(time.Second), m.budget), completedAt))
+	}
+
+	current := &coverage{quotas: len(quotas)}
+	for _, q := range quotas {
+		if q.HasUsageMetrics {
+			current.withUsage++
+		}
+	}
+	if prev := m.previous; prev != nil && m.dropPercent > 0 {
+		if drop := dropPercent(prev.quotas, current.quotas); drop > m.dropPercent {
+			alerts = append(alerts, m.raise(MetaCoverageDrop, fmt.Sprintf("Quotas returned dropped by %.0f%% (%d → %d) since the previous scan",
+				drop, prev.quotas, current.quotas), completedAt))
+		}
+		if drop := dropPercent(prev.withUsage, current.withUsage); drop > m.dropPercent {
+			alerts = append(alerts, m.raise(MetaCoverageDrop, fmt.Sprintf("Quotas with usage data dropped by %.0f%% (%d → %d) since the previous scan",
+				drop, prev.withUsage, current.withUsage), completedAt))
+		}
+	}
+	m.previous = current
+	return alerts
+}
+
+// Recent returns the meta alerts raised, newest first
+func (m *Monitor) Recent() []MetaAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	recent := make([]MetaAlert, 0, len(m.alerts))
+	for i := len(m.alerts) - 1; i >= 0; i-- {
+		recent = append(recent, m.alerts[i])
+	}
+	return recent
+}
+
+func (m *Monitor) raise(kind, message string, now time.Time) MetaAlert {
+	a := MetaAlert{Kind: kind, Message: message, RaisedAt: now}
+	m.alerts = append(m.alerts, a)
+	if len(m.alerts) > maxMetaAlerts {
+		m.alerts = m.alerts[len(m.alerts)-maxMetaAlerts:]
+	}
+	return a
+}
+
+// dropPercent returns by how many percent current is below previous
+func dropPercent(previous, current int) float64 {
+	if previous <= 0 || current >= previous {
+		return 0
+	}
+	return float64(previous-current) / float64(previous) * 100
+}
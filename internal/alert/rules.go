@@ -0,0 +1,108 @@
+// Package alert evaluates usage threshold rules against quota snapshots
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/version"
+)
+
+// DefaultSeverity is used for rules that do not set one
+const DefaultSeverity = "warning"
+
+// Alert is a rule firing for a single quota
+type Alert struct {
+	Rule            string  `json:"rule"`
+	Severity        string  `json:"severity"`
+	Threshold       float64 `json:"threshold"`
+	AccountID       string  `json:"account_id,omitempty"`
+	Region          string  `json:"region"`
+	ServiceCode     string  `json:"service_code"`
+	QuotaCode       string  `json:"quota_code"`
+	QuotaName       string  `json:"quota_name"`
+	Usage           float64 `json:"usage"`
+	Value           float64 `json:"value"`
+	UsagePercentage float64 `json:"usage_percentage"`
+	Owner           string  `json:"owner,omitempty"`
+	// Resolved is set on the notification of a quota recovering below its
+	// threshold
+	Resolved bool `json:"resolved,omitempty"`
+	// Version is the dashboard build that raised the alert
+	Version string `json:"dashboard_version"`
+}
+
+// Validate checks that every rule is named uniquely and has a usable threshold
+func Validate(rules []config.AlertRule) error {
+	seen := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if r.Name == "" {
+			return fmt.Errorf("alert rule without a name")
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("duplicate alert rule %q", r.Name)
+		}
+		seen[r.Name] = true
+		if r.Threshold <= 0 || r.Threshold > 100 {
+			return fmt.Errorf("alert rule %q: threshold must be between 0 and 100, got %v", r.Name, r.Threshold)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the alerts the rules fire for the given quotas, highest
+// usage first. Quotas without usage data never fire; a quota's annotated
+// threshold replaces the threshold of every rule in scope.
+func Evaluate(rules []config.AlertRule, quotas []model.Quota) []Alert {
+	alerts := []Alert{}
+	for _, r := range rules {
+		severity := r.Severity
+		if severity == "" {
+			severity = DefaultSeverity
+		}
+		for _, q := range quotas {
+			threshold := r.Threshold
+			if q.AlertThreshold > 0 {
+				threshold = q.AlertThreshold
+			}
+			if !q.HasUsageMetrics || q.UsagePercentage < threshold || !matches(r, q) {
+				continue
+			}
+			alerts = append(alerts, Alert{
+				Rule:            r.Name,
+				Severity:        severity,
+				Threshold:       threshold,
+				AccountID:       q.AccountID,
+				Region:          q.Region,
+				ServiceCode:     q.ServiceCode,
+				QuotaCode:       q.QuotaCode,
+				QuotaName:       q.QuotaName,
+				Usage:           q.Usage,
+				Value:           q.Value,
+				UsagePercentage: q.UsagePercentage,
+				Owner:           q.Owner,
+				Version:         version.String(),
+			})
+		}
+	}
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].UsagePercentage > alerts[j].UsagePercentage })
+	return alerts
+}
+
+// matches reports whether a quota is in the scope of a rule; empty scope
+// fields match everything
+func matches(r config.AlertRule, q model.Quota) bool {
+	if r.Service != "" && !strings.EqualFold(r.Service, q.ServiceCode) {
+		return false
+	}
+	if r.QuotaCode != "" && r.QuotaCode != q.QuotaCode {
+		return false
+	}
+	if r.Region != "" && r.Region != q.Region {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/persist"
+	"github.com/yuxishi/aws-quota-dashboard/internal/randid"
+)
+
+// ErrSnoozeNotFound is returned for unknown or expired snoozes
+var ErrSnoozeNotFound = errors.New("snooze not found")
+
+// Snooze silences the alerts of one quota until it expires. An empty Rule
+// silences every rule.
+type Snooze struct {
+	ID          string     `json:"id"`
+	Rule        string     `json:"rule,omitempty"`
+	AccountID   string     `json:"account_id,omitempty"`
+	Region      string     `json:"region"`
+	ServiceCode string     `json:"service_code"`
+	QuotaCode   string     `json:"quota_code"`
+	Reason      string     `json:"reason,omitempty"`
+	SnoozedBy   string     `json:"snoozed_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Until       time.Time  `json:"until"`
+	RemindedAt  *time.Time `json:"reminded_at,omitempty"`
+}
+
+// covers reports whether the snooze silences an alert
+func (s *Snooze) covers(a Alert) bool {
+	return (s.Rule == "" || s.Rule == a.Rule) &&
+		s.AccountID == a.AccountID &&
+		s.Region == a.Region &&
+		s.ServiceCode == a.ServiceCode &&
+		s.QuotaCode == a.QuotaCode
+}
+
+// Snoozes keeps the active snoozes. Expired snoozes are dropped lazily.
+type Snoozes struct {
+	mu      sync.Mutex
+	snoozes map[string]*Snooze
+	doc     *persist.Doc
+}
+
+func NewSnoozes() *Snoozes {
+	return &Snoozes{
+		snoozes: make(map[string]*Snooze),
+	}
+}
+
+// Persist loads the snoozes saved to a document and saves every later change
+// to it
+func (s *Snoozes) Persist(ctx context.Context, doc *persist.Doc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := doc.Load(ctx, &s.snoozes); err != nil {
+		return err
+	}
+	s.doc = doc
+	return nil
+}
+
+// Add registers a snooze, assigning its ID and creation time
+func (s *Snoozes) Add(snooze Snooze, now time.Time) (Snooze, error) {
+	if snooze.Region == "" || snooze.ServiceCode == "" || snooze.QuotaCode == "" {
+		return Snooze{}, fmt.Errorf("region, service_code and quota_code are required")
+	}
+	if !snooze.Until.After(now) {
+		return Snooze{}, fmt.Errorf("snooze must end in the future")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snooze.ID = randid.New()
+	snooze.CreatedAt = now
+	snooze.RemindedAt = nil
+	s.snoozes[snooze.ID] = &snooze
+	s.doc.Save(s.snoozes)
+	return snooze, nil
+}
+
+// Delete ends a snooze early
+func (s *Snoozes) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.snoozes[id]; !ok {
+		return ErrSnoozeNotFound
+	}
+	delete(s.snoozes, id)
+	s.doc.Save(s.snoozes)
+	return nil
+}
+
+// List returns the active snoozes, soonest to expire first
+func (s *Snoozes) List(now time.Time) []Snooze {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	list := make([]Snooze, 0, len(s.snoozes))
+	for _, snooze := range s.snoozes {
+		list = append(list, *snooze)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Until.Before(list[j].Until) })
+	return list
+}
+
+// Filter removes the alerts silenced by an active snooze and returns the
+// remaining alerts and the number removed
+func (s *Snoozes) Filter(alerts []Alert, now time.Time) ([]Alert, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	kept := make([]Alert, 0, len(alerts))
+	for _, a := range alerts {
+		if !s.coversLocked(a) {
+			kept = append(kept, a)
+		}
+	}
+	return kept, len(alerts) - len(kept)
+}
+
+// Silenced reports whether an active snooze of every rule covers a quota, so
+// its threshold notifications are silenced too
+func (s *Snoozes) Silenced(accountID, region, serviceCode, quotaCode string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	return s.coversLocked(Alert{AccountID: accountID, Region: region, ServiceCode: serviceCode, QuotaCode: quotaCode})
+}
+
+func (s *Snoozes) coversLocked(a Alert) bool {
+	for _, snooze := range s.snoozes {
+		if snooze.covers(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// Due returns the snoozes expiring within lead that have not been reminded
+// about yet, soonest to expire first
+func (s *Snoozes) Due(now time.Time, lead time.Duration) []Snooze {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	due := make([]Snooze, 0)
+	for _, snooze := range s.snoozes {
+		if snooze.RemindedAt == nil && !snooze.Until.After(now.Add(lead)) {
+			due = append(due, *snooze)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Until.Before(due[j].Until) })
+	return due
+}
+
+// MarkReminded records that the expiry reminder of a snooze was sent
+func (s *Snoozes) MarkReminded(id string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if snooze, ok := s.snoozes[id]; ok {
+		snooze.RemindedAt = &at
+		s.doc.Save(s.snoozes)
+	}
+}
+
+func (s *Snoozes) prune(now time.Time) {
+	for id, snooze := range s.snoozes {
+		if !snooze.Until.After(now) {
+			delete(s.snoozes, id)
+		}
+	}
+}
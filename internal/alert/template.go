@@ -0,0 +1,146 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Notifiers alert messages are rendered for
+const (
+	NotifierSlack   = "slack"
+	NotifierTeams   = "teams"
+	NotifierEmail   = "email"
+	NotifierWebhook = "webhook"
+)
+
+// emailSubjectPrefix starts the first line of an email message that is its
+// subject
+const emailSubjectPrefix = "Subject: "
+
+// defaultTemplates are used for notifiers without a configured template
+var defaultTemplates = map[string]string{
+	NotifierSlack: "*{{.QuotaName}}* ({{.QuotaCode}}) in {{if .AccountID}}{{.AccountID}}/{{end}}{{.Region}} " +
+		"{{if .Resolved}}recovered to{{else}}is {{.Severity}} at{{end}} " +
+		"{{printf \"%.1f\" .UsagePercentage}}% ({{.Usage}} of {{.Value}}){{if .Rule}}, rule `{{.Rule}}`{{end}}",
+	NotifierTeams: "{{.QuotaName}} ({{.QuotaCode}}) in {{if .AccountID}}{{.AccountID}}/{{end}}{{.Region}} " +
+		"{{if .Resolved}}recovered to{{else}}is {{.Severity}} at{{end}} " +
+		"{{printf \"%.1f\" .UsagePercentage}}% ({{.Usage}} of {{.Value}}){{if .Rule}}, rule {{.Rule}}{{end}}",
+	NotifierEmail: "Subject: [AWS quotas] {{.QuotaName}} " +
+		"{{if .Resolved}}recovered{{else}}is {{.Severity}} at {{printf \"%.1f\" .UsagePercentage}}%{{end}} in {{.Region}}\n\n" +
+		"The quota {{.QuotaName}} ({{.ServiceCode}}/{{.QuotaCode}}) in " +
+		"{{if .AccountID}}account {{.AccountID}}, {{end}}region {{.Region}} uses {{.Usage}} of {{.Value}} " +
+		"({{printf \"%.1f\" .UsagePercentage}}%), {{if .Resolved}}back below{{else}}reaching{{end}} the {{.Threshold}}% threshold" +
+		"{{if .Rule}} of rule {{.Rule}}{{end}}.{{if .Owner}}\n\nOwner: {{.Owner}}{{end}}\n",
+	NotifierWebhook: "{{json .}}",
+}
+
+// templateFuncs are available to message templates in addition to the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Templates holds the parsed alert message template of every notifier
+type Templates struct {
+	parsed map[string]*template.Template
+	custom map[string]bool
+}
+
+// NewTemplates parses the configured message templates, keyed by notifier.
+// Notifiers without one use the built-in template.
+func NewTemplates(custom map[string]string) (*Templates, error) {
+	t := &Templates{
+		parsed: make(map[string]*template.Template, len(defaultTemplates)),
+		custom: make(map[string]bool, len(custom)),
+	}
+	for notifier := range custom {
+		if _, ok := defaultTemplates[notifier]; !ok {
+			return nil, fmt.Errorf("alert template for unknown notifier %q", notifier)
+		}
+	}
+	for notifier, text := range defaultTemplates {
+		if c, ok := custom[notifier]; ok {
+			text = c
+			t.custom[notifier] = true
+		}
+		tmpl, err := template.New(notifier).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s alert template: %w", notifier, err)
+		}
+		// Fields that do not exist only fail when executed; catch them now
+		if err := tmpl.Execute(&bytes.Buffer{}, Alert{}); err != nil {
+			return nil, fmt.Errorf("invalid %s alert template: %w", notifier, err)
+		}
+		t.parsed[notifier] = tmpl
+	}
+	return t, nil
+}
+
+// DefaultTemplates returns the built-in templates of every notifier
+func DefaultTemplates() *Templates {
+	t, err := NewTemplates(nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Customized reports whether the template of a notifier is configured rather
+// than built in
+func (t *Templates) Customized(notifier string) bool {
+	return t.custom[notifier]
+}
+
+// Notifiers returns the notifiers with a template, sorted by name
+func (t *Templates) Notifiers() []string {
+	names := make([]string, 0, len(t.parsed))
+	for name := range t.parsed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render executes the template of a notifier for an alert
+func (t *Templates) Render(notifier string, a Alert) (string, error) {
+	tmpl, ok := t.parsed[notifier]
+	if !ok {
+		return "", fmt.Errorf("unknown notifier %q", notifier)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, a); err != nil {
+		return "", fmt.Errorf("failed to render %s alert template: %w", notifier, err)
+	}
+	return buf.String(), nil
+}
+
+// RenderAll renders the message of every notifier for an alert
+func (t *Templates) RenderAll(a Alert) (map[string]string, error) {
+	messages := make(map[string]string, len(t.parsed))
+	for _, notifier := range t.Notifiers() {
+		msg, err := t.Render(notifier, a)
+		if err != nil {
+			return nil, err
+		}
+		messages[notifier] = msg
+	}
+	return messages, nil
+}
+
+// SplitSubject splits a rendered email message into the subject on its
+// "Subject: " first line, if any, and the body after it
+func SplitSubject(msg string) (subject, body string) {
+	if !strings.HasPrefix(msg, emailSubjectPrefix) {
+		return "", msg
+	}
+	subject, body, _ = strings.Cut(strings.TrimPrefix(msg, emailSubjectPrefix), "\n")
+	return strings.TrimSpace(subject), strings.TrimLeft(body, "\n")
+}
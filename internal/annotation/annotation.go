@@ -0,0 +1,222 @@
+// Package annotation keeps the operator-maintained metadata of quotas: owners,
+// notes, runbooks and alert threshold overrides.
+package annotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/persist"
+)
+
+// ErrNotFound is returned for quotas without an annotation
+var ErrNotFound = errors.New("annotation not found")
+
+// Annotation describes one quota. An empty AccountID or Region matches every
+// account or region, so a single row can cover a quota everywhere.
+type Annotation struct {
+	AccountID   string `json:"account_id,omitempty"`
+	Region      string `json:"region,omitempty"`
+	ServiceCode string `json:"service_code"`
+	QuotaCode   string `json:"quota_code"`
+	Owner       string `json:"owner,omitempty"`
+	Note        string `json:"note,omitempty"`
+	Runbook     string `json:"runbook,omitempty"`
+	// Threshold overrides the usage percentage at which alert rules fire
+	// and the quota turns critical; zero keeps the configured thresholds
+	Threshold float64   `json:"threshold,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Key identifies the quotas an annotation applies to
+type Key struct {
+	AccountID   string
+	Region      string
+	ServiceCode string
+	QuotaCode   string
+}
+
+// KeyOf returns the key of an annotation
+func KeyOf(a Annotation) Key {
+	return Key{
+		AccountID:   a.AccountID,
+		Region:      a.Region,
+		ServiceCode: strings.ToLower(a.ServiceCode),
+		QuotaCode:   a.QuotaCode,
+	}
+}
+
+// Validate checks that an annotation identifies a quota and has a usable
+// threshold
+func (a Annotation) Validate() error {
+	if a.ServiceCode == "" || a.QuotaCode == "" {
+		return fmt.Errorf("service_code and quota_code are required")
+	}
+	if a.Threshold < 0 || a.Threshold > 100 {
+		return fmt.Errorf("annotation of %s/%s: threshold must be between 0 and 100, got %v", a.ServiceCode, a.QuotaCode, a.Threshold)
+	}
+	return nil
+}
+
+// Annotations keeps the quota annotations
+type Annotations struct {
+	mu          sync.RWMutex
+	annotations map[Key]Annotation
+	doc         *persist.Doc
+}
+
+func NewAnnotations() *Annotations {
+	return &Annotations{
+		annotations: make(map[Key]Annotation),
+	}
+}
+
+// Persist loads the annotations saved to a document and saves every later
+// change to it
+func (s *Annotations) Persist(ctx context.Context, doc *persist.Doc) error {
+	var saved []Annotation
+	if _, err := doc.Load(ctx, &saved); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range saved {
+		s.annotations[KeyOf(a)] = a
+	}
+	s.doc = doc
+	return nil
+}
+
+// save saves the annotations as a list, as their keys are no JSON object keys
+func (s *Annotations) save() {
+	if s.doc == nil {
+		return
+	}
+	list := make([]Annotation, 0, len(s.annotations))
+	for _, a := range s.annotations {
+		list = append(list, a)
+	}
+	s.doc.Save(list)
+}
+
+// Set creates or replaces the annotation of a quota
+func (s *Annotations) Set(a Annotation, now time.Time) (Annotation, error) {
+	if err := a.Validate(); err != nil {
+		return Annotation{}, err
+	}
+	a.UpdatedAt = now
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.annotations[KeyOf(a)] = a
+	s.save()
+	return a, nil
+}
+
+// Import creates or replaces annotations in bulk. Nothing is imported unless
+// every annotation is valid; with replace, annotations missing from the
+// import are deleted.
+func (s *Annotations) Import(annotations []Annotation, replace bool, now time.Time) (int, error) {
+	imported := make(map[Key]Annotation, len(annotations))
+	for i, a := range annotations {
+		if err := a.Validate(); err != nil {
+			return 0, fmt.Errorf("annotation %d: %w", i+1, err)
+		}
+		a.UpdatedAt = now
+		imported[KeyOf(a)] = a
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if replace {
+		s.annotations = make(map[Key]Annotation, len(imported))
+	}
+	for key, a := range imported {
+		s.annotations[key] = a
+	}
+	s.save()
+	return len(imported), nil
+}
+
+// Delete removes the annotation with the given key
+func (s *Annotations) Delete(key Key) error {
+	key.ServiceCode = strings.ToLower(key.ServiceCode)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.annotations[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.annotations, key)
+	s.save()
+	return nil
+}
+
+// List returns the annotations ordered by service, quota, account and region
+func (s *Annotations) List() []Annotation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]Annotation, 0, len(s.annotations))
+	for _, a := range s.annotations {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := KeyOf(list[i]), KeyOf(list[j])
+		if a.ServiceCode != b.ServiceCode {
+			return a.ServiceCode < b.ServiceCode
+		}
+		if a.QuotaCode != b.QuotaCode {
+			return a.QuotaCode < b.QuotaCode
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		return a.Region < b.Region
+	})
+	return list
+}
+
+// lookup returns the most specific annotation of a quota: one for its account
+// and region, then its account, then its region, then every quota of its code
+func (s *Annotations) lookup(q model.Quota) (Annotation, bool) {
+	service := strings.ToLower(q.ServiceCode)
+	for _, key := range []Key{
+		{AccountID: q.AccountID, Region: q.Region, ServiceCode: service, QuotaCode: q.QuotaCode},
+		{AccountID: q.AccountID, ServiceCode: service, QuotaCode: q.QuotaCode},
+		{Region: q.Region, ServiceCode: service, QuotaCode: q.QuotaCode},
+		{ServiceCode: service, QuotaCode: q.QuotaCode},
+	} {
+		if a, ok := s.annotations[key]; ok {
+			return a, true
+		}
+	}
+	return Annotation{}, false
+}
+
+// Apply returns a copy of quotas with their annotations filled in. An
+// annotated owner replaces the owner derived from tags.
+func (s *Annotations) Apply(quotas []model.Quota) []model.Quota {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.annotations) == 0 {
+		return quotas
+	}
+
+	result := make([]model.Quota, len(quotas))
+	for i, q := range quotas {
+		if a, ok := s.lookup(q); ok {
+			if a.Owner != "" {
+				q.Owner = a.Owner
+			}
+			q.Note, q.Runbook, q.AlertThreshold = a.Note, a.Runbook, a.Threshold
+		}
+		result[i] = q
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package annotation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation Annotation
+		wantErr    bool
+	}{
+		{name: "valid", annotation: Annotation{ServiceCode: "ec2", QuotaCode: "L-1216C47A", Threshold: 80}},
+		{name: "no threshold", annotation: Annotation{ServiceCode: "ec2", QuotaCode: "L-1216C47A"}},
+		{name: "threshold of 100", annotation: Annotation{ServiceCode: "ec2", QuotaCode: "L-1216C47A", Threshold: 100}},
+		{name: "missing service", annotation: Annotation{QuotaCode: "L-1216C47A"}, wantErr: true},
+		{name: "missing quota", annotation: Annotation{ServiceCode: "ec2"}, wantErr: true},
+		{name: "negative threshold", annotation: Annotation{ServiceCode: "ec2", QuotaCode: "L-1216C47A", Threshold: -1}, wantErr: true},
+		{name: "threshold above 100", annotation: Annotation{ServiceCode: "ec2", QuotaCode: "L-1216C47A", Threshold: 101}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.annotation.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImportJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr string
+	}{
+		{
+			name:  "annotations",
+			input: `[{"service_code":"ec2","quota_code":"L-1216C47A","owner":"team-a","threshold":85},{"account_id":"123456789012","service_code":"ec2","quota_code":"L-1216C47A"}]`,
+			want:  2,
+		},
+		{
+			name:  "duplicate keys count once",
+			input: `[{"service_code":"EC2","quota_code":"L-1216C47A"},{"service_code":"ec2","quota_code":"L-1216C47A","owner":"team-b"}]`,
+			want:  1,
+		},
+		{
+			name:    "invalid annotation",
+			input:   `[{"service_code":"ec2","quota_code":"L-1216C47A"},{"service_code":"ec2"}]`,
+			wantErr: "annotation 2:",
+		},
+		{
+			name:    "invalid threshold",
+			input:   `[{"service_code":"ec2","quota_code":"L-1216C47A","threshold":150}]`,
+			wantErr: "threshold must be between 0 and 100",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var annotations []Annotation
+			if err := json.Unmarshal([]byte(tt.input), &annotations); err != nil {
+				t.Fatal(err)
+			}
+			s := NewAnnotations()
+			got, err := s.Import(annotations, false, time.Now())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Import error = %v, want %q", err, tt.wantErr)
+				}
+				if n := len(s.List()); n != 0 {
+					t.Errorf("failed import kept %d annotations", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if got != tt.want || len(s.List()) != tt.want {
+				t.Errorf("Import = %d (%d listed), want %d", got, len(s.List()), tt.want)
+			}
+		})
+	}
+}
+
+func TestImportReplace(t *testing.T) {
+	now := time.Now()
+	s := NewAnnotations()
+	if _, err := s.Set(Annotation{ServiceCode: "ec2", QuotaCode: "L-1216C47A"}, now); err != nil {
+		t.Fatal(err)
+	}
+	imported := []Annotation{{ServiceCode: "lambda", QuotaCode: "L-B99A9384"}}
+
+	if _, err := s.Import(imported, false, now); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.List()); n != 2 {
+		t.Errorf("merge import kept %d annotations, want 2", n)
+	}
+	if _, err := s.Import(imported, true, now); err != nil {
+		t.Fatal(err)
+	}
+	if list := s.List(); len(list) != 1 || list[0].ServiceCode != "lambda" {
+		t.Errorf("replace import kept %+v, want only the imported annotation", list)
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Now()
+	s := NewAnnotations()
+	for _, a := range []Annotation{
+		{ServiceCode: "ec2", QuotaCode: "L-1216C47A", Note: "everywhere", Threshold: 90},
+		{Region: "eu-west-1", ServiceCode: "ec2", QuotaCode: "L-1216C47A", Note: "region"},
+		{AccountID: "111111111111", ServiceCode: "EC2", QuotaCode: "L-1216C47A", Note: "account", Owner: "team-a"},
+		{AccountID: "111111111111", Region: "eu-west-1", ServiceCode: "ec2", QuotaCode: "L-1216C47A", Note: "account and region", Threshold: 70},
+	} {
+		if _, err := s.Set(a, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		quota     model.Quota
+		note      string
+		owner     string
+		threshold float64
+	}{
+		{name: "account and region", quota: model.Quota{AccountID: "111111111111", Region: "eu-west-1"}, note: "account and region", owner: "tagged", threshold: 70},
+		{name: "account", quota: model.Quota{AccountID: "111111111111", Region: "us-east-1"}, note: "account", owner: "team-a"},
+		{name: "region", quota: model.Quota{AccountID: "222222222222", Region: "eu-west-1"}, note: "region", owner: "tagged"},
+		{name: "everywhere", quota: model.Quota{AccountID: "222222222222", Region: "us-east-1"}, note: "everywhere", owner: "tagged", threshold: 90},
+		{name: "other quota", quota: model.Quota{AccountID: "111111111111", Region: "eu-west-1", QuotaCode: "L-0263D0A3"}, owner: "tagged"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.quota
+			q.ServiceCode, q.Owner = "ec2", "tagged"
+			if q.QuotaCode == "" {
+				q.QuotaCode = "L-1216C47A"
+			}
+			got := s.Apply([]model.Quota{q})[0]
+			if got.Note != tt.note || got.Owner != tt.owner || got.AlertThreshold != tt.threshold {
+				t.Errorf("Apply = note %q owner %q threshold %v, want note %q owner %q threshold %v",
+					got.Note, got.Owner, got.AlertThreshold, tt.note, tt.owner, tt.threshold)
+			}
+		})
+	}
+}
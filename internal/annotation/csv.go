@@ -0,0 +1,96 @@
+package annotation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvColumns are the columns of an annotation sheet, in export order
+var csvColumns = []string{"account_id", "region", "service_code", "quota_code", "owner", "note", "runbook", "threshold"}
+
+// WriteCSV writes annotations as a sheet with a header row
+func WriteCSV(w io.Writer, annotations []Annotation) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, a := range annotations {
+		threshold := ""
+		if a.Threshold > 0 {
+			threshold = strconv.FormatFloat(a.Threshold, 'f', -1, 64)
+		}
+		if err := cw.Write([]string{a.AccountID, a.Region, a.ServiceCode, a.QuotaCode, a.Owner, a.Note, a.Runbook, threshold}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads an annotation sheet. The header row names the columns, which
+// may come in any order; only service_code and quota_code are required.
+func ReadCSV(r io.Reader) ([]Annotation, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("empty annotation sheet")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(csvColumns))
+	for _, col := range csvColumns {
+		known[col] = true
+	}
+	index := make(map[string]int, len(header))
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if !known[col] {
+			return nil, fmt.Errorf("unknown annotation column %q", col)
+		}
+		index[col] = i
+	}
+	for _, col := range []string{"service_code", "quota_code"} {
+		if _, ok := index[col]; !ok {
+			return nil, fmt.Errorf("annotation sheet is missing the %s column", col)
+		}
+	}
+
+	var annotations []Annotation
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return annotations, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(col string) string {
+			if i, ok := index[col]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		a := Annotation{
+			AccountID:   field("account_id"),
+			Region:      field("region"),
+			ServiceCode: field("service_code"),
+			QuotaCode:   field("quota_code"),
+			Owner:       field("owner"),
+			Note:        field("note"),
+			Runbook:     field("runbook"),
+		}
+		if threshold := strings.TrimSuffix(field("threshold"), "%"); threshold != "" {
+			if a.Threshold, err = strconv.ParseFloat(threshold, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid threshold %q", line, field("threshold"))
+			}
+		}
+		annotations = append(annotations, a)
+	}
+}
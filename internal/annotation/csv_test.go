@@ -0,0 +1,100 @@
+package annotation
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Annotation
+		wantErr string
+	}{
+		{
+			name:  "all columns",
+			input: "account_id,region,service_code,quota_code,owner,note,runbook,threshold\n123456789012,us-east-1,ec2,L-1216C47A,team-a,burst capacity,https://wiki/ec2,85\n",
+			want: []Annotation{{
+				AccountID: "123456789012", Region: "us-east-1", ServiceCode: "ec2", QuotaCode: "L-1216C47A",
+				Owner: "team-a", Note: "burst capacity", Runbook: "https://wiki/ec2", Threshold: 85,
+			}},
+		},
+		{
+			name:  "columns in any order and case",
+			input: "Quota_Code, SERVICE_CODE ,owner\nL-0263D0A3,ec2,team-b\n",
+			want:  []Annotation{{ServiceCode: "ec2", QuotaCode: "L-0263D0A3", Owner: "team-b"}},
+		},
+		{
+			name:  "percent threshold and trimmed fields",
+			input: "service_code,quota_code,threshold\n lambda , L-B99A9384 , 90.5%\n",
+			want:  []Annotation{{ServiceCode: "lambda", QuotaCode: "L-B99A9384", Threshold: 90.5}},
+		},
+		{
+			name:  "empty threshold",
+			input: "service_code,quota_code,threshold\nec2,L-1216C47A,\n",
+			want:  []Annotation{{ServiceCode: "ec2", QuotaCode: "L-1216C47A"}},
+		},
+		{
+			name:  "header only",
+			input: "service_code,quota_code\n",
+		},
+		{
+			name:    "empty sheet",
+			input:   "",
+			wantErr: "empty annotation sheet",
+		},
+		{
+			name:    "unknown column",
+			input:   "service_code,quota_code,team\nec2,L-1216C47A,a\n",
+			wantErr: `unknown annotation column "team"`,
+		},
+		{
+			name:    "missing quota code column",
+			input:   "service_code,owner\nec2,team-a\n",
+			wantErr: "missing the quota_code column",
+		},
+		{
+			name:    "invalid threshold",
+			input:   "service_code,quota_code,threshold\nec2,L-1216C47A,85\nec2,L-0263D0A3,high\n",
+			wantErr: `line 3: invalid threshold "high"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCSV(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReadCSV error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadCSV: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadCSV = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	annotations := []Annotation{
+		{AccountID: "123456789012", Region: "us-east-1", ServiceCode: "ec2", QuotaCode: "L-1216C47A", Owner: "team-a", Note: "a, quoted \"note\"", Threshold: 85},
+		{ServiceCode: "lambda", QuotaCode: "L-B99A9384", Runbook: "https://wiki/lambda"},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, annotations); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, annotations) {
+		t.Errorf("round trip = %+v, want %+v", got, annotations)
+	}
+}
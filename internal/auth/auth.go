@@ -0,0 +1,167 @@
+// Package auth authenticates API callers with OIDC ID tokens and maps their
+// IdP groups to dashboard roles, so access is managed in the IdP
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+)
+
+// Role is a dashboard role; each role includes the rights of the lower ones
+type Role int
+
+// Dashboard roles, lowest first
+const (
+	RoleNone Role = iota
+	// RoleViewer reads quotas, history and reports
+	RoleViewer
+	// RoleOperator also refreshes quotas, files increase requests and
+	// handles alerts and reviews
+	RoleOperator
+	// RoleAdmin also changes dashboard settings such as annotations and
+	// imported snapshots
+	RoleAdmin
+)
+
+// DefaultGroupsClaim is the ID token claim holding the user's groups
+const DefaultGroupsClaim = "groups"
+
+var roleNames = map[Role]string{
+	RoleNone:     "none",
+	RoleViewer:   "viewer",
+	RoleOperator: "operator",
+	RoleAdmin:    "admin",
+}
+
+// ParseRole parses a role name
+func ParseRole(name string) (Role, error) {
+	for role, n := range roleNames {
+		if role != RoleNone && strings.EqualFold(name, n) {
+			return role, nil
+		}
+	}
+	return RoleNone, fmt.Errorf("unknown role %q (want viewer, operator or admin)", name)
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("role(%d)", int(r))
+}
+
+// MarshalJSON renders the role by name
+func (r Role) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// Identity is an authenticated caller
+type Identity struct {
+	Subject string   `json:"subject"`
+	Email   string   `json:"email,omitempty"`
+	Groups  []string `json:"groups"`
+	Role    Role     `json:"role"`
+}
+
+// Verifier verifies ID tokens and resolves the caller's role
+type Verifier struct {
+	verifier    *oidc.IDTokenVerifier
+	groupsClaim string
+	groups      map[string]Role
+	defaultRole Role
+}
+
+// NewVerifier discovers the issuer's signing keys and validates the group
+// mapping
+func NewVerifier(ctx context.Context, cfg config.OIDCConfig) (*Verifier, error) {
+	if cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return nil, fmt.Errorf("oidc requires issuer_url and client_id")
+	}
+	v := &Verifier{
+		groupsClaim: cfg.GroupsClaim,
+		groups:      make(map[string]Role, len(cfg.Groups)),
+	}
+	if v.groupsClaim == "" {
+		v.groupsClaim = DefaultGroupsClaim
+	}
+	for group, name := range cfg.Groups {
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("oidc group %q: %w", group, err)
+		}
+		v.groups[group] = role
+	}
+	if cfg.DefaultRole != "" {
+		role, err := ParseRole(cfg.DefaultRole)
+		if err != nil {
+			return nil, fmt.Errorf("oidc default_role: %w", err)
+		}
+		v.defaultRole = role
+	}
+
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover oidc issuer: %w", err)
+	}
+	v.verifier = provider.Verifier(&oidc.Config{ClientID: cfg.ClientID})
+	return v, nil
+}
+
+// Verify checks an ID token and returns the caller with the role of their
+// groups. Callers without any role are rejected.
+func (v *Verifier) Verify(ctx context.Context, rawToken string) (*Identity, error) {
+	token, err := v.verifier.Verify(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+	var claims map[string]interface{}
+	if err := token.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{Subject: token.Subject, Groups: groupsOf(claims[v.groupsClaim])}
+	if email, ok := claims["email"].(string); ok {
+		identity.Email = email
+	}
+	identity.Role = v.roleOf(identity.Groups)
+	if identity.Role == RoleNone {
+		return nil, fmt.Errorf("no dashboard role is mapped to the groups of %s", identity.Subject)
+	}
+	return identity, nil
+}
+
+// roleOf returns the highest role mapped to any of the groups, or the
+// default role
+func (v *Verifier) roleOf(groups []string) Role {
+	role := v.defaultRole
+	for _, group := range groups {
+		if r := v.groups[group]; r > role {
+			role = r
+		}
+	}
+	return role
+}
+
+// groupsOf reads a groups claim, which IdPs send either as a list or as a
+// single string
+func groupsOf(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		groups := make([]string, 0, len(value))
+		for _, g := range value {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+		return groups
+	default:
+		return []string{}
+	}
+}
@@ -0,0 +1,306 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cttypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"golang.org/x/time/rate"
+)
+
+// CreateEvent identifies the CloudTrail event that creates a resource counted
+// against a quota
+type CreateEvent struct {
+	EventSource string
+	EventName   string
+}
+
+// QuotaCodeToCreateEvents maps count-based quota codes (or QuotaNameKeys) to
+// the CloudTrail events that consume them, used to attribute usage growth to principals
+var QuotaCodeToCreateEvents = map[string][]CreateEvent{
+	// EKS
+	"L-1194D53C": {{"eks.amazonaws.com", "CreateCluster"}},
+	"L-6D3F50E6": {{"eks.amazonaws.com", "CreateNodegroup"}},
+
+	// EC2
+	"L-1216C47A":                   {{"ec2.amazonaws.com", "RunInstances"}},
+	"L-DB2E81BA":                   {{"ec2.amazonaws.com", "RunInstances"}},
+	"L-417A185B":                   {{"ec2.amazonaws.com", "RunInstances"}},
+	"L-7295265B":                   {{"ec2.amazonaws.com", "RunInstances"}},
+	"L-74FC7D96":                   {{"ec2.amazonaws.com", "RunInstances"}},
+	"L-1945791B":                   {{"ec2.amazonaws.com", "RunInstances"}},
+	"L-2C3B7624":                   {{"ec2.amazonaws.com", "RunInstances"}},
+	"L-0263D0A3":                   {{"ec2.amazonaws.com", "AllocateAddress"}},
+	"L-0E3CBAB9":                   {{"ec2.amazonaws.com", "CreateKeyPair"}, {"ec2.amazonaws.com", "ImportKeyPair"}},
+	"L-0DA580E9":                   {{"ec2.amazonaws.com", "CreateImage"}, {"ec2.amazonaws.com", "RegisterImage"}, {"ec2.amazonaws.com", "CopyImage"}},
+	"L-309BACF6":                   {{"ec2.amazonaws.com", "CreateSnapshot"}, {"ec2.amazonaws.com", "CopySnapshot"}},
+	"L-407747CB":                   {{"ec2.amazonaws.com", "CreateInternetGateway"}},
+	"L-FE5A380F":                   {{"ec2.amazonaws.com", "CreateNatGateway"}},
+	"ec2:capacity-reservations":    {{"ec2.amazonaws.com", "CreateCapacityReservation"}},
+	"ec2:launch-templates":         {{"ec2.amazonaws.com", "CreateLaunchTemplate"}},
+	"ec2:launch-template-versions": {{"ec2.amazonaws.com", "CreateLaunchTemplateVersion"}},
+	"L-E2B68F07":                   {{"ec2.amazonaws.com", "CreatePlacementGroup"}},
+
+	// VPC
+	"L-F678F1CE": {{"ec2.amazonaws.com", "CreateVpc"}},
+	"L-DF5E4CA3": {{"ec2.amazonaws.com", "CreateNetworkInterface"}},
+	"L-E79EC296": {{"ec2.amazonaws.com", "CreateSecurityGroup"}},
+
+	// ELB
+	"L-53DA6B97": {{"elasticloadbalancing.amazonaws.com", "CreateLoadBalancer"}},
+	"L-69A177A2": {{"elasticloadbalancing.amazonaws.com", "CreateLoadBalancer"}},
+	"L-B22855CB": {{"elasticloadbalancing.amazonaws.com", "CreateTargetGroup"}},
+
+	// Auto Scaling
+	"L-CDE20ADC": {{"autoscaling.amazonaws.com", "CreateAutoScalingGroup"}},
+
+	// S3
+	"L-DC2B2D3D":                    {{"s3.amazonaws.com", "CreateBucket"}},
+	"s3:access-points":              {{"s3.amazonaws.com", "CreateAccessPoint"}},
+	"s3:multi-region-access-points": {{"s3.amazonaws.com", "CreateMultiRegionAccessPoint"}},
+
+	// Lambda
+	"L-9FEE3D26": {{"lambda.amazonaws.com", "CreateFunction20150331"}},
+
+	// RDS
+	"L-7B6409FD":                   {{"rds.amazonaws.com", "CreateDBInstance"}},
+	"L-952B80B8":                   {{"rds.amazonaws.com", "CreateDBCluster"}},
+	"L-272F1212":                   {{"rds.amazonaws.com", "CreateDBSnapshot"}, {"rds.amazonaws.com", "CopyDBSnapshot"}},
+	"L-9B510759":                   {{"rds.amazonaws.com", "CreateDBClusterSnapshot"}, {"rds.amazonaws.com", "CopyDBClusterSnapshot"}},
+	"L-48C6BF81":                   {{"rds.amazonaws.com", "CreateDBSubnetGroup"}},
+	"L-DE55804A":                   {{"rds.amazonaws.com", "CreateDBParameterGroup"}, {"rds.amazonaws.com", "CopyDBParameterGroup"}},
+	"rds:cluster-parameter-groups": {{"rds.amazonaws.com", "CreateDBClusterParameterGroup"}, {"rds.amazonaws.com", "CopyDBClusterParameterGroup"}},
+	"L-5BC124EF":                   {{"rds.amazonaws.com", "CreateDBInstanceReadReplica"}},
+
+	// DynamoDB
+	"L-F98FE922": {{"dynamodb.amazonaws.com", "CreateTable"}},
+
+	// IAM
+	"L-4019AD8D": {{"iam.amazonaws.com", "CreateUser"}},
+	"L-FE177D64": {{"iam.amazonaws.com", "CreateRole"}},
+	"L-0DA4ABF3": {{"iam.amazonaws.com", "CreateGroup"}},
+	"L-D0B7243C": {{"iam.amazonaws.com", "CreatePolicy"}},
+	"L-6E65F664": {{"iam.amazonaws.com", "CreateInstanceProfile"}},
+	"L-DB618D39": {{"iam.amazonaws.com", "CreateSAMLProvider"}},
+	"L-858F3967": {{"iam.amazonaws.com", "CreateOpenIDConnectProvider"}},
+	"L-BF35879D": {{"iam.amazonaws.com", "UploadServerCertificate"}},
+
+	// SNS / SQS / ECR
+	"L-61103206": {{"sns.amazonaws.com", "CreateTopic"}},
+	"L-75826ACE": {{"sqs.amazonaws.com", "CreateQueue"}},
+	"L-CFEB8E8D": {{"ecr.amazonaws.com", "CreateRepository"}},
+	"L-03A36CE1": {{"ecr.amazonaws.com", "PutImage"}},
+
+	// CloudFormation
+	"L-0485CB21": {{"cloudformation.amazonaws.com", "CreateStack"}},
+	"L-31709F13": {{"cloudformation.amazonaws.com", "CreateStackSet"}},
+
+	// Kinesis Data Streams
+	"L-D6C6A47E": {{"kinesis.amazonaws.com", "CreateStream"}},
+
+	// EventBridge
+	"L-244521F2": {{"events.amazonaws.com", "PutRule"}},
+	"L-BAE1A5C4": {{"events.amazonaws.com", "CreateEventBus"}},
+
+	// CloudWatch
+	"L-7F53D9D2": {{"monitoring.amazonaws.com", "PutMetricAlarm"}},
+	"L-A7CF0D2F": {{"monitoring.amazonaws.com", "PutDashboard"}},
+	"L-D2832119": {{"logs.amazonaws.com", "CreateLogGroup"}},
+
+	// Glue
+	"L-611FFD30":  {{"glue.amazonaws.com", "CreateJob"}},
+	"L-F1F2CC26":  {{"glue.amazonaws.com", "CreateCrawler"}},
+	"L-4AFE9C52":  {{"glue.amazonaws.com", "CreateDatabase"}},
+	"glue:tables": {{"glue.amazonaws.com", "CreateTable"}},
+
+	// Athena
+	"athena:workgroups":                        {{"athena.amazonaws.com", "CreateWorkGroup"}},
+	"athena:prepared-statements-per-workgroup": {{"athena.amazonaws.com", "CreatePreparedStatement"}},
+
+	// EMR
+	"elasticmapreduce:active-clusters":  {{"elasticmapreduce.amazonaws.com", "RunJobFlow"}},
+	"elasticmapreduce:active-instances": {{"elasticmapreduce.amazonaws.com", "RunJobFlow"}, {"elasticmapreduce.amazonaws.com", "ModifyInstanceGroups"}, {"elasticmapreduce.amazonaws.com", "ModifyInstanceFleet"}},
+
+	// MSK
+	"kafka:clusters": {{"kafka.amazonaws.com", "CreateCluster"}, {"kafka.amazonaws.com", "CreateClusterV2"}},
+	"kafka:brokers":  {{"kafka.amazonaws.com", "CreateCluster"}, {"kafka.amazonaws.com", "CreateClusterV2"}, {"kafka.amazonaws.com", "UpdateBrokerCount"}},
+
+	// OpenSearch Service
+	"L-076D529E": {{"es.amazonaws.com", "CreateDomain"}},
+	"L-6408ABDE": {{"es.amazonaws.com", "CreateDomain"}, {"es.amazonaws.com", "UpdateDomainConfig"}},
+
+	// VPC endpoints, peering and per-VPC resources
+	"L-29B6F2EB": {{"ec2.amazonaws.com", "CreateVpcEndpoint"}},
+	"L-1B52E74A": {{"ec2.amazonaws.com", "CreateVpcEndpoint"}},
+	"L-7E9ECCDB": {{"ec2.amazonaws.com", "AcceptVpcPeeringConnection"}, {"ec2.amazonaws.com", "CreateVpcPeeringConnection"}},
+	"L-44499CD2": {{"ec2.amazonaws.com", "CreateSubnet"}},
+	"L-589F43AA": {{"ec2.amazonaws.com", "CreateRouteTable"}},
+	"L-B4A6D682": {{"ec2.amazonaws.com", "CreateNetworkAcl"}},
+	"L-0EA8095F": {{"ec2.amazonaws.com", "AuthorizeSecurityGroupIngress"}, {"ec2.amazonaws.com", "AuthorizeSecurityGroupEgress"}},
+
+	// Transit Gateway
+	"L-A2478D36": {{"ec2.amazonaws.com", "CreateTransitGateway"}},
+	"L-E0233F82": {{"ec2.amazonaws.com", "CreateTransitGatewayVpcAttachment"}, {"ec2.amazonaws.com", "CreateTransitGatewayPeeringAttachment"}, {"ec2.amazonaws.com", "CreateTransitGatewayConnect"}},
+
+	// Direct Connect
+	"directconnect:connections":                 {{"directconnect.amazonaws.com", "CreateConnection"}},
+	"directconnect:private-vifs-per-connection": {{"directconnect.amazonaws.com", "CreatePrivateVirtualInterface"}, {"directconnect.amazonaws.com", "CreateTransitVirtualInterface"}},
+	"directconnect:public-vifs-per-connection":  {{"directconnect.amazonaws.com", "CreatePublicVirtualInterface"}},
+
+	// Global Accelerator
+	"globalaccelerator:accelerators":              {{"globalaccelerator.amazonaws.com", "CreateAccelerator"}},
+	"globalaccelerator:listeners-per-accelerator": {{"globalaccelerator.amazonaws.com", "CreateListener"}},
+
+	// WAFv2
+	"wafv2:regional-web-acls":           {{"wafv2.amazonaws.com", "CreateWebACL"}},
+	"wafv2:cloudfront-web-acls":         {{"wafv2.amazonaws.com", "CreateWebACL"}},
+	"wafv2:regional-web-acl-capacity":   {{"wafv2.amazonaws.com", "UpdateWebACL"}},
+	"wafv2:cloudfront-web-acl-capacity": {{"wafv2.amazonaws.com", "UpdateWebACL"}},
+
+	// CodeBuild
+	"codebuild:projects": {{"codebuild.amazonaws.com", "CreateProject"}},
+
+	// Cognito
+	"cognito-idp:user-pools":                {{"cognito-idp.amazonaws.com", "CreateUserPool"}},
+	"cognito-idp:app-clients-per-user-pool": {{"cognito-idp.amazonaws.com", "CreateUserPoolClient"}},
+
+	// AppSync
+	"appsync:graphql-apis":      {{"appsync.amazonaws.com", "CreateGraphqlApi"}},
+	"appsync:resolvers-per-api": {{"appsync.amazonaws.com", "CreateResolver"}},
+
+	// Elastic Beanstalk
+	"L-1CEABD17":                            {{"elasticbeanstalk.amazonaws.com", "CreateApplication"}},
+	"elasticbeanstalk:application-versions": {{"elasticbeanstalk.amazonaws.com", "CreateApplicationVersion"}},
+	"L-8EFC1C51":                            {{"elasticbeanstalk.amazonaws.com", "CreateEnvironment"}},
+}
+
+// maxAttributionEvents caps the number of events inspected per event name
+const maxAttributionEvents = 1000
+
+// LookupEvents is limited to 2 requests per second per account and region
+var lookupEventsLimiter = rate.NewLimiter(rate.Limit(2), 1)
+
+// AttributeUsage queries CloudTrail for recent create events of the resource
+// type counted by the quota and returns the principals creating them, busiest first
+func (f *QuotaFetcher) AttributeUsage(ctx context.Context, region string, quota *model.Quota, lookback time.Duration) (*model.Attribution, error) {
+	events, ok := QuotaCodeToCreateEvents[HandlerKey(quota.ServiceCode, quota.QuotaCode, quota.QuotaName)]
+	if !ok {
+		return nil, nil
+	}
+
+	// IAM is a global service whose events are recorded in us-east-1
+	if events[0].EventSource == "iam.amazonaws.com" {
+		region = "us-east-1"
+	}
+
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	client := cloudtrail.NewFromConfig(cfg)
+
+	endTime := time.Now()
+	startTime := endTime.Add(-lookback)
+	counts := make(map[string]*model.PrincipalActivity)
+	total := 0
+
+	for _, ev := range events {
+		paginator := cloudtrail.NewLookupEventsPaginator(client, &cloudtrail.LookupEventsInput{
+			LookupAttributes: []cttypes.LookupAttribute{{
+				AttributeKey:   cttypes.LookupAttributeKeyEventName,
+				AttributeValue: aws.String(ev.EventName),
+			}},
+			StartTime: &startTime,
+			EndTime:   &endTime,
+		})
+
+		seen := 0
+		for paginator.HasMorePages() && seen < maxAttributionEvents {
+			if err := lookupEventsLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range output.Events {
+				seen++
+				if safeString(e.EventSource) != ev.EventSource {
+					continue
+				}
+				principal := eventPrincipal(e)
+				activity, exists := counts[principal]
+				if !exists {
+					activity = &model.PrincipalActivity{Principal: principal}
+					counts[principal] = activity
+				}
+				activity.Count++
+				if e.EventTime != nil && e.EventTime.After(activity.LastEventAt) {
+					activity.LastEventAt = *e.EventTime
+				}
+				total++
+			}
+		}
+	}
+
+	principals := make([]model.PrincipalActivity, 0, len(counts))
+	for _, activity := range counts {
+		principals = append(principals, *activity)
+	}
+	sort.Slice(principals, func(i, j int) bool {
+		if principals[i].Count != principals[j].Count {
+			return principals[i].Count > principals[j].Count
+		}
+		return principals[i].Principal < principals[j].Principal
+	})
+
+	eventNames := make([]string, 0, len(events))
+	for _, ev := range events {
+		eventNames = append(eventNames, ev.EventName)
+	}
+
+	return &model.Attribution{
+		Region:      region,
+		QuotaCode:   quota.QuotaCode,
+		EventNames:  eventNames,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		TotalEvents: total,
+		Principals:  principals,
+	}, nil
+}
+
+// eventPrincipal returns the ARN of the identity that made the call, falling
+// back to the user name CloudTrail reports for the event
+func eventPrincipal(e cttypes.Event) string {
+	if e.CloudTrailEvent != nil {
+		var record struct {
+			UserIdentity struct {
+				ARN            string `json:"arn"`
+				SessionContext struct {
+					SessionIssuer struct {
+						ARN string `json:"arn"`
+					} `json:"sessionIssuer"`
+				} `json:"sessionContext"`
+			} `json:"userIdentity"`
+		}
+		if err := json.Unmarshal([]byte(*e.CloudTrailEvent), &record); err == nil {
+			// Group assumed-role sessions under the role rather than each session
+			if issuer := record.UserIdentity.SessionContext.SessionIssuer.ARN; issuer != "" {
+				return issuer
+			}
+			if record.UserIdentity.ARN != "" {
+				return record.UserIdentity.ARN
+			}
+		}
+	}
+	if e.Username != nil && *e.Username != "" {
+		return *e.Username
+	}
+	return "unknown"
+}
@@ -0,0 +1,31 @@
+package aws
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type callCounterKey struct{}
+
+// CallCounter counts the AWS API call attempts, retries included, made with
+// a context
+type CallCounter struct {
+	n atomic.Int64
+}
+
+// Count returns the number of call attempts counted so far
+func (c *CallCounter) Count() int64 {
+	return c.n.Load()
+}
+
+// WithCallCounter returns a context whose AWS calls are counted by c
+func WithCallCounter(ctx context.Context, c *CallCounter) context.Context {
+	return context.WithValue(ctx, callCounterKey{}, c)
+}
+
+// countCall adds a call attempt to the counter of the context, if any
+func countCall(ctx context.Context) {
+	if c, ok := ctx.Value(callCounterKey{}).(*CallCounter); ok {
+		c.n.Add(1)
+	}
+}
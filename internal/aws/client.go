@@ -2,11 +2,76 @@ package aws
 
 import (
 	"context"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 )
 
+// Custom endpoints for all SDK clients, e.g. LocalStack or moto. They are set
+// once at startup before any client is created.
+var (
+	globalEndpoint   string
+	serviceEndpoints map[string]string
+)
+
+// SetEndpoints points SDK clients at custom endpoints. The global endpoint
+// applies to every service; per-service endpoints are keyed by the lowercase
+// SDK service ID without spaces (e.g. "ec2", "servicequotas", "cloudwatch").
+func SetEndpoints(global string, services map[string]string) {
+	globalEndpoint = global
+	serviceEndpoints = make(map[string]string, len(services))
+	for service, url := range services {
+		serviceEndpoints[normalizeServiceID(service)] = url
+	}
+}
+
+func normalizeServiceID(service string) string {
+	return strings.ToLower(strings.ReplaceAll(service, " ", ""))
+}
+
 func LoadConfig(ctx context.Context, region string) (aws.Config, error) {
-	return config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	return LoadConfigWithCredentials(ctx, region, nil)
+}
+
+// LoadConfigWithCredentials loads the default config for a region, overriding
+// the credential chain with the given provider when it is non-nil
+func LoadConfigWithCredentials(ctx context.Context, region string, provider aws.CredentialsProvider) (aws.Config, error) {
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if provider != nil {
+		opts = append(opts, config.WithCredentialsProvider(provider))
+	}
+	if len(serviceEndpoints) > 0 {
+		opts = append(opts, config.WithEndpointResolverWithOptions(serviceEndpointResolver()))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return cfg, err
+	}
+	if globalEndpoint != "" {
+		cfg.BaseEndpoint = aws.String(globalEndpoint)
+	}
+	return cfg, nil
+}
+
+// serviceEndpointResolver resolves per-service endpoints, falling back to the
+// global endpoint and then to the SDK's default resolution.
+//
+//nolint:staticcheck // per-service overrides have no non-deprecated equivalent on a shared aws.Config
+func serviceEndpointResolver() aws.EndpointResolverWithOptions {
+	return aws.EndpointResolverWithOptionsFunc(func(service, region string, _ ...interface{}) (aws.Endpoint, error) {
+		url, ok := serviceEndpoints[normalizeServiceID(service)]
+		if !ok {
+			url = globalEndpoint
+		}
+		if url == "" {
+			return aws.Endpoint{}, &aws.EndpointNotFoundError{}
+		}
+		return aws.Endpoint{
+			URL:               url,
+			SigningRegion:     region,
+			HostnameImmutable: true,
+		}, nil
+	})
 }
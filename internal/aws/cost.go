@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// costExplorerServices maps Cost Explorer SERVICE dimension values to the
+// Service Quotas service codes they are billed under
+var costExplorerServices = map[string]string{
+	"Amazon Elastic Compute Cloud - Compute":          "ec2",
+	"EC2 - Other":                                     "ec2",
+	"Amazon Virtual Private Cloud":                    "vpc",
+	"Amazon Elastic Load Balancing":                   "elasticloadbalancing",
+	"AWS Lambda":                                      "lambda",
+	"Amazon Relational Database Service":              "rds",
+	"Amazon DynamoDB":                                 "dynamodb",
+	"Amazon Simple Storage Service":                   "s3",
+	"Amazon CloudFront":                               "cloudfront",
+	"Amazon Route 53":                                 "route53",
+	"Amazon Simple Notification Service":              "sns",
+	"Amazon Simple Queue Service":                     "sqs",
+	"Amazon EC2 Container Registry (ECR)":             "ecr",
+	"Amazon Elastic Container Service for Kubernetes": "eks",
+	"Amazon Elastic Container Service":                "ecs",
+	"AmazonCloudWatch":                                "monitoring",
+	"Amazon Kinesis":                                  "kinesis",
+}
+
+// GetMonthlyServiceCosts returns the month-to-date unblended cost per service
+// code, optionally restricted to one region. On the first day of a month the
+// previous month is reported, since the current one has no data yet.
+func (f *QuotaFetcher) GetMonthlyServiceCosts(ctx context.Context, region string) (map[string]model.ServiceCost, error) {
+	// Cost Explorer is served from us-east-1 only
+	cfg, err := f.loadConfig(ctx, "us-east-1")
+	if err != nil {
+		return nil, err
+	}
+	client := costexplorer.NewFromConfig(cfg)
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !end.After(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+
+	input := &costexplorer.GetCostAndUsageInput{
+		Granularity: cetypes.GranularityMonthly,
+		Metrics:     []string{"UnblendedCost"},
+		TimePeriod: &cetypes.DateInterval{
+			Start: aws.String(start.Format("2006-01-02")),
+			End:   aws.String(end.Format("2006-01-02")),
+		},
+		GroupBy: []cetypes.GroupDefinition{{
+			Type: cetypes.GroupDefinitionTypeDimension,
+			Key:  aws.String("SERVICE"),
+		}},
+	}
+	if region != "" && region != "all" && region != "global" {
+		input.Filter = &cetypes.Expression{
+			Dimensions: &cetypes.DimensionValues{
+				Key:    cetypes.DimensionRegion,
+				Values: []string{region},
+			},
+		}
+	}
+
+	period := start.Format("2006-01")
+	costs := make(map[string]model.ServiceCost)
+	for {
+		output, err := client.GetCostAndUsage(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range output.ResultsByTime {
+			for _, group := range result.Groups {
+				if len(group.Keys) == 0 {
+					continue
+				}
+				serviceCode, ok := costExplorerServices[group.Keys[0]]
+				if !ok {
+					continue
+				}
+				metric, ok := group.Metrics["UnblendedCost"]
+				if !ok || metric.Amount == nil {
+					continue
+				}
+				amount, err := strconv.ParseFloat(*metric.Amount, 64)
+				if err != nil {
+					continue
+				}
+				cost := costs[serviceCode]
+				cost.Amount += amount
+				cost.Unit = safeString(metric.Unit)
+				cost.Period = period
+				cost.Estimated = cost.Estimated || result.Estimated
+				costs[serviceCode] = cost
+			}
+		}
+		if output.NextPageToken == nil {
+			break
+		}
+		input.NextPageToken = output.NextPageToken
+	}
+	return costs, nil
+}
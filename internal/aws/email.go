@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// SendEmail sends an email with an HTML body and a plain text alternative
+// through Amazon SES. from must be an identity verified in the region.
+func SendEmail(ctx context.Context, region, from string, to []string, subject, htmlBody, textBody string) error {
+	cfg, err := LoadConfig(ctx, region)
+	if err != nil {
+		return err
+	}
+
+	_, err = sesv2.NewFromConfig(cfg).SendEmail(ctx, &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(from),
+		Destination:      &types.Destination{ToAddresses: to},
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Subject: &types.Content{Data: aws.String(subject), Charset: aws.String("UTF-8")},
+				Body: &types.Body{
+					Html: &types.Content{Data: aws.String(htmlBody), Charset: aws.String("UTF-8")},
+					Text: &types.Content{Data: aws.String(textBody), Charset: aws.String("UTF-8")},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send email to %v: %w", to, err)
+	}
+	return nil
+}
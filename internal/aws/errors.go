@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+var deniedErrorCodes = map[string]bool{
+	"AccessDenied":                true,
+	"AccessDeniedException":       true,
+	"UnauthorizedOperation":       true,
+	"AuthFailure":                 true,
+	"InvalidClientTokenId":        true,
+	"UnrecognizedClientException": true,
+	"ExpiredToken":                true,
+}
+
+var throttledErrorCodes = map[string]bool{
+	"Throttling":                             true,
+	"ThrottlingException":                    true,
+	"ThrottledException":                     true,
+	"TooManyRequestsException":               true,
+	"RequestLimitExceeded":                   true,
+	"RequestThrottled":                       true,
+	"ProvisionedThroughputExceededException": true,
+}
+
+// ClassifyError maps an AWS error to a fetch status: denied for permission and
+// credential failures, throttled for rate limiting, error for anything else
+func ClassifyError(err error) string {
+	if err == nil {
+		return model.FetchStatusOK
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case deniedErrorCodes[apiErr.ErrorCode()]:
+			return model.FetchStatusDenied
+		case throttledErrorCodes[apiErr.ErrorCode()]:
+			return model.FetchStatusThrottled
+		}
+	}
+	return model.FetchStatusError
+}
+
+// IsRegionDisabled reports whether err means the region is not enabled for the
+// account, because it was not opted into or a service control policy denies
+// it, as opposed to a failure of the call itself
+func IsRegionDisabled(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.ErrorCode() == "OptInRequired" {
+		return true
+	}
+	return deniedErrorCodes[apiErr.ErrorCode()] && strings.Contains(apiErr.ErrorMessage(), "service control policy")
+}
+
+// IsServiceUnavailable reports whether err means the service has no endpoint
+// in the region, as opposed to a failing call to an existing endpoint
+func IsServiceUnavailable(err error) bool {
+	var endpointErr *aws.EndpointNotFoundError
+	if errors.As(err, &endpointErr) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
@@ -0,0 +1,43 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+type fetchLogKey struct{}
+
+// WithFetchLog returns a context whose quota fetches also report their
+// progress to fn, in addition to the server log. fn may be called
+// concurrently from several region scans.
+func WithFetchLog(ctx context.Context, fn func(model.FetchLogLine)) context.Context {
+	return context.WithValue(ctx, fetchLogKey{}, fn)
+}
+
+// FetchLog returns the function the fetch log of a context reports to, if any
+func FetchLog(ctx context.Context) (func(model.FetchLogLine), bool) {
+	fn, ok := ctx.Value(fetchLogKey{}).(func(model.FetchLogLine))
+	return fn, ok
+}
+
+// logFetch writes a progress line to the server log and to the fetch log of
+// the context, if any
+func logFetch(ctx context.Context, level, region, service, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	log.Print(msg)
+	fn, ok := FetchLog(ctx)
+	if !ok {
+		return
+	}
+	fn(model.FetchLogLine{
+		Time:    time.Now(),
+		Level:   level,
+		Region:  region,
+		Service: service,
+		Message: msg,
+	})
+}
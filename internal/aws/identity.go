@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Identity is the account and principal behind a fetcher's credentials
+type Identity struct {
+	AccountID string `json:"account_id"`
+	ARN       string `json:"arn"`
+}
+
+// Principal returns the stable part of the principal ARN: the role of an
+// assumed-role session without the session name, or the IAM user
+func (i Identity) Principal() string {
+	parts := strings.SplitN(i.ARN, ":", 6)
+	if len(parts) < 6 {
+		return i.ARN
+	}
+	resource := parts[5]
+	if strings.HasPrefix(resource, "assumed-role/") {
+		segments := strings.SplitN(resource, "/", 3)
+		if len(segments) >= 2 {
+			return segments[0] + "/" + segments[1]
+		}
+	}
+	return resource
+}
+
+// identityRetryAfter is how long a failed identity lookup is remembered
+// before STS is called again
+const identityRetryAfter = time.Minute
+
+// identityTimeout bounds an identity lookup, which runs apart from the
+// caller's context
+const identityTimeout = 15 * time.Second
+
+// defaultIdentityRegion is the STS region of fetchers without a default region
+const defaultIdentityRegion = "us-east-1"
+
+// identityCache memoizes the caller identity of a fetcher. A failure is kept
+// for identityRetryAfter, so a broken credential chain does not cost an STS
+// call on every request while a transient error is still retried.
+type identityCache struct {
+	mu       sync.Mutex
+	identity *Identity
+	err      error
+	failedAt time.Time
+}
+
+// Identity returns the account and principal of the fetcher's credentials.
+// STS is called in the fetcher's default region, so its regional endpoint
+// and partition are used.
+func (f *QuotaFetcher) Identity(ctx context.Context) (Identity, error) {
+	f.identity.mu.Lock()
+	defer f.identity.mu.Unlock()
+	if f.identity.identity != nil {
+		return *f.identity.identity, nil
+	}
+	if f.identity.err != nil && time.Since(f.identity.failedAt) < identityRetryAfter {
+		return Identity{}, f.identity.err
+	}
+
+	// Resolved apart from the caller's cancellation and deadline, so one
+	// client going away does not leave a failure behind for everyone else
+	lookupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), identityTimeout)
+	defer cancel()
+	identity, err := f.callerIdentity(lookupCtx)
+	if err != nil {
+		f.identity.err, f.identity.failedAt = err, time.Now()
+		return Identity{}, err
+	}
+	f.identity.identity, f.identity.err = &identity, nil
+	return identity, nil
+}
+
+// callerIdentity asks STS for the identity of the fetcher's credentials
+func (f *QuotaFetcher) callerIdentity(ctx context.Context) (Identity, error) {
+	region := f.defaultRegion
+	if region == "" {
+		region = defaultIdentityRegion
+	}
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return Identity{}, err
+	}
+	output, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return Identity{}, err
+	}
+	return Identity{
+		AccountID: safeString(output.Account),
+		ARN:       safeString(output.Arn),
+	}, nil
+}
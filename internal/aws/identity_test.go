@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIdentityPrincipal(t *testing.T) {
+	tests := []struct {
+		arn  string
+		want string
+	}{
+		{arn: "arn:aws:sts::123456789012:assumed-role/QuotaReader/session-1", want: "assumed-role/QuotaReader"},
+		{arn: "arn:aws:sts::123456789012:assumed-role/QuotaReader", want: "assumed-role/QuotaReader"},
+		{arn: "arn:aws-cn:sts::123456789012:assumed-role/QuotaReader/i-0abc", want: "assumed-role/QuotaReader"},
+		{arn: "arn:aws:iam::123456789012:user/alice", want: "user/alice"},
+		{arn: "arn:aws:iam::123456789012:root", want: "root"},
+		{arn: "not-an-arn", want: "not-an-arn"},
+		{arn: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arn, func(t *testing.T) {
+			if got := (Identity{ARN: tt.arn}).Principal(); got != tt.want {
+				t.Errorf("Principal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentityCache(t *testing.T) {
+	cached := Identity{AccountID: "123456789012", ARN: "arn:aws:iam::123456789012:user/alice"}
+	lookupErr := errors.New("no credentials")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		cache   *identityCache
+		ctx     context.Context
+		want    Identity
+		wantErr error
+	}{
+		{name: "cached identity", cache: &identityCache{identity: &cached}, ctx: context.Background(), want: cached},
+		{name: "cached identity with canceled context", cache: &identityCache{identity: &cached}, ctx: canceled, want: cached},
+		{name: "recent failure", cache: &identityCache{err: lookupErr, failedAt: time.Now()}, ctx: context.Background(), wantErr: lookupErr},
+		{name: "recent failure near expiry", cache: &identityCache{err: lookupErr, failedAt: time.Now().Add(-identityRetryAfter + time.Second)}, ctx: context.Background(), wantErr: lookupErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &QuotaFetcher{identity: tt.cache}
+			got, err := f.Identity(tt.ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Identity error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Identity = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	sqtypes "github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// RequestQuotaIncrease submits a quota increase request to Service Quotas
+func (f *QuotaFetcher) RequestQuotaIncrease(ctx context.Context, region, serviceCode, quotaCode string, desiredValue float64) (*model.IncreaseRequest, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	client := servicequotas.NewFromConfig(cfg)
+	output, err := client.RequestServiceQuotaIncrease(ctx, &servicequotas.RequestServiceQuotaIncreaseInput{
+		ServiceCode:  &serviceCode,
+		QuotaCode:    &quotaCode,
+		DesiredValue: &desiredValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req := &model.IncreaseRequest{
+		Region:       region,
+		ServiceCode:  serviceCode,
+		QuotaCode:    quotaCode,
+		DesiredValue: desiredValue,
+	}
+	if rq := output.RequestedQuota; rq != nil {
+		req.ID = safeString(rq.Id)
+		req.CaseID = safeString(rq.CaseId)
+		req.QuotaName = safeString(rq.QuotaName)
+		req.Status = string(rq.Status)
+		if rq.Created != nil {
+			req.CreatedAt = *rq.Created
+		}
+	}
+	return req, nil
+}
+
+// GetIncreaseRequest returns the current state of an increase request
+func (f *QuotaFetcher) GetIncreaseRequest(ctx context.Context, region, requestID string) (*model.IncreaseRequest, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	client := servicequotas.NewFromConfig(cfg)
+	output, err := client.GetRequestedServiceQuotaChange(ctx, &servicequotas.GetRequestedServiceQuotaChangeInput{
+		RequestId: &requestID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req := &model.IncreaseRequest{ID: requestID, Region: region}
+	if rq := output.RequestedQuota; rq != nil {
+		req.CaseID = safeString(rq.CaseId)
+		req.ServiceCode = safeString(rq.ServiceCode)
+		req.QuotaCode = safeString(rq.QuotaCode)
+		req.QuotaName = safeString(rq.QuotaName)
+		req.Status = string(rq.Status)
+		if rq.DesiredValue != nil {
+			req.DesiredValue = *rq.DesiredValue
+		}
+		if rq.Created != nil {
+			req.CreatedAt = *rq.Created
+		}
+	}
+	return req, nil
+}
+
+// ListIncreaseRequests lists the increase requests of a region with a status
+// from the Service Quotas request history, which also holds requests made in
+// the console or by other tools. Decided requests are resolved at their last
+// update.
+func (f *QuotaFetcher) ListIncreaseRequests(ctx context.Context, region, status string) ([]model.IncreaseRequest, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	client := servicequotas.NewFromConfig(cfg)
+	paginator := servicequotas.NewListRequestedServiceQuotaChangeHistoryPaginator(client, &servicequotas.ListRequestedServiceQuotaChangeHistoryInput{
+		Status: sqtypes.RequestStatus(status),
+	})
+	var requests []model.IncreaseRequest
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, rq := range page.RequestedQuotas {
+			req := model.IncreaseRequest{
+				ID:          safeString(rq.Id),
+				CaseID:      safeString(rq.CaseId),
+				Region:      region,
+				ServiceCode: safeString(rq.ServiceCode),
+				QuotaCode:   safeString(rq.QuotaCode),
+				QuotaName:   safeString(rq.QuotaName),
+				Status:      string(rq.Status),
+			}
+			if rq.DesiredValue != nil {
+				req.DesiredValue = *rq.DesiredValue
+			}
+			if rq.Created != nil {
+				req.CreatedAt = *rq.Created
+			}
+			if rq.LastUpdated != nil && rq.Status != sqtypes.RequestStatusPending && rq.Status != sqtypes.RequestStatusCaseOpened {
+				resolvedAt := *rq.LastUpdated
+				req.ResolvedAt = &resolvedAt
+			}
+			requests = append(requests, req)
+		}
+	}
+	return requests, nil
+}
+
+// GetDefaultQuotaValue returns the AWS default value of a quota, before any
+// increase was applied
+func (f *QuotaFetcher) GetDefaultQuotaValue(ctx context.Context, region, serviceCode, quotaCode string) (float64, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return 0, err
+	}
+
+	client := servicequotas.NewFromConfig(cfg)
+	output, err := client.GetAWSDefaultServiceQuota(ctx, &servicequotas.GetAWSDefaultServiceQuotaInput{
+		ServiceCode: &serviceCode,
+		QuotaCode:   &quotaCode,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if output.Quota == nil || output.Quota.Value == nil {
+		return 0, fmt.Errorf("quota %s/%s has no default value", serviceCode, quotaCode)
+	}
+	return *output.Quota.Value, nil
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// roleSessionName identifies the dashboard in the member accounts' CloudTrail
+const roleSessionName = "aws-quota-dashboard"
+
+// ListOrgAccounts returns all accounts in the organization. It must be called
+// from the management account or a delegated administrator account.
+func ListOrgAccounts(ctx context.Context) ([]model.Account, error) {
+	cfg, err := LoadConfig(ctx, "us-east-1")
+	if err != nil {
+		return nil, err
+	}
+
+	client := organizations.NewFromConfig(cfg)
+	var accounts []model.Account
+	paginator := organizations.NewListAccountsPaginator(client, &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range output.Accounts {
+			accounts = append(accounts, model.Account{
+				ID:     safeString(a.Id),
+				Name:   safeString(a.Name),
+				Email:  safeString(a.Email),
+				Status: string(a.Status),
+			})
+		}
+	}
+	return accounts, nil
+}
+
+// GetCallerAccountID returns the account ID of the credentials the server runs with
+func GetCallerAccountID(ctx context.Context) (string, error) {
+	cfg, err := LoadConfig(ctx, "us-east-1")
+	if err != nil {
+		return "", err
+	}
+
+	output, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", err
+	}
+	return safeString(output.Account), nil
+}
+
+// RoleARN builds the ARN of a role with the given name in the given account.
+// A full role ARN is returned unchanged, so roles in other accounts can be used.
+func RoleARN(accountID, roleName string) string {
+	if strings.HasPrefix(roleName, "arn:") {
+		return roleName
+	}
+	return fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, roleName)
+}
+
+// AssumeRoleCredentials returns a cached credentials provider that assumes the
+// given role using the server's own credentials
+func AssumeRoleCredentials(ctx context.Context, roleARN string) (aws.CredentialsProvider, error) {
+	cfg, err := LoadConfig(ctx, "us-east-1")
+	if err != nil {
+		return nil, err
+	}
+
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = roleSessionName
+	})
+	return aws.NewCredentialsCache(provider), nil
+}
@@ -0,0 +1,229 @@
+package aws
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingtypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// QuotaCodeToResourceTypes maps count-based quota codes (or QuotaNameKeys) to
+// the resources they count, as "service:resource" prefixes of the resource ARN
+var QuotaCodeToResourceTypes = map[string][]string{
+	// EKS
+	"L-1194D53C": {"eks:cluster/"},
+	"L-6D3F50E6": {"eks:nodegroup/"},
+
+	// EC2
+	"L-1216C47A":                   {"ec2:instance/"},
+	"L-DB2E81BA":                   {"ec2:instance/"},
+	"L-417A185B":                   {"ec2:instance/"},
+	"L-7295265B":                   {"ec2:instance/"},
+	"L-74FC7D96":                   {"ec2:instance/"},
+	"L-1945791B":                   {"ec2:instance/"},
+	"L-2C3B7624":                   {"ec2:instance/"},
+	"L-0263D0A3":                   {"ec2:elastic-ip/"},
+	"L-0DA580E9":                   {"ec2:image/"},
+	"L-309BACF6":                   {"ec2:snapshot/"},
+	"L-407747CB":                   {"ec2:internet-gateway/"},
+	"L-FE5A380F":                   {"ec2:natgateway/"},
+	"ec2:capacity-reservations":    {"ec2:capacity-reservation/"},
+	"ec2:launch-templates":         {"ec2:launch-template/"},
+	"ec2:launch-template-versions": {"ec2:launch-template/"},
+	"L-E2B68F07":                   {"ec2:placement-group/"},
+
+	// VPC
+	"L-F678F1CE": {"ec2:vpc/"},
+	"L-DF5E4CA3": {"ec2:network-interface/"},
+	"L-E79EC296": {"ec2:security-group/"},
+
+	// ELB
+	"L-53DA6B97": {"elasticloadbalancing:loadbalancer/app/"},
+	"L-69A177A2": {"elasticloadbalancing:loadbalancer/net/"},
+	"L-B22855CB": {"elasticloadbalancing:targetgroup/"},
+
+	// Auto Scaling
+	"L-CDE20ADC": {"autoscaling:autoScalingGroup:"},
+
+	// Lambda
+	"L-9FEE3D26": {"lambda:function:"},
+
+	// RDS
+	"L-7B6409FD":                   {"rds:db:"},
+	"L-952B80B8":                   {"rds:cluster:"},
+	"L-272F1212":                   {"rds:snapshot:"},
+	"L-9B510759":                   {"rds:cluster-snapshot:"},
+	"L-48C6BF81":                   {"rds:subgrp:"},
+	"L-DE55804A":                   {"rds:pg:"},
+	"rds:cluster-parameter-groups": {"rds:cluster-pg:"},
+
+	// DynamoDB
+	"L-F98FE922": {"dynamodb:table/"},
+
+	// SNS / SQS / ECR
+	"L-61103206": {"sns:"},
+	"L-75826ACE": {"sqs:"},
+	"L-CFEB8E8D": {"ecr:repository/"},
+	"L-03A36CE1": {"ecr:repository/"},
+
+	// CloudFormation
+	"L-0485CB21": {"cloudformation:stack/"},
+	"L-31709F13": {"cloudformation:stackset/"},
+
+	// Kinesis Data Streams
+	"L-E16B1B7C": {"kinesis:stream/"},
+	"L-D6C6A47E": {"kinesis:stream/"},
+
+	// CloudWatch
+	"L-7F53D9D2": {"cloudwatch:alarm:"},
+	"L-D2832119": {"logs:log-group:"},
+
+	// Glue
+	"L-611FFD30":  {"glue:job/"},
+	"L-F1F2CC26":  {"glue:crawler/"},
+	"L-4AFE9C52":  {"glue:database/"},
+	"glue:tables": {"glue:table/"},
+
+	// Athena
+	"athena:workgroups": {"athena:workgroup/"},
+
+	// EMR
+	"elasticmapreduce:active-clusters": {"elasticmapreduce:cluster/"},
+
+	// MSK
+	"kafka:clusters": {"kafka:cluster/"},
+
+	// OpenSearch Service
+	"L-076D529E": {"es:domain/"},
+
+	// VPC endpoints and per-VPC resources
+	"L-29B6F2EB": {"ec2:vpc-endpoint/"},
+	"L-1B52E74A": {"ec2:vpc-endpoint/"},
+	"L-44499CD2": {"ec2:subnet/"},
+	"L-589F43AA": {"ec2:route-table/"},
+	"L-B4A6D682": {"ec2:network-acl/"},
+	"L-0EA8095F": {"ec2:security-group/"},
+
+	// Transit Gateway
+	"L-A2478D36": {"ec2:transit-gateway/"},
+
+	// Direct Connect
+	"directconnect:connections": {"directconnect:dxcon/"},
+
+	// Global Accelerator
+	"globalaccelerator:accelerators": {"globalaccelerator:accelerator/"},
+
+	// WAFv2
+	"wafv2:regional-web-acls": {"wafv2:regional/webacl/"},
+
+	// CodeBuild
+	"codebuild:projects": {"codebuild:project/"},
+
+	// Cognito
+	"cognito-idp:user-pools": {"cognito-idp:userpool/"},
+
+	// AppSync
+	"appsync:graphql-apis": {"appsync:apis/"},
+
+	// Elastic Beanstalk
+	"L-1CEABD17": {"elasticbeanstalk:application/"},
+	"L-8EFC1C51": {"elasticbeanstalk:environment/"},
+}
+
+// taggedResource is a resource carrying the owner tag
+type taggedResource struct {
+	resourceType string
+	owner        string
+}
+
+// annotateOwners sets the owner of each quota to the most common value of the
+// owner tag among the resources the quota counts. Ties go to the
+// alphabetically first owner.
+func (f *QuotaFetcher) annotateOwners(ctx context.Context, region string, quotas []model.Quota) {
+	if f.ownerTagKey == "" {
+		return
+	}
+
+	resources, err := f.listOwnedResources(ctx, region)
+	if err != nil {
+		log.Printf("Failed to list resources tagged %q in %s: %v", f.ownerTagKey, region, err)
+		return
+	}
+
+	for i := range quotas {
+		prefixes, ok := QuotaCodeToResourceTypes[HandlerKey(quotas[i].ServiceCode, quotas[i].QuotaCode, quotas[i].QuotaName)]
+		if !ok {
+			continue
+		}
+		counts := make(map[string]int)
+		for _, r := range resources {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(r.resourceType, prefix) {
+					counts[r.owner]++
+					break
+				}
+			}
+		}
+		quotas[i].Owner = majorityOwner(counts)
+	}
+}
+
+// listOwnedResources lists the resources of a region that carry the owner tag
+func (f *QuotaFetcher) listOwnedResources(ctx context.Context, region string) ([]taggedResource, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	client := resourcegroupstaggingapi.NewFromConfig(cfg)
+
+	var resources []taggedResource
+	paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(client, &resourcegroupstaggingapi.GetResourcesInput{
+		TagFilters: []taggingtypes.TagFilter{{Key: &f.ownerTagKey}},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, mapping := range output.ResourceTagMappingList {
+			resourceType := arnResourceType(safeString(mapping.ResourceARN))
+			for _, tag := range mapping.Tags {
+				if safeString(tag.Key) == f.ownerTagKey && safeString(tag.Value) != "" {
+					resources = append(resources, taggedResource{resourceType: resourceType, owner: *tag.Value})
+					break
+				}
+			}
+		}
+	}
+	return resources, nil
+}
+
+// arnResourceType returns "service:resource" of an ARN, e.g.
+// "ec2:instance/i-0abc" for arn:aws:ec2:us-east-1:123456789012:instance/i-0abc
+func arnResourceType(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[2] + ":" + parts[5]
+}
+
+func majorityOwner(counts map[string]int) string {
+	owners := make([]string, 0, len(counts))
+	for owner := range counts {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	best := ""
+	for _, owner := range owners {
+		if best == "" || counts[owner] > counts[best] {
+			best = owner
+		}
+	}
+	return best
+}
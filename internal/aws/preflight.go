@@ -0,0 +1,128 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/smithy-go"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// Probe is a cheap read-only call standing in for an IAM action the
+// dashboard needs, made by the startup preflight check
+type Probe struct {
+	Action string
+	// Feature is the optional feature needing the action, empty for actions
+	// every deployment needs
+	Feature string
+	// region pins probes of APIs served from a single region
+	region string
+	call   func(ctx context.Context, cfg aws.Config) error
+}
+
+// Probes samples the IAM actions of iam-policy.json: the core actions and
+// those of the enabled optional features. Usage handler actions are not
+// probed, as there are too many; a missing one only loses that usage.
+func Probes(features []string) []Probe {
+	enabled := make(map[string]bool, len(features))
+	for _, f := range features {
+		enabled[f] = true
+	}
+
+	probes := []Probe{
+		{Action: "servicequotas:ListServices", call: func(ctx context.Context, cfg aws.Config) error {
+			_, err := servicequotas.NewFromConfig(cfg).ListServices(ctx, &servicequotas.ListServicesInput{MaxResults: aws.Int32(1)})
+			return err
+		}},
+		{Action: "servicequotas:ListServiceQuotas", call: func(ctx context.Context, cfg aws.Config) error {
+			_, err := servicequotas.NewFromConfig(cfg).ListServiceQuotas(ctx, &servicequotas.ListServiceQuotasInput{
+				ServiceCode: aws.String("ec2"),
+				MaxResults:  aws.Int32(1),
+			})
+			return err
+		}},
+		{Action: "cloudwatch:ListMetrics", call: func(ctx context.Context, cfg aws.Config) error {
+			_, err := cloudwatch.NewFromConfig(cfg).ListMetrics(ctx, &cloudwatch.ListMetricsInput{Namespace: aws.String("AWS/Usage")})
+			return err
+		}},
+		{Action: "ec2:DescribeRegions", call: func(ctx context.Context, cfg aws.Config) error {
+			_, err := ec2.NewFromConfig(cfg).DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+			return err
+		}},
+		{Action: "ec2:DescribeInstances", call: func(ctx context.Context, cfg aws.Config) error {
+			_, err := ec2.NewFromConfig(cfg).DescribeInstances(ctx, &ec2.DescribeInstancesInput{MaxResults: aws.Int32(5)})
+			return err
+		}},
+	}
+	if enabled["org_scan"] && !enabled["demo"] {
+		probes = append(probes, Probe{Action: "organizations:ListAccounts", Feature: "org_scan", region: "us-east-1", call: func(ctx context.Context, cfg aws.Config) error {
+			_, err := organizations.NewFromConfig(cfg).ListAccounts(ctx, &organizations.ListAccountsInput{MaxResults: aws.Int32(1)})
+			return err
+		}})
+	}
+	if enabled["attribution"] {
+		probes = append(probes, Probe{Action: "cloudtrail:LookupEvents", Feature: "attribution", call: func(ctx context.Context, cfg aws.Config) error {
+			_, err := cloudtrail.NewFromConfig(cfg).LookupEvents(ctx, &cloudtrail.LookupEventsInput{MaxResults: aws.Int32(1)})
+			return err
+		}})
+	}
+	if enabled["ownership"] {
+		probes = append(probes, Probe{Action: "tag:GetResources", Feature: "ownership", call: func(ctx context.Context, cfg aws.Config) error {
+			_, err := resourcegroupstaggingapi.NewFromConfig(cfg).GetResources(ctx, &resourcegroupstaggingapi.GetResourcesInput{ResourcesPerPage: aws.Int32(1)})
+			return err
+		}})
+	}
+	if enabled["cost"] {
+		// Cost Explorer bills every request, so this probe costs $0.01
+		probes = append(probes, Probe{Action: "ce:GetCostAndUsage", Feature: "cost", region: "us-east-1", call: func(ctx context.Context, cfg aws.Config) error {
+			today := time.Now().UTC()
+			_, err := costexplorer.NewFromConfig(cfg).GetCostAndUsage(ctx, &costexplorer.GetCostAndUsageInput{
+				Granularity: cetypes.GranularityDaily,
+				Metrics:     []string{"UnblendedCost"},
+				TimePeriod: &cetypes.DateInterval{
+					Start: aws.String(today.AddDate(0, 0, -1).Format("2006-01-02")),
+					End:   aws.String(today.Format("2006-01-02")),
+				},
+			})
+			return err
+		}})
+	}
+	return probes
+}
+
+// RunProbe makes the call of a probe in a region with the fetcher's
+// credentials. The error tells a denied action apart from an unreachable
+// endpoint.
+func (f *QuotaFetcher) RunProbe(ctx context.Context, region string, p Probe) error {
+	if p.region != "" {
+		region = p.region
+	}
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	err = p.call(ctx, cfg)
+	if err == nil {
+		return nil
+	}
+	var apiErr smithy.APIError
+	switch {
+	case ClassifyError(err) == model.FetchStatusDenied:
+		return fmt.Errorf("denied: %w", err)
+	case !errors.As(err, &apiErr):
+		return fmt.Errorf("endpoint unreachable: %w", err)
+	default:
+		return err
+	}
+}
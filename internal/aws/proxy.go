@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	sqtypes "github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+)
+
+// Read operations of the Service Quotas API exposed through the dashboard's
+// proxy endpoints. They return the API shapes unchanged.
+
+// ListServiceInfos lists the services that have quotas
+func (f *QuotaFetcher) ListServiceInfos(ctx context.Context, region string) ([]sqtypes.ServiceInfo, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	var services []sqtypes.ServiceInfo
+	paginator := servicequotas.NewListServicesPaginator(servicequotas.NewFromConfig(cfg), &servicequotas.ListServicesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, output.Services...)
+	}
+	return services, nil
+}
+
+// ListServiceQuotas lists the applied quotas of a service, or its AWS
+// default quotas when defaults is set
+func (f *QuotaFetcher) ListServiceQuotas(ctx context.Context, region, serviceCode string, defaults bool) ([]sqtypes.ServiceQuota, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	client := servicequotas.NewFromConfig(cfg)
+	if defaults {
+		return f.listDefaultQuotas(ctx, client, serviceCode)
+	}
+	return f.listAppliedQuotas(ctx, client, serviceCode)
+}
+
+// GetServiceQuota returns the applied value of a quota
+func (f *QuotaFetcher) GetServiceQuota(ctx context.Context, region, serviceCode, quotaCode string) (*sqtypes.ServiceQuota, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	output, err := servicequotas.NewFromConfig(cfg).GetServiceQuota(ctx, &servicequotas.GetServiceQuotaInput{
+		ServiceCode: &serviceCode,
+		QuotaCode:   &quotaCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.Quota, nil
+}
@@ -0,0 +1,31 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// PublishHTML uploads an HTML page to an S3 bucket configured for static
+// website hosting and returns the website URL of the page
+func PublishHTML(ctx context.Context, region, bucket, key string, page []byte) (string, error) {
+	cfg, err := LoadConfig(ctx, region)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s3.NewFromConfig(cfg).PutObject(ctx, &s3.PutObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		Body:         bytes.NewReader(page),
+		ContentType:  aws.String("text/html; charset=utf-8"),
+		CacheControl: aws.String("no-cache"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload s3://%s/%s: %w", bucket, key, err)
+	}
+	return fmt.Sprintf("http://%s.s3-website.%s.amazonaws.com/%s", bucket, region, key), nil
+}
@@ -0,0 +1,121 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/catalog"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"golang.org/x/sync/errgroup"
+)
+
+// QuotaRef identifies a single quota to fetch
+type QuotaRef struct {
+	ServiceCode string
+	QuotaCode   string
+}
+
+// ParseQuotaRefs parses quota codes given as "service/L-XXXXXXXX" or as a
+// bare code. The service of a bare code is serviceFilter when set, otherwise
+// it is looked up in the usage handlers and the catalog.
+func ParseQuotaRefs(codes []string, serviceFilter string) ([]QuotaRef, error) {
+	var refs []QuotaRef
+	seen := make(map[QuotaRef]bool)
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+		ref := QuotaRef{ServiceCode: serviceFilter, QuotaCode: code}
+		if service, quotaCode, ok := strings.Cut(code, "/"); ok {
+			ref = QuotaRef{ServiceCode: service, QuotaCode: quotaCode}
+		}
+		if ref.ServiceCode == "" {
+			service, ok := serviceOfQuotaCode(ref.QuotaCode)
+			if !ok {
+				return nil, fmt.Errorf("unknown service of quota %s; pass it as service/%s", ref.QuotaCode, ref.QuotaCode)
+			}
+			ref.ServiceCode = service
+		}
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// serviceOfQuotaCode finds the service of a quota code in the usage handlers
+// and the catalog
+func serviceOfQuotaCode(quotaCode string) (string, bool) {
+	if handler, ok := QuotaCodeToServiceMapping[quotaCode]; ok {
+		return handler.ServiceCode, true
+	}
+	for _, svc := range catalog.Default().Services() {
+		if _, ok := catalog.Default().Lookup(svc.Code, quotaCode); ok {
+			return svc.Code, true
+		}
+	}
+	return "", false
+}
+
+// GetQuotasByCode fetches only the given quotas in each region, with one
+// GetServiceQuota call per quota instead of listing whole services. It is
+// meant for targeted checks, such as CI gates, that need an answer in
+// seconds.
+func (f *QuotaFetcher) GetQuotasByCode(ctx context.Context, regions []string, refs []QuotaRef) (*FetchResult, error) {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(f.maxConcurrency)
+
+	var mu sync.Mutex
+	var quotas []model.Quota
+	var warnings []string
+	disabled := make(map[string]bool)
+
+	for _, region := range regions {
+		for _, ref := range refs {
+			region, ref := region, ref
+			g.Go(func() error {
+				quota, err := f.GetQuota(ctx, region, ref.ServiceCode, ref.QuotaCode)
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err != nil && IsRegionDisabled(err):
+					disabled[region] = true
+				case err != nil:
+					warnings = append(warnings, fmt.Sprintf("Failed to fetch quota %s/%s in region %s: %v", ref.ServiceCode, ref.QuotaCode, region, err))
+				default:
+					quotas = append(quotas, *quota)
+				}
+				return nil
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	result := &FetchResult{
+		Quotas:   DeduplicateGlobalQuotas(quotas),
+		Warnings: warnings,
+	}
+	for region := range disabled {
+		result.DisabledRegions = append(result.DisabledRegions, region)
+	}
+	sort.Strings(result.DisabledRegions)
+	sort.Strings(result.Warnings)
+	sort.SliceStable(result.Quotas, func(i, j int) bool {
+		a, b := result.Quotas[i], result.Quotas[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.ServiceCode != b.ServiceCode {
+			return a.ServiceCode < b.ServiceCode
+		}
+		return a.QuotaCode < b.QuotaCode
+	})
+	return result, nil
+}
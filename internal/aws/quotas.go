@@ -2,8 +2,10 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,14 +15,24 @@ import (
 	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
 	sqtypes "github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+	"github.com/yuxishi/aws-quota-dashboard/internal/catalog"
 	"github.com/yuxishi/aws-quota-dashboard/internal/model"
 	"golang.org/x/sync/errgroup"
-	"golang.org/x/time/rate"
 )
 
+// defaultHandlerTimeout bounds each usage handler invocation unless
+// overridden with SetUsageHandlerTimeout
+const defaultHandlerTimeout = 30 * time.Second
+
 type QuotaFetcher struct {
 	maxConcurrency int
-	limiter        *rate.Limiter
+	limiter        *RateController
+	credentials    aws.CredentialsProvider
+	ownerTagKey    string
+	usageCounts    *usageCounts
+	handlerTimeout time.Duration
+	defaultRegion  string
+	identity       *identityCache
 }
 
 func NewQuotaFetcher(maxConcurrency int) *QuotaFetcher {
@@ -29,16 +41,72 @@ func NewQuotaFetcher(maxConcurrency int) *QuotaFetcher {
 	}
 	return &QuotaFetcher{
 		maxConcurrency: maxConcurrency,
-		limiter:        rate.NewLimiter(rate.Limit(5), 10),
+		limiter:        NewRateController(DefaultInitialRate, DefaultMinRate, DefaultMaxRate),
+		usageCounts:    newUsageCounts(),
+		handlerTimeout: defaultHandlerTimeout,
+		identity:       &identityCache{},
 	}
 }
 
-func (f *QuotaFetcher) GetServices(ctx context.Context, region string) ([]model.Service, error) {
-	if err := f.limiter.Wait(ctx); err != nil {
-		return nil, err
+// WithCredentials returns a fetcher that makes its AWS calls with the given
+// credentials provider while sharing the rate controller with f
+func (f *QuotaFetcher) WithCredentials(provider aws.CredentialsProvider) *QuotaFetcher {
+	return &QuotaFetcher{
+		maxConcurrency: f.maxConcurrency,
+		limiter:        f.limiter,
+		credentials:    provider,
+		ownerTagKey:    f.ownerTagKey,
+		usageCounts:    newUsageCounts(),
+		handlerTimeout: f.handlerTimeout,
+		defaultRegion:  f.defaultRegion,
+		identity:       &identityCache{},
+	}
+}
+
+// SetOwnerTagKey enables owner annotations derived from the given tag on the
+// resources counted by each quota
+func (f *QuotaFetcher) SetOwnerTagKey(key string) {
+	f.ownerTagKey = key
+}
+
+// SetUsageHandlerTimeout sets how long a single usage handler may run before
+// it is canceled and the quota is reported without usage. Zero disables the
+// timeout.
+func (f *QuotaFetcher) SetUsageHandlerTimeout(d time.Duration) {
+	f.handlerTimeout = d
+}
+
+// SetDefaultRegion sets the region of calls that are not tied to a fetched
+// region, such as resolving the caller identity
+func (f *QuotaFetcher) SetDefaultRegion(region string) {
+	f.defaultRegion = region
+}
+
+// SetRateLimits sets the initial, minimum and maximum request rate per API
+// of the adaptive rate controller. Non-positive values keep the defaults.
+func (f *QuotaFetcher) SetRateLimits(initial, minRate, maxRate float64) {
+	f.limiter.SetRates(initial, minRate, maxRate)
+}
+
+// RateStats returns the adaptive request rate and throttling of every API
+// called so far
+func (f *QuotaFetcher) RateStats() []model.APIRate {
+	return f.limiter.Stats()
+}
+
+// loadConfig loads the config of a region with the fetcher's credentials.
+// Every call made with it is paced by the adaptive rate controller.
+func (f *QuotaFetcher) loadConfig(ctx context.Context, region string) (aws.Config, error) {
+	cfg, err := LoadConfigWithCredentials(ctx, region, f.credentials)
+	if err != nil {
+		return cfg, err
 	}
+	cfg.APIOptions = append(cfg.APIOptions, f.limiter.addMiddleware)
+	return cfg, nil
+}
 
-	cfg, err := LoadConfig(ctx, region)
+func (f *QuotaFetcher) GetServices(ctx context.Context, region string) ([]model.Service, error) {
+	cfg, err := f.loadConfig(ctx, region)
 	if err != nil {
 		return nil, err
 	}
@@ -48,9 +116,6 @@ func (f *QuotaFetcher) GetServices(ctx context.Context, region string) ([]model.
 	paginator := servicequotas.NewListServicesPaginator(client, &servicequotas.ListServicesInput{})
 
 	for paginator.HasMorePages() {
-		if err := f.limiter.Wait(ctx); err != nil {
-			return nil, err
-		}
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
 			return nil, err
@@ -66,7 +131,7 @@ func (f *QuotaFetcher) GetServices(ctx context.Context, region string) ([]model.
 }
 
 func (f *QuotaFetcher) GetQuotasForRegion(ctx context.Context, region string, serviceFilter string) ([]model.Quota, error) {
-	cfg, err := LoadConfig(ctx, region)
+	cfg, err := f.loadConfig(ctx, region)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +140,20 @@ func (f *QuotaFetcher) GetQuotasForRegion(ctx context.Context, region string, se
 
 	services, err := f.GetServices(ctx, region)
 	if err != nil {
-		return nil, err
+		// Fall back to the offline catalog unless the caller lacks permission
+		// or the region is not enabled
+		if ClassifyError(err) == model.FetchStatusDenied || IsRegionDisabled(err) {
+			return nil, err
+		}
+		if IsServiceUnavailable(err) {
+			logFetch(ctx, model.LogLevelWarn, region, "", "Service Quotas is unavailable in %s, reporting usage without limits: %v", region, err)
+			return f.getQuotasWithoutServiceQuotas(ctx, region, serviceFilter), nil
+		}
+		services = catalog.Default().Services()
+		if len(services) == 0 {
+			return nil, err
+		}
+		logFetch(ctx, model.LogLevelWarn, region, "", "Failed to list services in %s, using offline catalog: %v", region, err)
 	}
 
 	if serviceFilter != "" {
@@ -96,69 +174,134 @@ func (f *QuotaFetcher) GetQuotasForRegion(ctx context.Context, region string, se
 		}
 		quotas = append(quotas, svcQuotas...)
 	}
+	f.annotateOwners(ctx, region, quotas)
 
 	return quotas, nil
 }
 
 func (f *QuotaFetcher) getQuotasForService(ctx context.Context, client *servicequotas.Client, region string, svc model.Service) ([]model.Quota, error) {
-	cfg, err := LoadConfig(ctx, region)
+	cfg, err := f.loadConfig(ctx, region)
 	if err != nil {
 		return nil, err
 	}
 	cwClient := cloudwatch.NewFromConfig(cfg)
 
-	log.Printf("Fetching quotas for service: %s (%s) in region: %s", svc.Name, svc.Code, region)
+	logFetch(ctx, model.LogLevelInfo, region, svc.Code, "Fetching quotas for service: %s (%s) in region: %s", svc.Name, svc.Code, region)
 
 	quotaMap := make(map[string]sqtypes.ServiceQuota)
 
-	f.fetchDefaultQuotas(ctx, client, svc.Code, quotaMap)
+	f.fetchDefaultQuotas(ctx, client, svc, quotaMap)
 	f.fetchAppliedQuotas(ctx, client, svc.Code, quotaMap)
 
 	return f.buildQuotaList(ctx, cwClient, region, svc, quotaMap), nil
 }
 
-func (f *QuotaFetcher) fetchDefaultQuotas(ctx context.Context, client *servicequotas.Client, serviceCode string, quotaMap map[string]sqtypes.ServiceQuota) {
+func (f *QuotaFetcher) fetchDefaultQuotas(ctx context.Context, client *servicequotas.Client, svc model.Service, quotaMap map[string]sqtypes.ServiceQuota) {
+	quotas, err := f.listDefaultQuotas(ctx, client, svc.Code)
+	for i := range quotas {
+		quotaMap[*quotas[i].QuotaCode] = quotas[i]
+	}
+	if err != nil {
+		logFetch(ctx, model.LogLevelWarn, "", svc.Code, "Failed to get default quotas for %s: %v", svc.Code, err)
+		return
+	}
+	// A complete listing refreshes the offline catalog
+	catalog.Default().Update(svc.Code, svc.Name, catalogQuotas(quotas))
+}
+
+// listDefaultQuotas lists the default quotas of a service. Quotas listed
+// before an error are returned along with it.
+func (f *QuotaFetcher) listDefaultQuotas(ctx context.Context, client *servicequotas.Client, serviceCode string) ([]sqtypes.ServiceQuota, error) {
+	var quotas []sqtypes.ServiceQuota
 	paginator := servicequotas.NewListAWSDefaultServiceQuotasPaginator(client, &servicequotas.ListAWSDefaultServiceQuotasInput{
 		ServiceCode: &serviceCode,
 	})
 	for paginator.HasMorePages() {
-		if err := f.limiter.Wait(ctx); err != nil {
-			return
-		}
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
-			log.Printf("Failed to get default quotas for %s: %v", serviceCode, err)
-			return
+			return quotas, err
 		}
-		for i := range output.Quotas {
-			q := output.Quotas[i]
+		for _, q := range output.Quotas {
 			if q.QuotaCode != nil {
-				quotaMap[*q.QuotaCode] = q
+				quotas = append(quotas, q)
 			}
 		}
 	}
+	return quotas, nil
+}
+
+// ListCatalog lists the default quota metadata of every service in a region
+func (f *QuotaFetcher) ListCatalog(ctx context.Context, region string) ([]catalog.Service, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	client := servicequotas.NewFromConfig(cfg)
+
+	services, err := f.GetServices(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]catalog.Service, 0, len(services))
+	for _, svc := range services {
+		quotas, err := f.listDefaultQuotas(ctx, client, svc.Code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list default quotas for %s: %w", svc.Code, err)
+		}
+		result = append(result, catalog.Service{
+			ServiceCode: svc.Code,
+			ServiceName: svc.Name,
+			Quotas:      catalogQuotas(quotas),
+		})
+	}
+	return result, nil
+}
+
+func catalogQuotas(quotas []sqtypes.ServiceQuota) []catalog.Quota {
+	result := make([]catalog.Quota, 0, len(quotas))
+	for _, q := range quotas {
+		result = append(result, catalog.Quota{
+			QuotaCode:  safeString(q.QuotaCode),
+			QuotaName:  safeString(q.QuotaName),
+			Unit:       safeString(q.Unit),
+			Adjustable: q.Adjustable,
+			Global:     q.GlobalQuota,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].QuotaCode < result[j].QuotaCode })
+	return result
 }
 
 func (f *QuotaFetcher) fetchAppliedQuotas(ctx context.Context, client *servicequotas.Client, serviceCode string, quotaMap map[string]sqtypes.ServiceQuota) {
+	quotas, err := f.listAppliedQuotas(ctx, client, serviceCode)
+	for i := range quotas {
+		quotaMap[*quotas[i].QuotaCode] = quotas[i]
+	}
+	if err != nil {
+		logFetch(ctx, model.LogLevelWarn, "", serviceCode, "Failed to get applied quotas for %s: %v", serviceCode, err)
+	}
+}
+
+// listAppliedQuotas lists the applied quotas of a service. Quotas listed
+// before an error are returned along with it.
+func (f *QuotaFetcher) listAppliedQuotas(ctx context.Context, client *servicequotas.Client, serviceCode string) ([]sqtypes.ServiceQuota, error) {
+	var quotas []sqtypes.ServiceQuota
 	paginator := servicequotas.NewListServiceQuotasPaginator(client, &servicequotas.ListServiceQuotasInput{
 		ServiceCode: &serviceCode,
 	})
 	for paginator.HasMorePages() {
-		if err := f.limiter.Wait(ctx); err != nil {
-			return
-		}
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
-			log.Printf("Failed to get applied quotas for %s: %v", serviceCode, err)
-			return
+			return quotas, err
 		}
-		for i := range output.Quotas {
-			q := output.Quotas[i]
+		for _, q := range output.Quotas {
 			if q.QuotaCode != nil {
-				quotaMap[*q.QuotaCode] = q
+				quotas = append(quotas, q)
 			}
 		}
 	}
+	return quotas, nil
 }
 
 func (f *QuotaFetcher) buildQuotaList(ctx context.Context, cwClient *cloudwatch.Client, region string, svc model.Service, quotaMap map[string]sqtypes.ServiceQuota) []model.Quota {
@@ -177,6 +320,7 @@ func (f *QuotaFetcher) buildQuotaList(ctx context.Context, cwClient *cloudwatch.
 		if q.Value != nil {
 			quota.Value = *q.Value
 		}
+		labelFromCatalog(&quota)
 
 		f.enrichWithDirectAPI(ctx, region, &quota)
 
@@ -189,6 +333,61 @@ func (f *QuotaFetcher) buildQuotaList(ctx context.Context, cwClient *cloudwatch.
 	return quotas
 }
 
+// getQuotasWithoutServiceQuotas builds the quotas of a region that lacks
+// Service Quotas from the offline catalog. Limits are unknown; usage is
+// filled in by the direct usage handlers. Global quotas are left to regions
+// where their limit can be read.
+func (f *QuotaFetcher) getQuotasWithoutServiceQuotas(ctx context.Context, region, serviceFilter string) []model.Quota {
+	var quotas []model.Quota
+	for _, svc := range catalog.Default().Services() {
+		if serviceFilter != "" && !strings.EqualFold(svc.Code, serviceFilter) {
+			continue
+		}
+		for _, entry := range catalog.Default().Quotas(svc.Code) {
+			if entry.Global {
+				continue
+			}
+			quota := model.Quota{
+				Region:       region,
+				ServiceCode:  svc.Code,
+				ServiceName:  svc.Name,
+				QuotaName:    entry.QuotaName,
+				QuotaCode:    entry.QuotaCode,
+				Unit:         entry.Unit,
+				Adjustable:   entry.Adjustable,
+				LimitUnknown: true,
+			}
+			f.enrichWithDirectAPI(ctx, region, &quota)
+			quotas = append(quotas, quota)
+		}
+	}
+	f.annotateOwners(ctx, region, quotas)
+	return quotas
+}
+
+// labelFromCatalog fills metadata missing from the API response from the
+// offline catalog
+func labelFromCatalog(quota *model.Quota) {
+	if quota.ServiceName == "" {
+		quota.ServiceName = catalog.Default().ServiceName(quota.ServiceCode)
+	}
+	entry, ok := catalog.Default().Lookup(quota.ServiceCode, quota.QuotaCode)
+	if !ok {
+		return
+	}
+	if quota.QuotaName == "" {
+		quota.QuotaName = entry.QuotaName
+	}
+	if quota.Unit == "" {
+		quota.Unit = entry.Unit
+	}
+	// Some global services, such as Global Accelerator, do not flag their
+	// quotas as global; the catalog does so they are deduplicated
+	if entry.Global {
+		quota.Global = true
+	}
+}
+
 func (f *QuotaFetcher) enrichWithUsageFromCloudWatch(ctx context.Context, cwClient *cloudwatch.Client, usageMetric *sqtypes.MetricInfo, quota *model.Quota) {
 	if usageMetric.MetricNamespace == nil || usageMetric.MetricName == nil {
 		return
@@ -221,8 +420,13 @@ func (f *QuotaFetcher) enrichWithUsageFromCloudWatch(ctx context.Context, cwClie
 
 func (f *QuotaFetcher) enrichWithDirectAPI(ctx context.Context, region string, quota *model.Quota) {
 	usage, supported, err := f.GetUsageDirectly(ctx, region, quota)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		logFetch(ctx, model.LogLevelWarn, region, quota.ServiceCode, "Direct API query for %s/%s timed out after %s",
+			quota.ServiceCode, quota.QuotaCode, f.handlerTimeout)
+		return
+	}
 	if err != nil {
-		log.Printf("Direct API query failed for %s/%s: %v", quota.ServiceCode, quota.QuotaCode, err)
+		logFetch(ctx, model.LogLevelWarn, region, quota.ServiceCode, "Direct API query failed for %s/%s: %v", quota.ServiceCode, quota.QuotaCode, err)
 		return
 	}
 
@@ -339,6 +543,9 @@ func updateQuotaUsage(quota *model.Quota, value float64) {
 type FetchResult struct {
 	Quotas   []model.Quota
 	Warnings []string
+	// DisabledRegions failed because they are not enabled for the account;
+	// they are reported apart from the warnings
+	DisabledRegions []string
 }
 
 func (f *QuotaFetcher) GetQuotasForAllRegions(ctx context.Context, regions []string, serviceFilter string) (*FetchResult, error) {
@@ -346,19 +553,29 @@ func (f *QuotaFetcher) GetQuotasForAllRegions(ctx context.Context, regions []str
 	g.SetLimit(f.maxConcurrency)
 
 	quotasChan := make(chan []model.Quota, len(regions))
-	var warnings []string
+	var warnings, disabled []string
 	var warningsMu sync.Mutex
 
 	for _, region := range regions {
 		region := region
 		g.Go(func() error {
+			logFetch(ctx, model.LogLevelInfo, region, serviceFilter, "Fetching quotas in region: %s", region)
 			quotas, err := f.GetQuotasForRegion(ctx, region, serviceFilter)
+			if err != nil && IsRegionDisabled(err) {
+				logFetch(ctx, model.LogLevelInfo, region, serviceFilter, "Skipping region %s: not enabled for this account", region)
+				warningsMu.Lock()
+				disabled = append(disabled, region)
+				warningsMu.Unlock()
+				return nil
+			}
 			if err != nil {
+				logFetch(ctx, model.LogLevelError, region, serviceFilter, "Failed to fetch quotas for region %s: %v", region, err)
 				warningsMu.Lock()
 				warnings = append(warnings, fmt.Sprintf("Failed to fetch quotas for region %s: %v", region, err))
 				warningsMu.Unlock()
 				return nil
 			}
+			logFetch(ctx, model.LogLevelInfo, region, serviceFilter, "Fetched %d quotas in region: %s", len(quotas), region)
 			quotasChan <- quotas
 			return nil
 		})
@@ -374,21 +591,25 @@ func (f *QuotaFetcher) GetQuotasForAllRegions(ctx context.Context, regions []str
 		allQuotas = append(allQuotas, quotas...)
 	}
 
-	allQuotas = deduplicateGlobalQuotas(allQuotas)
+	allQuotas = DeduplicateGlobalQuotas(allQuotas)
 
+	sort.Strings(disabled)
 	return &FetchResult{
-		Quotas:   allQuotas,
-		Warnings: warnings,
+		Quotas:          allQuotas,
+		Warnings:        warnings,
+		DisabledRegions: disabled,
 	}, nil
 }
 
-func deduplicateGlobalQuotas(quotas []model.Quota) []model.Quota {
+// DeduplicateGlobalQuotas keeps a single copy of each global quota per account,
+// reported under the "global" region
+func DeduplicateGlobalQuotas(quotas []model.Quota) []model.Quota {
 	seen := make(map[string]bool)
 	var result []model.Quota
 
 	for _, q := range quotas {
 		if q.Global {
-			key := q.ServiceCode + ":" + q.QuotaCode
+			key := q.AccountID + ":" + q.ServiceCode + ":" + q.QuotaCode
 			if seen[key] {
 				continue
 			}
@@ -406,3 +627,70 @@ func safeString(s *string) string {
 	}
 	return *s
 }
+
+// GetQuota fetches a single quota by code, preferring the applied value and
+// falling back to the AWS default when no value has been applied. quotaCode
+// may also be the key of a QuotaNameKeys entry, which is resolved to the code
+// of the service's quota matching its name.
+func (f *QuotaFetcher) GetQuota(ctx context.Context, region, serviceCode, quotaCode string) (*model.Quota, error) {
+	cfg, err := f.loadConfig(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	client := servicequotas.NewFromConfig(cfg)
+	if isQuotaNameKey(quotaCode) {
+		if quotaCode, err = f.resolveQuotaNameKey(ctx, client, serviceCode, quotaCode); err != nil {
+			return nil, err
+		}
+	}
+
+	var sq *sqtypes.ServiceQuota
+	applied, err := client.GetServiceQuota(ctx, &servicequotas.GetServiceQuotaInput{
+		ServiceCode: &serviceCode,
+		QuotaCode:   &quotaCode,
+	})
+	if err == nil {
+		sq = applied.Quota
+	} else {
+		def, defErr := client.GetAWSDefaultServiceQuota(ctx, &servicequotas.GetAWSDefaultServiceQuotaInput{
+			ServiceCode: &serviceCode,
+			QuotaCode:   &quotaCode,
+		})
+		if defErr != nil {
+			return nil, fmt.Errorf("failed to get quota %s/%s: %w", serviceCode, quotaCode, defErr)
+		}
+		sq = def.Quota
+	}
+	if sq == nil {
+		return nil, fmt.Errorf("quota %s/%s not found", serviceCode, quotaCode)
+	}
+
+	svc := model.Service{Code: serviceCode, Name: safeString(sq.ServiceName)}
+	quotas := f.buildQuotaList(ctx, cloudwatch.NewFromConfig(cfg), region, svc, map[string]sqtypes.ServiceQuota{quotaCode: *sq})
+	return &quotas[0], nil
+}
+
+// isQuotaNameKey reports whether key is the key of a QuotaNameKeys entry
+func isQuotaNameKey(key string) bool {
+	for _, k := range QuotaNameKeys {
+		if k.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveQuotaNameKey returns the code of the service's quota registered
+// under a QuotaNameKeys key
+func (f *QuotaFetcher) resolveQuotaNameKey(ctx context.Context, client *servicequotas.Client, serviceCode, key string) (string, error) {
+	quotas, err := f.listDefaultQuotas(ctx, client, serviceCode)
+	if err != nil {
+		return "", fmt.Errorf("failed to list quotas of %s: %w", serviceCode, err)
+	}
+	for _, q := range quotas {
+		if HandlerKey(serviceCode, *q.QuotaCode, safeString(q.QuotaName)) == key {
+			return *q.QuotaCode, nil
+		}
+	}
+	return "", fmt.Errorf("no %s quota matches %s", serviceCode, key)
+}
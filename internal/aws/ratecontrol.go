@@ -0,0 +1,157 @@
+package aws
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"golang.org/x/time/rate"
+)
+
+// Defaults of the adaptive rate controller, in requests per second per API
+const (
+	DefaultInitialRate = 20
+	DefaultMinRate     = 1
+	DefaultMaxRate     = 50
+)
+
+// rateIncreaseStep is added to the rate of an API after each successful call;
+// a throttled call halves it
+const rateIncreaseStep = 0.5
+
+// RateController paces AWS calls per region and API. Every API starts at the
+// initial rate so small accounts finish quickly; its rate halves whenever a
+// call is throttled and recovers additively while calls succeed, so large
+// accounts settle just below their throttling limits.
+type RateController struct {
+	mu                        sync.Mutex
+	initial, minRate, maxRate float64
+	apis                      map[string]*apiRate
+}
+
+type apiRate struct {
+	region    string
+	api       string
+	limiter   *rate.Limiter
+	requests  int
+	throttled int
+}
+
+func NewRateController(initial, minRate, maxRate float64) *RateController {
+	c := &RateController{apis: make(map[string]*apiRate)}
+	c.SetRates(initial, minRate, maxRate)
+	return c
+}
+
+// SetRates changes the bounds of the controller. Non-positive values keep the
+// defaults; rates already adapted are clamped to the new bounds.
+func (c *RateController) SetRates(initial, minRate, maxRate float64) {
+	if initial <= 0 {
+		initial = DefaultInitialRate
+	}
+	if minRate <= 0 {
+		minRate = DefaultMinRate
+	}
+	if maxRate <= 0 {
+		maxRate = DefaultMaxRate
+	}
+	maxRate = math.Max(maxRate, minRate)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.initial = math.Min(math.Max(initial, minRate), maxRate)
+	c.minRate, c.maxRate = minRate, maxRate
+	for _, a := range c.apis {
+		c.setLimit(a, float64(a.limiter.Limit()))
+	}
+}
+
+// Stats returns the current rate and throttling of every API called so far,
+// most throttled first
+func (c *RateController) Stats() []model.APIRate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make([]model.APIRate, 0, len(c.apis))
+	for _, a := range c.apis {
+		s := model.APIRate{
+			Region:    a.region,
+			API:       a.api,
+			Rate:      float64(a.limiter.Limit()),
+			Requests:  a.requests,
+			Throttled: a.throttled,
+		}
+		if a.requests > 0 {
+			s.ThrottlePercentage = float64(a.throttled) / float64(a.requests) * 100
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Throttled != stats[j].Throttled {
+			return stats[i].Throttled > stats[j].Throttled
+		}
+		return stats[i].Region+stats[i].API < stats[j].Region+stats[j].API
+	})
+	return stats
+}
+
+func (c *RateController) get(region, api string) *apiRate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := region + "/" + api
+	a, ok := c.apis[key]
+	if !ok {
+		a = &apiRate{region: region, api: api, limiter: rate.NewLimiter(rate.Limit(c.initial), burst(c.initial))}
+		c.apis[key] = a
+	}
+	return a
+}
+
+// observe adapts the rate of an API to the outcome of one call attempt
+func (c *RateController) observe(a *apiRate, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a.requests++
+	current := float64(a.limiter.Limit())
+	if err != nil && ClassifyError(err) == model.FetchStatusThrottled {
+		a.throttled++
+		c.setLimit(a, current/2)
+		return
+	}
+	if err == nil && current < c.maxRate {
+		c.setLimit(a, current+rateIncreaseStep)
+	}
+}
+
+func (c *RateController) setLimit(a *apiRate, limit float64) {
+	limit = math.Min(math.Max(limit, c.minRate), c.maxRate)
+	a.limiter.SetLimit(rate.Limit(limit))
+	a.limiter.SetBurst(burst(limit))
+}
+
+// burst allows up to one second of requests at once
+func burst(limit float64) int {
+	return int(math.Max(1, math.Ceil(limit)))
+}
+
+// addMiddleware paces every attempt of a call, including retries, so the
+// controller sees each throttling response
+func (c *RateController) addMiddleware(stack *middleware.Stack) error {
+	mw := middleware.FinalizeMiddlewareFunc("AdaptiveRateControl", func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+		a := c.get(awsmiddleware.GetRegion(ctx), awsmiddleware.GetServiceID(ctx)+"."+awsmiddleware.GetOperationName(ctx))
+		if err := a.limiter.Wait(ctx); err != nil {
+			return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+		}
+		countCall(ctx)
+		out, metadata, err := next.HandleFinalize(ctx, in)
+		c.observe(a, err)
+		return out, metadata, err
+	})
+	if err := stack.Finalize.Insert(mw, "Retry", middleware.After); err != nil {
+		return stack.Finalize.Add(mw, middleware.After)
+	}
+	return nil
+}
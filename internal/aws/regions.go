@@ -7,6 +7,15 @@ import (
 	"github.com/yuxishi/aws-quota-dashboard/internal/model"
 )
 
+// Opt-in statuses reported by DescribeRegions
+const (
+	OptInNotRequired = "opt-in-not-required"
+	OptedIn          = "opted-in"
+	NotOptedIn       = "not-opted-in"
+)
+
+// GetRegions lists all regions of the partition. Regions the account has not
+// opted into are included with Enabled false.
 func GetRegions(ctx context.Context) ([]model.Region, error) {
 	cfg, err := LoadConfig(ctx, "us-east-1")
 	if err != nil {
@@ -15,7 +24,7 @@ func GetRegions(ctx context.Context) ([]model.Region, error) {
 
 	client := ec2.NewFromConfig(cfg)
 	output, err := client.DescribeRegions(ctx, &ec2.DescribeRegionsInput{
-		AllRegions: boolPtr(false),
+		AllRegions: boolPtr(true),
 	})
 	if err != nil {
 		return nil, err
@@ -23,14 +32,32 @@ func GetRegions(ctx context.Context) ([]model.Region, error) {
 
 	regions := make([]model.Region, 0, len(output.Regions))
 	for _, r := range output.Regions {
+		status := safeString(r.OptInStatus)
 		regions = append(regions, model.Region{
-			Code: *r.RegionName,
-			Name: *r.RegionName,
+			Code:        *r.RegionName,
+			Name:        *r.RegionName,
+			OptInStatus: status,
+			Enabled:     status != NotOptedIn,
 		})
 	}
 	return regions, nil
 }
 
+// SplitRegions separates the enabled regions from those the account has not
+// opted into
+func SplitRegions(regions []model.Region) (enabled, disabled []model.Region) {
+	enabled = make([]model.Region, 0, len(regions))
+	disabled = make([]model.Region, 0)
+	for _, r := range regions {
+		if r.Enabled {
+			enabled = append(enabled, r)
+		} else {
+			disabled = append(disabled, r)
+		}
+	}
+	return enabled, disabled
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
@@ -0,0 +1,93 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/support"
+	"github.com/aws/smithy-go"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+const (
+	// supportRegion serves the AWS Support API, which has no other regional
+	// endpoints in the commercial partition
+	supportRegion = "us-east-1"
+
+	// supportServiceCode files cases under "Service limit increase"
+	supportServiceCode = "service-limit-increase"
+)
+
+// IsSupportPlanRequired reports whether err means the account has no
+// Business, Enterprise On-Ramp or Enterprise support plan, which the Support
+// API requires
+func IsSupportPlanRequired(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "SubscriptionRequiredException"
+}
+
+// CreateSupportCase opens a service limit increase case and returns its ID
+func (f *QuotaFetcher) CreateSupportCase(ctx context.Context, subject, body, severity string, ccEmails []string) (string, error) {
+	cfg, err := f.loadConfig(ctx, supportRegion)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := support.NewFromConfig(cfg).CreateCase(ctx, &support.CreateCaseInput{
+		Subject:           aws.String(subject),
+		CommunicationBody: aws.String(body),
+		ServiceCode:       aws.String(supportServiceCode),
+		SeverityCode:      aws.String(severity),
+		Language:          aws.String("en"),
+		CcEmailAddresses:  ccEmails,
+	})
+	if err != nil {
+		return "", err
+	}
+	return safeString(output.CaseId), nil
+}
+
+// FindSupportCases returns the cases created since the given time whose
+// subject contains the search text, resolved ones included, newest first
+func (f *QuotaFetcher) FindSupportCases(ctx context.Context, search string, since time.Time) ([]model.SupportCase, error) {
+	cfg, err := f.loadConfig(ctx, supportRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := []model.SupportCase{}
+	paginator := support.NewDescribeCasesPaginator(support.NewFromConfig(cfg), &support.DescribeCasesInput{
+		AfterTime:             aws.String(since.UTC().Format(time.RFC3339)),
+		IncludeResolvedCases:  true,
+		IncludeCommunications: aws.Bool(false),
+		MaxResults:            aws.Int32(100),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range page.Cases {
+			subject := safeString(c.Subject)
+			if !strings.Contains(subject, search) {
+				continue
+			}
+			cases = append(cases, model.SupportCase{
+				ID:          safeString(c.CaseId),
+				DisplayID:   safeString(c.DisplayId),
+				Subject:     subject,
+				Status:      safeString(c.Status),
+				Severity:    safeString(c.SeverityCode),
+				SubmittedBy: safeString(c.SubmittedBy),
+				CreatedAt:   safeString(c.TimeCreated),
+			})
+		}
+	}
+	// Creation times are ISO 8601 in UTC, so they sort as strings
+	sort.Slice(cases, func(i, j int) bool { return cases[i].CreatedAt > cases[j].CreatedAt })
+	return cases, nil
+}
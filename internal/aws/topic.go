@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// TopicRegion returns the region of an SNS topic ARN
+// (arn:aws:sns:<region>:<account>:<name>)
+func TopicRegion(topicARN string) (string, error) {
+	parts := strings.Split(topicARN, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sns" || parts[3] == "" {
+		return "", fmt.Errorf("invalid SNS topic ARN %q", topicARN)
+	}
+	return parts[3], nil
+}
+
+// PublishEvent publishes a JSON message to an SNS topic. The attributes are
+// set as string message attributes, so subscriptions can filter on them;
+// empty ones are left out.
+func PublishEvent(ctx context.Context, topicARN, subject string, message []byte, attributes map[string]string) error {
+	region, err := TopicRegion(topicARN)
+	if err != nil {
+		return err
+	}
+	cfg, err := LoadConfig(ctx, region)
+	if err != nil {
+		return err
+	}
+
+	attrs := make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		if value != "" {
+			attrs[name] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+		}
+	}
+	// Subjects are limited to 100 characters
+	if len(subject) > 100 {
+		subject = subject[:97] + "..."
+	}
+	_, err = sns.NewFromConfig(cfg).Publish(ctx, &sns.PublishInput{
+		TopicArn:          aws.String(topicARN),
+		Subject:           aws.String(subject),
+		Message:           aws.String(string(message)),
+		MessageAttributes: attrs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topicARN, err)
+	}
+	return nil
+}
@@ -2,30 +2,75 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	apigwtypes "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/appsync"
+	appsynctypes "github.com/aws/aws-sdk-go-v2/service/appsync/types"
+	"github.com/aws/aws-sdk-go-v2/service/athena"
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cfntypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codebuild"
+	cbtypes "github.com/aws/aws-sdk-go-v2/service/codebuild/types"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
+	dxtypes "github.com/aws/aws-sdk-go-v2/service/directconnect/types"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk"
+	ebstypes "github.com/aws/aws-sdk-go-v2/service/elasticbeanstalk/types"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/emr"
+	emrtypes "github.com/aws/aws-sdk-go-v2/service/emr/types"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	ebtypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/globalaccelerator"
+	"github.com/aws/aws-sdk-go-v2/service/glue"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	kinesistypes "github.com/aws/aws-sdk-go-v2/service/kinesis/types"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+	opensearchtypes "github.com/aws/aws-sdk-go-v2/service/opensearch/types"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3control"
+	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/sagemaker/types"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+	waftypes "github.com/aws/aws-sdk-go-v2/service/wafv2/types"
 	"github.com/yuxishi/aws-quota-dashboard/internal/model"
 )
 
-// QuotaCodeToServiceMapping maps quota codes to their service and usage type
+// QuotaCodeToServiceMapping maps quota codes (or QuotaNameKeys) to their
+// service and usage type
 // This helps identify which direct API to call for specific quotas
 var QuotaCodeToServiceMapping = map[string]UsageHandler{
 	// EKS
@@ -35,13 +80,23 @@ var QuotaCodeToServiceMapping = map[string]UsageHandler{
 	"L-6E77F4DE": {ServiceCode: "eks", Handler: getEKSAddonsUsage},
 
 	// EC2
-	"L-1216C47A": {ServiceCode: "ec2", Handler: getEC2RunningInstancesUsage},
-	"L-0263D0A3": {ServiceCode: "ec2", Handler: getElasticIPsUsage},
-	"L-0E3CBAB9": {ServiceCode: "ec2", Handler: getEC2KeyPairsUsage},
-	"L-0DA580E9": {ServiceCode: "ec2", Handler: getEC2AMIsUsage},
-	"L-309BACF6": {ServiceCode: "ec2", Handler: getEC2SnapshotsUsage},
-	"L-407747CB": {ServiceCode: "ec2", Handler: getEC2InternetGatewaysUsage},
-	"L-FE5A380F": {ServiceCode: "ec2", Handler: getEC2NATGatewaysUsage},
+	"L-1216C47A":                   {ServiceCode: "ec2", Handler: getEC2RunningInstancesUsage},
+	"L-DB2E81BA":                   {ServiceCode: "ec2", Handler: ec2VCPUUsageHandler(ec2GVTVCPUQuota)},
+	"L-417A185B":                   {ServiceCode: "ec2", Handler: ec2VCPUUsageHandler(ec2PVCPUQuota)},
+	"L-7295265B":                   {ServiceCode: "ec2", Handler: ec2VCPUUsageHandler(ec2XVCPUQuota)},
+	"L-74FC7D96":                   {ServiceCode: "ec2", Handler: ec2VCPUUsageHandler(ec2FVCPUQuota)},
+	"L-1945791B":                   {ServiceCode: "ec2", Handler: ec2VCPUUsageHandler(ec2InfVCPUQuota)},
+	"L-2C3B7624":                   {ServiceCode: "ec2", Handler: ec2VCPUUsageHandler(ec2TrnVCPUQuota)},
+	"L-0263D0A3":                   {ServiceCode: "ec2", Handler: getElasticIPsUsage},
+	"L-0E3CBAB9":                   {ServiceCode: "ec2", Handler: getEC2KeyPairsUsage},
+	"L-0DA580E9":                   {ServiceCode: "ec2", Handler: getEC2AMIsUsage},
+	"L-309BACF6":                   {ServiceCode: "ec2", Handler: getEC2SnapshotsUsage},
+	"L-407747CB":                   {ServiceCode: "ec2", Handler: getEC2InternetGatewaysUsage},
+	"L-FE5A380F":                   {ServiceCode: "ec2", Handler: getEC2NATGatewaysUsage},
+	"ec2:capacity-reservations":    {ServiceCode: "ec2", Handler: getEC2CapacityReservationsUsage},
+	"ec2:launch-templates":         {ServiceCode: "ec2", Handler: getEC2LaunchTemplatesUsage},
+	"ec2:launch-template-versions": {ServiceCode: "ec2", Handler: getEC2LaunchTemplateVersionsUsage},
+	"L-E2B68F07":                   {ServiceCode: "ec2", Handler: getEC2PlacementGroupsUsage},
 
 	// EBS
 	"L-D18FCD1D": {ServiceCode: "ebs", Handler: getEBSGP2Usage},
@@ -53,6 +108,13 @@ var QuotaCodeToServiceMapping = map[string]UsageHandler{
 	"L-F678F1CE": {ServiceCode: "vpc", Handler: getVPCsUsage},
 	"L-DF5E4CA3": {ServiceCode: "vpc", Handler: getNetworkInterfacesUsage},
 	"L-E79EC296": {ServiceCode: "vpc", Handler: getSecurityGroupsUsage},
+	"L-29B6F2EB": {ServiceCode: "vpc", Handler: getInterfaceEndpointsPerVPCUsage},
+	"L-1B52E74A": {ServiceCode: "vpc", Handler: getGatewayEndpointsUsage},
+	"L-7E9ECCDB": {ServiceCode: "vpc", Handler: getActivePeeringConnectionsPerVPCUsage},
+	"L-44499CD2": {ServiceCode: "vpc", Handler: getSubnetsPerVPCUsage},
+	"L-589F43AA": {ServiceCode: "vpc", Handler: getRouteTablesPerVPCUsage},
+	"L-B4A6D682": {ServiceCode: "vpc", Handler: getNetworkACLsPerVPCUsage},
+	"L-0EA8095F": {ServiceCode: "vpc", Handler: getRulesPerSecurityGroupUsage},
 
 	// ELB
 	"L-53DA6B97": {ServiceCode: "elasticloadbalancing", Handler: getALBsUsage},
@@ -63,14 +125,22 @@ var QuotaCodeToServiceMapping = map[string]UsageHandler{
 	"L-CDE20ADC": {ServiceCode: "autoscaling", Handler: getAutoScalingGroupsUsage},
 
 	// S3
-	"L-DC2B2D3D": {ServiceCode: "s3", Handler: getS3BucketsUsage},
+	"L-DC2B2D3D":                    {ServiceCode: "s3", Handler: getS3BucketsUsage},
+	"s3:access-points":              {ServiceCode: "s3", Handler: getS3AccessPointsUsage, NeedsAccountID: true},
+	"s3:multi-region-access-points": {ServiceCode: "s3", Handler: getS3MultiRegionAccessPointsUsage, NeedsAccountID: true},
 
 	// Lambda
 	"L-9FEE3D26": {ServiceCode: "lambda", Handler: getLambdaFunctionsUsage},
 
 	// RDS
-	"L-7B6409FD": {ServiceCode: "rds", Handler: getRDSInstancesUsage},
-	"L-952B80B8": {ServiceCode: "rds", Handler: getRDSClustersUsage},
+	"L-7B6409FD":                   {ServiceCode: "rds", Handler: getRDSInstancesUsage},
+	"L-952B80B8":                   {ServiceCode: "rds", Handler: getRDSClustersUsage},
+	"L-272F1212":                   {ServiceCode: "rds", Handler: rdsAccountQuotaHandler("ManualSnapshots")},
+	"L-9B510759":                   {ServiceCode: "rds", Handler: rdsAccountQuotaHandler("ManualClusterSnapshots")},
+	"L-48C6BF81":                   {ServiceCode: "rds", Handler: rdsAccountQuotaHandler("DBSubnetGroups")},
+	"L-DE55804A":                   {ServiceCode: "rds", Handler: rdsAccountQuotaHandler("DBParameterGroups")},
+	"rds:cluster-parameter-groups": {ServiceCode: "rds", Handler: rdsAccountQuotaHandler("DBClusterParameterGroups")},
+	"L-5BC124EF":                   {ServiceCode: "rds", Handler: getRDSReadReplicasPerSourceUsage},
 
 	// DynamoDB
 	"L-F98FE922": {ServiceCode: "dynamodb", Handler: getDynamoDBTablesUsage},
@@ -79,13 +149,19 @@ var QuotaCodeToServiceMapping = map[string]UsageHandler{
 	"L-5B2E3F44": {ServiceCode: "cloudfront", Handler: getCloudFrontDistributionsUsage},
 
 	// Route53
-	"L-ACB674F3": {ServiceCode: "route53", Handler: getRoute53HostedZonesUsage},
+	"route53:hosted-zones":  {ServiceCode: "route53", Handler: getRoute53HostedZonesUsage},
+	"L-E209CC9F":            {ServiceCode: "route53", Handler: getRoute53RecordsPerZoneUsage},
+	"route53:health-checks": {ServiceCode: "route53", Handler: getRoute53HealthChecksUsage},
 
 	// IAM
 	"L-4019AD8D": {ServiceCode: "iam", Handler: getIAMUsersUsage},
 	"L-FE177D64": {ServiceCode: "iam", Handler: getIAMRolesUsage},
 	"L-0DA4ABF3": {ServiceCode: "iam", Handler: getIAMGroupsUsage},
 	"L-D0B7243C": {ServiceCode: "iam", Handler: getIAMPoliciesUsage},
+	"L-6E65F664": {ServiceCode: "iam", Handler: getIAMInstanceProfilesUsage},
+	"L-DB618D39": {ServiceCode: "iam", Handler: getIAMSAMLProvidersUsage},
+	"L-858F3967": {ServiceCode: "iam", Handler: getIAMOIDCProvidersUsage},
+	"L-BF35879D": {ServiceCode: "iam", Handler: getIAMServerCertificatesUsage},
 
 	// SNS
 	"L-61103206": {ServiceCode: "sns", Handler: getSNSTopicsUsage},
@@ -95,19 +171,391 @@ var QuotaCodeToServiceMapping = map[string]UsageHandler{
 
 	// ECR
 	"L-CFEB8E8D": {ServiceCode: "ecr", Handler: getECRRepositoriesUsage},
+	"L-03A36CE1": {ServiceCode: "ecr", Handler: getECRImagesPerRepositoryUsage},
+
+	// Fargate
+	"L-3032A538": {ServiceCode: "fargate", Handler: getFargateOnDemandVCPUUsage},
+	"L-36FBB829": {ServiceCode: "fargate", Handler: getFargateSpotVCPUUsage},
+
+	// CloudFormation
+	"L-0485CB21": {ServiceCode: "cloudformation", Handler: getCloudFormationStacksUsage},
+	"L-31709F13": {ServiceCode: "cloudformation", Handler: getCloudFormationStackSetsUsage},
+
+	// Kinesis Data Streams
+	"L-E16B1B7C": {ServiceCode: "kinesis", Handler: getKinesisShardsUsage},
+	"L-D6C6A47E": {ServiceCode: "kinesis", Handler: getKinesisOnDemandStreamsUsage},
+
+	// API Gateway
+	"L-AA0FF27B": {ServiceCode: "apigateway", Handler: getAPIGatewayRegionalRestAPIsUsage},
+	"L-B97207D0": {ServiceCode: "apigateway", Handler: getAPIGatewayEdgeRestAPIsUsage},
+	"L-BCF49E9D": {ServiceCode: "apigateway", Handler: getAPIGatewayHTTPAPIsUsage},
+	"L-A4C7274F": {ServiceCode: "apigateway", Handler: getAPIGatewayVPCLinksUsage},
+
+	// EventBridge
+	"L-244521F2": {ServiceCode: "events", Handler: getEventBridgeRulesPerBusUsage},
+	"L-BAE1A5C4": {ServiceCode: "events", Handler: getEventBridgeEventBusesUsage},
+
+	// CloudWatch
+	"L-7F53D9D2": {ServiceCode: "monitoring", Handler: getCloudWatchMetricAlarmsUsage},
+	"L-A7CF0D2F": {ServiceCode: "monitoring", Handler: getCloudWatchDashboardsUsage},
+	"L-D2832119": {ServiceCode: "logs", Handler: getCloudWatchLogGroupsUsage},
+
+	// Glue
+	"L-611FFD30":  {ServiceCode: "glue", Handler: getGlueJobsUsage},
+	"L-F1F2CC26":  {ServiceCode: "glue", Handler: getGlueCrawlersUsage},
+	"L-4AFE9C52":  {ServiceCode: "glue", Handler: getGlueDatabasesUsage},
+	"glue:tables": {ServiceCode: "glue", Handler: getGlueTablesUsage},
+
+	// Athena
+	"athena:workgroups":                        {ServiceCode: "athena", Handler: getAthenaWorkGroupsUsage},
+	"athena:prepared-statements-per-workgroup": {ServiceCode: "athena", Handler: getAthenaPreparedStatementsPerWorkGroupUsage},
+
+	// EMR
+	"elasticmapreduce:active-clusters":  {ServiceCode: "elasticmapreduce", Handler: getEMRActiveClustersUsage},
+	"elasticmapreduce:active-instances": {ServiceCode: "elasticmapreduce", Handler: getEMRClusterInstancesUsage},
+
+	// MSK
+	"kafka:clusters": {ServiceCode: "kafka", Handler: getMSKClustersUsage},
+	"kafka:brokers":  {ServiceCode: "kafka", Handler: getMSKBrokersUsage},
+
+	// OpenSearch Service
+	"L-076D529E": {ServiceCode: "es", Handler: getOpenSearchDomainsUsage},
+	"L-6408ABDE": {ServiceCode: "es", Handler: getOpenSearchInstancesPerDomainUsage},
+
+	// Transit Gateway
+	"L-A2478D36": {ServiceCode: "ec2", Handler: getTransitGatewaysUsage},
+	"L-E0233F82": {ServiceCode: "ec2", Handler: getTransitGatewayAttachmentsUsage},
+
+	// Direct Connect
+	"directconnect:connections":                 {ServiceCode: "directconnect", Handler: getDirectConnectConnectionsUsage},
+	"directconnect:private-vifs-per-connection": {ServiceCode: "directconnect", Handler: getDirectConnectPrivateVIFsPerConnectionUsage},
+	"directconnect:public-vifs-per-connection":  {ServiceCode: "directconnect", Handler: getDirectConnectPublicVIFsPerConnectionUsage},
+
+	// Global Accelerator
+	"globalaccelerator:accelerators":              {ServiceCode: "globalaccelerator", Handler: getGlobalAcceleratorsUsage},
+	"globalaccelerator:listeners-per-accelerator": {ServiceCode: "globalaccelerator", Handler: getGlobalAcceleratorListenersPerAcceleratorUsage},
+
+	// WAFv2
+	"wafv2:regional-web-acls":           {ServiceCode: "wafv2", Handler: getWAFRegionalWebACLsUsage},
+	"wafv2:cloudfront-web-acls":         {ServiceCode: "wafv2", Handler: getWAFCloudFrontWebACLsUsage},
+	"wafv2:regional-web-acl-capacity":   {ServiceCode: "wafv2", Handler: getWAFRegionalWebACLCapacityUsage},
+	"wafv2:cloudfront-web-acl-capacity": {ServiceCode: "wafv2", Handler: getWAFCloudFrontWebACLCapacityUsage},
+
+	// CodeBuild
+	"codebuild:projects": {ServiceCode: "codebuild", Handler: getCodeBuildProjectsUsage},
+
+	// Cognito
+	"cognito-idp:user-pools":                {ServiceCode: "cognito-idp", Handler: getCognitoUserPoolsUsage},
+	"cognito-idp:app-clients-per-user-pool": {ServiceCode: "cognito-idp", Handler: getCognitoAppClientsPerUserPoolUsage},
+
+	// AppSync
+	"appsync:graphql-apis":      {ServiceCode: "appsync", Handler: getAppSyncGraphQLAPIsUsage},
+	"appsync:resolvers-per-api": {ServiceCode: "appsync", Handler: getAppSyncResolversPerAPIUsage},
+
+	// Elastic Beanstalk
+	"L-1CEABD17":                            {ServiceCode: "elasticbeanstalk", Handler: getBeanstalkApplicationsUsage},
+	"elasticbeanstalk:application-versions": {ServiceCode: "elasticbeanstalk", Handler: getBeanstalkApplicationVersionsUsage},
+	"L-8EFC1C51":                            {ServiceCode: "elasticbeanstalk", Handler: getBeanstalkEnvironmentsUsage},
 }
 
 type UsageHandler struct {
 	ServiceCode string
 	Handler     func(context.Context, aws.Config, string) (float64, error)
+	// NeedsAccountID resolves the account of the credentials before the
+	// handler runs, for APIs taking it as a parameter; the handler reads it
+	// with accountIDOf
+	NeedsAccountID bool
+}
+
+// QuotaNameKeys register quotas by name instead of code, for quotas whose code
+// has not been confirmed against Service Quotas. The usage, ownership and
+// attribution handlers of such a quota are keyed by Key rather than a quota
+// code; HandlerKey resolves it from the listed quota.
+var QuotaNameKeys = []QuotaNameKey{
+	// MSK
+	{ServiceCode: "kafka", Pattern: regexp.MustCompile(`(?i)^(number of )?clusters per account$`), Key: "kafka:clusters"},
+	{ServiceCode: "kafka", Pattern: regexp.MustCompile(`(?i)^(number of )?brokers per account$`), Key: "kafka:brokers"},
+
+	// WAFv2
+	{ServiceCode: "wafv2", Pattern: regexp.MustCompile(`(?i)^(maximum )?web ACLs per account in (AWS )?WAF for regional$`), Key: "wafv2:regional-web-acls"},
+	{ServiceCode: "wafv2", Pattern: regexp.MustCompile(`(?i)^(maximum )?web ACLs per account in (AWS )?WAF for CloudFront$`), Key: "wafv2:cloudfront-web-acls"},
+	{ServiceCode: "wafv2", Pattern: regexp.MustCompile(`(?i)^(maximum )?web ACL capacity units (\(WCUs?\) )?in a web ACL in (AWS )?WAF for regional$`), Key: "wafv2:regional-web-acl-capacity"},
+	{ServiceCode: "wafv2", Pattern: regexp.MustCompile(`(?i)^(maximum )?web ACL capacity units (\(WCUs?\) )?in a web ACL in (AWS )?WAF for CloudFront$`), Key: "wafv2:cloudfront-web-acl-capacity"},
+
+	// AppSync
+	{ServiceCode: "appsync", Pattern: regexp.MustCompile(`(?i)^(number of )?GraphQL APIs( per (account|Region))?$`), Key: "appsync:graphql-apis"},
+	{ServiceCode: "appsync", Pattern: regexp.MustCompile(`(?i)^(number of )?resolvers per (GraphQL )?API$`), Key: "appsync:resolvers-per-api"},
+
+	// Route 53
+	{ServiceCode: "route53", Pattern: regexp.MustCompile(`(?i)^(number of )?hosted zones( per account)?$`), Key: "route53:hosted-zones"},
+	{ServiceCode: "route53", Pattern: regexp.MustCompile(`(?i)^(number of )?health checks( per account)?$`), Key: "route53:health-checks"},
+
+	// Glue
+	{ServiceCode: "glue", Pattern: regexp.MustCompile(`(?i)^(number of )?tables per account$`), Key: "glue:tables"},
+
+	// Athena
+	{ServiceCode: "athena", Pattern: regexp.MustCompile(`(?i)^(number of )?workgroups( per account)?$`), Key: "athena:workgroups"},
+	{ServiceCode: "athena", Pattern: regexp.MustCompile(`(?i)^(number of )?prepared statements per workgroup$`), Key: "athena:prepared-statements-per-workgroup"},
+
+	// EMR
+	{ServiceCode: "elasticmapreduce", Pattern: regexp.MustCompile(`(?i)^(number of )?active clusters( per account)?$`), Key: "elasticmapreduce:active-clusters"},
+	{ServiceCode: "elasticmapreduce", Pattern: regexp.MustCompile(`(?i)^(number of )?active instances across all clusters$`), Key: "elasticmapreduce:active-instances"},
+
+	// Direct Connect
+	{ServiceCode: "directconnect", Pattern: regexp.MustCompile(`(?i)^(number of )?dedicated connections per Region per account$`), Key: "directconnect:connections"},
+	{ServiceCode: "directconnect", Pattern: regexp.MustCompile(`(?i)^(number of )?private or transit virtual interfaces per (Direct Connect )?dedicated connection$`), Key: "directconnect:private-vifs-per-connection"},
+	{ServiceCode: "directconnect", Pattern: regexp.MustCompile(`(?i)^(number of )?public virtual interfaces per (Direct Connect )?dedicated connection$`), Key: "directconnect:public-vifs-per-connection"},
+
+	// Global Accelerator
+	{ServiceCode: "globalaccelerator", Pattern: regexp.MustCompile(`(?i)^(number of )?(standard )?accelerators per account$`), Key: "globalaccelerator:accelerators"},
+	{ServiceCode: "globalaccelerator", Pattern: regexp.MustCompile(`(?i)^(number of )?listeners per accelerator$`), Key: "globalaccelerator:listeners-per-accelerator"},
+
+	// CodeBuild
+	{ServiceCode: "codebuild", Pattern: regexp.MustCompile(`(?i)^(number of )?build projects( per (account|Region))?$`), Key: "codebuild:projects"},
+
+	// Cognito User Pools
+	{ServiceCode: "cognito-idp", Pattern: regexp.MustCompile(`(?i)^(number of )?user pools( per (account|Region))?$`), Key: "cognito-idp:user-pools"},
+	{ServiceCode: "cognito-idp", Pattern: regexp.MustCompile(`(?i)^(number of )?app clients per user pool$`), Key: "cognito-idp:app-clients-per-user-pool"},
+
+	// Elastic Beanstalk
+	{ServiceCode: "elasticbeanstalk", Pattern: regexp.MustCompile(`(?i)^(number of )?application versions( per (account|Region))?$`), Key: "elasticbeanstalk:application-versions"},
+
+	// EC2 capacity reservations
+	{ServiceCode: "ec2", Pattern: regexp.MustCompile(`(?i)^(number of )?(open )?On-Demand Capacity Reservations( per Region)?$`), Key: "ec2:capacity-reservations"},
+
+	// EC2 launch templates
+	{ServiceCode: "ec2", Pattern: regexp.MustCompile(`(?i)^(number of )?launch templates( per Region)?$`), Key: "ec2:launch-templates"},
+	{ServiceCode: "ec2", Pattern: regexp.MustCompile(`(?i)^(number of )?versions per launch template$`), Key: "ec2:launch-template-versions"},
+
+	// RDS
+	{ServiceCode: "rds", Pattern: regexp.MustCompile(`(?i)^(number of )?DB cluster parameter groups$`), Key: "rds:cluster-parameter-groups"},
+
+	// S3 access points
+	{ServiceCode: "s3", Pattern: regexp.MustCompile(`(?i)^(number of )?access points( per (account|Region))?$`), Key: "s3:access-points"},
+	{ServiceCode: "s3", Pattern: regexp.MustCompile(`(?i)^(number of )?Multi-Region Access Points( per account)?$`), Key: "s3:multi-region-access-points"},
+}
+
+// QuotaNameKey keys the handlers of the quotas of a service whose name matches
+// Pattern
+type QuotaNameKey struct {
+	ServiceCode string
+	Pattern     *regexp.Regexp
+	Key         string
+}
+
+// HandlerKey returns the key the handlers of a quota are registered under: the
+// key of the first name pattern the quota matches, otherwise its code
+func HandlerKey(serviceCode, quotaCode, quotaName string) string {
+	for _, k := range QuotaNameKeys {
+		if k.ServiceCode == serviceCode && k.Pattern.MatchString(quotaName) {
+			return k.Key
+		}
+	}
+	return quotaCode
+}
+
+// QuotaNameUsageHandlers cover families of quotas that share a name pattern,
+// such as the per-instance-type quotas of SageMaker, instead of one quota code
+var QuotaNameUsageHandlers = []NameUsageHandler{
+	// EC2
+	{
+		ServiceCode: "ec2",
+		Pattern:     regexp.MustCompile(`^Running Dedicated (\S+) Hosts$`),
+		Count:       getEC2DedicatedHostCounts,
+	},
+
+	// SageMaker
+	{
+		ServiceCode: "sagemaker",
+		Pattern:     regexp.MustCompile(`^(ml\.\S+) for endpoint usage$`),
+		Count:       getSageMakerEndpointInstanceCounts,
+	},
+	{
+		ServiceCode: "sagemaker",
+		Pattern:     regexp.MustCompile(`^(ml\.\S+) for notebook instance usage$`),
+		Count:       getSageMakerNotebookInstanceCounts,
+	},
+	{
+		ServiceCode: "sagemaker",
+		Pattern:     regexp.MustCompile(`^(ml\.\S+) for training job usage$`),
+		Count:       getSageMakerTrainingInstanceCounts,
+	},
+
+	// CodeBuild, one concurrent build quota per environment and compute type
+	// (e.g. "Concurrently running builds for Linux/Small environment")
+	{
+		ServiceCode: "codebuild",
+		Pattern:     regexp.MustCompile(`(?i)^concurrently running builds for (.+?) environments?$`),
+		Count:       getCodeBuildRunningBuildCounts,
+		Key:         codeBuildEnvironmentKey,
+	},
+}
+
+// NameUsageHandler counts usage for the quotas whose name matches Pattern.
+// Count returns the usage of the whole family keyed by the first submatch of
+// the pattern (e.g. the instance type), passed through Key when set; a missing
+// key means zero usage.
+type NameUsageHandler struct {
+	ServiceCode string
+	Pattern     *regexp.Regexp
+	Count       func(context.Context, aws.Config, string) (map[string]float64, error)
+	Key         func(string) string
+}
+
+// usageCountsTTL is how long the counts of a name handler are reused, so a
+// family of hundreds of quotas costs one set of API calls per fetch
+const usageCountsTTL = time.Minute
+
+type usageCountsEntry struct {
+	counts    map[string]float64
+	err       error
+	fetchedAt time.Time
+	done      chan struct{}
+	// canceled marks a fetch cut short by its caller's context
+	canceled bool
+}
+
+// usageCounts memoizes name handler counts per handler and region
+type usageCounts struct {
+	mu      sync.Mutex
+	entries map[string]*usageCountsEntry
+}
+
+func newUsageCounts() *usageCounts {
+	return &usageCounts{entries: make(map[string]*usageCountsEntry)}
+}
+
+// get returns the memoized counts for key, calling fetch at most once per
+// TTL; concurrent callers wait for the same fetch until ctx is done. Only
+// successful counts are kept, and callers waiting on a fetch cut short by its
+// caller's context fetch again themselves.
+func (u *usageCounts) get(ctx context.Context, key string, fetch func() (map[string]float64, error)) (map[string]float64, error) {
+	for {
+		u.mu.Lock()
+		entry, ok := u.entries[key]
+		if !ok || time.Since(entry.fetchedAt) >= usageCountsTTL {
+			break
+		}
+		u.mu.Unlock()
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.canceled {
+			continue
+		}
+		return entry.counts, entry.err
+	}
+	entry := &usageCountsEntry{fetchedAt: time.Now(), done: make(chan struct{})}
+	u.entries[key] = entry
+	u.mu.Unlock()
+
+	entry.counts, entry.err = fetch()
+	entry.canceled = entry.err != nil && ctx.Err() != nil
+	if entry.err != nil {
+		u.mu.Lock()
+		if u.entries[key] == entry {
+			delete(u.entries, key)
+		}
+		u.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.counts, entry.err
+}
+
+// getUsageByName resolves usage through the name handlers
+func (f *QuotaFetcher) getUsageByName(ctx context.Context, region string, quota *model.Quota) (float64, bool, error) {
+	for i, handler := range QuotaNameUsageHandlers {
+		if handler.ServiceCode != quota.ServiceCode {
+			continue
+		}
+		match := handler.Pattern.FindStringSubmatch(quota.QuotaName)
+		if match == nil {
+			continue
+		}
+
+		cfg, err := f.loadConfig(ctx, region)
+		if err != nil {
+			return 0, false, err
+		}
+		counts, err := f.usageCounts.get(ctx, fmt.Sprintf("%d:%s", i, region), func() (map[string]float64, error) {
+			ctx, cancel := f.handlerContext(ctx)
+			defer cancel()
+			return handler.Count(ctx, cfg, region)
+		})
+		if err != nil {
+			return 0, false, err
+		}
+		key := match[1]
+		if handler.Key != nil {
+			key = handler.Key(key)
+		}
+		return counts[key], true, nil
+	}
+	return 0, false, nil
+}
+
+// handlerContext bounds a single usage handler invocation, so one stuck API
+// call cannot stall the quota list of its whole service. Handlers must pass
+// the context to every call and paginator they make.
+func (f *QuotaFetcher) handlerContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if f.handlerTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, f.handlerTimeout)
+}
+
+// HasUsageHandler reports whether the usage of a quota is counted by a direct
+// usage handler, by quota code or by name pattern
+func HasUsageHandler(serviceCode, quotaCode, quotaName string) bool {
+	if handler, ok := QuotaCodeToServiceMapping[HandlerKey(serviceCode, quotaCode, quotaName)]; ok {
+		return handler.ServiceCode == serviceCode
+	}
+	for _, handler := range QuotaNameUsageHandlers {
+		if handler.ServiceCode == serviceCode && handler.Pattern.MatchString(quotaName) {
+			return true
+		}
+	}
+	return false
+}
+
+// usageDetailsKey holds the usage details of a handler call in its context
+type usageDetailsKey struct{}
+
+// withUsageDetails returns a context in which a usage handler can name the
+// resource behind its usage with setUsageDetails
+func withUsageDetails(ctx context.Context) (context.Context, *string) {
+	details := new(string)
+	return context.WithValue(ctx, usageDetailsKey{}, details), details
+}
+
+// setUsageDetails records the usage details of the current handler call
+func setUsageDetails(ctx context.Context, details string) {
+	if p, ok := ctx.Value(usageDetailsKey{}).(*string); ok {
+		*p = details
+	}
+}
+
+// accountIDKey holds the account ID of a handler call in its context
+type accountIDKey struct{}
+
+// accountIDOf returns the account ID resolved for handlers with
+// NeedsAccountID
+func accountIDOf(ctx context.Context) (string, error) {
+	id, ok := ctx.Value(accountIDKey{}).(string)
+	if !ok || id == "" {
+		return "", errors.New("the account ID was not resolved for this handler")
+	}
+	return id, nil
 }
 
 // GetUsageDirectly attempts to get usage via direct API calls
 // Returns (usage, true, nil) if successful, (0, false, nil) if not supported
 func (f *QuotaFetcher) GetUsageDirectly(ctx context.Context, region string, quota *model.Quota) (float64, bool, error) {
-	handler, exists := QuotaCodeToServiceMapping[quota.QuotaCode]
+	handler, exists := QuotaCodeToServiceMapping[HandlerKey(quota.ServiceCode, quota.QuotaCode, quota.QuotaName)]
 	if !exists {
-		return 0, false, nil // No direct handler available
+		return f.getUsageByName(ctx, region, quota)
 	}
 
 	// Only call if service codes match
@@ -115,16 +563,27 @@ func (f *QuotaFetcher) GetUsageDirectly(ctx context.Context, region string, quot
 		return 0, false, nil
 	}
 
-	cfg, err := LoadConfig(ctx, region)
+	cfg, err := f.loadConfig(ctx, region)
 	if err != nil {
 		return 0, false, err
 	}
 
-	usage, err := handler.Handler(ctx, cfg, region)
+	handlerCtx, cancel := f.handlerContext(ctx)
+	defer cancel()
+	handlerCtx, details := withUsageDetails(handlerCtx)
+	if handler.NeedsAccountID {
+		id, err := f.Identity(handlerCtx)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to resolve the account ID: %w", err)
+		}
+		handlerCtx = context.WithValue(handlerCtx, accountIDKey{}, id.AccountID)
+	}
+	usage, err := handler.Handler(handlerCtx, cfg, region)
 	if err != nil {
 		log.Printf("Direct API failed for %s/%s: %v", quota.ServiceCode, quota.QuotaCode, err)
 		return 0, false, err
 	}
+	quota.UsageDetails = *details
 
 	return usage, true, nil // Return true indicating successful data retrieval (even if usage is 0)
 }
@@ -225,17 +684,76 @@ func getEKSClusterResourceCount(ctx context.Context, client *eks.Client, countFu
 // ============================================================================
 
 func getEC2RunningInstancesUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
-	return getEC2VCPUUsageByInstanceFamily(ctx, cfg, standardInstanceFamilies)
+	return getEC2VCPUUsageByInstanceFamily(ctx, cfg, ec2StandardVCPUQuota)
+}
+
+// Running On-Demand vCPU quotas, one per group of instance families
+const (
+	ec2StandardVCPUQuota = "L-1216C47A"
+	ec2GVTVCPUQuota      = "L-DB2E81BA"
+	ec2PVCPUQuota        = "L-417A185B"
+	ec2XVCPUQuota        = "L-7295265B"
+	ec2FVCPUQuota        = "L-74FC7D96"
+	ec2InfVCPUQuota      = "L-1945791B"
+	ec2TrnVCPUQuota      = "L-2C3B7624"
+)
+
+// ec2VCPUQuotaPrefixes maps instance type prefixes to the On-Demand vCPU
+// quota counting them. Longer prefixes come first, so inf1 and trn1 are not
+// taken for the standard I and T families, nor dl1 for D. Families whose
+// quotas have no handler (DL, HPC, high memory, Mac) map to no quota.
+var ec2VCPUQuotaPrefixes = []struct {
+	prefix    string
+	quotaCode string
+}{
+	{"inf", ec2InfVCPUQuota},
+	{"trn", ec2TrnVCPUQuota},
+	{"hpc", ""},
+	{"mac", ""},
+	{"dl", ""},
+	{"vt", ec2GVTVCPUQuota},
+	{"u", ""},
+	{"g", ec2GVTVCPUQuota},
+	{"p", ec2PVCPUQuota},
+	{"x", ec2XVCPUQuota},
+	{"f", ec2FVCPUQuota},
+	{"a", ec2StandardVCPUQuota},
+	{"c", ec2StandardVCPUQuota},
+	{"d", ec2StandardVCPUQuota},
+	{"h", ec2StandardVCPUQuota},
+	{"i", ec2StandardVCPUQuota},
+	{"m", ec2StandardVCPUQuota},
+	{"r", ec2StandardVCPUQuota},
+	{"t", ec2StandardVCPUQuota},
+	{"z", ec2StandardVCPUQuota},
+}
+
+// ec2VCPUQuotaOf returns the On-Demand vCPU quota an instance type counts
+// against, or "" when no handled quota counts it
+func ec2VCPUQuotaOf(instanceType string) string {
+	// Instance type format: <family><generation>.<size> e.g., m5.large, inf2.xlarge
+	instanceType = strings.ToLower(instanceType)
+	for _, p := range ec2VCPUQuotaPrefixes {
+		if strings.HasPrefix(instanceType, p.prefix) {
+			return p.quotaCode
+		}
+	}
+	return ""
 }
 
-// standardInstanceFamilies contains instance type prefixes for Standard On-Demand vCPU quota (L-1216C47A)
-var standardInstanceFamilies = []string{"a", "c", "d", "h", "i", "m", "r", "t", "z"}
+// ec2VCPUUsageHandler returns the usage handler of an On-Demand vCPU quota
+func ec2VCPUUsageHandler(quotaCode string) func(context.Context, aws.Config, string) (float64, error) {
+	return func(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+		return getEC2VCPUUsageByInstanceFamily(ctx, cfg, quotaCode)
+	}
+}
 
-// getEC2VCPUUsageByInstanceFamily calculates total vCPU usage for specified instance families
-func getEC2VCPUUsageByInstanceFamily(ctx context.Context, cfg aws.Config, families []string) (float64, error) {
+// getEC2VCPUUsageByInstanceFamily calculates the total vCPUs of the running
+// instances counted by an On-Demand vCPU quota
+func getEC2VCPUUsageByInstanceFamily(ctx context.Context, cfg aws.Config, quotaCode string) (float64, error) {
 	client := ec2.NewFromConfig(cfg)
 
-	instanceTypeCounts, cpuOptionsByType, err := getRunningInstanceTypeCounts(ctx, client, families)
+	instanceTypeCounts, cpuOptionsByType, err := getRunningInstanceTypeCounts(ctx, client, quotaCode)
 	if err != nil {
 		return 0, err
 	}
@@ -255,7 +773,7 @@ func getEC2VCPUUsageByInstanceFamily(ctx context.Context, cfg aws.Config, famili
 	return float64(totalVCPUs), nil
 }
 
-func getRunningInstanceTypeCounts(ctx context.Context, client *ec2.Client, families []string) (map[string]int, map[string]ec2types.CpuOptions, error) {
+func getRunningInstanceTypeCounts(ctx context.Context, client *ec2.Client, quotaCode string) (map[string]int, map[string]ec2types.CpuOptions, error) {
 	input := &ec2.DescribeInstancesInput{
 		Filters: []ec2types.Filter{
 			{
@@ -279,7 +797,7 @@ func getRunningInstanceTypeCounts(ctx context.Context, client *ec2.Client, famil
 					continue
 				}
 				instanceType := string(instance.InstanceType)
-				if !isInstanceInFamilies(instanceType, families) {
+				if ec2VCPUQuotaOf(instanceType) != quotaCode {
 					continue
 				}
 				instanceTypeCounts[instanceType]++
@@ -351,21 +869,6 @@ func getInstanceTypeVCPUs(ctx context.Context, client *ec2.Client, instanceTypes
 	return vcpuMap, nil
 }
 
-// isInstanceInFamilies checks if an instance type belongs to any of the specified families
-func isInstanceInFamilies(instanceType string, families []string) bool {
-	if len(instanceType) == 0 {
-		return false
-	}
-	// Instance type format: <family><generation>.<size> e.g., m5.large, c6i.xlarge
-	firstChar := strings.ToLower(string(instanceType[0]))
-	for _, family := range families {
-		if firstChar == family {
-			return true
-		}
-	}
-	return false
-}
-
 func getElasticIPsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
 	client := ec2.NewFromConfig(cfg)
 	result, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
@@ -468,6 +971,147 @@ func getEC2NATGatewaysUsage(ctx context.Context, cfg aws.Config, _ string) (floa
 	return float64(count), nil
 }
 
+// getEC2DedicatedHostCounts counts the allocated Dedicated Hosts by instance
+// family. Hosts supporting a single instance type count against the family
+// of that type.
+func getEC2DedicatedHostCounts(ctx context.Context, cfg aws.Config, _ string) (map[string]float64, error) {
+	client := ec2.NewFromConfig(cfg)
+
+	counts := make(map[string]float64)
+	paginator := ec2.NewDescribeHostsPaginator(client, &ec2.DescribeHostsInput{
+		Filter: []ec2types.Filter{
+			{
+				Name: aws.String("state"),
+				Values: []string{
+					string(ec2types.AllocationStateAvailable),
+					string(ec2types.AllocationStateUnderAssessment),
+					string(ec2types.AllocationStatePermanentFailure),
+					string(ec2types.AllocationStatePending),
+				},
+			},
+		},
+	})
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range output.Hosts {
+			if host.HostProperties == nil {
+				continue
+			}
+			family := aws.ToString(host.HostProperties.InstanceFamily)
+			if family == "" {
+				family, _, _ = strings.Cut(aws.ToString(host.HostProperties.InstanceType), ".")
+			}
+			if family != "" {
+				counts[family]++
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// getEC2CapacityReservationsUsage counts the open On-Demand Capacity
+// Reservations, those active or still being provisioned
+func getEC2CapacityReservationsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ec2.NewFromConfig(cfg)
+
+	count := 0
+	paginator := ec2.NewDescribeCapacityReservationsPaginator(client, &ec2.DescribeCapacityReservationsInput{
+		Filters: []ec2types.Filter{
+			{
+				Name: aws.String("state"),
+				Values: []string{
+					string(ec2types.CapacityReservationStateActive),
+					string(ec2types.CapacityReservationStatePending),
+				},
+			},
+		},
+	})
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.CapacityReservations)
+	}
+
+	return float64(count), nil
+}
+
+func getEC2LaunchTemplatesUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ec2.NewFromConfig(cfg)
+
+	count := 0
+	paginator := ec2.NewDescribeLaunchTemplatesPaginator(client, &ec2.DescribeLaunchTemplatesInput{})
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.LaunchTemplates)
+	}
+
+	return float64(count), nil
+}
+
+// getEC2LaunchTemplateVersionsUsage returns the version count of the launch
+// template with the most versions. Deleted versions no longer count, so the
+// versions are listed rather than read from the latest version number.
+func getEC2LaunchTemplateVersionsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ec2.NewFromConfig(cfg)
+
+	counts := make(map[string]int)
+	templates := ec2.NewDescribeLaunchTemplatesPaginator(client, &ec2.DescribeLaunchTemplatesInput{})
+	for templates.HasMorePages() {
+		output, err := templates.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, t := range output.LaunchTemplates {
+			id := aws.ToString(t.LaunchTemplateId)
+			versions := ec2.NewDescribeLaunchTemplateVersionsPaginator(client, &ec2.DescribeLaunchTemplateVersionsInput{
+				LaunchTemplateId: t.LaunchTemplateId,
+			})
+			for versions.HasMorePages() {
+				page, err := versions.NextPage(ctx)
+				if err != nil {
+					return 0, err
+				}
+				counts[id] += len(page.LaunchTemplateVersions)
+			}
+		}
+	}
+
+	return float64(maxCount(counts)), nil
+}
+
+// getEC2PlacementGroupsUsage counts the placement groups not being deleted
+func getEC2PlacementGroupsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ec2.NewFromConfig(cfg)
+
+	// DescribePlacementGroups is not paginated and returns every group
+	output, err := client.DescribePlacementGroups(ctx, &ec2.DescribePlacementGroupsInput{})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, group := range output.PlacementGroups {
+		if group.State == ec2types.PlacementGroupStateAvailable ||
+			group.State == ec2types.PlacementGroupStatePending {
+			count++
+		}
+	}
+
+	return float64(count), nil
+}
+
 // ============================================================================
 // EBS Usage Handlers
 // ============================================================================
@@ -571,77 +1215,299 @@ func getSecurityGroupsUsage(ctx context.Context, cfg aws.Config, _ string) (floa
 	return float64(count), nil
 }
 
-// ============================================================================
-// ELB Usage Handlers
-// ============================================================================
+// getInterfaceEndpointsPerVPCUsage returns the interface endpoint count of
+// the VPC with the most interface endpoints
+func getInterfaceEndpointsPerVPCUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	counts, err := countVPCEndpointsPerVPC(ctx, ec2.NewFromConfig(cfg), ec2types.VpcEndpointTypeInterface)
+	if err != nil {
+		return 0, err
+	}
+	return float64(maxCountOf(ctx, counts)), nil
+}
 
-func getALBsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
-	return getLoadBalancersUsageByType(ctx, cfg, "application")
+// getGatewayEndpointsUsage counts the gateway endpoints of all VPCs; their
+// quota applies per region
+func getGatewayEndpointsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	counts, err := countVPCEndpointsPerVPC(ctx, ec2.NewFromConfig(cfg), ec2types.VpcEndpointTypeGateway)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return float64(total), nil
 }
 
-func getNLBsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
-	return getLoadBalancersUsageByType(ctx, cfg, "network")
+// countVPCEndpointsPerVPC counts the endpoints of one type per VPC, skipping
+// endpoints that are being deleted or have failed
+func countVPCEndpointsPerVPC(ctx context.Context, client *ec2.Client, endpointType ec2types.VpcEndpointType) (map[string]int, error) {
+	counts := make(map[string]int)
+	paginator := ec2.NewDescribeVpcEndpointsPaginator(client, &ec2.DescribeVpcEndpointsInput{
+		Filters: []ec2types.Filter{{
+			Name:   aws.String("vpc-endpoint-type"),
+			Values: []string{string(endpointType)},
+		}},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range output.VpcEndpoints {
+			switch ep.State {
+			case ec2types.StateDeleting, ec2types.StateDeleted, ec2types.StateFailed, ec2types.StateRejected, ec2types.StateExpired:
+				continue
+			}
+			counts[aws.ToString(ep.VpcId)]++
+		}
+	}
+	return counts, nil
 }
 
-func getLoadBalancersUsageByType(ctx context.Context, cfg aws.Config, lbType string) (float64, error) {
-	client := elasticloadbalancingv2.NewFromConfig(cfg)
+// getActivePeeringConnectionsPerVPCUsage returns the active peering
+// connection count of the most peered VPC of the region. A connection counts
+// for both the requester and the accepter VPC; VPCs of other accounts and
+// regions are ignored.
+func getActivePeeringConnectionsPerVPCUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ec2.NewFromConfig(cfg)
 
-	count := 0
-	paginator := elasticloadbalancingv2.NewDescribeLoadBalancersPaginator(client, &elasticloadbalancingv2.DescribeLoadBalancersInput{})
+	counts := make(map[string]int)
+	vpcs := ec2.NewDescribeVpcsPaginator(client, &ec2.DescribeVpcsInput{})
+	for vpcs.HasMorePages() {
+		output, err := vpcs.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, vpc := range output.Vpcs {
+			counts[aws.ToString(vpc.VpcId)] = 0
+		}
+	}
+
+	paginator := ec2.NewDescribeVpcPeeringConnectionsPaginator(client, &ec2.DescribeVpcPeeringConnectionsInput{
+		Filters: []ec2types.Filter{{
+			Name:   aws.String("status-code"),
+			Values: []string{string(ec2types.VpcPeeringConnectionStateReasonCodeActive)},
+		}},
+	})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
 			return 0, err
 		}
-		for _, lb := range output.LoadBalancers {
-			if strings.EqualFold(string(lb.Type), lbType) {
-				count++
+		for _, pc := range output.VpcPeeringConnections {
+			for _, info := range []*ec2types.VpcPeeringConnectionVpcInfo{pc.RequesterVpcInfo, pc.AccepterVpcInfo} {
+				if info == nil {
+					continue
+				}
+				if _, ok := counts[aws.ToString(info.VpcId)]; ok {
+					counts[aws.ToString(info.VpcId)]++
+				}
 			}
 		}
 	}
 
-	return float64(count), nil
+	return float64(maxCountOf(ctx, counts)), nil
 }
 
-func getTargetGroupsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
-	client := elasticloadbalancingv2.NewFromConfig(cfg)
+// maxCount returns the largest count, or 0 for no counts
+func maxCount(counts map[string]int) int {
+	largest := 0
+	for _, n := range counts {
+		if n > largest {
+			largest = n
+		}
+	}
+	return largest
+}
 
-	count := 0
-	paginator := elasticloadbalancingv2.NewDescribeTargetGroupsPaginator(client, &elasticloadbalancingv2.DescribeTargetGroupsInput{})
+// maxCountOf returns the largest count like maxCount and records the
+// resource holding it as the usage details; ties go to the lowest ID
+func maxCountOf(ctx context.Context, counts map[string]int) int {
+	worst, largest := "", 0
+	for id, n := range counts {
+		if n > largest || (n == largest && n > 0 && id < worst) {
+			worst, largest = id, n
+		}
+	}
+	if worst != "" {
+		setUsageDetails(ctx, worst)
+	}
+	return largest
+}
+
+// getSubnetsPerVPCUsage returns the subnet count of the VPC with the most
+// subnets
+func getSubnetsPerVPCUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ec2.NewFromConfig(cfg)
+
+	counts := make(map[string]int)
+	paginator := ec2.NewDescribeSubnetsPaginator(client, &ec2.DescribeSubnetsInput{})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
 			return 0, err
 		}
-		count += len(output.TargetGroups)
+		for _, subnet := range output.Subnets {
+			counts[aws.ToString(subnet.VpcId)]++
+		}
 	}
 
-	return float64(count), nil
+	return float64(maxCountOf(ctx, counts)), nil
 }
 
-// ============================================================================
-// Auto Scaling Usage Handlers
-// ============================================================================
-
-func getAutoScalingGroupsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
-	client := autoscaling.NewFromConfig(cfg)
+// getRouteTablesPerVPCUsage returns the route table count, main route table
+// included, of the VPC with the most route tables
+func getRouteTablesPerVPCUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ec2.NewFromConfig(cfg)
 
-	count := 0
-	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(client, &autoscaling.DescribeAutoScalingGroupsInput{})
+	counts := make(map[string]int)
+	paginator := ec2.NewDescribeRouteTablesPaginator(client, &ec2.DescribeRouteTablesInput{})
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
 			return 0, err
 		}
-		count += len(output.AutoScalingGroups)
+		for _, table := range output.RouteTables {
+			counts[aws.ToString(table.VpcId)]++
+		}
 	}
 
-	return float64(count), nil
+	return float64(maxCountOf(ctx, counts)), nil
 }
 
-// ============================================================================
-// S3 Usage Handlers
-// ============================================================================
+// getNetworkACLsPerVPCUsage returns the network ACL count, default ACL
+// included, of the VPC with the most network ACLs
+func getNetworkACLsPerVPCUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ec2.NewFromConfig(cfg)
+
+	counts := make(map[string]int)
+	paginator := ec2.NewDescribeNetworkAclsPaginator(client, &ec2.DescribeNetworkAclsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, acl := range output.NetworkAcls {
+			counts[aws.ToString(acl.VpcId)]++
+		}
+	}
+
+	return float64(maxCountOf(ctx, counts)), nil
+}
+
+// getRulesPerSecurityGroupUsage returns the rule count of the security group
+// and direction closest to the quota. The quota applies to inbound and
+// outbound rules separately, and to IPv4 and IPv6 rules separately; every
+// CIDR, prefix list and referenced group of a permission is one rule, and
+// references count against both the IPv4 and the IPv6 rules.
+func getRulesPerSecurityGroupUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ec2.NewFromConfig(cfg)
+
+	counts := make(map[string]int)
+	paginator := ec2.NewDescribeSecurityGroupsPaginator(client, &ec2.DescribeSecurityGroupsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, sg := range output.SecurityGroups {
+			id := aws.ToString(sg.GroupId)
+			for direction, permissions := range map[string][]ec2types.IpPermission{
+				"inbound":  sg.IpPermissions,
+				"outbound": sg.IpPermissionsEgress,
+			} {
+				ipv4, ipv6 := countSecurityGroupRules(permissions)
+				counts[fmt.Sprintf("%s (%s IPv4)", id, direction)] = ipv4
+				counts[fmt.Sprintf("%s (%s IPv6)", id, direction)] = ipv6
+			}
+		}
+	}
+
+	return float64(maxCountOf(ctx, counts)), nil
+}
+
+// countSecurityGroupRules counts the IPv4 and IPv6 rules of a direction
+func countSecurityGroupRules(permissions []ec2types.IpPermission) (ipv4, ipv6 int) {
+	for _, p := range permissions {
+		references := len(p.UserIdGroupPairs) + len(p.PrefixListIds)
+		ipv4 += len(p.IpRanges) + references
+		ipv6 += len(p.Ipv6Ranges) + references
+	}
+	return ipv4, ipv6
+}
+
+// ============================================================================
+// ELB Usage Handlers
+// ============================================================================
+
+func getALBsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	return getLoadBalancersUsageByType(ctx, cfg, "application")
+}
+
+func getNLBsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	return getLoadBalancersUsageByType(ctx, cfg, "network")
+}
+
+func getLoadBalancersUsageByType(ctx context.Context, cfg aws.Config, lbType string) (float64, error) {
+	client := elasticloadbalancingv2.NewFromConfig(cfg)
+
+	count := 0
+	paginator := elasticloadbalancingv2.NewDescribeLoadBalancersPaginator(client, &elasticloadbalancingv2.DescribeLoadBalancersInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, lb := range output.LoadBalancers {
+			if strings.EqualFold(string(lb.Type), lbType) {
+				count++
+			}
+		}
+	}
+
+	return float64(count), nil
+}
+
+func getTargetGroupsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := elasticloadbalancingv2.NewFromConfig(cfg)
+
+	count := 0
+	paginator := elasticloadbalancingv2.NewDescribeTargetGroupsPaginator(client, &elasticloadbalancingv2.DescribeTargetGroupsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.TargetGroups)
+	}
+
+	return float64(count), nil
+}
+
+// ============================================================================
+// Auto Scaling Usage Handlers
+// ============================================================================
+
+func getAutoScalingGroupsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := autoscaling.NewFromConfig(cfg)
+
+	count := 0
+	paginator := autoscaling.NewDescribeAutoScalingGroupsPaginator(client, &autoscaling.DescribeAutoScalingGroupsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.AutoScalingGroups)
+	}
+
+	return float64(count), nil
+}
+
+// ============================================================================
+// S3 Usage Handlers
+// ============================================================================
 
 func getS3BucketsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
 	client := s3.NewFromConfig(cfg)
@@ -652,6 +1518,56 @@ func getS3BucketsUsage(ctx context.Context, cfg aws.Config, _ string) (float64,
 	return float64(len(result.Buckets)), nil
 }
 
+// getS3AccessPointsUsage counts the access points of the region, those of
+// general purpose buckets and of directory buckets
+func getS3AccessPointsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	accountID, err := accountIDOf(ctx)
+	if err != nil {
+		return 0, err
+	}
+	client := s3control.NewFromConfig(cfg)
+
+	count := 0
+	paginator := s3control.NewListAccessPointsPaginator(client, &s3control.ListAccessPointsInput{
+		AccountId: aws.String(accountID),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.AccessPointList)
+	}
+
+	return float64(count), nil
+}
+
+// getS3MultiRegionAccessPointsUsage counts the Multi-Region Access Points of
+// the account. Their control plane is served from us-west-2 only.
+func getS3MultiRegionAccessPointsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	accountID, err := accountIDOf(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cfg = cfg.Copy()
+	cfg.Region = "us-west-2"
+	client := s3control.NewFromConfig(cfg)
+
+	count := 0
+	paginator := s3control.NewListMultiRegionAccessPointsPaginator(client, &s3control.ListMultiRegionAccessPointsInput{
+		AccountId: aws.String(accountID),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.AccessPoints)
+	}
+
+	return float64(count), nil
+}
+
 // ============================================================================
 // Lambda Usage Handlers
 // ============================================================================
@@ -708,6 +1624,47 @@ func getRDSClustersUsage(ctx context.Context, cfg aws.Config, _ string) (float64
 	return float64(count), nil
 }
 
+// rdsAccountQuotaHandler returns a handler reading the usage of an account
+// quota from RDS itself, which counts exactly what the quota counts, e.g.
+// only manual snapshots or custom parameter groups
+func rdsAccountQuotaHandler(name string) func(context.Context, aws.Config, string) (float64, error) {
+	return func(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+		// DescribeAccountAttributes is not paginated and returns every quota
+		output, err := rds.NewFromConfig(cfg).DescribeAccountAttributes(ctx, &rds.DescribeAccountAttributesInput{})
+		if err != nil {
+			return 0, err
+		}
+		for _, q := range output.AccountQuotas {
+			if aws.ToString(q.AccountQuotaName) == name {
+				return float64(aws.ToInt64(q.Used)), nil
+			}
+		}
+		return 0, fmt.Errorf("RDS does not report the %s account quota", name)
+	}
+}
+
+// getRDSReadReplicasPerSourceUsage returns the read replica count of the DB
+// instance with the most replicas
+func getRDSReadReplicasPerSourceUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := rds.NewFromConfig(cfg)
+
+	counts := make(map[string]int)
+	paginator := rds.NewDescribeDBInstancesPaginator(client, &rds.DescribeDBInstancesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, db := range output.DBInstances {
+			if n := len(db.ReadReplicaDBInstanceIdentifiers); n > 0 {
+				counts[aws.ToString(db.DBInstanceIdentifier)] = n
+			}
+		}
+	}
+
+	return float64(maxCountOf(ctx, counts)), nil
+}
+
 // ============================================================================
 // DynamoDB Usage Handlers
 // ============================================================================
@@ -775,6 +1732,44 @@ func getRoute53HostedZonesUsage(ctx context.Context, cfg aws.Config, _ string) (
 	return float64(count), nil
 }
 
+// getRoute53RecordsPerZoneUsage returns the record count of the hosted zone
+// with the most records. ListHostedZones reports the count of each zone, so
+// the records themselves are not listed.
+func getRoute53RecordsPerZoneUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := route53.NewFromConfig(cfg)
+
+	counts := make(map[string]int)
+	paginator := route53.NewListHostedZonesPaginator(client, &route53.ListHostedZonesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, zone := range output.HostedZones {
+			id := strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/")
+			counts[fmt.Sprintf("%s (%s)", aws.ToString(zone.Name), id)] = int(aws.ToInt64(zone.ResourceRecordSetCount))
+		}
+	}
+
+	return float64(maxCountOf(ctx, counts)), nil
+}
+
+func getRoute53HealthChecksUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := route53.NewFromConfig(cfg)
+
+	count := 0
+	paginator := route53.NewListHealthChecksPaginator(client, &route53.ListHealthChecksInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.HealthChecks)
+	}
+
+	return float64(count), nil
+}
+
 // ============================================================================
 // IAM Usage Handlers
 // ============================================================================
@@ -846,6 +1841,58 @@ func getIAMPoliciesUsage(ctx context.Context, cfg aws.Config, _ string) (float64
 	return float64(count), nil
 }
 
+func getIAMInstanceProfilesUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := iam.NewFromConfig(cfg)
+
+	count := 0
+	paginator := iam.NewListInstanceProfilesPaginator(client, &iam.ListInstanceProfilesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.InstanceProfiles)
+	}
+
+	return float64(count), nil
+}
+
+func getIAMSAMLProvidersUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	// ListSAMLProviders is not paginated and returns every provider
+	output, err := iam.NewFromConfig(cfg).ListSAMLProviders(ctx, &iam.ListSAMLProvidersInput{})
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(output.SAMLProviderList)), nil
+}
+
+func getIAMOIDCProvidersUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	// ListOpenIDConnectProviders is not paginated and returns every provider
+	output, err := iam.NewFromConfig(cfg).ListOpenIDConnectProviders(ctx, &iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(output.OpenIDConnectProviderList)), nil
+}
+
+// getIAMServerCertificatesUsage counts the server certificates uploaded to
+// IAM; certificates managed by ACM do not count
+func getIAMServerCertificatesUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := iam.NewFromConfig(cfg)
+
+	count := 0
+	paginator := iam.NewListServerCertificatesPaginator(client, &iam.ListServerCertificatesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.ServerCertificateMetadataList)
+	}
+
+	return float64(count), nil
+}
+
 // ============================================================================
 // SNS Usage Handlers
 // ============================================================================
@@ -905,3 +1952,1450 @@ func getECRRepositoriesUsage(ctx context.Context, cfg aws.Config, _ string) (flo
 
 	return float64(count), nil
 }
+
+// getECRImagesPerRepositoryUsage returns the image count of the repository
+// with the most images. Images are counted by digest, so an image with
+// several tags counts once.
+func getECRImagesPerRepositoryUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := ecr.NewFromConfig(cfg)
+
+	counts := make(map[string]int)
+	repositories := ecr.NewDescribeRepositoriesPaginator(client, &ecr.DescribeRepositoriesInput{})
+	for repositories.HasMorePages() {
+		output, err := repositories.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, repo := range output.Repositories {
+			name := aws.ToString(repo.RepositoryName)
+			images := ecr.NewDescribeImagesPaginator(client, &ecr.DescribeImagesInput{
+				RepositoryName: repo.RepositoryName,
+				RegistryId:     repo.RegistryId,
+			})
+			for images.HasMorePages() {
+				page, err := images.NextPage(ctx)
+				if err != nil {
+					return 0, err
+				}
+				counts[name] += len(page.ImageDetails)
+			}
+		}
+	}
+
+	return float64(maxCountOf(ctx, counts)), nil
+}
+
+// ============================================================================
+// Fargate Usage Handlers
+// ============================================================================
+
+func getFargateOnDemandVCPUUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	return getFargateVCPUUsage(ctx, cfg, false)
+}
+
+func getFargateSpotVCPUUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	return getFargateVCPUUsage(ctx, cfg, true)
+}
+
+// getFargateVCPUUsage sums the CPU of running ECS tasks on Fargate (on-demand)
+// or Fargate Spot. Fargate pods of EKS clusters also count against these
+// quotas but are not included.
+func getFargateVCPUUsage(ctx context.Context, cfg aws.Config, spot bool) (float64, error) {
+	client := ecs.NewFromConfig(cfg)
+
+	var totalCPU float64
+	clusters := ecs.NewListClustersPaginator(client, &ecs.ListClustersInput{})
+	for clusters.HasMorePages() {
+		output, err := clusters.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, cluster := range output.ClusterArns {
+			cpu, err := getFargateClusterCPU(ctx, client, cluster, spot)
+			if err != nil {
+				return 0, err
+			}
+			totalCPU += cpu
+		}
+	}
+
+	// Task CPU is expressed in CPU units, 1024 units per vCPU
+	return totalCPU / 1024, nil
+}
+
+// getFargateClusterCPU returns the CPU units of running Fargate tasks in a cluster
+func getFargateClusterCPU(ctx context.Context, client *ecs.Client, cluster string, spot bool) (float64, error) {
+	var taskArns []string
+	paginator := ecs.NewListTasksPaginator(client, &ecs.ListTasksInput{
+		Cluster:       aws.String(cluster),
+		DesiredStatus: ecstypes.DesiredStatusRunning,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		taskArns = append(taskArns, output.TaskArns...)
+	}
+
+	var cpu float64
+	// DescribeTasks accepts up to 100 tasks per call
+	for start := 0; start < len(taskArns); start += 100 {
+		end := min(start+100, len(taskArns))
+		output, err := client.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   taskArns[start:end],
+		})
+		if err != nil {
+			return 0, err
+		}
+		for _, task := range output.Tasks {
+			if !isFargateTask(task, spot) || task.Cpu == nil {
+				continue
+			}
+			units, err := strconv.ParseFloat(*task.Cpu, 64)
+			if err != nil {
+				log.Printf("Warning: unexpected CPU value %q for task %s", *task.Cpu, aws.ToString(task.TaskArn))
+				continue
+			}
+			cpu += units
+		}
+	}
+
+	return cpu, nil
+}
+
+// isFargateTask reports whether a task runs on Fargate Spot (spot) or on
+// regular Fargate capacity
+func isFargateTask(task ecstypes.Task, spot bool) bool {
+	switch aws.ToString(task.CapacityProviderName) {
+	case "FARGATE_SPOT":
+		return spot
+	case "FARGATE":
+		return !spot
+	}
+	return !spot && task.LaunchType == ecstypes.LaunchTypeFargate
+}
+
+// ============================================================================
+// CloudFormation Usage Handlers
+// ============================================================================
+
+func getCloudFormationStacksUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := cloudformation.NewFromConfig(cfg)
+
+	// Deleted stacks stay listed for 90 days but no longer count against the quota
+	var statuses []cfntypes.StackStatus
+	for _, status := range cfntypes.StackStatus("").Values() {
+		if status != cfntypes.StackStatusDeleteComplete {
+			statuses = append(statuses, status)
+		}
+	}
+
+	count := 0
+	paginator := cloudformation.NewListStacksPaginator(client, &cloudformation.ListStacksInput{
+		StackStatusFilter: statuses,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.StackSummaries)
+	}
+
+	return float64(count), nil
+}
+
+func getCloudFormationStackSetsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := cloudformation.NewFromConfig(cfg)
+
+	count := 0
+	paginator := cloudformation.NewListStackSetsPaginator(client, &cloudformation.ListStackSetsInput{
+		Status: cfntypes.StackSetStatusActive,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.Summaries)
+	}
+
+	return float64(count), nil
+}
+
+// ============================================================================
+// Kinesis Data Streams Usage Handlers
+// ============================================================================
+
+func getKinesisShardsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := kinesis.NewFromConfig(cfg)
+
+	streams, err := listKinesisStreams(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, stream := range streams {
+		output, err := client.DescribeStreamSummary(ctx, &kinesis.DescribeStreamSummaryInput{
+			StreamARN: stream.StreamARN,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if output.StreamDescriptionSummary != nil && output.StreamDescriptionSummary.OpenShardCount != nil {
+			total += int(*output.StreamDescriptionSummary.OpenShardCount)
+		}
+	}
+
+	return float64(total), nil
+}
+
+func getKinesisOnDemandStreamsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := kinesis.NewFromConfig(cfg)
+
+	streams, err := listKinesisStreams(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, stream := range streams {
+		if stream.StreamModeDetails != nil && stream.StreamModeDetails.StreamMode == kinesistypes.StreamModeOnDemand {
+			count++
+		}
+	}
+
+	return float64(count), nil
+}
+
+func listKinesisStreams(ctx context.Context, client *kinesis.Client) ([]kinesistypes.StreamSummary, error) {
+	var streams []kinesistypes.StreamSummary
+	paginator := kinesis.NewListStreamsPaginator(client, &kinesis.ListStreamsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, output.StreamSummaries...)
+	}
+	return streams, nil
+}
+
+// ============================================================================
+// API Gateway Usage Handlers
+// ============================================================================
+
+func getAPIGatewayRegionalRestAPIsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	return getAPIGatewayRestAPIsUsage(ctx, cfg, apigwtypes.EndpointTypeRegional)
+}
+
+func getAPIGatewayEdgeRestAPIsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	return getAPIGatewayRestAPIsUsage(ctx, cfg, apigwtypes.EndpointTypeEdge)
+}
+
+// getAPIGatewayRestAPIsUsage counts the REST APIs with the given endpoint type
+func getAPIGatewayRestAPIsUsage(ctx context.Context, cfg aws.Config, endpointType apigwtypes.EndpointType) (float64, error) {
+	client := apigateway.NewFromConfig(cfg)
+
+	count := 0
+	paginator := apigateway.NewGetRestApisPaginator(client, &apigateway.GetRestApisInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, api := range output.Items {
+			if api.EndpointConfiguration == nil {
+				continue
+			}
+			for _, t := range api.EndpointConfiguration.Types {
+				if t == endpointType {
+					count++
+					break
+				}
+			}
+		}
+	}
+
+	return float64(count), nil
+}
+
+func getAPIGatewayHTTPAPIsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := apigatewayv2.NewFromConfig(cfg)
+
+	// GetApis returns both HTTP and WebSocket APIs; it has no paginator
+	count := 0
+	input := &apigatewayv2.GetApisInput{}
+	for {
+		output, err := client.GetApis(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.Items)
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	return float64(count), nil
+}
+
+func getAPIGatewayVPCLinksUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := apigateway.NewFromConfig(cfg)
+
+	count := 0
+	paginator := apigateway.NewGetVpcLinksPaginator(client, &apigateway.GetVpcLinksInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.Items)
+	}
+
+	return float64(count), nil
+}
+
+// ============================================================================
+// EventBridge Usage Handlers
+// ============================================================================
+
+func getEventBridgeEventBusesUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := eventbridge.NewFromConfig(cfg)
+
+	buses, err := listEventBuses(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	// The default event bus exists in every region and is not counted
+	count := 0
+	for _, bus := range buses {
+		if aws.ToString(bus.Name) != "default" {
+			count++
+		}
+	}
+
+	return float64(count), nil
+}
+
+// getEventBridgeRulesPerBusUsage returns the rule count of the event bus with
+// the most rules, since the quota applies to each bus separately
+func getEventBridgeRulesPerBusUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := eventbridge.NewFromConfig(cfg)
+
+	buses, err := listEventBuses(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	maxRules := 0
+	for _, bus := range buses {
+		count := 0
+		input := &eventbridge.ListRulesInput{EventBusName: bus.Name}
+		for {
+			output, err := client.ListRules(ctx, input)
+			if err != nil {
+				return 0, err
+			}
+			count += len(output.Rules)
+			if output.NextToken == nil {
+				break
+			}
+			input.NextToken = output.NextToken
+		}
+		if count > maxRules {
+			maxRules = count
+		}
+	}
+
+	return float64(maxRules), nil
+}
+
+// listEventBuses lists all event buses; ListEventBuses has no paginator
+func listEventBuses(ctx context.Context, client *eventbridge.Client) ([]ebtypes.EventBus, error) {
+	var buses []ebtypes.EventBus
+	input := &eventbridge.ListEventBusesInput{}
+	for {
+		output, err := client.ListEventBuses(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		buses = append(buses, output.EventBuses...)
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return buses, nil
+}
+
+// ============================================================================
+// CloudWatch Usage Handlers
+// ============================================================================
+
+func getCloudWatchMetricAlarmsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := cloudwatch.NewFromConfig(cfg)
+
+	count := 0
+	paginator := cloudwatch.NewDescribeAlarmsPaginator(client, &cloudwatch.DescribeAlarmsInput{
+		AlarmTypes: []cwtypes.AlarmType{cwtypes.AlarmTypeMetricAlarm},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.MetricAlarms)
+	}
+
+	return float64(count), nil
+}
+
+func getCloudWatchDashboardsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := cloudwatch.NewFromConfig(cfg)
+
+	count := 0
+	paginator := cloudwatch.NewListDashboardsPaginator(client, &cloudwatch.ListDashboardsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.DashboardEntries)
+	}
+
+	return float64(count), nil
+}
+
+func getCloudWatchLogGroupsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := cloudwatchlogs.NewFromConfig(cfg)
+
+	count := 0
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(client, &cloudwatchlogs.DescribeLogGroupsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.LogGroups)
+	}
+
+	return float64(count), nil
+}
+
+// ============================================================================
+// Glue Usage Handlers
+// ============================================================================
+
+func getGlueJobsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := glue.NewFromConfig(cfg)
+
+	count := 0
+	paginator := glue.NewGetJobsPaginator(client, &glue.GetJobsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.Jobs)
+	}
+
+	return float64(count), nil
+}
+
+func getGlueCrawlersUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := glue.NewFromConfig(cfg)
+
+	count := 0
+	paginator := glue.NewListCrawlersPaginator(client, &glue.ListCrawlersInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.CrawlerNames)
+	}
+
+	return float64(count), nil
+}
+
+func getGlueDatabasesUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	databases, err := listGlueDatabases(ctx, glue.NewFromConfig(cfg))
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(databases)), nil
+}
+
+func getGlueTablesUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := glue.NewFromConfig(cfg)
+
+	databases, err := listGlueDatabases(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, name := range databases {
+		paginator := glue.NewGetTablesPaginator(client, &glue.GetTablesInput{
+			DatabaseName: aws.String(name),
+		})
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return 0, err
+			}
+			count += len(output.TableList)
+		}
+	}
+
+	return float64(count), nil
+}
+
+// listGlueDatabases returns the names of the Data Catalog databases owned by
+// the account; databases shared from other accounts do not count against it
+func listGlueDatabases(ctx context.Context, client *glue.Client) ([]string, error) {
+	var names []string
+	paginator := glue.NewGetDatabasesPaginator(client, &glue.GetDatabasesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, db := range output.DatabaseList {
+			if db.TargetDatabase != nil {
+				continue
+			}
+			names = append(names, aws.ToString(db.Name))
+		}
+	}
+	return names, nil
+}
+
+// ============================================================================
+// Athena Usage Handlers
+// ============================================================================
+
+func getAthenaWorkGroupsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	names, err := listAthenaWorkGroups(ctx, athena.NewFromConfig(cfg))
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(names)), nil
+}
+
+func getAthenaPreparedStatementsPerWorkGroupUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := athena.NewFromConfig(cfg)
+
+	names, err := listAthenaWorkGroups(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	maxStatements := 0
+	for _, name := range names {
+		count := 0
+		paginator := athena.NewListPreparedStatementsPaginator(client, &athena.ListPreparedStatementsInput{
+			WorkGroup: aws.String(name),
+		})
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return 0, err
+			}
+			count += len(output.PreparedStatements)
+		}
+		if count > maxStatements {
+			maxStatements = count
+		}
+	}
+
+	return float64(maxStatements), nil
+}
+
+// listAthenaWorkGroups returns the names of all workgroups, including primary
+func listAthenaWorkGroups(ctx context.Context, client *athena.Client) ([]string, error) {
+	var names []string
+	paginator := athena.NewListWorkGroupsPaginator(client, &athena.ListWorkGroupsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, wg := range output.WorkGroups {
+			names = append(names, aws.ToString(wg.Name))
+		}
+	}
+	return names, nil
+}
+
+// ============================================================================
+// SageMaker Usage Handlers
+// ============================================================================
+
+// getSageMakerEndpointInstanceCounts sums the current instance count of every
+// in-service endpoint variant by instance type
+func getSageMakerEndpointInstanceCounts(ctx context.Context, cfg aws.Config, _ string) (map[string]float64, error) {
+	client := sagemaker.NewFromConfig(cfg)
+
+	counts := make(map[string]float64)
+	paginator := sagemaker.NewListEndpointsPaginator(client, &sagemaker.ListEndpointsInput{
+		StatusEquals: smtypes.EndpointStatusInService,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ep := range output.Endpoints {
+			endpoint, err := client.DescribeEndpoint(ctx, &sagemaker.DescribeEndpointInput{EndpointName: ep.EndpointName})
+			if err != nil {
+				return nil, err
+			}
+			config, err := client.DescribeEndpointConfig(ctx, &sagemaker.DescribeEndpointConfigInput{
+				EndpointConfigName: endpoint.EndpointConfigName,
+			})
+			if err != nil {
+				return nil, err
+			}
+			// The instance type is only in the endpoint config; the live
+			// instance count is on the endpoint
+			instanceTypes := make(map[string]string)
+			for _, v := range config.ProductionVariants {
+				instanceTypes[aws.ToString(v.VariantName)] = string(v.InstanceType)
+			}
+			for _, v := range endpoint.ProductionVariants {
+				instanceType := instanceTypes[aws.ToString(v.VariantName)]
+				if instanceType == "" {
+					continue // serverless variants use no instances
+				}
+				counts[instanceType] += float64(aws.ToInt32(v.CurrentInstanceCount))
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// getSageMakerNotebookInstanceCounts counts in-service notebook instances by
+// instance type
+func getSageMakerNotebookInstanceCounts(ctx context.Context, cfg aws.Config, _ string) (map[string]float64, error) {
+	client := sagemaker.NewFromConfig(cfg)
+
+	counts := make(map[string]float64)
+	paginator := sagemaker.NewListNotebookInstancesPaginator(client, &sagemaker.ListNotebookInstancesInput{
+		StatusEquals: smtypes.NotebookInstanceStatusInService,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, nb := range output.NotebookInstances {
+			counts[string(nb.InstanceType)]++
+		}
+	}
+
+	return counts, nil
+}
+
+// getSageMakerTrainingInstanceCounts sums the instances of running training
+// jobs by instance type
+func getSageMakerTrainingInstanceCounts(ctx context.Context, cfg aws.Config, _ string) (map[string]float64, error) {
+	client := sagemaker.NewFromConfig(cfg)
+
+	counts := make(map[string]float64)
+	paginator := sagemaker.NewListTrainingJobsPaginator(client, &sagemaker.ListTrainingJobsInput{
+		StatusEquals: smtypes.TrainingJobStatusInProgress,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range output.TrainingJobSummaries {
+			detail, err := client.DescribeTrainingJob(ctx, &sagemaker.DescribeTrainingJobInput{TrainingJobName: job.TrainingJobName})
+			if err != nil {
+				return nil, err
+			}
+			if rc := detail.ResourceConfig; rc != nil && rc.InstanceType != "" {
+				counts[string(rc.InstanceType)] += float64(aws.ToInt32(rc.InstanceCount))
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// ============================================================================
+// EMR Usage Handlers
+// ============================================================================
+
+// emrActiveClusterStates are the states of clusters that count against the
+// quotas; terminated clusters do not
+var emrActiveClusterStates = []emrtypes.ClusterState{
+	emrtypes.ClusterStateStarting,
+	emrtypes.ClusterStateBootstrapping,
+	emrtypes.ClusterStateRunning,
+	emrtypes.ClusterStateWaiting,
+	emrtypes.ClusterStateTerminating,
+}
+
+func getEMRActiveClustersUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	ids, err := listEMRActiveClusters(ctx, emr.NewFromConfig(cfg))
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(ids)), nil
+}
+
+func getEMRClusterInstancesUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := emr.NewFromConfig(cfg)
+
+	ids, err := listEMRActiveClusters(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, id := range ids {
+		paginator := emr.NewListInstancesPaginator(client, &emr.ListInstancesInput{
+			ClusterId: aws.String(id),
+			InstanceStates: []emrtypes.InstanceState{
+				emrtypes.InstanceStateAwaitingFulfillment,
+				emrtypes.InstanceStateProvisioning,
+				emrtypes.InstanceStateBootstrapping,
+				emrtypes.InstanceStateRunning,
+			},
+		})
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return 0, err
+			}
+			count += len(output.Instances)
+		}
+	}
+
+	return float64(count), nil
+}
+
+func listEMRActiveClusters(ctx context.Context, client *emr.Client) ([]string, error) {
+	var ids []string
+	paginator := emr.NewListClustersPaginator(client, &emr.ListClustersInput{
+		ClusterStates: emrActiveClusterStates,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range output.Clusters {
+			ids = append(ids, aws.ToString(c.Id))
+		}
+	}
+	return ids, nil
+}
+
+// ============================================================================
+// MSK Usage Handlers
+// ============================================================================
+
+func getMSKClustersUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	clusters, err := listMSKClusters(ctx, kafka.NewFromConfig(cfg))
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(clusters)), nil
+}
+
+// getMSKBrokersUsage counts the broker nodes of all provisioned clusters.
+// Serverless clusters have no brokers of their own and are not counted.
+func getMSKBrokersUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	clusters, err := listMSKClusters(ctx, kafka.NewFromConfig(cfg))
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, c := range clusters {
+		if c.Provisioned != nil {
+			count += int(aws.ToInt32(c.Provisioned.NumberOfBrokerNodes))
+		}
+	}
+	return float64(count), nil
+}
+
+// listMSKClusters lists the provisioned and serverless clusters, skipping
+// clusters being deleted
+func listMSKClusters(ctx context.Context, client *kafka.Client) ([]kafkatypes.Cluster, error) {
+	var clusters []kafkatypes.Cluster
+	paginator := kafka.NewListClustersV2Paginator(client, &kafka.ListClustersV2Input{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range output.ClusterInfoList {
+			if c.State == kafkatypes.ClusterStateDeleting {
+				continue
+			}
+			clusters = append(clusters, c)
+		}
+	}
+	return clusters, nil
+}
+
+// ============================================================================
+// OpenSearch Service Usage Handlers
+// ============================================================================
+
+// openSearchDescribeBatch is the maximum number of domains DescribeDomains
+// accepts per call
+const openSearchDescribeBatch = 5
+
+func getOpenSearchDomainsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	output, err := opensearch.NewFromConfig(cfg).ListDomainNames(ctx, &opensearch.ListDomainNamesInput{})
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(output.DomainNames)), nil
+}
+
+// getOpenSearchInstancesPerDomainUsage returns the data node count of the
+// largest domain
+func getOpenSearchInstancesPerDomainUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	domains, err := describeOpenSearchDomains(ctx, opensearch.NewFromConfig(cfg))
+	if err != nil {
+		return 0, err
+	}
+
+	maxInstances := int32(0)
+	for _, d := range domains {
+		if d.ClusterConfig == nil {
+			continue
+		}
+		if n := aws.ToInt32(d.ClusterConfig.InstanceCount); n > maxInstances {
+			maxInstances = n
+		}
+	}
+	return float64(maxInstances), nil
+}
+
+// describeOpenSearchDomains returns the status of all domains not being deleted
+func describeOpenSearchDomains(ctx context.Context, client *opensearch.Client) ([]opensearchtypes.DomainStatus, error) {
+	output, err := client.ListDomainNames(ctx, &opensearch.ListDomainNamesInput{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(output.DomainNames))
+	for _, d := range output.DomainNames {
+		names = append(names, aws.ToString(d.DomainName))
+	}
+
+	var domains []opensearchtypes.DomainStatus
+	for start := 0; start < len(names); start += openSearchDescribeBatch {
+		end := start + openSearchDescribeBatch
+		if end > len(names) {
+			end = len(names)
+		}
+		described, err := client.DescribeDomains(ctx, &opensearch.DescribeDomainsInput{
+			DomainNames: names[start:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range described.DomainStatusList {
+			if aws.ToBool(d.Deleted) {
+				continue
+			}
+			domains = append(domains, d)
+		}
+	}
+	return domains, nil
+}
+
+// ============================================================================
+// Transit Gateway Usage Handlers
+// ============================================================================
+
+// getTransitGatewaysUsage counts the transit gateways the account owns.
+// Gateways shared with it through RAM count against their owner's quota.
+func getTransitGatewaysUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	ids, err := listOwnedTransitGateways(ctx, cfg)
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(ids)), nil
+}
+
+// getTransitGatewayAttachmentsUsage returns the attachment count of the
+// account's most attached transit gateway, including attachments from other
+// accounts to its shared gateways
+func getTransitGatewayAttachmentsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	ids, err := listOwnedTransitGateways(ctx, cfg)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	counts := make(map[string]int, len(ids))
+	paginator := ec2.NewDescribeTransitGatewayAttachmentsPaginator(ec2.NewFromConfig(cfg), &ec2.DescribeTransitGatewayAttachmentsInput{
+		Filters: []ec2types.Filter{{
+			Name:   aws.String("transit-gateway-id"),
+			Values: ids,
+		}},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, a := range output.TransitGatewayAttachments {
+			switch a.State {
+			case ec2types.TransitGatewayAttachmentStateDeleted,
+				ec2types.TransitGatewayAttachmentStateDeleting,
+				ec2types.TransitGatewayAttachmentStateFailed,
+				ec2types.TransitGatewayAttachmentStateRejected:
+				continue
+			}
+			counts[aws.ToString(a.TransitGatewayId)]++
+		}
+	}
+
+	maxAttachments := 0
+	for _, n := range counts {
+		if n > maxAttachments {
+			maxAttachments = n
+		}
+	}
+	return float64(maxAttachments), nil
+}
+
+// listOwnedTransitGateways returns the IDs of the transit gateways owned by
+// the account of cfg that are not being deleted
+func listOwnedTransitGateways(ctx context.Context, cfg aws.Config) ([]string, error) {
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	paginator := ec2.NewDescribeTransitGatewaysPaginator(ec2.NewFromConfig(cfg), &ec2.DescribeTransitGatewaysInput{
+		Filters: []ec2types.Filter{
+			{Name: aws.String("owner-id"), Values: []string{aws.ToString(identity.Account)}},
+			{Name: aws.String("state"), Values: []string{"pending", "available", "modifying"}},
+		},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, tgw := range output.TransitGateways {
+			ids = append(ids, aws.ToString(tgw.TransitGatewayId))
+		}
+	}
+	return ids, nil
+}
+
+// ============================================================================
+// Direct Connect Usage Handlers
+// ============================================================================
+
+func getDirectConnectConnectionsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	output, err := directconnect.NewFromConfig(cfg).DescribeConnections(ctx, &directconnect.DescribeConnectionsInput{})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, conn := range output.Connections {
+		switch conn.ConnectionState {
+		case dxtypes.ConnectionStateDeleting, dxtypes.ConnectionStateDeleted, dxtypes.ConnectionStateRejected:
+			continue
+		}
+		count++
+	}
+	return float64(count), nil
+}
+
+// getDirectConnectPrivateVIFsPerConnectionUsage returns the private and
+// transit virtual interface count of the busiest connection; both count
+// against the same quota
+func getDirectConnectPrivateVIFsPerConnectionUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	counts, err := countDirectConnectVIFsPerConnection(ctx, cfg, "private", "transit")
+	if err != nil {
+		return 0, err
+	}
+	return float64(maxCount(counts)), nil
+}
+
+func getDirectConnectPublicVIFsPerConnectionUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	counts, err := countDirectConnectVIFsPerConnection(ctx, cfg, "public")
+	if err != nil {
+		return 0, err
+	}
+	return float64(maxCount(counts)), nil
+}
+
+// countDirectConnectVIFsPerConnection counts the virtual interfaces of the
+// given types per connection, skipping deleted and rejected ones
+func countDirectConnectVIFsPerConnection(ctx context.Context, cfg aws.Config, vifTypes ...string) (map[string]int, error) {
+	output, err := directconnect.NewFromConfig(cfg).DescribeVirtualInterfaces(ctx, &directconnect.DescribeVirtualInterfacesInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, vif := range output.VirtualInterfaces {
+		switch vif.VirtualInterfaceState {
+		case dxtypes.VirtualInterfaceStateDeleting, dxtypes.VirtualInterfaceStateDeleted, dxtypes.VirtualInterfaceStateRejected:
+			continue
+		}
+		for _, t := range vifTypes {
+			if aws.ToString(vif.VirtualInterfaceType) == t {
+				counts[aws.ToString(vif.ConnectionId)]++
+				break
+			}
+		}
+	}
+	return counts, nil
+}
+
+// ============================================================================
+// Global Accelerator Usage Handlers
+// ============================================================================
+
+// globalAcceleratorRegion is the only region serving the Global Accelerator
+// API. Its quotas are global, so every region reports the same usage and the
+// quotas are deduplicated like other global quotas.
+const globalAcceleratorRegion = "us-west-2"
+
+func globalAcceleratorClient(cfg aws.Config) *globalaccelerator.Client {
+	cfg = cfg.Copy()
+	cfg.Region = globalAcceleratorRegion
+	return globalaccelerator.NewFromConfig(cfg)
+}
+
+func getGlobalAcceleratorsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	arns, err := listGlobalAccelerators(ctx, globalAcceleratorClient(cfg))
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(arns)), nil
+}
+
+// getGlobalAcceleratorListenersPerAcceleratorUsage returns the listener count
+// of the accelerator with the most listeners
+func getGlobalAcceleratorListenersPerAcceleratorUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := globalAcceleratorClient(cfg)
+	arns, err := listGlobalAccelerators(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	counts := make(map[string]int, len(arns))
+	for _, arn := range arns {
+		paginator := globalaccelerator.NewListListenersPaginator(client, &globalaccelerator.ListListenersInput{
+			AcceleratorArn: aws.String(arn),
+		})
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return 0, err
+			}
+			counts[arn] += len(output.Listeners)
+		}
+	}
+	return float64(maxCount(counts)), nil
+}
+
+// listGlobalAccelerators returns the ARNs of the standard accelerators of the
+// account; custom routing accelerators have quotas of their own
+func listGlobalAccelerators(ctx context.Context, client *globalaccelerator.Client) ([]string, error) {
+	var arns []string
+	paginator := globalaccelerator.NewListAcceleratorsPaginator(client, &globalaccelerator.ListAcceleratorsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, acc := range output.Accelerators {
+			arns = append(arns, aws.ToString(acc.AcceleratorArn))
+		}
+	}
+	return arns, nil
+}
+
+// ============================================================================
+// WAFv2 Usage Handlers
+// ============================================================================
+
+// wafCloudFrontRegion is the region serving the web ACLs of the CLOUDFRONT
+// scope. Their quotas are global and deduplicated like other global quotas.
+const wafCloudFrontRegion = "us-east-1"
+
+// wafClient returns a client for the web ACLs of a scope
+func wafClient(cfg aws.Config, scope waftypes.Scope) *wafv2.Client {
+	if scope == waftypes.ScopeCloudfront {
+		cfg = cfg.Copy()
+		cfg.Region = wafCloudFrontRegion
+	}
+	return wafv2.NewFromConfig(cfg)
+}
+
+func getWAFRegionalWebACLsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	acls, err := listWAFWebACLs(ctx, wafClient(cfg, waftypes.ScopeRegional), waftypes.ScopeRegional)
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(acls)), nil
+}
+
+func getWAFCloudFrontWebACLsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	acls, err := listWAFWebACLs(ctx, wafClient(cfg, waftypes.ScopeCloudfront), waftypes.ScopeCloudfront)
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(acls)), nil
+}
+
+func getWAFRegionalWebACLCapacityUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	return maxWAFWebACLCapacity(ctx, wafClient(cfg, waftypes.ScopeRegional), waftypes.ScopeRegional)
+}
+
+func getWAFCloudFrontWebACLCapacityUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	return maxWAFWebACLCapacity(ctx, wafClient(cfg, waftypes.ScopeCloudfront), waftypes.ScopeCloudfront)
+}
+
+// maxWAFWebACLCapacity returns the WCUs used by the web ACL of a scope with
+// the most capacity in use
+func maxWAFWebACLCapacity(ctx context.Context, client *wafv2.Client, scope waftypes.Scope) (float64, error) {
+	acls, err := listWAFWebACLs(ctx, client, scope)
+	if err != nil {
+		return 0, err
+	}
+
+	var maxCapacity int64
+	for _, acl := range acls {
+		output, err := client.GetWebACL(ctx, &wafv2.GetWebACLInput{
+			Id:    acl.Id,
+			Name:  acl.Name,
+			Scope: scope,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if output.WebACL != nil && output.WebACL.Capacity > maxCapacity {
+			maxCapacity = output.WebACL.Capacity
+		}
+	}
+	return float64(maxCapacity), nil
+}
+
+func listWAFWebACLs(ctx context.Context, client *wafv2.Client, scope waftypes.Scope) ([]waftypes.WebACLSummary, error) {
+	// ListWebACLs has no paginator
+	var acls []waftypes.WebACLSummary
+	input := &wafv2.ListWebACLsInput{Scope: scope, Limit: aws.Int32(100)}
+	for {
+		output, err := client.ListWebACLs(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		acls = append(acls, output.WebACLs...)
+		if output.NextMarker == nil || *output.NextMarker == "" {
+			break
+		}
+		input.NextMarker = output.NextMarker
+	}
+	return acls, nil
+}
+
+// ============================================================================
+// CodeBuild Usage Handlers
+// ============================================================================
+
+// codeBuildMaxBuildAge is the longest a build can run (the maximum build
+// timeout); older builds cannot still be in progress
+const codeBuildMaxBuildAge = 36 * time.Hour
+
+// codeBuildBatchSize is the maximum number of builds BatchGetBuilds accepts
+const codeBuildBatchSize = 100
+
+func getCodeBuildProjectsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := codebuild.NewFromConfig(cfg)
+
+	count := 0
+	paginator := codebuild.NewListProjectsPaginator(client, &codebuild.ListProjectsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.Projects)
+	}
+	return float64(count), nil
+}
+
+// codeBuildEnvironmentKey normalizes an environment and compute type, as
+// named by a quota ("Linux/Small", "Linux GPU/Large") or derived from a build
+// ("linux/small"), into the key running builds are counted under
+func codeBuildEnvironmentKey(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "/", "", "-", "", "_", "").Replace(name))
+}
+
+// codeBuildEnvironmentNames are the quota name spellings of the environment
+// types; compute types are named by their size (BUILD_GENERAL1_SMALL is
+// "Small", BUILD_LAMBDA_1GB is "1GB")
+var codeBuildEnvironmentNames = map[cbtypes.EnvironmentType]string{
+	cbtypes.EnvironmentTypeLinuxContainer:             "Linux",
+	cbtypes.EnvironmentTypeLinuxGpuContainer:          "Linux GPU",
+	cbtypes.EnvironmentTypeArmContainer:               "ARM",
+	cbtypes.EnvironmentTypeWindowsContainer:           "Windows",
+	cbtypes.EnvironmentTypeWindowsServer2019Container: "Windows Server 2019",
+	cbtypes.EnvironmentTypeLinuxLambdaContainer:       "Linux Lambda",
+	cbtypes.EnvironmentTypeArmLambdaContainer:         "ARM Lambda",
+}
+
+// codeBuildComputeName returns the size a compute type is named by in quotas
+func codeBuildComputeName(computeType cbtypes.ComputeType) string {
+	name := string(computeType)
+	for _, prefix := range []string{"BUILD_GENERAL1_", "BUILD_LAMBDA_"} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	return name
+}
+
+// getCodeBuildRunningBuildCounts counts the builds in progress per environment
+// and compute type. Builds are listed newest first and the scan stops at the
+// first batch started before any build still running could have been.
+func getCodeBuildRunningBuildCounts(ctx context.Context, cfg aws.Config, _ string) (map[string]float64, error) {
+	client := codebuild.NewFromConfig(cfg)
+	cutoff := time.Now().Add(-codeBuildMaxBuildAge)
+
+	counts := make(map[string]float64)
+	paginator := codebuild.NewListBuildsPaginator(client, &codebuild.ListBuildsInput{
+		SortOrder: cbtypes.SortOrderTypeDescending,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for start := 0; start < len(output.Ids); start += codeBuildBatchSize {
+			end := min(start+codeBuildBatchSize, len(output.Ids))
+			builds, err := client.BatchGetBuilds(ctx, &codebuild.BatchGetBuildsInput{Ids: output.Ids[start:end]})
+			if err != nil {
+				return nil, err
+			}
+			recent := false
+			for _, build := range builds.Builds {
+				if build.BuildStatus == cbtypes.StatusTypeInProgress && build.Environment != nil {
+					env, ok := codeBuildEnvironmentNames[build.Environment.Type]
+					if !ok {
+						env = string(build.Environment.Type)
+					}
+					counts[codeBuildEnvironmentKey(env+"/"+codeBuildComputeName(build.Environment.ComputeType))]++
+				}
+				if build.StartTime != nil && build.StartTime.After(cutoff) {
+					recent = true
+				}
+			}
+			if !recent {
+				return counts, nil
+			}
+		}
+	}
+	return counts, nil
+}
+
+// ============================================================================
+// Cognito Usage Handlers
+// ============================================================================
+
+// cognitoPageSize is the maximum page size of the Cognito list APIs, which
+// require one
+const cognitoPageSize = 60
+
+func getCognitoUserPoolsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	ids, err := listCognitoUserPools(ctx, cognitoidentityprovider.NewFromConfig(cfg))
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(ids)), nil
+}
+
+// getCognitoAppClientsPerUserPoolUsage returns the app client count of the
+// user pool with the most clients
+func getCognitoAppClientsPerUserPoolUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := cognitoidentityprovider.NewFromConfig(cfg)
+	ids, err := listCognitoUserPools(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	counts := make(map[string]int, len(ids))
+	for _, id := range ids {
+		paginator := cognitoidentityprovider.NewListUserPoolClientsPaginator(client, &cognitoidentityprovider.ListUserPoolClientsInput{
+			UserPoolId: aws.String(id),
+			MaxResults: aws.Int32(cognitoPageSize),
+		})
+		for paginator.HasMorePages() {
+			output, err := paginator.NextPage(ctx)
+			if err != nil {
+				return 0, err
+			}
+			counts[id] += len(output.UserPoolClients)
+		}
+	}
+	return float64(maxCount(counts)), nil
+}
+
+func listCognitoUserPools(ctx context.Context, client *cognitoidentityprovider.Client) ([]string, error) {
+	var ids []string
+	paginator := cognitoidentityprovider.NewListUserPoolsPaginator(client, &cognitoidentityprovider.ListUserPoolsInput{
+		MaxResults: aws.Int32(cognitoPageSize),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, pool := range output.UserPools {
+			ids = append(ids, aws.ToString(pool.Id))
+		}
+	}
+	return ids, nil
+}
+
+// ============================================================================
+// AppSync Usage Handlers
+// ============================================================================
+
+func getAppSyncGraphQLAPIsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	ids, err := listAppSyncGraphQLAPIs(ctx, appsync.NewFromConfig(cfg))
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(ids)), nil
+}
+
+// getAppSyncResolversPerAPIUsage returns the resolver count of the API with
+// the most resolvers. Resolvers can only be listed per type, so every type of
+// every API is visited.
+func getAppSyncResolversPerAPIUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := appsync.NewFromConfig(cfg)
+	ids, err := listAppSyncGraphQLAPIs(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	counts := make(map[string]int, len(ids))
+	for _, id := range ids {
+		types := appsync.NewListTypesPaginator(client, &appsync.ListTypesInput{
+			ApiId:  aws.String(id),
+			Format: appsynctypes.TypeDefinitionFormatSdl,
+		})
+		for types.HasMorePages() {
+			output, err := types.NextPage(ctx)
+			if err != nil {
+				return 0, err
+			}
+			for _, t := range output.Types {
+				resolvers := appsync.NewListResolversPaginator(client, &appsync.ListResolversInput{
+					ApiId:    aws.String(id),
+					TypeName: t.Name,
+				})
+				for resolvers.HasMorePages() {
+					page, err := resolvers.NextPage(ctx)
+					if err != nil {
+						return 0, err
+					}
+					counts[id] += len(page.Resolvers)
+				}
+			}
+		}
+	}
+	return float64(maxCount(counts)), nil
+}
+
+func listAppSyncGraphQLAPIs(ctx context.Context, client *appsync.Client) ([]string, error) {
+	var ids []string
+	paginator := appsync.NewListGraphqlApisPaginator(client, &appsync.ListGraphqlApisInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, api := range output.GraphqlApis {
+			ids = append(ids, aws.ToString(api.ApiId))
+		}
+	}
+	return ids, nil
+}
+
+// ============================================================================
+// Elastic Beanstalk Usage Handlers
+// ============================================================================
+
+func getBeanstalkApplicationsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := elasticbeanstalk.NewFromConfig(cfg)
+	// DescribeApplications is not paginated and returns every application
+	output, err := client.DescribeApplications(ctx, &elasticbeanstalk.DescribeApplicationsInput{})
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(output.Applications)), nil
+}
+
+func getBeanstalkApplicationVersionsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := elasticbeanstalk.NewFromConfig(cfg)
+	// DescribeApplicationVersions has no paginator
+	count := 0
+	input := &elasticbeanstalk.DescribeApplicationVersionsInput{MaxRecords: aws.Int32(1000)}
+	for {
+		output, err := client.DescribeApplicationVersions(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+		count += len(output.ApplicationVersions)
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return float64(count), nil
+}
+
+// getBeanstalkEnvironmentsUsage counts the environments that are not
+// terminated
+func getBeanstalkEnvironmentsUsage(ctx context.Context, cfg aws.Config, _ string) (float64, error) {
+	client := elasticbeanstalk.NewFromConfig(cfg)
+	// DescribeEnvironments has no paginator
+	count := 0
+	input := &elasticbeanstalk.DescribeEnvironmentsInput{
+		IncludeDeleted: aws.Bool(false),
+		MaxRecords:     aws.Int32(1000),
+	}
+	for {
+		output, err := client.DescribeEnvironments(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+		for _, env := range output.Environments {
+			if env.Status != ebstypes.EnvironmentStatusTerminated {
+				count++
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+	return float64(count), nil
+}
@@ -3,6 +3,8 @@ package cache
 import (
 	"sync"
 	"time"
+
+	bolt "go.etcd.io/bbolt"
 )
 
 type Item struct {
@@ -14,6 +16,12 @@ type Cache struct {
 	items map[string]Item
 	mu    sync.RWMutex
 	ttl   time.Duration
+
+	// db persists entries and stale holds those loaded from it at startup
+	// until they are set again; both are unset for a memory-only cache
+	db       *bolt.DB
+	stale    map[string]StaleItem
+	maxStale time.Duration
 }
 
 func New(ttl time.Duration) *Cache {
@@ -26,12 +34,15 @@ func New(ttl time.Duration) *Cache {
 }
 
 func (c *Cache) Set(key string, value interface{}) {
+	now := time.Now()
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	c.items[key] = Item{
 		Value:     value,
-		ExpiresAt: time.Now().Add(c.ttl),
+		ExpiresAt: now.Add(c.ttl),
 	}
+	delete(c.stale, key)
+	c.mu.Unlock()
+	c.persist(key, value, now)
 }
 
 func (c *Cache) Get(key string) (interface{}, bool) {
@@ -49,14 +60,39 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 
 func (c *Cache) Delete(key string) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	delete(c.items, key)
+	delete(c.stale, key)
+	c.mu.Unlock()
+	c.unpersist(func(k string) bool { return k == key })
+}
+
+// DeleteFunc removes the entries whose key matches and returns how many were
+// removed
+func (c *Cache) DeleteFunc(match func(key string) bool) int {
+	c.mu.Lock()
+	removed := 0
+	for key := range c.items {
+		if match(key) {
+			delete(c.items, key)
+			removed++
+		}
+	}
+	for key := range c.stale {
+		if match(key) {
+			delete(c.stale, key)
+		}
+	}
+	c.mu.Unlock()
+	c.unpersist(match)
+	return removed
 }
 
 func (c *Cache) Clear() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	c.items = make(map[string]Item)
+	c.stale = nil
+	c.mu.Unlock()
+	c.unpersist(func(string) bool { return true })
 }
 
 func (c *Cache) cleanup() {
@@ -70,5 +106,6 @@ func (c *Cache) cleanup() {
 			}
 		}
 		c.mu.Unlock()
+		c.prunePersisted(now)
 	}
 }
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketName is the BoltDB bucket holding the persisted entries
+var bucketName = []byte("cache")
+
+// StaleItem is an entry persisted before the server restarted, served while
+// fresh data is fetched
+type StaleItem struct {
+	Value    interface{}
+	StoredAt time.Time
+}
+
+// persistedEntry is the encoded form of a value. On disk it follows the
+// store time, in Unix nanoseconds, so old entries are found without decoding.
+type persistedEntry struct {
+	Value interface{}
+}
+
+// Register makes values of the type of v persistable. Values of unregistered
+// types are only cached in memory.
+func Register(v interface{}) {
+	gob.Register(v)
+}
+
+// NewPersistent creates a cache whose entries of registered types are also
+// written to a BoltDB file. Entries persisted by a previous run and younger
+// than maxStale are available through GetStale until they are set again.
+func NewPersistent(ttl time.Duration, path string, maxStale time.Duration) (*Cache, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache file %s: %w", path, err)
+	}
+	stale := make(map[string]StaleItem)
+	cutoff := time.Now().Add(-maxStale)
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucketName)
+		if err != nil {
+			return err
+		}
+		var expired [][]byte
+		err = b.ForEach(func(k, v []byte) error {
+			at, ok := storedAt(v)
+			if !ok || at.Before(cutoff) {
+				expired = append(expired, append([]byte(nil), k...))
+				return nil
+			}
+			// Entries of types not registered are skipped, and pruned once
+			// they are too old
+			var entry persistedEntry
+			if gob.NewDecoder(bytes.NewReader(v[8:])).Decode(&entry) == nil {
+				stale[string(k)] = StaleItem{Value: entry.Value, StoredAt: at}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			log.Printf("Failed to close cache file: %v", closeErr)
+		}
+		return nil, fmt.Errorf("failed to load cache file %s: %w", path, err)
+	}
+
+	c := New(ttl)
+	c.db, c.stale, c.maxStale = db, stale, maxStale
+	return c, nil
+}
+
+// GetStale returns an entry persisted before the server restarted that has
+// not been set since, and when it was stored
+func (c *Cache) GetStale(key string) (interface{}, time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, ok := c.stale[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return item.Value, item.StoredAt, true
+}
+
+// StaleKeys returns the keys of the persisted entries not refreshed since the
+// server started
+func (c *Cache) StaleKeys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, len(c.stale))
+	for key := range c.stale {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// StaleCount returns the number of persisted entries not refreshed since the
+// server started
+func (c *Cache) StaleCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.stale)
+}
+
+// Close closes the cache file of a persistent cache
+func (c *Cache) Close() error {
+	if c.db == nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// persist writes an entry to the cache file, if its type is registered
+func (c *Cache) persist(key string, value interface{}, at time.Time) {
+	if c.db == nil {
+		return
+	}
+	var buf bytes.Buffer
+	buf.Write(binary.BigEndian.AppendUint64(nil, uint64(at.UnixNano())))
+	if err := gob.NewEncoder(&buf).Encode(persistedEntry{Value: value}); err != nil {
+		// Not a registered type
+		return
+	}
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put([]byte(key), buf.Bytes())
+	})
+	if err != nil {
+		log.Printf("Failed to persist cache entry %s: %v", key, err)
+	}
+}
+
+// unpersist removes the entries whose key matches from the cache file
+func (c *Cache) unpersist(match func(key string) bool) {
+	c.unpersistFunc(func(key string, _ []byte) bool { return match(key) })
+}
+
+func (c *Cache) unpersistFunc(match func(key string, value []byte) bool) {
+	if c.db == nil {
+		return
+	}
+	// Matched in a read transaction so that the periodic pruning does not
+	// write the file when nothing is removed
+	var keys [][]byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).ForEach(func(k, v []byte) error {
+			if match(string(k), v) {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+	})
+	if err == nil && len(keys) > 0 {
+		err = c.db.Update(func(tx *bolt.Tx) error {
+			b := tx.Bucket(bucketName)
+			for _, k := range keys {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		log.Printf("Failed to remove persisted cache entries: %v", err)
+	}
+}
+
+// prunePersisted drops the persisted entries older than the stale limit,
+// which a restart would not serve anymore
+func (c *Cache) prunePersisted(now time.Time) {
+	if c.db == nil {
+		return
+	}
+	cutoff := now.Add(-c.maxStale)
+	c.mu.Lock()
+	for key, item := range c.stale {
+		if item.StoredAt.Before(cutoff) {
+			delete(c.stale, key)
+		}
+	}
+	c.mu.Unlock()
+	c.unpersistFunc(func(_ string, value []byte) bool {
+		at, ok := storedAt(value)
+		return !ok || at.Before(cutoff)
+	})
+}
+
+// storedAt reads the store time prefixed to a persisted entry
+func storedAt(value []byte) (time.Time, bool) {
+	if len(value) < 8 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(value))), true
+}
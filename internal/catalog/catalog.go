@@ -0,0 +1,173 @@
+// Package catalog provides offline service and quota metadata so quotas can be
+// labeled when the Service Quotas listing APIs are throttled or unavailable.
+package catalog
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// bundled is the catalog shipped with the binary. Regenerate it with
+// `make catalog`; until then it is empty.
+//
+//go:embed catalog.json
+var bundled []byte
+
+// ErrNotGenerated reports that the bundled catalog was never generated from
+// Service Quotas, so there is nothing to compare a live listing against
+var ErrNotGenerated = errors.New("the bundled quota catalog has not been generated; run make catalog with AWS credentials")
+
+// Quota is the metadata of a single quota code
+type Quota struct {
+	QuotaCode  string `json:"quota_code"`
+	QuotaName  string `json:"quota_name"`
+	Unit       string `json:"unit"`
+	Adjustable bool   `json:"adjustable"`
+	Global     bool   `json:"global"`
+}
+
+// Service is the metadata of a service and its quota codes
+type Service struct {
+	ServiceCode string  `json:"service_code"`
+	ServiceName string  `json:"service_name"`
+	Quotas      []Quota `json:"quotas"`
+}
+
+// Bundle is the serialized form of a catalog
+type Bundle struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Services    []Service `json:"services"`
+}
+
+// Catalog holds quota metadata indexed by service and quota code. It starts
+// from the bundled snapshot and is refreshed as live listings succeed.
+type Catalog struct {
+	mu          sync.RWMutex
+	generatedAt time.Time
+	services    map[string]*Service
+	quotas      map[string]map[string]Quota
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+)
+
+// Default returns the process-wide catalog loaded from the bundled snapshot
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		defaultCatalog = Bundled()
+	})
+	return defaultCatalog
+}
+
+// Bundled returns a fresh copy of the catalog shipped with the binary,
+// without the live listings merged into Default since startup
+func Bundled() *Catalog {
+	c, err := Parse(bundled)
+	if err != nil {
+		// The bundle is embedded at build time, so this is a programming error
+		panic(fmt.Sprintf("invalid bundled quota catalog: %v", err))
+	}
+	return c
+}
+
+// Parse builds a catalog from a serialized bundle
+func Parse(data []byte) (*Catalog, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	c := &Catalog{
+		generatedAt: b.GeneratedAt,
+		services:    make(map[string]*Service),
+		quotas:      make(map[string]map[string]Quota),
+	}
+	for _, svc := range b.Services {
+		c.Update(svc.ServiceCode, svc.ServiceName, svc.Quotas)
+	}
+	return c, nil
+}
+
+// Update replaces the known quotas of a service with a fresh listing
+func (c *Catalog) Update(serviceCode, serviceName string, quotas []Quota) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.services[serviceCode] = &Service{
+		ServiceCode: serviceCode,
+		ServiceName: serviceName,
+		Quotas:      quotas,
+	}
+	byCode := make(map[string]Quota, len(quotas))
+	for _, q := range quotas {
+		byCode[q.QuotaCode] = q
+	}
+	c.quotas[serviceCode] = byCode
+}
+
+// Lookup returns the metadata of a quota code
+func (c *Catalog) Lookup(serviceCode, quotaCode string) (Quota, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	q, ok := c.quotas[serviceCode][quotaCode]
+	return q, ok
+}
+
+// ServiceName returns the display name of a service, or the empty string
+func (c *Catalog) ServiceName(serviceCode string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if svc, ok := c.services[serviceCode]; ok {
+		return svc.ServiceName
+	}
+	return ""
+}
+
+// Generated reports whether the catalog was generated from a live listing, as
+// opposed to the empty placeholder bundle
+func (c *Catalog) Generated() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.generatedAt.IsZero()
+}
+
+// Services returns the known services ordered by service code
+func (c *Catalog) Services() []model.Service {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	services := make([]model.Service, 0, len(c.services))
+	for _, svc := range c.services {
+		services = append(services, model.Service{Code: svc.ServiceCode, Name: svc.ServiceName})
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Code < services[j].Code })
+	return services
+}
+
+// Quotas returns the known quotas of a service
+func (c *Catalog) Quotas(serviceCode string) []Quota {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if svc, ok := c.services[serviceCode]; ok {
+		return append([]Quota(nil), svc.Quotas...)
+	}
+	return nil
+}
+
+// Bundle returns a serializable snapshot of the catalog
+func (c *Catalog) Bundle() Bundle {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	b := Bundle{GeneratedAt: c.generatedAt}
+	for _, svc := range c.services {
+		b.Services = append(b.Services, *svc)
+	}
+	sort.Slice(b.Services, func(i, j int) bool { return b.Services[i].ServiceCode < b.Services[j].ServiceCode })
+	return b
+}
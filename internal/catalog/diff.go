@@ -0,0 +1,65 @@
+package catalog
+
+import (
+	"sort"
+	"time"
+)
+
+// Diff is the difference between the bundled catalog and a live listing
+type Diff struct {
+	ComparedAt time.Time `json:"compared_at"`
+	// BundledAt is when the bundled catalog was generated
+	BundledAt time.Time     `json:"bundled_at"`
+	Region    string        `json:"region"`
+	Services  []ServiceDiff `json:"services"`
+	Added     int           `json:"added"`
+	Removed   int           `json:"removed"`
+}
+
+// ServiceDiff lists the quota codes of a service that AWS introduced or
+// retired since the bundled catalog was generated. NewService is set when the
+// whole service is missing from the bundle.
+type ServiceDiff struct {
+	ServiceCode string  `json:"service_code"`
+	ServiceName string  `json:"service_name"`
+	NewService  bool    `json:"new_service,omitempty"`
+	Added       []Quota `json:"added,omitempty"`
+	Removed     []Quota `json:"removed,omitempty"`
+}
+
+// Compare diffs a live listing against the catalog. Services missing from the
+// live listing are not reported as removed, as a listing may skip services it
+// failed to list.
+func (c *Catalog) Compare(live []Service, region string, now time.Time) Diff {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	diff := Diff{ComparedAt: now, BundledAt: c.generatedAt, Region: region, Services: []ServiceDiff{}}
+	for _, svc := range live {
+		known, ok := c.quotas[svc.ServiceCode]
+		sd := ServiceDiff{ServiceCode: svc.ServiceCode, ServiceName: svc.ServiceName, NewService: !ok}
+
+		liveCodes := make(map[string]bool, len(svc.Quotas))
+		for _, q := range svc.Quotas {
+			liveCodes[q.QuotaCode] = true
+			if _, ok := known[q.QuotaCode]; !ok {
+				sd.Added = append(sd.Added, q)
+			}
+		}
+		for code, q := range known {
+			if !liveCodes[code] {
+				sd.Removed = append(sd.Removed, q)
+			}
+		}
+		if len(sd.Added) == 0 && len(sd.Removed) == 0 {
+			continue
+		}
+		sort.Slice(sd.Added, func(i, j int) bool { return sd.Added[i].QuotaCode < sd.Added[j].QuotaCode })
+		sort.Slice(sd.Removed, func(i, j int) bool { return sd.Removed[i].QuotaCode < sd.Removed[j].QuotaCode })
+		diff.Added += len(sd.Added)
+		diff.Removed += len(sd.Removed)
+		diff.Services = append(diff.Services, sd)
+	}
+	sort.Slice(diff.Services, func(i, j int) bool { return diff.Services[i].ServiceCode < diff.Services[j].ServiceCode })
+	return diff
+}
@@ -0,0 +1,125 @@
+// Package composite evaluates config-defined quotas that combine the usage of
+// several underlying quotas against a self-imposed limit
+package composite
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// DefaultServiceCode is used for composite quotas that do not set a service
+const DefaultServiceCode = "composite"
+
+// Quota is a compiled composite quota definition
+type Quota struct {
+	def  config.CompositeQuota
+	expr expr
+	refs map[string]bool
+}
+
+// Compile parses the composite quota definitions
+func Compile(defs []config.CompositeQuota) ([]Quota, error) {
+	quotas := make([]Quota, 0, len(defs))
+	seen := make(map[string]bool, len(defs))
+	for _, def := range defs {
+		if def.Name == "" || def.Code == "" {
+			return nil, fmt.Errorf("composite quota requires a name and a code")
+		}
+		if seen[def.Code] {
+			return nil, fmt.Errorf("duplicate composite quota code %q", def.Code)
+		}
+		seen[def.Code] = true
+		if def.Limit <= 0 {
+			return nil, fmt.Errorf("composite quota %q: limit must be positive", def.Code)
+		}
+		e, refs, err := parse(def.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("composite quota %q: invalid expression: %w", def.Code, err)
+		}
+		q := Quota{def: def, expr: e, refs: make(map[string]bool, len(refs))}
+		for _, r := range refs {
+			q.refs[r] = true
+		}
+		quotas = append(quotas, q)
+	}
+	return quotas, nil
+}
+
+// group is the set of quotas of one account and region
+type group struct {
+	accountID string
+	region    string
+	usage     map[string]float64
+	hasUsage  map[string]bool
+}
+
+// Append evaluates the composite quotas for every account and region present
+// in quotas and returns quotas with the composite rows appended. A composite
+// row is only produced where every referenced quota is present and reports
+// usage, so a partial total is never recorded or alerted on. quotas must come
+// from a fetch of every service; filtered fetches carry no composite rows.
+func Append(composites []Quota, quotas []model.Quota) []model.Quota {
+	if len(composites) == 0 {
+		return quotas
+	}
+
+	groups := make(map[string]*group)
+	var keys []string
+	for _, q := range quotas {
+		key := q.AccountID + "/" + q.Region
+		g, ok := groups[key]
+		if !ok {
+			g = &group{accountID: q.AccountID, region: q.Region, usage: make(map[string]float64), hasUsage: make(map[string]bool)}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		if q.HasUsageMetrics {
+			g.usage[q.QuotaCode] = q.Usage
+			g.hasUsage[q.QuotaCode] = true
+		}
+	}
+	sort.Strings(keys)
+
+	result := quotas
+	for _, key := range keys {
+		g := groups[key]
+		for _, c := range composites {
+			if !c.covers(g) {
+				continue
+			}
+			usage := c.expr.eval(func(code string) float64 { return g.usage[code] })
+			serviceCode := c.def.Service
+			if serviceCode == "" {
+				serviceCode = DefaultServiceCode
+			}
+			result = append(result, model.Quota{
+				AccountID:       g.accountID,
+				Region:          g.region,
+				ServiceCode:     serviceCode,
+				ServiceName:     "Composite",
+				QuotaName:       c.def.Name,
+				QuotaCode:       c.def.Code,
+				Value:           c.def.Limit,
+				Usage:           usage,
+				UsagePercentage: usage / c.def.Limit * 100,
+				HasUsageMetrics: true,
+				Unit:            "None",
+				Composite:       true,
+			})
+		}
+	}
+	return result
+}
+
+// covers reports whether every quota referenced by c reports usage in g
+func (c Quota) covers(g *group) bool {
+	for code := range c.refs {
+		if !g.hasUsage[code] {
+			return false
+		}
+	}
+	return true
+}
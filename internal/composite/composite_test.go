@@ -0,0 +1,125 @@
+package composite
+
+import (
+	"testing"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+func TestCompile(t *testing.T) {
+	valid := config.CompositeQuota{Name: "vCPUs", Code: "vcpus", Expression: "L-A + L-B", Limit: 100}
+	tests := []struct {
+		name    string
+		defs    []config.CompositeQuota
+		wantErr bool
+	}{
+		{name: "valid", defs: []config.CompositeQuota{valid}},
+		{name: "none"},
+		{name: "missing name", defs: []config.CompositeQuota{{Code: "c", Expression: "L-A", Limit: 1}}, wantErr: true},
+		{name: "missing code", defs: []config.CompositeQuota{{Name: "n", Expression: "L-A", Limit: 1}}, wantErr: true},
+		{name: "duplicate code", defs: []config.CompositeQuota{valid, valid}, wantErr: true},
+		{name: "zero limit", defs: []config.CompositeQuota{{Name: "n", Code: "c", Expression: "L-A"}}, wantErr: true},
+		{name: "negative limit", defs: []config.CompositeQuota{{Name: "n", Code: "c", Expression: "L-A", Limit: -1}}, wantErr: true},
+		{name: "invalid expression", defs: []config.CompositeQuota{{Name: "n", Code: "c", Expression: "L-A +", Limit: 1}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotas, err := Compile(tt.defs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compile error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(quotas) != len(tt.defs) {
+				t.Errorf("Compile returned %d quotas, want %d", len(quotas), len(tt.defs))
+			}
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	composites, err := Compile([]config.CompositeQuota{
+		{Name: "vCPUs", Code: "vcpus", Expression: "L-A + L-B", Limit: 200},
+		{Name: "Network", Code: "network", Service: "vpc", Expression: "L-C * 2", Limit: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	quota := func(account, region, code string, usage float64) model.Quota {
+		return model.Quota{AccountID: account, Region: region, QuotaCode: code, Usage: usage, HasUsageMetrics: true}
+	}
+	noUsage := func(account, region, code string) model.Quota {
+		return model.Quota{AccountID: account, Region: region, QuotaCode: code}
+	}
+
+	type row struct {
+		account, region, code, service string
+		usage, percentage              float64
+	}
+	tests := []struct {
+		name   string
+		quotas []model.Quota
+		want   []row
+	}{
+		{
+			name:   "complete",
+			quotas: []model.Quota{quota("1", "us-east-1", "L-A", 30), quota("1", "us-east-1", "L-B", 20), quota("1", "us-east-1", "L-C", 4)},
+			want: []row{
+				{account: "1", region: "us-east-1", code: "vcpus", service: DefaultServiceCode, usage: 50, percentage: 25},
+				{account: "1", region: "us-east-1", code: "network", service: "vpc", usage: 8, percentage: 80},
+			},
+		},
+		{
+			name:   "referenced quota missing",
+			quotas: []model.Quota{quota("1", "us-east-1", "L-A", 30), quota("1", "us-east-1", "L-C", 4)},
+			want:   []row{{account: "1", region: "us-east-1", code: "network", service: "vpc", usage: 8, percentage: 80}},
+		},
+		{
+			name:   "referenced quota without usage",
+			quotas: []model.Quota{quota("1", "us-east-1", "L-A", 30), noUsage("1", "us-east-1", "L-B")},
+		},
+		{
+			name: "per account and region",
+			quotas: []model.Quota{
+				quota("2", "us-west-2", "L-A", 10), quota("2", "us-west-2", "L-B", 10),
+				quota("1", "us-east-1", "L-A", 100), quota("1", "us-east-1", "L-B", 100),
+				quota("1", "eu-west-1", "L-A", 1),
+			},
+			want: []row{
+				{account: "1", region: "us-east-1", code: "vcpus", service: DefaultServiceCode, usage: 200, percentage: 100},
+				{account: "2", region: "us-west-2", code: "vcpus", service: DefaultServiceCode, usage: 20, percentage: 10},
+			},
+		},
+		{name: "no quotas"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Append(composites, tt.quotas)
+			if len(result) < len(tt.quotas) {
+				t.Fatalf("Append dropped quotas: got %d rows from %d", len(result), len(tt.quotas))
+			}
+			added := result[len(tt.quotas):]
+			if len(added) != len(tt.want) {
+				t.Fatalf("Append added %d composite rows, want %d: %+v", len(added), len(tt.want), added)
+			}
+			for i, w := range tt.want {
+				got := added[i]
+				if got.AccountID != w.account || got.Region != w.region || got.QuotaCode != w.code || got.ServiceCode != w.service {
+					t.Errorf("row %d = %s/%s %s %s, want %s/%s %s %s", i, got.AccountID, got.Region, got.ServiceCode, got.QuotaCode, w.account, w.region, w.service, w.code)
+				}
+				if got.Usage != w.usage || got.UsagePercentage != w.percentage {
+					t.Errorf("row %d usage = %v (%v%%), want %v (%v%%)", i, got.Usage, got.UsagePercentage, w.usage, w.percentage)
+				}
+				if !got.Composite || !got.HasUsageMetrics {
+					t.Errorf("row %d is not marked as a composite with usage", i)
+				}
+			}
+		})
+	}
+}
+
+func TestAppendWithoutComposites(t *testing.T) {
+	quotas := []model.Quota{{AccountID: "1", Region: "us-east-1", QuotaCode: "L-A", Usage: 1, HasUsageMetrics: true}}
+	if got := Append(nil, quotas); len(got) != 1 {
+		t.Errorf("Append(nil) returned %d rows, want 1", len(got))
+	}
+}
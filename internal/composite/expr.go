@@ -0,0 +1,187 @@
+package composite
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// expr is a parsed arithmetic expression over quota codes
+type expr interface {
+	eval(lookup func(code string) float64) float64
+}
+
+type number float64
+
+func (n number) eval(func(string) float64) float64 { return float64(n) }
+
+type ref string
+
+func (r ref) eval(lookup func(string) float64) float64 { return lookup(string(r)) }
+
+type binary struct {
+	op          byte
+	left, right expr
+}
+
+func (b binary) eval(lookup func(string) float64) float64 {
+	l, r := b.left.eval(lookup), b.right.eval(lookup)
+	switch b.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	default:
+		if r == 0 {
+			return 0
+		}
+		return l / r
+	}
+}
+
+type negate struct{ operand expr }
+
+func (n negate) eval(lookup func(string) float64) float64 { return -n.operand.eval(lookup) }
+
+// parser is a recursive descent parser for
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = factor { ("*" | "/") factor }
+//	factor = number | code | "(" expr ")" | "-" factor
+//
+// Quota codes may contain hyphens (L-FE5A380F), so a minus sign following a
+// code must be separated by whitespace.
+type parser struct {
+	input string
+	pos   int
+	refs  []string
+}
+
+func parse(input string) (expr, []string, error) {
+	p := &parser{input: input}
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+	}
+	return e, p.refs, nil
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '+' && p.input[p.pos] != '-') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseTerm() (expr, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '*' && p.input[p.pos] != '/') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseFactor() (expr, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return e, nil
+	case c == '-':
+		p.pos++
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return negate{operand: operand}, nil
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+		}
+		return number(v), nil
+	case unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.input) {
+			ch := p.input[p.pos]
+			if isIdentChar(ch) {
+				p.pos++
+				continue
+			}
+			// A hyphen is part of the code only when directly followed by
+			// another code character
+			if ch == '-' && p.pos+1 < len(p.input) && isIdentChar(p.input[p.pos+1]) {
+				p.pos++
+				continue
+			}
+			break
+		}
+		code := p.input[start:p.pos]
+		p.refs = append(p.refs, code)
+		return ref(code), nil
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c byte) bool {
+	return isDigit(c) || c == '_' || unicode.IsLetter(rune(c))
+}
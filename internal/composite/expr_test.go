@@ -0,0 +1,77 @@
+package composite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	usage := map[string]float64{"L-A": 10, "L-B": 4, "L-C": 2}
+	lookup := func(code string) float64 { return usage[code] }
+
+	tests := []struct {
+		input string
+		want  float64
+		refs  []string
+	}{
+		{input: "42", want: 42},
+		{input: "1.5", want: 1.5},
+		{input: "L-A", want: 10, refs: []string{"L-A"}},
+		{input: "L-A + L-B", want: 14, refs: []string{"L-A", "L-B"}},
+		{input: "L-A - L-B", want: 6, refs: []string{"L-A", "L-B"}},
+		{input: "L-A + L-B * L-C", want: 18, refs: []string{"L-A", "L-B", "L-C"}},
+		{input: "(L-A + L-B) * L-C", want: 28, refs: []string{"L-A", "L-B", "L-C"}},
+		{input: "L-A - L-B - L-C", want: 4, refs: []string{"L-A", "L-B", "L-C"}},
+		{input: "L-A / L-B / L-C", want: 1.25, refs: []string{"L-A", "L-B", "L-C"}},
+		{input: "-L-A + 2", want: -8, refs: []string{"L-A"}},
+		{input: "L-A / 0", want: 0, refs: []string{"L-A"}},
+		{input: "L-A * 2 + L-MISSING", want: 20, refs: []string{"L-A", "L-MISSING"}},
+		{input: "  L-A\t+\nL-B  ", want: 14, refs: []string{"L-A", "L-B"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			e, refs, err := parse(tt.input)
+			if err != nil {
+				t.Fatalf("parse(%q): %v", tt.input, err)
+			}
+			if got := e.eval(lookup); got != tt.want {
+				t.Errorf("eval = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(refs, tt.refs) {
+				t.Errorf("refs = %v, want %v", refs, tt.refs)
+			}
+		})
+	}
+}
+
+func TestParseHyphenatedCodes(t *testing.T) {
+	// Without whitespace the minus sign is read as part of the code
+	_, refs, err := parse("L-A-L-B")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"L-A-L-B"}; !reflect.DeepEqual(refs, want) {
+		t.Errorf("refs = %v, want %v", refs, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"L-A +",
+		"(L-A + L-B",
+		"L-A + L-B)",
+		"L-A L-B",
+		"L-A $ 2",
+		"1..2",
+		"* L-A",
+	}
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if _, _, err := parse(input); err == nil {
+				t.Errorf("parse(%q) succeeded, want error", input)
+			}
+		})
+	}
+}
@@ -8,20 +8,461 @@ import (
 )
 
 type Config struct {
-	DefaultRegion  string       `yaml:"default_region"`
-	DefaultService string       `yaml:"default_service"`
-	Server         ServerConfig `yaml:"server"`
-	Cache          CacheConfig  `yaml:"cache"`
-	MaxConcurrency int          `yaml:"max_concurrency"`
-	Regions        []string     `yaml:"regions"`
+	DefaultRegion  string              `yaml:"default_region"`
+	DefaultService string              `yaml:"default_service"`
+	Server         ServerConfig        `yaml:"server"`
+	Cache          CacheConfig         `yaml:"cache"`
+	Thresholds     ThresholdsConfig    `yaml:"thresholds"`
+	Webhooks       WebhooksConfig      `yaml:"webhooks"`
+	MaxConcurrency int                 `yaml:"max_concurrency"`
+	Regions        []string            `yaml:"regions"`
+	OrgScan        OrgScanConfig       `yaml:"org_scan"`
+	Increase       IncreaseConfig      `yaml:"increase_requests"`
+	Attribution    AttributionConfig   `yaml:"attribution"`
+	Cost           CostConfig          `yaml:"cost"`
+	Alerts         AlertsConfig        `yaml:"alerts"`
+	Ownership      OwnershipConfig     `yaml:"ownership"`
+	History        HistoryConfig       `yaml:"history"`
+	ReportHosting  ReportHostingConfig `yaml:"report_hosting"`
+	Slack          SlackConfig         `yaml:"slack"`
+	Teams          TeamsConfig         `yaml:"teams"`
+	Email          EmailConfig         `yaml:"email"`
+	SNS            SNSConfig           `yaml:"sns"`
+	Digest         DigestConfig        `yaml:"digest"`
+	Composites     []CompositeQuota    `yaml:"composite_quotas"`
+	Proxy          ProxyConfig         `yaml:"proxy"`
+	Review         ReviewConfig        `yaml:"review"`
+	EndpointURL    string              `yaml:"endpoint_url"`
+	Endpoints      map[string]string   `yaml:"endpoints"`
+
+	// UsageHandlerTimeoutSeconds bounds each direct usage API query
+	UsageHandlerTimeoutSeconds int `yaml:"usage_handler_timeout_seconds"`
+
+	// ScanRate bounds the adaptive request rate per AWS API
+	ScanRate ScanRateConfig `yaml:"scan_rate"`
+
+	// ScanProfiles are named scan scopes selected with the profile parameter
+	ScanProfiles []ScanProfile `yaml:"scan_profiles"`
+
+	// PostgresSink mirrors every snapshot into PostgreSQL for BI tools
+	PostgresSink PostgresSinkConfig `yaml:"postgres_sink"`
+
+	// OIDC authenticates API callers and maps their IdP groups to roles
+	OIDC OIDCConfig `yaml:"oidc"`
+
+	// CatalogDiff compares the live quota catalog against the bundled one
+	CatalogDiff CatalogDiffConfig `yaml:"catalog_diff"`
+
+	// Demo simulates an organization instead of scanning AWS
+	Demo DemoConfig `yaml:"demo"`
+
+	// AccessLog records API requests for usage analytics
+	AccessLog AccessLogConfig `yaml:"access_log"`
+
+	// SupportCases opens AWS Support cases for non-adjustable quotas
+	SupportCases SupportCasesConfig `yaml:"support_cases"`
+}
+
+// SupportCasesConfig enables opening AWS Support cases for quotas Service
+// Quotas cannot adjust, and listing the cases about a quota opened in the
+// last LookbackDays. The Support API needs a Business, Enterprise On-Ramp or
+// Enterprise support plan.
+type SupportCasesConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Severity     string   `yaml:"severity"`
+	CCEmails     []string `yaml:"cc_emails"`
+	LookbackDays int      `yaml:"lookback_days"`
+}
+
+// AccessLogConfig records API requests, keeping the last MaxEntries in
+// memory for usage analytics. With the PostgreSQL sink enabled, requests are
+// also written to its access_log table every FlushSeconds.
+type AccessLogConfig struct {
+	Enabled      bool `yaml:"enabled"`
+	MaxEntries   int  `yaml:"max_entries"`
+	FlushSeconds int  `yaml:"flush_seconds"`
+}
+
+// DemoConfig simulates an organization, so the org inventory, its
+// comparisons and alerts can be demoed and tested without AWS. The org scan
+// then walks Accounts simulated accounts in Regions on Schedule; usage is
+// deterministic for a Seed.
+type DemoConfig struct {
+	Enabled  bool     `yaml:"enabled"`
+	Accounts int      `yaml:"accounts"`
+	Regions  []string `yaml:"regions"`
+	Seed     int64    `yaml:"seed"`
+	Schedule string   `yaml:"schedule"`
+}
+
+// CatalogDiffConfig schedules the comparison of the live Service Quotas
+// catalog of Region against the bundled catalog, reporting quota codes AWS
+// added or retired since
+type CatalogDiffConfig struct {
+	Schedule string `yaml:"schedule"`
+	Region   string `yaml:"region"`
+}
+
+// OIDCConfig configures OIDC authentication of the API. Callers send an ID
+// token issued for ClientID as a bearer token; the groups in GroupsClaim
+// decide their role (viewer, operator or admin), the highest mapped role
+// winning. Users without a mapped group get DefaultRole, or are denied when
+// it is empty.
+type OIDCConfig struct {
+	Enabled     bool              `yaml:"enabled"`
+	IssuerURL   string            `yaml:"issuer_url"`
+	ClientID    string            `yaml:"client_id"`
+	GroupsClaim string            `yaml:"groups_claim"`
+	Groups      map[string]string `yaml:"groups"`
+	DefaultRole string            `yaml:"default_role"`
+}
+
+// PostgresSinkConfig configures the PostgreSQL mirror of quota snapshots. It
+// is enabled by setting DSN.
+type PostgresSinkConfig struct {
+	DSN    string `yaml:"dsn"`
+	Schema string `yaml:"schema"`
+	// RetentionDays deletes snapshots and access log entries older than
+	// this; the latest snapshot of each source is always kept. 0 keeps
+	// them forever.
+	RetentionDays int `yaml:"retention_days"`
+}
+
+// ScanProfile is a named scan scope. With QuotaCodes only those quotas are
+// fetched, one call each, instead of listing whole services.
+type ScanProfile struct {
+	Name       string   `yaml:"name" json:"name"`
+	Regions    []string `yaml:"regions" json:"regions,omitempty"`
+	Service    string   `yaml:"service" json:"service,omitempty"`
+	QuotaCodes []string `yaml:"quota_codes" json:"quota_codes,omitempty"`
+}
+
+// ScanRateConfig bounds the adaptive request rate, in requests per second per
+// region and API. Each API starts at Initial, halves on throttling and climbs
+// back towards Max while calls succeed.
+type ScanRateConfig struct {
+	Initial float64 `yaml:"initial"`
+	Min     float64 `yaml:"min"`
+	Max     float64 `yaml:"max"`
 }
 
 type ServerConfig struct {
 	Port string `yaml:"port"`
+	// RequestTimeoutSeconds bounds synchronous requests that scan AWS;
+	// longer scans go through the fetch job API. 0 disables the limit.
+	RequestTimeoutSeconds int `yaml:"request_timeout_seconds"`
+	// TrustedProxies are the reverse proxies (IPs or CIDRs) whose
+	// X-Forwarded-* headers are trusted; none by default
+	TrustedProxies []string `yaml:"trusted_proxies"`
 }
 
 type CacheConfig struct {
 	TTLMinutes int `yaml:"ttl_minutes"`
+	// Path persists cached quotas in a BoltDB file, so a restarted server
+	// serves them, marked stale, while refreshing in the background
+	Path string `yaml:"path"`
+	// StaleMaxAgeHours is how old persisted entries may be and still be
+	// served after a restart
+	StaleMaxAgeHours int `yaml:"stale_max_age_hours"`
+}
+
+// HistoryConfig configures the quota history store
+type HistoryConfig struct {
+	// RetireAfterHours is how long a quota must be missing from complete
+	// fetches before its series is retired
+	RetireAfterHours int `yaml:"retire_after_hours"`
+	// SQLitePath keeps the history in a SQLite database file instead of
+	// memory, so it survives restarts
+	SQLitePath string `yaml:"sqlite_path"`
+	// RawRetentionDays keeps every observation this long; older ones are
+	// compacted to one per RollupResolution bucket. 0 disables compaction.
+	RawRetentionDays int    `yaml:"raw_retention_days"`
+	RollupResolution string `yaml:"rollup_resolution"`
+	// RollupRetentionDays deletes observations and warnings older than
+	// this; 0 keeps them forever
+	RollupRetentionDays int `yaml:"rollup_retention_days"`
+	// CompactionSchedule is the cron schedule of the compaction job; empty
+	// disables it
+	CompactionSchedule string `yaml:"compaction_schedule"`
+}
+
+// OrgScanConfig configures the scheduled organization-wide scan, used when the
+// server runs in the management or a delegated administrator account
+type OrgScanConfig struct {
+	Enabled     bool     `yaml:"enabled"`
+	Schedule    string   `yaml:"schedule"`
+	RoleName    string   `yaml:"role_name"`
+	Regions     []string `yaml:"regions"`
+	Service     string   `yaml:"service"`
+	ScanOnStart bool     `yaml:"scan_on_start"`
+	// FallbackRoleName is assumed when the primary role cannot be assumed
+	FallbackRoleName string                              `yaml:"fallback_role_name"`
+	Accounts         map[string]AccountCredentialsConfig `yaml:"accounts"`
+}
+
+// AccountCredentialsConfig overrides the roles assumed in a single account.
+// Values are role names in the account or full role ARNs.
+type AccountCredentialsConfig struct {
+	RoleName         string `yaml:"role_name"`
+	FallbackRoleName string `yaml:"fallback_role_name"`
+}
+
+// AccountRoles returns the primary and fallback role for an account, applying
+// per-account overrides to the scan-wide defaults
+func (c OrgScanConfig) AccountRoles(accountID string) (primary, fallback string) {
+	primary, fallback = c.RoleName, c.FallbackRoleName
+	if override, ok := c.Accounts[accountID]; ok {
+		if override.RoleName != "" {
+			primary = override.RoleName
+		}
+		if override.FallbackRoleName != "" {
+			fallback = override.FallbackRoleName
+		}
+	}
+	return primary, fallback
+}
+
+// IncreaseConfig configures quota increase requests submitted through the dashboard
+type IncreaseConfig struct {
+	Templates []JustificationTemplate `yaml:"templates"`
+	// WatchMinutes is how often open requests are polled for a decision;
+	// 0 disables the watcher
+	WatchMinutes int `yaml:"watch_minutes"`
+}
+
+// JustificationTemplate is a reusable business justification, written as a Go
+// text/template over the quota being increased
+type JustificationTemplate struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Text        string `yaml:"text" json:"text"`
+}
+
+// AttributionConfig configures the CloudTrail-based analysis of who is creating
+// the resources counted by a breaching quota
+type AttributionConfig struct {
+	Enabled            bool    `yaml:"enabled"`
+	LookbackHours      int     `yaml:"lookback_hours"`
+	MinUsagePercentage float64 `yaml:"min_usage_percentage"`
+}
+
+// ThresholdsConfig sets the usage percentages at which a quota's status
+// becomes warning and critical
+type ThresholdsConfig struct {
+	Warning   float64             `yaml:"warning"`
+	Critical  float64             `yaml:"critical"`
+	Overrides []ThresholdOverride `yaml:"overrides"`
+}
+
+// ThresholdOverride replaces the thresholds of a service, or of a quota code
+// optionally within a service. A zero threshold keeps the default.
+type ThresholdOverride struct {
+	Service   string  `yaml:"service"`
+	QuotaCode string  `yaml:"quota_code"`
+	Warning   float64 `yaml:"warning"`
+	Critical  float64 `yaml:"critical"`
+}
+
+// WebhooksConfig sends a notification to every endpoint when a quota's
+// status moves into warning or critical
+type WebhooksConfig struct {
+	Endpoints []WebhookEndpoint `yaml:"endpoints"`
+	// MaxAttempts is how often a delivery is tried, retries included
+	MaxAttempts int `yaml:"max_attempts"`
+}
+
+// WebhookEndpoint is a webhook URL; payloads are signed with HMAC-SHA256
+// when a secret is set
+type WebhookEndpoint struct {
+	URL    string `yaml:"url"`
+	Secret string `yaml:"secret"`
+}
+
+// AlertsConfig holds the usage threshold alert rules
+type AlertsConfig struct {
+	Rules []AlertRule `yaml:"rules"`
+	// ReminderLeadMinutes is how long before a snooze expires its reminder
+	// is sent
+	ReminderLeadMinutes int `yaml:"reminder_lead_minutes"`
+	// Templates replace the threshold notification message of a notifier
+	// (slack, teams, email, webhook) with a Go text/template over the alert
+	// fields
+	Templates map[string]string `yaml:"templates"`
+	// SelfMonitoring raises meta alerts about the scheduled scans themselves
+	SelfMonitoring SelfMonitoringConfig `yaml:"self_monitoring"`
+}
+
+// SelfMonitoringConfig configures the meta alerts about scheduled scans. A
+// scan that fails entirely always raises one.
+type SelfMonitoringConfig struct {
+	// ScanBudgetMinutes is how long a scan may run before it is reported;
+	// 0 disables the check
+	ScanBudgetMinutes int `yaml:"scan_budget_minutes"`
+	// CoverageDropPercent reports a scan returning this many percent fewer
+	// quotas, or quotas with usage, than the previous one; 0 disables the
+	// check
+	CoverageDropPercent float64 `yaml:"coverage_drop_percent"`
+}
+
+// AlertRule fires when a quota in its scope reaches the usage threshold (in
+// percent). Empty scope fields match every quota.
+type AlertRule struct {
+	Name      string  `yaml:"name" json:"name"`
+	Service   string  `yaml:"service" json:"service,omitempty"`
+	QuotaCode string  `yaml:"quota_code" json:"quota_code,omitempty"`
+	Region    string  `yaml:"region" json:"region,omitempty"`
+	Threshold float64 `yaml:"threshold" json:"threshold"`
+	Severity  string  `yaml:"severity" json:"severity,omitempty"`
+}
+
+// OwnershipConfig derives quota owners from a tag on the counted resources
+type OwnershipConfig struct {
+	// TagKey is the tag holding the owner; ownership sync is disabled when empty
+	TagKey string `yaml:"tag_key"`
+}
+
+// ReportHostingConfig publishes the HTML report to an S3 bucket configured for
+// static website hosting after each org scan
+type ReportHostingConfig struct {
+	Bucket string `yaml:"bucket"`
+	Key    string `yaml:"key"`
+	Region string `yaml:"region"`
+	Locale string `yaml:"locale"`
+}
+
+// SlackConfig configures the Slack integration. Interactive approvals
+// require a Slack app with interactivity pointed at /api/slack/interactions.
+type SlackConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+	// BotToken posts with chat.postMessage instead of the webhook, to Channel
+	// or the channel of a severity
+	BotToken      string `yaml:"bot_token"`
+	Channel       string `yaml:"channel"`
+	SigningSecret string `yaml:"signing_secret"`
+	// Approvers are the Slack user IDs allowed to approve increase proposals;
+	// required with SigningSecret
+	Approvers []string `yaml:"approvers"`
+	// DashboardURL is the externally visible URL of the dashboard that
+	// notifications link back to
+	DashboardURL string `yaml:"dashboard_url"`
+	// Thresholds configures notifications of quotas moving into warning or
+	// critical
+	Thresholds ThresholdRoutes `yaml:"thresholds"`
+}
+
+// TeamsConfig configures Microsoft Teams notifications through an incoming
+// webhook connector
+type TeamsConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+	// DashboardURL is the externally visible URL of the dashboard that
+	// notifications link back to
+	DashboardURL string          `yaml:"dashboard_url"`
+	Thresholds   ThresholdRoutes `yaml:"thresholds"`
+}
+
+// EmailConfig emails threshold notifications through Amazon SES. Each
+// recipient gets one email per fetch or scan listing the crossings it is
+// routed.
+type EmailConfig struct {
+	// From is an SES identity verified in Region (default_region when empty)
+	From   string `yaml:"from"`
+	Region string `yaml:"region"`
+	// DashboardURL is the externally visible URL of the dashboard that
+	// notifications link back to
+	DashboardURL string           `yaml:"dashboard_url"`
+	Recipients   []EmailRecipient `yaml:"recipients"`
+}
+
+// EmailRecipient routes crossings to an address. Empty lists match every
+// severity (warning, critical), service, region or account.
+type EmailRecipient struct {
+	Address    string   `yaml:"address"`
+	Severities []string `yaml:"severities"`
+	Services   []string `yaml:"services"`
+	Regions    []string `yaml:"regions"`
+	Accounts   []string `yaml:"accounts"`
+}
+
+// SNSConfig publishes quota events as JSON messages to an SNS topic, for
+// automation subscribing to it
+type SNSConfig struct {
+	TopicARN string `yaml:"topic_arn"`
+	// Events restricts the published event types (quota.threshold_crossed,
+	// quota.alert_resolved, quota.limit_anomaly, quota.increase_decided,
+	// quota.digest); all are
+	// published when empty
+	Events []string `yaml:"events"`
+}
+
+// DigestConfig sends a summary of the quota utilization, breaches and
+// approved increases of a period to the configured notifiers on a cron
+// schedule
+type DigestConfig struct {
+	Schedule string `yaml:"schedule"`
+	// Period is the span a digest covers: daily or weekly
+	Period string `yaml:"period"`
+	// Top is how many of the most utilized quotas a digest lists
+	Top int `yaml:"top"`
+	// ReplaceRealtime leaves threshold crossings to the digest instead of
+	// notifying them as they happen
+	ReplaceRealtime bool `yaml:"replace_realtime"`
+}
+
+// ThresholdRoutes routes the notifications of quotas moving into warning or
+// critical, per severity, for a chat notifier
+type ThresholdRoutes struct {
+	Warning  SeverityRoute `yaml:"warning"`
+	Critical SeverityRoute `yaml:"critical"`
+}
+
+// Enabled reports whether any severity is notified
+func (r ThresholdRoutes) Enabled() bool {
+	return r.Warning.Enabled || r.Critical.Enabled
+}
+
+// SeverityRoute configures the notifications of one severity. Channel is a
+// Slack channel (requires a bot token) or the webhook URL of another Teams
+// channel. Mention is prepended to the message (e.g. "<!here>" on Slack).
+type SeverityRoute struct {
+	Enabled bool   `yaml:"enabled"`
+	Channel string `yaml:"channel"`
+	Mention string `yaml:"mention"`
+}
+
+// CompositeQuota combines the usage of several quotas with an arithmetic
+// expression over quota codes (e.g. "L-FE5A380F + L-407747CB") and compares it
+// to a self-imposed limit
+type CompositeQuota struct {
+	Name       string  `yaml:"name"`
+	Code       string  `yaml:"code"`
+	Service    string  `yaml:"service"`
+	Expression string  `yaml:"expression"`
+	Limit      float64 `yaml:"limit"`
+}
+
+// ProxyConfig enables the read-only Service Quotas proxy endpoints for
+// internal tools holding one of the API tokens
+type ProxyConfig struct {
+	Enabled           bool     `yaml:"enabled"`
+	Tokens            []string `yaml:"tokens"`
+	RequestsPerMinute int      `yaml:"requests_per_minute"`
+}
+
+// ReviewConfig configures quota reviews. When Schedule is set a review is
+// opened automatically on that cron expression.
+type ReviewConfig struct {
+	Schedule  string   `yaml:"schedule"`
+	Threshold float64  `yaml:"threshold"`
+	Region    string   `yaml:"region"`
+	Service   string   `yaml:"service"`
+	Reviewers []string `yaml:"reviewers"`
+	// Org reviews the org scan inventory instead of the server's own account
+	Org bool `yaml:"org"`
+}
+
+// CostConfig enables the optional Cost Explorer integration
+type CostConfig struct {
+	Enabled bool `yaml:"enabled"`
 }
 
 // Default configuration
@@ -30,13 +471,84 @@ func Default() *Config {
 		DefaultRegion:  "us-east-1",
 		DefaultService: "ec2",
 		Server: ServerConfig{
-			Port: "8080",
+			Port:                  "8080",
+			RequestTimeoutSeconds: 120,
 		},
 		Cache: CacheConfig{
-			TTLMinutes: 5,
+			TTLMinutes:       5,
+			StaleMaxAgeHours: 24,
+		},
+		Thresholds: ThresholdsConfig{
+			Warning:  70,
+			Critical: 90,
+		},
+		Webhooks: WebhooksConfig{
+			MaxAttempts: 3,
 		},
 		MaxConcurrency: 10,
 		Regions:        []string{},
+		OrgScan: OrgScanConfig{
+			Schedule:    "0 */6 * * *",
+			RoleName:    "OrganizationAccountAccessRole",
+			ScanOnStart: true,
+		},
+		Demo: DemoConfig{
+			Accounts: 6,
+			Regions:  []string{"us-east-1", "eu-west-1", "ap-southeast-2"},
+			Schedule: "@every 5m",
+		},
+		Attribution: AttributionConfig{
+			LookbackHours:      168,
+			MinUsagePercentage: 80,
+		},
+		Alerts: AlertsConfig{
+			ReminderLeadMinutes: 60,
+			SelfMonitoring: SelfMonitoringConfig{
+				ScanBudgetMinutes:   120,
+				CoverageDropPercent: 20,
+			},
+		},
+		History: HistoryConfig{
+			RetireAfterHours:    24,
+			RawRetentionDays:    30,
+			RollupResolution:    "hourly",
+			RollupRetentionDays: 365,
+			CompactionSchedule:  "0 3 * * *",
+		},
+		Proxy: ProxyConfig{
+			RequestsPerMinute: 60,
+		},
+		PostgresSink: PostgresSinkConfig{
+			RetentionDays: 90,
+		},
+		Review: ReviewConfig{
+			Threshold: 60,
+		},
+		Digest: DigestConfig{
+			Period: "daily",
+			Top:    10,
+		},
+		ReportHosting: ReportHostingConfig{
+			Key:    "index.html",
+			Locale: "en",
+		},
+		Increase: IncreaseConfig{
+			WatchMinutes: 15,
+		},
+		SupportCases: SupportCasesConfig{
+			Severity:     "low",
+			LookbackDays: 365,
+		},
+		AccessLog: AccessLogConfig{
+			MaxEntries:   100000,
+			FlushSeconds: 30,
+		},
+		UsageHandlerTimeoutSeconds: 30,
+		ScanRate: ScanRateConfig{
+			Initial: 20,
+			Min:     1,
+			Max:     50,
+		},
 	}
 }
 
@@ -69,6 +581,73 @@ func (c *Config) GetCacheTTL() time.Duration {
 	return time.Duration(c.Cache.TTLMinutes) * time.Minute
 }
 
+// GetCacheStaleMaxAge returns how old persisted cache entries may be and
+// still be served after a restart
+func (c *Config) GetCacheStaleMaxAge() time.Duration {
+	return time.Duration(c.Cache.StaleMaxAgeHours) * time.Hour
+}
+
+// GetUsageHandlerTimeout returns the timeout of a single direct usage query
+func (c *Config) GetUsageHandlerTimeout() time.Duration {
+	return time.Duration(c.UsageHandlerTimeoutSeconds) * time.Second
+}
+
+// GetRetireAfter returns the grace period before a vanished quota is retired
+func (c *Config) GetRetireAfter() time.Duration {
+	return time.Duration(c.History.RetireAfterHours) * time.Hour
+}
+
+// GetRawRetention returns how long every history observation is kept
+func (c *Config) GetRawRetention() time.Duration {
+	return time.Duration(c.History.RawRetentionDays) * 24 * time.Hour
+}
+
+// GetRollupRetention returns how long compacted history is kept
+func (c *Config) GetRollupRetention() time.Duration {
+	return time.Duration(c.History.RollupRetentionDays) * 24 * time.Hour
+}
+
+// GetPostgresRetention returns how long mirrored snapshots are kept
+func (c *Config) GetPostgresRetention() time.Duration {
+	return time.Duration(c.PostgresSink.RetentionDays) * 24 * time.Hour
+}
+
+// GetReminderLead returns how long before a snooze expires its reminder is sent
+func (c *Config) GetReminderLead() time.Duration {
+	return time.Duration(c.Alerts.ReminderLeadMinutes) * time.Minute
+}
+
+// GetScanBudget returns how long a scheduled scan may run before it is
+// reported
+func (c *Config) GetScanBudget() time.Duration {
+	return time.Duration(c.Alerts.SelfMonitoring.ScanBudgetMinutes) * time.Minute
+}
+
+// GetRequestTimeout returns the maximum duration of a synchronous request
+func (c *Config) GetRequestTimeout() time.Duration {
+	return time.Duration(c.Server.RequestTimeoutSeconds) * time.Second
+}
+
+// GetAccessLogFlushInterval returns how often recorded requests are written
+// to the PostgreSQL sink
+func (c *Config) GetAccessLogFlushInterval() time.Duration {
+	return time.Duration(c.AccessLog.FlushSeconds) * time.Second
+}
+
+// GetIncreaseWatchInterval returns how often open increase requests are
+// polled for a decision
+func (c *Config) GetIncreaseWatchInterval() time.Duration {
+	return time.Duration(c.Increase.WatchMinutes) * time.Minute
+}
+
+// GetEmailRegion returns the SES region of email notifications
+func (c *Config) GetEmailRegion() string {
+	if c.Email.Region != "" {
+		return c.Email.Region
+	}
+	return c.DefaultRegion
+}
+
 // GetPort returns the server port, checking environment variable first
 func (c *Config) GetPort() string {
 	if port := os.Getenv("PORT"); port != "" {
@@ -76,3 +655,53 @@ func (c *Config) GetPort() string {
 	}
 	return c.Server.Port
 }
+
+// GetOrgScanRegions returns the regions walked by the org scan, falling back to
+// the configured region list and then the default region
+func (c *Config) GetOrgScanRegions() []string {
+	if c.Demo.Enabled && len(c.Demo.Regions) > 0 {
+		return c.Demo.Regions
+	}
+	if len(c.OrgScan.Regions) > 0 {
+		return c.OrgScan.Regions
+	}
+	if len(c.Regions) > 0 {
+		return c.Regions
+	}
+	return []string{c.DefaultRegion}
+}
+
+// Features lists the optional features enabled by the configuration
+func (c *Config) Features() []string {
+	features := []string{}
+	add := func(enabled bool, name string) {
+		if enabled {
+			features = append(features, name)
+		}
+	}
+	add(c.OrgScan.Enabled || c.Demo.Enabled, "org_scan")
+	add(c.Demo.Enabled, "demo")
+	add(c.Attribution.Enabled, "attribution")
+	add(c.Cost.Enabled, "cost")
+	add(len(c.Alerts.Rules) > 0, "alerts")
+	add(c.Ownership.TagKey != "", "ownership")
+	add(c.ReportHosting.Bucket != "", "report_hosting")
+	add(c.Slack.WebhookURL != "" || c.Slack.BotToken != "", "slack")
+	add(c.Teams.WebhookURL != "", "teams")
+	add(len(c.Email.Recipients) > 0, "email")
+	add(c.SNS.TopicARN != "", "sns")
+	add(c.Digest.Schedule != "", "digest")
+	add(len(c.Composites) > 0, "composite_quotas")
+	add(c.Proxy.Enabled, "proxy")
+	add(c.Review.Schedule != "", "scheduled_reviews")
+	add(c.EndpointURL != "" || len(c.Endpoints) > 0, "custom_endpoints")
+	add(c.PostgresSink.DSN != "", "postgres_sink")
+	add(c.OIDC.Enabled, "oidc")
+	add(c.CatalogDiff.Schedule != "", "catalog_diff")
+	add(c.AccessLog.Enabled, "access_log")
+	add(c.SupportCases.Enabled, "support_cases")
+	add(c.History.SQLitePath != "", "sqlite_history")
+	add(c.Cache.Path != "", "persistent_cache")
+	add(len(c.Webhooks.Endpoints) > 0, "webhooks")
+	return features
+}
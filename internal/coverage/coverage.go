@@ -0,0 +1,146 @@
+// Package coverage collects requests for usage handlers of quotas the
+// dashboard cannot count yet, as a demand signal for which handlers to add
+// next.
+package coverage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/persist"
+)
+
+// maxNotes bounds the notes kept per quota
+const maxNotes = 20
+
+// Requests keeps the coverage requests, aggregated per quota
+type Requests struct {
+	mu       sync.RWMutex
+	requests map[string]*model.CoverageRequest
+	doc      *persist.Doc
+}
+
+func NewRequests() *Requests {
+	return &Requests{
+		requests: make(map[string]*model.CoverageRequest),
+	}
+}
+
+func key(serviceCode, quotaCode string) string {
+	return serviceCode + "/" + quotaCode
+}
+
+// Persist loads the requests saved to a document and saves every later change
+// to it
+func (r *Requests) Persist(ctx context.Context, doc *persist.Doc) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := doc.Load(ctx, &r.requests); err != nil {
+		return err
+	}
+	r.doc = doc
+	return nil
+}
+
+// Add records a request for a quota. A requester is counted once per quota;
+// anonymous requests always count.
+func (r *Requests) Add(serviceCode, quotaCode, quotaName, requester, note string, now time.Time) model.CoverageRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, ok := r.requests[key(serviceCode, quotaCode)]
+	if !ok {
+		req = &model.CoverageRequest{
+			ServiceCode:      serviceCode,
+			QuotaCode:        quotaCode,
+			FirstRequestedAt: now,
+		}
+		r.requests[key(serviceCode, quotaCode)] = req
+	}
+	if quotaName != "" {
+		req.QuotaName = quotaName
+	}
+
+	counted := requester == ""
+	if !counted && !contains(req.Requesters, requester) {
+		req.Requesters = append(req.Requesters, requester)
+		counted = true
+	}
+	if counted {
+		req.Count++
+		req.LastRequestedAt = now
+		if note != "" && len(req.Notes) < maxNotes {
+			req.Notes = append(req.Notes, note)
+		}
+	}
+	r.doc.Save(r.requests)
+	return copyRequest(req)
+}
+
+// Count returns how often a handler was requested for a quota
+func (r *Requests) Count(serviceCode, quotaCode string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if req, ok := r.requests[key(serviceCode, quotaCode)]; ok {
+		return req.Count
+	}
+	return 0
+}
+
+// List returns the requests matching a service and a case-insensitive search
+// over service code, quota code and quota name, most requested first
+func (r *Requests) List(serviceCode, search string) []model.CoverageRequest {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]model.CoverageRequest, 0, len(r.requests))
+	for _, req := range r.requests {
+		if serviceCode != "" && !strings.EqualFold(req.ServiceCode, serviceCode) {
+			continue
+		}
+		if !Matches(search, req.ServiceCode, req.QuotaCode, req.QuotaName) {
+			continue
+		}
+		list = append(list, copyRequest(req))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return key(list[i].ServiceCode, list[i].QuotaCode) < key(list[j].ServiceCode, list[j].QuotaCode)
+	})
+	return list
+}
+
+// Matches reports whether any of the fields contains the search term,
+// ignoring case. An empty search matches everything.
+func Matches(search string, fields ...string) bool {
+	if search == "" {
+		return true
+	}
+	search = strings.ToLower(search)
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), search) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func copyRequest(req *model.CoverageRequest) model.CoverageRequest {
+	c := *req
+	c.Requesters = append([]string(nil), req.Requesters...)
+	c.Notes = append([]string(nil), req.Notes...)
+	return c
+}
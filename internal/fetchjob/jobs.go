@@ -0,0 +1,167 @@
+// Package fetchjob tracks quota fetches running in the background and keeps
+// their progress log so it can be followed while the fetch runs.
+package fetchjob
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/randid"
+)
+
+const (
+	// maxJobs bounds how many jobs are kept; the oldest finished jobs are
+	// dropped first
+	maxJobs = 100
+	// maxLines bounds the log kept per job
+	maxLines = 5000
+)
+
+type job struct {
+	info    model.FetchJob
+	lines   []model.FetchLogLine
+	dropped int
+	// changed is closed and replaced whenever a line is added or the job
+	// finishes, waking up followers
+	changed chan struct{}
+}
+
+// Jobs keeps the fetch jobs and their log lines
+type Jobs struct {
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+func NewJobs() *Jobs {
+	return &Jobs{
+		jobs: make(map[string]*job),
+	}
+}
+
+// Create registers a running job for a region/service scope
+func (j *Jobs) Create(region, service string) model.FetchJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.evict()
+	info := model.FetchJob{
+		ID:        randid.New(),
+		Region:    region,
+		Service:   service,
+		Status:    model.FetchJobRunning,
+		StartedAt: time.Now(),
+	}
+	j.jobs[info.ID] = &job{info: info, changed: make(chan struct{})}
+	return info
+}
+
+// Append adds a log line to a running job
+func (j *Jobs) Append(id string, line model.FetchLogLine) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	jb, ok := j.jobs[id]
+	if !ok || jb.info.Status != model.FetchJobRunning {
+		return
+	}
+	if len(jb.lines) >= maxLines {
+		jb.dropped++
+		return
+	}
+	jb.lines = append(jb.lines, line)
+	jb.notify()
+}
+
+// Finish records the outcome of a job and appends a final log line
+func (j *Jobs) Finish(id string, quotaCount int, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	jb, ok := j.jobs[id]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	line := model.FetchLogLine{Time: now, Level: model.LogLevelInfo}
+	if err != nil {
+		jb.info.Status = model.FetchJobFailed
+		jb.info.Error = err.Error()
+		line.Level = model.LogLevelError
+		line.Message = fmt.Sprintf("Fetch failed: %v", err)
+	} else {
+		jb.info.Status = model.FetchJobSucceeded
+		jb.info.QuotaCount = quotaCount
+		line.Message = fmt.Sprintf("Fetch completed: %d quotas", quotaCount)
+	}
+	if jb.dropped > 0 {
+		line.Message += fmt.Sprintf(" (%d log lines dropped)", jb.dropped)
+	}
+	jb.info.CompletedAt = &now
+	jb.lines = append(jb.lines, line)
+	jb.notify()
+}
+
+// Get returns a job by ID
+func (j *Jobs) Get(id string) (model.FetchJob, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	jb, ok := j.jobs[id]
+	if !ok {
+		return model.FetchJob{}, false
+	}
+	return jb.info, true
+}
+
+// Lines returns the log lines of a job from index from onward, whether the
+// job has finished, and a channel closed when more lines are available. ok is
+// false when the job does not exist.
+func (j *Jobs) Lines(id string, from int) (lines []model.FetchLogLine, done bool, changed <-chan struct{}, ok bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	jb, ok := j.jobs[id]
+	if !ok {
+		return nil, false, nil, false
+	}
+	if from < len(jb.lines) {
+		lines = append(lines, jb.lines[from:]...)
+	}
+	return lines, jb.info.Status != model.FetchJobRunning, jb.changed, true
+}
+
+// List returns the jobs, newest first
+func (j *Jobs) List() []model.FetchJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	list := make([]model.FetchJob, 0, len(j.jobs))
+	for _, jb := range j.jobs {
+		list = append(list, jb.info)
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].StartedAt.After(list[b].StartedAt) })
+	return list
+}
+
+func (jb *job) notify() {
+	close(jb.changed)
+	jb.changed = make(chan struct{})
+}
+
+// evict drops the oldest finished jobs once the limit is reached. Running
+// jobs are never dropped.
+func (j *Jobs) evict() {
+	if len(j.jobs) < maxJobs {
+		return
+	}
+	finished := make([]*job, 0, len(j.jobs))
+	for _, jb := range j.jobs {
+		if jb.info.Status != model.FetchJobRunning {
+			finished = append(finished, jb)
+		}
+	}
+	sort.Slice(finished, func(a, b int) bool { return finished[a].info.StartedAt.Before(finished[b].info.StartedAt) })
+	for _, jb := range finished {
+		if len(j.jobs) < maxJobs {
+			return
+		}
+		delete(j.jobs, jb.info.ID)
+	}
+}
@@ -0,0 +1,62 @@
+package fetchjob
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/persist"
+	"github.com/yuxishi/aws-quota-dashboard/internal/randid"
+)
+
+// maxRuns bounds how many completed runs are kept; the oldest are dropped
+// first
+const maxRuns = 1000
+
+// Runs keeps the history of completed fetches and org scans
+type Runs struct {
+	mu   sync.Mutex
+	runs []model.Run
+	doc  *persist.Doc
+}
+
+func NewRuns() *Runs {
+	return &Runs{}
+}
+
+// Persist loads the runs saved to a document and saves every later run to it
+func (r *Runs) Persist(ctx context.Context, doc *persist.Doc) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := doc.Load(ctx, &r.runs); err != nil {
+		return err
+	}
+	r.doc = doc
+	return nil
+}
+
+// Record adds a completed run, assigning its ID and duration
+func (r *Runs) Record(run model.Run) model.Run {
+	run.ID = randid.New()
+	run.DurationMS = float64(run.CompletedAt.Sub(run.StartedAt).Microseconds()) / 1000
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, run)
+	if over := len(r.runs) - maxRuns; over > 0 {
+		r.runs = append([]model.Run(nil), r.runs[over:]...)
+	}
+	r.doc.Save(r.runs)
+	return run
+}
+
+// List returns the runs, most recently completed first
+func (r *Runs) List() []model.Run {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]model.Run, len(r.runs))
+	for i, run := range r.runs {
+		list[len(r.runs)-1-i] = run
+	}
+	return list
+}
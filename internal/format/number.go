@@ -0,0 +1,147 @@
+// Package format renders quota values for human-readable exports
+package format
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// separators holds the grouping and decimal separators of a locale
+type separators struct {
+	group   string
+	decimal string
+}
+
+var locales = map[string]separators{
+	"en": {",", "."},
+	"de": {".", ","},
+	"es": {".", ","},
+	"it": {".", ","},
+	"nl": {".", ","},
+	"pt": {".", ","},
+	"fr": {" ", ","},
+	"ch": {"'", "."},
+	"ja": {",", "."},
+	"zh": {",", "."},
+}
+
+// Options controls how numbers and units are rendered. The zero value renders
+// plain machine-readable numbers with the unit as reported by AWS.
+type Options struct {
+	// Locale selects the separators ("en", "de", ...); empty disables grouping
+	Locale string
+	// Compact abbreviates large numbers (1.2k, 3.4M)
+	Compact bool
+	// ScaleUnits converts byte-based values to the largest readable binary
+	// unit, e.g. 2048 Gigabytes to 2 TiB
+	ScaleUnits bool
+}
+
+// ParseLocale validates a locale name, accepting region-qualified tags such as
+// "de-DE" or "pt_BR"
+func ParseLocale(locale string) (string, error) {
+	if locale == "" {
+		return "", nil
+	}
+	base, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	base = strings.ToLower(base)
+	if _, ok := locales[base]; !ok {
+		return "", fmt.Errorf("unsupported locale %q", locale)
+	}
+	return base, nil
+}
+
+// Quantity renders a value and its unit
+func (o Options) Quantity(value float64, unit string) (string, string) {
+	if o.ScaleUnits {
+		value, unit = scaleBytes(value, unit)
+	}
+	return o.Number(value), unit
+}
+
+// Number renders a value according to the options
+func (o Options) Number(value float64) string {
+	sep, ok := locales[o.Locale]
+	if !ok {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	suffix := ""
+	if o.Compact {
+		value, suffix = compact(value)
+	}
+
+	decimals := 2
+	if value == math.Trunc(value) {
+		decimals = 0
+	} else if suffix != "" {
+		decimals = 1
+	}
+	s := strconv.FormatFloat(value, 'f', decimals, 64)
+	if decimals > 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	negative := strings.HasPrefix(intPart, "-")
+	intPart = strings.TrimPrefix(intPart, "-")
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(sep.group)
+		}
+		b.WriteRune(digit)
+	}
+	if fracPart != "" {
+		b.WriteString(sep.decimal)
+		b.WriteString(fracPart)
+	}
+	b.WriteString(suffix)
+	return b.String()
+}
+
+func compact(value float64) (float64, string) {
+	abs := math.Abs(value)
+	switch {
+	case abs >= 1e9:
+		return value / 1e9, "B"
+	case abs >= 1e6:
+		return value / 1e6, "M"
+	case abs >= 1e3:
+		return value / 1e3, "k"
+	}
+	return value, ""
+}
+
+// byteUnits maps the byte-based units reported by Service Quotas to their size in bytes
+var byteUnits = map[string]float64{
+	"Bytes":     1,
+	"Kilobytes": 1 << 10,
+	"Megabytes": 1 << 20,
+	"Gigabytes": 1 << 30,
+	"Terabytes": 1 << 40,
+}
+
+var binaryUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+
+// scaleBytes converts a byte-based value to the largest binary unit that keeps
+// it at or above 1. Other units are returned unchanged.
+func scaleBytes(value float64, unit string) (float64, string) {
+	size, ok := byteUnits[unit]
+	if !ok {
+		return value, unit
+	}
+	bytes := value * size
+	i := 0
+	for i < len(binaryUnits)-1 && math.Abs(bytes) >= 1024 {
+		bytes /= 1024
+		i++
+	}
+	return bytes, binaryUnits[i]
+}
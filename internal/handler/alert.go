@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/alert"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+type alertTestBody struct {
+	Rules     []config.AlertRule `json:"rules"`
+	Templates map[string]string  `json:"templates"`
+}
+
+// renderedAlert is an alert with its message rendered for every notifier
+type renderedAlert struct {
+	alert.Alert
+	Messages map[string]string `json:"messages"`
+}
+
+// SetAlertRules sets the configured alert rules
+func (h *Handler) SetAlertRules(rules []config.AlertRule) {
+	h.alertRules = rules
+}
+
+// SetAlertTemplates replaces the built-in alert message templates
+func (h *Handler) SetAlertTemplates(templates *alert.Templates) {
+	h.messages = templates
+}
+
+// alertTemplates returns the message templates of a dry run: the templates in
+// the request body merged over the built-in ones, or the configured ones
+func (h *Handler) alertTemplates(custom map[string]string) (*alert.Templates, error) {
+	if len(custom) == 0 && h.messages != nil {
+		return h.messages, nil
+	}
+	return alert.NewTemplates(custom)
+}
+
+// TestAlertRules evaluates alert rules against the current snapshot without
+// sending notifications and returns the alerts that would fire, with the
+// message each notifier would send. Rules and templates in the request body
+// replace the configured ones, so changes can be validated before they are
+// deployed.
+func (h *Handler) TestAlertRules(c *gin.Context) {
+	var body alertTestBody
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rules := h.alertRules
+	if len(body.Rules) > 0 {
+		rules = body.Rules
+	}
+	if err := alert.Validate(rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	templates, err := h.alertTemplates(body.Templates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := h.loadQuotas(c.Request.Context(), c.Query("region"), c.Query("service"))
+	if err != nil {
+		loadFailed(c, err)
+		return
+	}
+
+	// Retired quotas keep their last value in history but must not fire
+	quotas, err := store.Active(c.Request.Context(), h.store, set.quotas)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	alerts, snoozed := h.snoozes.Filter(alert.Evaluate(rules, quotas), time.Now())
+	alerts, silenced, err := h.withoutSilencedAlerts(c.Request.Context(), alerts, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	snoozed += silenced
+	rendered := make([]renderedAlert, 0, len(alerts))
+	for _, a := range alerts {
+		messages, err := templates.RenderAll(a)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rendered = append(rendered, renderedAlert{Alert: a, Messages: messages})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":    true,
+		"rules":      len(rules),
+		"alerts":     rendered,
+		"total":      len(rendered),
+		"snoozed":    snoozed,
+		"from_cache": set.fromCache,
+		"warnings":   set.warnings,
+	})
+}
+
+// withoutSilencedAlerts removes the alerts of quotas whose threshold alert is
+// acknowledged or snoozed and returns the remaining alerts and the number
+// removed
+func (h *Handler) withoutSilencedAlerts(ctx context.Context, alerts []alert.Alert, now time.Time) ([]alert.Alert, int, error) {
+	keys := make([]store.QuotaKey, 0, len(alerts))
+	for _, a := range alerts {
+		keys = append(keys, alertKey(a))
+	}
+	states, err := h.store.AlertStates(ctx, keys)
+	if err != nil {
+		return nil, 0, err
+	}
+	kept := make([]alert.Alert, 0, len(alerts))
+	for _, a := range alerts {
+		state := states[alertKey(a)]
+		if state.AcknowledgedAt != nil || state.SnoozedUntil != nil && state.SnoozedUntil.After(now) {
+			continue
+		}
+		kept = append(kept, a)
+	}
+	return kept, len(alerts) - len(kept), nil
+}
+
+// alertKey returns the series key of the quota of a rule alert
+func alertKey(a alert.Alert) store.QuotaKey {
+	return store.QuotaKey{AccountID: a.AccountID, Region: a.Region, ServiceCode: a.ServiceCode, QuotaCode: a.QuotaCode}
+}
+
+type snoozeBody struct {
+	alert.Snooze
+	// Duration is a Go duration such as "72h", used when Until is not set
+	Duration string `json:"duration"`
+}
+
+// SetSnoozeReminderLead sets how long before a snooze expires its reminder
+// is sent
+func (h *Handler) SetSnoozeReminderLead(lead time.Duration) {
+	h.snoozeLead = lead
+}
+
+// SnoozeAlert silences the alerts of a quota until the snooze expires. The
+// account defaults to the account of the server's credentials.
+func (h *Handler) SnoozeAlert(c *gin.Context) {
+	var body snoozeBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	if body.Until.IsZero() {
+		d, err := time.ParseDuration(body.Duration)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "until or a valid duration is required"})
+			return
+		}
+		body.Until = now.Add(d)
+	}
+	if body.AccountID == "" {
+		body.AccountID = h.accountID(c.Request.Context())
+	}
+
+	snooze, err := h.snoozes.Add(body.Snooze, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, snooze)
+}
+
+// GetSnoozes lists the active snoozes, soonest to expire first
+func (h *Handler) GetSnoozes(c *gin.Context) {
+	snoozes := h.snoozes.List(time.Now())
+	c.JSON(http.StatusOK, gin.H{
+		"snoozes": snoozes,
+		"total":   len(snoozes),
+	})
+}
+
+// DeleteSnooze ends a snooze early
+func (h *Handler) DeleteSnooze(c *gin.Context) {
+	if err := h.snoozes.Delete(c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SendSnoozeReminders notifies about snoozes about to expire with the current
+// usage of their quota, so acknowledged risks are looked at again before the
+// alerts resume. Each snooze is reminded about once; a failed notification is
+// retried on the next run.
+func (h *Handler) SendSnoozeReminders(ctx context.Context) {
+	lead := h.snoozeLead
+	if lead <= 0 {
+		lead = config.Default().GetReminderLead()
+	}
+
+	now := time.Now()
+	for _, snooze := range h.snoozes.Due(now, lead) {
+		q, found := h.currentQuota(store.QuotaKey{
+			AccountID:   snooze.AccountID,
+			Region:      snooze.Region,
+			ServiceCode: snooze.ServiceCode,
+			QuotaCode:   snooze.QuotaCode,
+		})
+		msg := snoozeReminderMessage(snooze, q, found, h.firingRules(q, found))
+		if h.slack == nil {
+			log.Printf("Snooze reminder: %s", msg.Text)
+		} else if err := h.slack.Post(ctx, msg); err != nil {
+			log.Printf("Failed to send reminder of snooze %s: %v", snooze.ID, err)
+			continue
+		}
+		h.snoozes.MarkReminded(snooze.ID, now)
+	}
+}
+
+// currentQuota returns the latest fetched or scanned value of a quota
+func (h *Handler) currentQuota(key store.QuotaKey) (model.Quota, bool) {
+	h.quotasMu.RLock()
+	q, ok := h.latest[key]
+	h.quotasMu.RUnlock()
+	if ok {
+		return q, true
+	}
+
+	if h.orgScanner != nil {
+		if inv := h.orgScanner.Inventory(); inv != nil {
+			for _, q := range inv.Quotas {
+				if store.KeyOf(q) == key {
+					return q, true
+				}
+			}
+		}
+	}
+	return model.Quota{}, false
+}
+
+// firingRules returns the names of the configured rules the quota fires,
+// ignoring snoozes
+func (h *Handler) firingRules(q model.Quota, found bool) []string {
+	if !found {
+		return nil
+	}
+	var names []string
+	for _, a := range alert.Evaluate(h.alertRules, h.notes.Apply([]model.Quota{q})) {
+		names = append(names, a.Rule)
+	}
+	return names
+}
+
+// snoozeReminderMessage renders the expiry reminder of a snooze
+func snoozeReminderMessage(s alert.Snooze, q model.Quota, found bool, firing []string) notify.Message {
+	name := s.QuotaCode
+	if found && q.QuotaName != "" {
+		name = fmt.Sprintf("%s (%s)", q.QuotaName, s.QuotaCode)
+	}
+	summary := fmt.Sprintf("Snooze of %s in %s expires %s", name, s.Region, s.Until.Format(time.RFC3339))
+	if s.AccountID != "" {
+		summary = fmt.Sprintf("Snooze of %s in %s/%s expires %s", name, s.AccountID, s.Region, s.Until.Format(time.RFC3339))
+	}
+
+	usage := "No recent usage data"
+	if found && q.HasUsageMetrics {
+		usage = fmt.Sprintf("%g of %g (%.1f%%)", q.Usage, q.Value, q.UsagePercentage)
+	}
+	status := "Below all alert thresholds"
+	if len(firing) > 0 {
+		status = "Still firing: " + strings.Join(firing, ", ")
+	}
+
+	fields := []notify.Text{
+		{Type: "mrkdwn", Text: "*Service*\n" + s.ServiceCode},
+		{Type: "mrkdwn", Text: "*Current usage*\n" + usage},
+		{Type: "mrkdwn", Text: "*Alerts*\n" + status},
+	}
+	if s.SnoozedBy != "" {
+		fields = append(fields, notify.Text{Type: "mrkdwn", Text: "*Snoozed by*\n" + s.SnoozedBy})
+	}
+
+	blocks := []notify.Block{
+		{Type: "section", Text: notify.Markdown("*" + summary + "*")},
+		{Type: "section", Fields: fields},
+	}
+	if s.Reason != "" {
+		blocks = append(blocks, notify.Block{Type: "section", Text: notify.Markdown(">" + s.Reason)})
+	}
+	return notify.Message{Text: summary + ": " + usage, Blocks: blocks}
+}
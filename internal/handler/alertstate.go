@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/threshold"
+)
+
+// errAlertNotFound is returned for unknown alert IDs
+var errAlertNotFound = errors.New("alert not found")
+
+// errAlertResolved is returned when silencing a resolved alert
+var errAlertResolved = errors.New("alert is resolved")
+
+// quotaAlert is the alert state of a quota
+type quotaAlert struct {
+	store.QuotaKey
+	store.AlertState
+	QuotaName string `json:"quota_name,omitempty"`
+}
+
+// alertActionBody is the optional body of an acknowledgement or snooze
+type alertActionBody struct {
+	By   string `json:"by"`
+	Note string `json:"note"`
+}
+
+// GetAlerts returns the quota alerts, oldest first. Filter on state with a
+// comma-separated list; the default lists the active alerts (warning,
+// critical).
+func (h *Handler) GetAlerts(c *gin.Context) {
+	wanted := map[string]bool{threshold.StatusWarning: true, threshold.StatusCritical: true}
+	if param := c.Query("state"); param != "" {
+		wanted = make(map[string]bool)
+		for _, state := range strings.Split(param, ",") {
+			wanted[strings.TrimSpace(state)] = true
+		}
+	}
+
+	states, err := h.store.AlertStates(c.Request.Context(), nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	alerts := make([]quotaAlert, 0)
+	for key, state := range states {
+		if !wanted[state.State] {
+			continue
+		}
+		a := quotaAlert{QuotaKey: key, AlertState: state}
+		if q, ok := h.currentQuota(key); ok {
+			a.QuotaName = q.QuotaName
+		}
+		alerts = append(alerts, a)
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].Since.Before(alerts[j].Since) })
+
+	c.JSON(http.StatusOK, gin.H{
+		"alerts": alerts,
+		"total":  len(alerts),
+	})
+}
+
+// AcknowledgeAlert silences the notifications of an active alert until it is
+// resolved or escalates to critical
+func (h *Handler) AcknowledgeAlert(c *gin.Context) {
+	body, ok := bindAlertAction(c)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	h.updateAlert(c, func(state *store.AlertState) {
+		state.AcknowledgedAt, state.AcknowledgedBy = &now, body.By
+		if body.Note != "" {
+			state.Note = body.Note
+		}
+	})
+}
+
+// SnoozeActiveAlert silences the notifications of an active alert until the
+// until parameter, an RFC 3339 timestamp or a duration from now such as
+// "72h". An alert still active when the snooze ends is notified again.
+func (h *Handler) SnoozeActiveAlert(c *gin.Context) {
+	now := time.Now()
+	until, err := parseUntilParam(c.Query("until"), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until: " + err.Error()})
+		return
+	}
+	if !until.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "snooze must end in the future"})
+		return
+	}
+	body, ok := bindAlertAction(c)
+	if !ok {
+		return
+	}
+	h.updateAlert(c, func(state *store.AlertState) {
+		state.SnoozedUntil, state.SnoozedBy = &until, body.By
+		if body.Note != "" {
+			state.Note = body.Note
+		}
+	})
+}
+
+// bindAlertAction reads the optional body of an alert action
+func bindAlertAction(c *gin.Context) (alertActionBody, bool) {
+	var body alertActionBody
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return body, false
+	}
+	return body, true
+}
+
+// updateAlert applies update to the active alert of the id path parameter and
+// responds with the updated alert
+func (h *Handler) updateAlert(c *gin.Context, update func(state *store.AlertState)) {
+	a, err := h.modifyAlert(c.Request.Context(), c.Param("id"), update)
+	switch {
+	case errors.Is(err, errAlertNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errAlertResolved):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, a)
+	}
+}
+
+// modifyAlert finds an alert by ID and stores its updated state. It holds the
+// alert lock, so a concurrent fetch does not overwrite the update.
+func (h *Handler) modifyAlert(ctx context.Context, id string, update func(state *store.AlertState)) (quotaAlert, error) {
+	h.alertMu.Lock()
+	defer h.alertMu.Unlock()
+	states, err := h.store.AlertStates(ctx, nil)
+	if err != nil {
+		return quotaAlert{}, err
+	}
+	for key, state := range states {
+		if id == "" || state.ID != id {
+			continue
+		}
+		if !alerting(state.State) {
+			return quotaAlert{}, errAlertResolved
+		}
+		update(&state)
+		if err := h.store.SetAlertStates(ctx, map[store.QuotaKey]store.AlertState{key: state}); err != nil {
+			return quotaAlert{}, err
+		}
+		a := quotaAlert{QuotaKey: key, AlertState: state}
+		if q, ok := h.currentQuota(key); ok {
+			a.QuotaName = q.QuotaName
+		}
+		return a, nil
+	}
+	return quotaAlert{}, errAlertNotFound
+}
+
+// parseUntilParam parses an RFC 3339 timestamp or a duration from now
+func parseUntilParam(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 timestamp or duration, got %q", value)
+	}
+	return now.Add(d), nil
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/accesslog"
+)
+
+// defaultAnalyticsWindow is used when no since parameter is given
+const defaultAnalyticsWindow = 7 * 24 * time.Hour
+
+// SetAccessLog enables recording of API requests
+func (h *Handler) SetAccessLog(accesses *accesslog.Log) {
+	h.accesses = accesses
+}
+
+// LogAccess records the request once it has been handled, including
+// requests rejected by authentication. The caller is the OIDC identity set
+// further down the chain, if any.
+func (h *Handler) LogAccess(c *gin.Context) {
+	if h.accesses == nil {
+		c.Next()
+		return
+	}
+	start := time.Now()
+	c.Next()
+
+	entry := accesslog.Entry{
+		Time:       start,
+		Method:     c.Request.Method,
+		Route:      c.FullPath(),
+		Status:     c.Writer.Status(),
+		ClientIP:   c.ClientIP(),
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		Bytes:      max(c.Writer.Size(), 0),
+	}
+	if identity, ok := identityOf(c); ok {
+		entry.User = identity.Email
+		if entry.User == "" {
+			entry.User = identity.Subject
+		}
+	}
+	h.accesses.Record(entry)
+}
+
+// GetUsageAnalytics summarizes which endpoints were used and by whom since
+// the since parameter (default 7 days), busiest first
+func (h *Handler) GetUsageAnalytics(c *gin.Context) {
+	if h.accesses == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "access logging is not enabled"})
+		return
+	}
+	since, until, ok := analyticsWindow(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, accesslog.Summarize(h.accesses.Entries(since, until), since, until))
+}
+
+// ExportAccessLog returns the recorded requests between since (default 7
+// days) and until as JSON or, with format=csv, as CSV
+func (h *Handler) ExportAccessLog(c *gin.Context) {
+	if h.accesses == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "access logging is not enabled"})
+		return
+	}
+	since, until, ok := analyticsWindow(c)
+	if !ok {
+		return
+	}
+	entries := h.accesses.Entries(since, until)
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, gin.H{"since": since, "until": until, "entries": entries, "total": len(entries)})
+	case "csv":
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.Write([]string{"Time", "Method", "Route", "Status", "User", "Client IP", "Duration ms", "Bytes"}); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, e := range entries {
+			if err := w.Write([]string{
+				e.Time.UTC().Format(time.RFC3339Nano), e.Method, e.Route, strconv.Itoa(e.Status), e.User, e.ClientIP,
+				strconv.FormatFloat(e.DurationMS, 'f', 3, 64), strconv.Itoa(e.Bytes),
+			}); err != nil {
+				c.String(http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		filename := fmt.Sprintf("access-log-%s.csv", time.Now().Format("2006-01-02"))
+		c.Header("Content-Disposition", "attachment; filename="+filename)
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+	}
+}
+
+// analyticsWindow parses the since and until parameters, writing a 400 when
+// either is invalid
+func analyticsWindow(c *gin.Context) (since, until time.Time, ok bool) {
+	now := time.Now()
+	since, err := parseTimeParam(c.Query("since"), now.Add(-defaultAnalyticsWindow), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since: " + err.Error()})
+		return since, until, false
+	}
+	until, err = parseTimeParam(c.Query("until"), now, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until: " + err.Error()})
+		return since, until, false
+	}
+	return since, until, true
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/annotation"
+)
+
+// maxAnnotationUpload bounds the size of imported annotation sheets
+const maxAnnotationUpload = 8 << 20
+
+// GetAnnotations lists the quota annotations
+func (h *Handler) GetAnnotations(c *gin.Context) {
+	annotations := h.notes.List()
+	c.JSON(http.StatusOK, gin.H{
+		"annotations": annotations,
+		"total":       len(annotations),
+	})
+}
+
+// SetAnnotation creates or replaces the annotation of a quota
+func (h *Handler) SetAnnotation(c *gin.Context) {
+	var body annotation.Annotation
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	a, err := h.notes.Set(body, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// DeleteAnnotation removes the annotation identified by the account, region,
+// service and quota_code parameters
+func (h *Handler) DeleteAnnotation(c *gin.Context) {
+	err := h.notes.Delete(annotation.Key{
+		AccountID:   c.Query("account"),
+		Region:      c.Query("region"),
+		ServiceCode: c.Query("service"),
+		QuotaCode:   c.Query("quota_code"),
+	})
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ExportAnnotations downloads every annotation as a CSV sheet (format=csv,
+// the default) or JSON (format=json), in the layout ImportAnnotations reads
+func (h *Handler) ExportAnnotations(c *gin.Context) {
+	annotations := h.notes.List()
+	date := time.Now().Format("2006-01-02")
+
+	switch c.DefaultQuery("format", "csv") {
+	case "csv":
+		var buf bytes.Buffer
+		if err := annotation.WriteCSV(&buf, annotations); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=quota-annotations-%s.csv", date))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	case "json":
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=quota-annotations-%s.json", date))
+		c.JSON(http.StatusOK, annotations)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or json"})
+	}
+}
+
+// ImportAnnotations loads annotations in bulk from a CSV sheet or a JSON
+// array, sent as the request body or as the "file" field of a multipart form.
+// The format is taken from the format parameter, the file name or the content
+// type. Nothing is imported if any row is invalid; replace=true deletes the
+// annotations missing from the import.
+func (h *Handler) ImportAnnotations(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAnnotationUpload)
+
+	var body io.Reader = c.Request.Body
+	isCSV := strings.HasPrefix(c.ContentType(), "text/csv")
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing annotation file: " + err.Error()})
+			return
+		}
+		defer file.Close()
+		body = file
+		isCSV = strings.HasSuffix(strings.ToLower(header.Filename), ".csv")
+	}
+	if format, ok := c.GetQuery("format"); ok {
+		isCSV = format == "csv"
+	}
+
+	var annotations []annotation.Annotation
+	var err error
+	if isCSV {
+		annotations, err = annotation.ReadCSV(body)
+	} else {
+		err = json.NewDecoder(body).Decode(&annotations)
+		if errors.Is(err, io.EOF) {
+			err = fmt.Errorf("empty annotation import")
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	imported, err := h.notes.Import(annotations, c.Query("replace") == "true", time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"imported": imported,
+		"total":    len(h.notes.List()),
+	})
+}
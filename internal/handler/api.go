@@ -1,59 +1,209 @@
 package handler
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"net"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/accesslog"
+	"github.com/yuxishi/aws-quota-dashboard/internal/alert"
+	"github.com/yuxishi/aws-quota-dashboard/internal/annotation"
 	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
 	"github.com/yuxishi/aws-quota-dashboard/internal/cache"
+	"github.com/yuxishi/aws-quota-dashboard/internal/composite"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/coverage"
+	"github.com/yuxishi/aws-quota-dashboard/internal/fetchjob"
+	"github.com/yuxishi/aws-quota-dashboard/internal/increase"
 	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+	"github.com/yuxishi/aws-quota-dashboard/internal/org"
+	"github.com/yuxishi/aws-quota-dashboard/internal/persist"
+	"github.com/yuxishi/aws-quota-dashboard/internal/review"
+	"github.com/yuxishi/aws-quota-dashboard/internal/sink"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/threshold"
 )
 
 type Handler struct {
-	fetcher *aws.QuotaFetcher
-	cache   *cache.Cache
-	config  interface{} // Store config for API access
+	fetcher    *aws.QuotaFetcher
+	cache      *cache.Cache
+	config     interface{} // Store config for API access
+	orgScanner *org.Scanner
+	templates  *increase.Templates
+	increases  *increase.Tracker
+	incRegions []string
+
+	attribution *config.AttributionConfig
+	supportCfg  *config.SupportCasesConfig
+	costEnabled bool
+	alertRules  []config.AlertRule
+	snoozes     *alert.Snoozes
+	snoozeLead  time.Duration
+	retireAfter time.Duration
+	proposals   *increase.Proposals
+	slack       *notify.Slack
+	slackCfg    config.SlackConfig
+	teams       *notify.Teams
+	teamsCfg    config.TeamsConfig
+	email       config.EmailConfig
+	emailRegion string
+	sns         config.SNSConfig
+	digest      config.DigestConfig
+	composites  []composite.Quota
+	reviews     *review.Reviews
+	reviewCfg   config.ReviewConfig
+	features    []string
+	thresholds  *threshold.Thresholds
+	webhooks    []*notify.Webhook
+	proxies     []*net.IPNet
+
+	inflight  flights
+	fetchJobs *fetchjob.Jobs
+	runs      *fetchjob.Runs
+	store     store.Store
+	coverage  *coverage.Requests
+	messages  *alert.Templates
+	notes     *annotation.Annotations
+	profiles  map[string]config.ScanProfile
+	monitor   *alert.Monitor
+	sink      sink.Sink
+	diffs     catalogDiffs
+	accesses  *accesslog.Log
+	alertMu   sync.Mutex
+
+	// refreshes tracks the background refreshes of persisted quotas by
+	// cache key, so a scope that fails to refresh is retried with backoff
+	refreshMu sync.Mutex
+	refreshes map[string]*staleRefresh
+
+	// defaultRegion is where proxied reads that name no region are served
+	// from
+	defaultRegion string
+
+	// watched holds the increase requests awaiting a decision at the last
+	// poll, by ID
+	watchMu sync.Mutex
+	watched map[string]model.IncreaseRequest
+
+	// latest holds the most recent fetched value of every quota for
+	// snapshot exports; imported replaces AWS as the source of quotas once
+	// a snapshot has been imported
+	quotasMu sync.RWMutex
+	latest   map[store.QuotaKey]model.Quota
+	imported *importedSnapshot
 }
 
-func New(fetcher *aws.QuotaFetcher, cache *cache.Cache) *Handler {
+func New(fetcher *aws.QuotaFetcher, cache *cache.Cache, store store.Store) *Handler {
+	templates, _ := increase.NewTemplates(nil)
 	return &Handler{
-		fetcher: fetcher,
-		cache:   cache,
+		fetcher:   fetcher,
+		cache:     cache,
+		store:     store,
+		templates: templates,
+		increases: increase.NewTracker(),
+		proposals: increase.NewProposals(),
+		fetchJobs: fetchjob.NewJobs(),
+		runs:      fetchjob.NewRuns(),
+		reviews:   review.NewReviews(),
+		reviewCfg: config.Default().Review,
+		digest:    config.Default().Digest,
+		snoozes:   alert.NewSnoozes(),
+		messages:  alert.DefaultTemplates(),
+		coverage:  coverage.NewRequests(),
+		notes:     annotation.NewAnnotations(),
+
+		defaultRegion: config.Default().DefaultRegion,
+	}
+}
+
+// PersistState loads the reviews, coverage requests, snoozes, annotations,
+// increase proposals and run history saved in the store, and saves their
+// later changes to it, so they survive restarts with a persistent store
+func (h *Handler) PersistState(ctx context.Context) error {
+	for name, collection := range map[string]interface {
+		Persist(ctx context.Context, doc *persist.Doc) error
+	}{
+		"reviews":     h.reviews,
+		"coverage":    h.coverage,
+		"snoozes":     h.snoozes,
+		"annotations": h.notes,
+		"proposals":   h.proposals,
+		"runs":        h.runs,
+	} {
+		if err := collection.Persist(ctx, persist.NewDoc(h.store, name)); err != nil {
+			return fmt.Errorf("failed to load saved %s: %w", name, err)
+		}
 	}
+	return nil
 }
 
 func (h *Handler) SetConfig(config interface{}) {
 	h.config = config
 }
 
-func (h *Handler) GetRegions(c *gin.Context) {
-	cacheKey := "regions"
-	if cached, ok := h.cache.Get(cacheKey); ok {
-		c.JSON(http.StatusOK, gin.H{
-			"regions":    cached,
-			"from_cache": true,
-		})
-		return
+// SetDefaultRegion sets the region of proxied reads that name none
+func (h *Handler) SetDefaultRegion(region string) {
+	if region != "" {
+		h.defaultRegion = region
 	}
+}
 
-	regions, err := aws.GetRegions(c.Request.Context())
+// SetOrgScanner enables the organization inventory endpoints
+func (h *Handler) SetOrgScanner(scanner *org.Scanner) {
+	h.orgScanner = scanner
+}
+
+// SetJustificationTemplates replaces the built-in justification template
+func (h *Handler) SetJustificationTemplates(templates *increase.Templates) {
+	h.templates = templates
+}
+
+// GetRegions lists the regions enabled for the account. Regions it has not
+// opted into are listed apart under disabled_regions.
+func (h *Handler) GetRegions(c *gin.Context) {
+	regions, fromCache, err := h.regions(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	h.cache.Set(cacheKey, regions)
+	enabled, disabled := aws.SplitRegions(regions)
 	c.JSON(http.StatusOK, gin.H{
-		"regions":    regions,
-		"from_cache": false,
+		"regions":          enabled,
+		"disabled_regions": disabled,
+		"from_cache":       fromCache,
 	})
 }
 
+// regions returns all regions of the partition with their opt-in status,
+// from cache when possible
+func (h *Handler) regions(ctx context.Context) ([]model.Region, bool, error) {
+	cacheKey := h.cacheKey(ctx, "regions")
+	if cached, ok := h.cache.Get(cacheKey); ok {
+		if regions, ok := cached.([]model.Region); ok {
+			return regions, true, nil
+		}
+	}
+
+	regions, err := aws.GetRegions(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	h.cache.Set(cacheKey, regions)
+	return regions, false, nil
+}
+
 func (h *Handler) GetServices(c *gin.Context) {
 	region := c.DefaultQuery("region", "us-east-1")
-	cacheKey := "services:" + region
+	cacheKey := h.cacheKey(c.Request.Context(), "services", region)
 
 	if cached, ok := h.cache.Get(cacheKey); ok {
 		c.JSON(http.StatusOK, gin.H{
@@ -76,67 +226,477 @@ func (h *Handler) GetServices(c *gin.Context) {
 	})
 }
 
+// GetQuotas returns the quotas of a region/service scope. With quota_codes
+// (bare codes or service/code, comma-separated) only those quotas are fetched.
+// A scan profile fills in the parameters the request leaves out.
 func (h *Handler) GetQuotas(c *gin.Context) {
 	regionParam := c.Query("region")
 	serviceFilter := c.Query("service")
 	search := c.Query("search")
+	quotaCodes := c.Query("quota_codes")
 
-	var regions []string
-	if regionParam == "" || regionParam == "all" {
-		regionList, err := aws.GetRegions(c.Request.Context())
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if name := c.Query("profile"); name != "" {
+		profile, ok := h.profiles[name]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("unknown scan profile %q", name)})
 			return
 		}
-		for _, r := range regionList {
-			regions = append(regions, r.Code)
+		if regionParam == "" {
+			regionParam = strings.Join(profile.Regions, ",")
+		}
+		if serviceFilter == "" {
+			serviceFilter = profile.Service
+		}
+		if quotaCodes == "" {
+			quotaCodes = strings.Join(profile.QuotaCodes, ",")
 		}
-	} else {
-		regions = strings.Split(regionParam, ",")
 	}
 
-	cacheKey := "quotas:" + regionParam + ":" + serviceFilter
-	var quotas []model.Quota
-	var warnings []string
-	fromCache := false
-
-	if cached, ok := h.cache.Get(cacheKey); ok {
-		if quotas, ok = cached.([]model.Quota); !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid cache data type"})
+	var set *quotaSet
+	var err error
+	if quotaCodes != "" {
+		refs, parseErr := aws.ParseQuotaRefs(strings.Split(quotaCodes, ","), serviceFilter)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": parseErr.Error()})
 			return
 		}
-		fromCache = true
+		set, err = h.loadQuotasByCode(c.Request.Context(), regionParam, refs)
 	} else {
-		result, err := h.fetcher.GetQuotasForAllRegions(c.Request.Context(), regions, serviceFilter)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		quotas = result.Quotas
-		warnings = result.Warnings
-		h.cache.Set(cacheKey, quotas)
+		set, err = h.loadQuotas(c.Request.Context(), regionParam, serviceFilter)
 	}
+	if err != nil {
+		loadFailed(c, err)
+		return
+	}
+	quotas := set.quotas
 
 	if search != "" {
-		search = strings.ToLower(search)
-		filtered := make([]model.Quota, 0)
-		for _, q := range quotas {
-			if strings.Contains(strings.ToLower(q.QuotaName), search) ||
-				strings.Contains(strings.ToLower(q.ServiceName), search) ||
-				strings.Contains(strings.ToLower(q.ServiceCode), search) {
-				filtered = append(filtered, q)
-			}
-		}
-		quotas = filtered
+		quotas = searchQuotas(quotas, search)
+	}
+	quotas, err = h.withStatus(quotas, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
 	c.JSON(http.StatusOK, model.QuotaResponse{
 		Quotas:    quotas,
 		Total:     len(quotas),
 		FetchedAt: time.Now(),
-		FromCache: fromCache,
-		Warnings:  warnings,
+		FromCache: set.fromCache,
+		Stale:     set.stale,
+		Warnings:  set.warnings,
+
+		DisabledRegions: set.disabledRegions,
+	})
+}
+
+// quotaSet is the result of loading the quotas of a region/service scope
+type quotaSet struct {
+	quotas    []model.Quota
+	warnings  []string
+	fromCache bool
+	// stale marks quotas persisted before a restart, served while a
+	// background fetch refreshes them
+	stale bool
+	// disabledRegions were skipped because they are not enabled for the
+	// account
+	disabledRegions []string
+}
+
+// loadQuotas returns the quotas for a region parameter ("all", empty, or a
+// comma-separated list) and service filter, from cache when possible
+func (h *Handler) loadQuotas(ctx context.Context, regionParam, serviceFilter string) (*quotaSet, error) {
+	cacheKey := h.cacheKey(ctx, "quotas", regionParam, serviceFilter)
+	if set, ok := h.importedQuotas(regionParam, serviceFilter); ok {
+		// Cached so the exports, which read the cache, work on imported data
+		h.cache.Set(cacheKey, set.quotas)
+		set.quotas = h.notes.Apply(set.quotas)
+		return set, nil
+	}
+
+	if cached, ok := h.cache.Get(cacheKey); ok {
+		quotas, ok := cached.([]model.Quota)
+		if !ok {
+			return nil, fmt.Errorf("invalid cache data type")
+		}
+		set := &quotaSet{quotas: h.notes.Apply(quotas), fromCache: true}
+		if cached, ok := h.cache.Get(cacheKey + ":disabled"); ok {
+			if disabled, ok := cached.([]string); ok {
+				set.disabledRegions = disabled
+			}
+		}
+		return set, nil
+	}
+	// Fetch jobs wait for fresh data; the UI reads it once the job is done
+	if runTrigger(ctx) != model.RunTriggerFetchJob {
+		if set, ok := h.staleQuotas(ctx, cacheKey, regionParam, serviceFilter); ok {
+			return set, nil
+		}
+	}
+
+	regions, disabled, err := h.scanRegions(ctx, regionParam)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.fetchQuotas(ctx, cacheKey, regions, serviceFilter)
+	if err != nil {
+		return nil, err
+	}
+	disabled = append(disabled, result.DisabledRegions...)
+	sort.Strings(disabled)
+	h.cache.Set(cacheKey+":disabled", disabled)
+	return &quotaSet{quotas: h.notes.Apply(result.Quotas), warnings: result.Warnings, disabledRegions: disabled}, nil
+}
+
+// staleQuotas serves the quotas of a scope persisted before the server
+// restarted, if any, and refreshes them in the background
+func (h *Handler) staleQuotas(ctx context.Context, cacheKey, regionParam, serviceFilter string) (*quotaSet, bool) {
+	cached, storedAt, ok := h.cache.GetStale(cacheKey)
+	if !ok {
+		return nil, false
+	}
+	quotas, ok := cached.([]model.Quota)
+	if !ok {
+		return nil, false
+	}
+	set := &quotaSet{
+		quotas:    h.notes.Apply(quotas),
+		fromCache: true,
+		stale:     true,
+		warnings:  []string{fmt.Sprintf("Serving quotas cached at %s while refreshing", storedAt.Format(time.RFC3339))},
+	}
+	if cached, _, ok := h.cache.GetStale(cacheKey + ":disabled"); ok {
+		if disabled, ok := cached.([]string); ok {
+			set.disabledRegions = disabled
+		}
+	}
+
+	h.refreshStale(withRunTrigger(ctx, model.RunTriggerRefresh), cacheKey, regionParam, serviceFilter)
+	return set, true
+}
+
+// staleRefreshBackoff is the wait before retrying a failed refresh of
+// persisted quotas; it doubles with every further failure up to
+// maxStaleRefreshBackoff
+const (
+	staleRefreshBackoff    = time.Minute
+	maxStaleRefreshBackoff = 30 * time.Minute
+)
+
+// staleRefresh is the background refresh of a scope's persisted quotas
+type staleRefresh struct {
+	running  bool
+	failures int
+	retryAt  time.Time
+}
+
+// RefreshStaleQuotas refreshes, in the background, every scope whose quotas
+// were persisted before the server restarted, so they are fresh before they
+// are first requested
+func (h *Handler) RefreshStaleQuotas(ctx context.Context) {
+	keys := h.cache.StaleKeys()
+	if len(keys) == 0 {
+		return
+	}
+	prefix := h.cacheKey(ctx, "quotas") + ":"
+	ctx = withRunTrigger(ctx, model.RunTriggerRefresh)
+	for _, key := range keys {
+		// Keys of other accounts, and the disabled regions stored next to
+		// each scope, are skipped
+		scope, ok := strings.CutPrefix(key, prefix)
+		if !ok || strings.Count(scope, ":") != 1 {
+			continue
+		}
+		regionParam, serviceFilter, _ := strings.Cut(scope, ":")
+		h.refreshStale(ctx, key, regionParam, serviceFilter)
+	}
+}
+
+// refreshStale starts refreshing the persisted quotas of a scope unless a
+// refresh is already running or the last one failed too recently
+func (h *Handler) refreshStale(ctx context.Context, cacheKey, regionParam, serviceFilter string) {
+	h.refreshMu.Lock()
+	defer h.refreshMu.Unlock()
+	if h.refreshes == nil {
+		h.refreshes = make(map[string]*staleRefresh)
+	}
+	refresh, ok := h.refreshes[cacheKey]
+	if !ok {
+		refresh = &staleRefresh{}
+		h.refreshes[cacheKey] = refresh
+	}
+	if refresh.running || time.Now().Before(refresh.retryAt) {
+		return
+	}
+	refresh.running = true
+
+	go func() {
+		err := h.refreshQuotas(context.WithoutCancel(ctx), cacheKey, regionParam, serviceFilter)
+		h.refreshMu.Lock()
+		defer h.refreshMu.Unlock()
+		refresh.running = false
+		if err == nil {
+			delete(h.refreshes, cacheKey)
+			return
+		}
+		refresh.failures++
+		backoff := staleRefreshBackoff
+		for i := 1; i < refresh.failures && backoff < maxStaleRefreshBackoff; i++ {
+			backoff *= 2
+		}
+		backoff = min(backoff, maxStaleRefreshBackoff)
+		refresh.retryAt = time.Now().Add(backoff)
+		log.Printf("Failed to refresh stale quotas, retrying in %s: %v", backoff, err)
+	}()
+}
+
+// refreshQuotas fetches the quotas of a scope into the cache. Concurrent
+// fetches of a scope share a single call.
+func (h *Handler) refreshQuotas(ctx context.Context, cacheKey, regionParam, serviceFilter string) error {
+	regions, disabled, err := h.scanRegions(ctx, regionParam)
+	if err != nil {
+		return err
+	}
+	result, err := h.fetchQuotas(ctx, cacheKey, regions, serviceFilter)
+	if err != nil {
+		return err
+	}
+	disabled = append(disabled, result.DisabledRegions...)
+	sort.Strings(disabled)
+	h.cache.Set(cacheKey+":disabled", disabled)
+	return nil
+}
+
+// scanRegions resolves a region parameter ("all", empty, or a comma-separated
+// list) into the regions to scan. Regions the account has not opted into are
+// never scanned and are returned apart.
+func (h *Handler) scanRegions(ctx context.Context, regionParam string) (regions, disabled []string, err error) {
+	all, _, err := h.regions(ctx)
+	if err != nil {
+		if regionParam == "" || regionParam == "all" {
+			return nil, nil, err
+		}
+		// Without the region list, scan the requested regions as given
+		return strings.Split(regionParam, ","), nil, nil
+	}
+
+	enabled := make(map[string]bool, len(all))
+	for _, r := range all {
+		enabled[r.Code] = r.Enabled
+	}
+	if regionParam == "" || regionParam == "all" {
+		for _, r := range all {
+			if r.Enabled {
+				regions = append(regions, r.Code)
+			} else {
+				disabled = append(disabled, r.Code)
+			}
+		}
+		return regions, disabled, nil
+	}
+	for _, code := range strings.Split(regionParam, ",") {
+		if isEnabled, known := enabled[code]; known && !isEnabled {
+			disabled = append(disabled, code)
+			continue
+		}
+		regions = append(regions, code)
+	}
+	return regions, disabled, nil
+}
+
+// fetchQuotas scans AWS and caches the result. Concurrent requests for the same
+// scope share a single scan instead of each hitting the AWS APIs and racing to
+// write the cache. One client going away does not fail the others waiting on
+// the scan, but once all of them are gone the scan is cancelled and nothing is
+// cached.
+func (h *Handler) fetchQuotas(ctx context.Context, cacheKey string, regions []string, serviceFilter string) (*aws.FetchResult, error) {
+	v, err := h.inflight.Do(ctx, cacheKey, func(scanCtx context.Context) (interface{}, error) {
+		// The shared fetch outlives the caller that started it: everything
+		// after the scan runs on the flight's own context, never cancelled
+		ctx := context.WithoutCancel(scanCtx)
+		calls := &aws.CallCounter{}
+		startedAt := time.Now()
+		result, err := h.fetcher.GetQuotasForAllRegions(aws.WithCallCounter(scanCtx, calls), regions, serviceFilter)
+		if err == nil {
+			// Regions cut short by the cancellation only show up as warnings
+			err = scanCtx.Err()
+		}
+		h.recordFetchRun(ctx, startedAt, regions, serviceFilter, calls, result, err)
+		if err != nil {
+			return nil, err
+		}
+		accountID := h.accountID(ctx)
+		for i := range result.Quotas {
+			if result.Quotas[i].AccountID == "" {
+				result.Quotas[i].AccountID = accountID
+			}
+		}
+		// A fetch of one service lacks the quotas of other services that
+		// composites may reference
+		if serviceFilter == "" {
+			result.Quotas = composite.Append(h.composites, result.Quotas)
+		}
+		result.Quotas = h.withDeltas(ctx, result.Quotas)
+		h.recordHistory(ctx, accountID, regions, serviceFilter, result.Quotas)
+		result.Quotas = h.withPeaks(ctx, result.Quotas)
+		h.cache.Set(cacheKey, result.Quotas)
+		h.setLatest(result.Quotas)
+		if err := h.store.RecordWarnings(ctx, time.Now(), store.WarningSourceFetch, result.Warnings); err != nil {
+			log.Printf("Failed to record fetch warnings: %v", err)
+		}
+		h.mirror(ctx, regions, serviceFilter, result)
+		return result, nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := v.(*aws.FetchResult)
+	if !ok {
+		return nil, fmt.Errorf("unexpected fetch result type %T", v)
+	}
+	return result, nil
+}
+
+// recordHistory records a complete fetch and retires the series it no longer contains
+func (h *Handler) recordHistory(ctx context.Context, accountID string, regions []string, serviceFilter string, quotas []model.Quota) {
+	now := time.Now()
+	h.TrackLimitChanges(ctx, now, quotas)
+	h.NotifyThresholdCrossings(ctx, now, quotas)
+	if err := h.store.Record(ctx, now, quotas); err != nil {
+		log.Printf("Failed to record quota history: %v", err)
+		return
+	}
+	scope := store.Scope{
+		AccountIDs:  []string{accountID},
+		Regions:     append([]string{"global"}, regions...),
+		ServiceCode: serviceFilter,
+	}
+	retired, err := store.Reconcile(ctx, h.store, now, scope, quotas, h.retireAfter)
+	if err != nil {
+		log.Printf("Failed to reconcile quota history: %v", err)
+		return
+	}
+	if retired > 0 {
+		log.Printf("Retired %d quotas missing from the latest fetch", retired)
+	}
+}
+
+// SetSink sets the sink every unfiltered fetch is mirrored to
+func (h *Handler) SetSink(s sink.Sink) {
+	h.sink = s
+}
+
+// mirror writes a fetch covering every enabled region and service to the
+// sink in the background, so a slow database does not hold up the response.
+// Fetches filtered by region or service are not mirrored, as the sink takes
+// each snapshot as the whole inventory of its source.
+func (h *Handler) mirror(ctx context.Context, regions []string, serviceFilter string, result *aws.FetchResult) {
+	if h.sink == nil || serviceFilter != "" {
+		return
+	}
+	all, _, err := h.regions(context.WithoutCancel(ctx))
+	if err != nil {
+		return
+	}
+	scanned := make(map[string]bool, len(regions))
+	for _, r := range regions {
+		scanned[r] = true
+	}
+	for _, r := range all {
+		if r.Enabled && !scanned[r.Code] {
+			return
+		}
+	}
+	at := time.Now()
+	go func() {
+		if err := h.sink.WriteSnapshot(context.WithoutCancel(ctx), at, sink.SourceFetch, result.Quotas, result.Warnings); err != nil {
+			log.Printf("Failed to mirror quota snapshot: %v", err)
+		}
+	}()
+}
+
+// withDeltas fills in the change of each quota since the previous fetch; it
+// must run before the fetch is recorded. Quotas are returned unchanged when
+// the history cannot be read.
+func (h *Handler) withDeltas(ctx context.Context, quotas []model.Quota) []model.Quota {
+	annotated, err := store.WithDeltas(ctx, h.store, quotas)
+	if err != nil {
+		log.Printf("Failed to read previous quota observations: %v", err)
+		return quotas
+	}
+	return annotated
+}
+
+// withPeaks fills in the peak usage of each quota from the recorded history.
+// Quotas are returned unchanged when the history cannot be read.
+func (h *Handler) withPeaks(ctx context.Context, quotas []model.Quota) []model.Quota {
+	annotated, err := store.WithPeaks(ctx, h.store, quotas)
+	if err != nil {
+		log.Printf("Failed to read peak usage: %v", err)
+		return quotas
+	}
+	return annotated
+}
+
+// SetScanProfiles sets the scan profiles selectable with the profile parameter
+func (h *Handler) SetScanProfiles(profiles []config.ScanProfile) {
+	h.profiles = make(map[string]config.ScanProfile, len(profiles))
+	for _, p := range profiles {
+		h.profiles[p.Name] = p
+	}
+}
+
+// SetComposites sets the composite quotas appended to every fetch
+func (h *Handler) SetComposites(composites []composite.Quota) {
+	h.composites = composites
+}
+
+// SetRetireAfter sets how long a quota must be missing from fetches before
+// its history is retired
+func (h *Handler) SetRetireAfter(d time.Duration) {
+	h.retireAfter = d
+}
+
+// SetThresholds sets the thresholds the status of each quota is computed with
+func (h *Handler) SetThresholds(t *threshold.Thresholds) {
+	h.thresholds = t
+}
+
+// withStatus sets the status of each quota and keeps those matching the
+// comma-separated status filter, if any
+func (h *Handler) withStatus(quotas []model.Quota, filter string) ([]model.Quota, error) {
+	if h.thresholds == nil {
+		return quotas, nil
+	}
+	quotas = h.thresholds.Apply(quotas)
+	if filter == "" {
+		return quotas, nil
+	}
+	for _, s := range strings.Split(filter, ",") {
+		switch strings.TrimSpace(strings.ToLower(s)) {
+		case threshold.StatusOK, threshold.StatusWarning, threshold.StatusCritical, threshold.StatusUnknown:
+		default:
+			return nil, fmt.Errorf("unknown status %q (expected ok, warning, critical or unknown)", s)
+		}
+	}
+	return threshold.Filter(quotas, filter), nil
+}
+
+// searchQuotas returns the quotas whose quota name, service name or service
+// code contains the search term
+func searchQuotas(quotas []model.Quota, search string) []model.Quota {
+	search = strings.ToLower(search)
+	filtered := make([]model.Quota, 0)
+	for _, q := range quotas {
+		if strings.Contains(strings.ToLower(q.QuotaName), search) ||
+			strings.Contains(strings.ToLower(q.ServiceName), search) ||
+			strings.Contains(strings.ToLower(q.ServiceCode), search) {
+			filtered = append(filtered, q)
+		}
+	}
+	return filtered
 }
 
 func (h *Handler) Refresh(c *gin.Context) {
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+)
+
+// SetAttributionConfig enables the CloudTrail usage attribution endpoint
+func (h *Handler) SetAttributionConfig(cfg config.AttributionConfig) {
+	h.attribution = &cfg
+}
+
+// GetAttribution reports the principals that created the resources counted by
+// a breaching quota. Quotas below the configured usage percentage are rejected
+// unless force=true is given, since CloudTrail lookups are slow and rate limited.
+// Quotas keyed by name need their service_code.
+func (h *Handler) GetAttribution(c *gin.Context) {
+	if h.attribution == nil || !h.attribution.Enabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usage attribution is not enabled"})
+		return
+	}
+
+	region := c.Query("region")
+	quotaCode := c.Query("quota_code")
+	serviceCode := c.Query("service_code")
+	if region == "" || quotaCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "region and quota_code are required"})
+		return
+	}
+	if handler, ok := aws.QuotaCodeToServiceMapping[quotaCode]; ok {
+		serviceCode = handler.ServiceCode
+	}
+	if serviceCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attribution is not supported for quota " + quotaCode + " without service_code"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	quota, err := h.fetcher.GetQuota(ctx, region, serviceCode, quotaCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := aws.QuotaCodeToCreateEvents[aws.HandlerKey(quota.ServiceCode, quota.QuotaCode, quota.QuotaName)]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attribution is not supported for quota " + quotaCode})
+		return
+	}
+
+	if c.Query("force") != "true" && quota.UsagePercentage < h.attribution.MinUsagePercentage {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":            "Quota is not breaching; pass force=true to analyze anyway",
+			"usage_percentage": quota.UsagePercentage,
+			"threshold":        h.attribution.MinUsagePercentage,
+		})
+		return
+	}
+
+	lookback := time.Duration(h.attribution.LookbackHours) * time.Hour
+	result, err := h.fetcher.AttributeUsage(ctx, region, quota, lookback)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quota":       quota,
+		"attribution": result,
+	})
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/auth"
+)
+
+// identityKey holds the authenticated caller in the gin context
+const identityKey = "identity"
+
+// Access guards the API with OIDC. Without a verifier every caller is let
+// through with full rights, as before OIDC was configured.
+type Access struct {
+	verifier *auth.Verifier
+}
+
+// NewAccess creates the API guard; verifier is nil when OIDC is disabled
+func NewAccess(verifier *auth.Verifier) *Access {
+	return &Access{verifier: verifier}
+}
+
+// Authenticate requires a valid ID token as bearer token and at least the
+// viewer role
+func (a *Access) Authenticate(c *gin.Context) {
+	if a.verifier == nil {
+		c.Next()
+		return
+	}
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "an OIDC ID token is required as bearer token"})
+		return
+	}
+	identity, err := a.verifier.Verify(c.Request.Context(), token)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.Set(identityKey, identity)
+	c.Next()
+}
+
+// Require returns a middleware letting through only callers with at least
+// the given role
+func (a *Access) Require(role auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.verifier == nil {
+			c.Next()
+			return
+		}
+		identity, ok := identityOf(c)
+		if !ok || identity.Role < role {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "this action requires the " + role.String() + " role"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetIdentity returns the authenticated caller and their role, so the
+// dashboard can hide actions the caller may not take
+func (a *Access) GetIdentity(c *gin.Context) {
+	if a.verifier == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false, "role": auth.RoleAdmin})
+		return
+	}
+	identity, ok := identityOf(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "identity": identity, "role": identity.Role})
+}
+
+func identityOf(c *gin.Context) (*auth.Identity, bool) {
+	v, ok := c.Get(identityKey)
+	if !ok {
+		return nil, false
+	}
+	identity, ok := v.(*auth.Identity)
+	return identity, ok
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// bulkConcurrency bounds the regions whose quota is read at once
+const bulkConcurrency = 4
+
+// Bulk increase row actions
+const (
+	bulkSubmit    = "submit"
+	bulkSkip      = "skip"
+	bulkSubmitted = "submitted"
+	bulkFailed    = "failed"
+)
+
+type bulkIncreaseBody struct {
+	ServiceCode   string            `json:"service_code" binding:"required"`
+	QuotaCode     string            `json:"quota_code" binding:"required"`
+	DesiredValue  float64           `json:"desired_value" binding:"required,gt=0"`
+	Regions       []string          `json:"regions"`
+	Template      string            `json:"template"`
+	GrowthRate    float64           `json:"growth_rate"`
+	Variables     map[string]string `json:"variables"`
+	Justification string            `json:"justification"`
+}
+
+// bulkIncreaseRow is the plan, and after submission the outcome, of one
+// region of a bulk increase
+type bulkIncreaseRow struct {
+	Region          string                 `json:"region"`
+	QuotaName       string                 `json:"quota_name,omitempty"`
+	CurrentValue    float64                `json:"current_value"`
+	Usage           float64                `json:"usage"`
+	UsagePercentage float64                `json:"usage_percentage"`
+	HasUsage        bool                   `json:"has_usage_metrics"`
+	ProposedValue   float64                `json:"proposed_value"`
+	Action          string                 `json:"action"`
+	Reason          string                 `json:"reason,omitempty"`
+	Request         *model.IncreaseRequest `json:"request,omitempty"`
+
+	quota *model.Quota
+}
+
+// SetIncreaseRegions sets the regions a bulk increase covers when the
+// request names none
+func (h *Handler) SetIncreaseRegions(regions []string) {
+	h.incRegions = regions
+}
+
+// PreviewBulkIncrease shows, per region, the current value and usage of a
+// quota next to the proposed value, and whether the increase would be
+// submitted there. Nothing is submitted.
+func (h *Handler) PreviewBulkIncrease(c *gin.Context) {
+	var body bulkIncreaseBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rows, err := h.planBulkIncrease(c.Request.Context(), &body)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, bulkIncreaseResponse(&body, rows))
+}
+
+// SubmitBulkIncrease submits the increase in every region the preview marks
+// for submission and tracks the resulting requests
+func (h *Handler) SubmitBulkIncrease(c *gin.Context) {
+	var body bulkIncreaseBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx := c.Request.Context()
+	rows, err := h.planBulkIncrease(ctx, &body)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		if row.Action != bulkSubmit {
+			continue
+		}
+		req, err := h.submitBulkRow(ctx, &body, row)
+		if err != nil {
+			row.Action, row.Reason = bulkFailed, err.Error()
+			continue
+		}
+		row.Action, row.Request = bulkSubmitted, req
+	}
+
+	c.JSON(http.StatusOK, bulkIncreaseResponse(&body, rows))
+}
+
+// planBulkIncrease reads the quota in every region of the bulk increase and
+// decides where to submit it. A region is skipped when the quota is not
+// adjustable, already at or above the desired value, has an undecided
+// request, or is global and was already planned in an earlier region.
+func (h *Handler) planBulkIncrease(ctx context.Context, body *bulkIncreaseBody) ([]bulkIncreaseRow, error) {
+	regions := body.Regions
+	if len(regions) == 0 {
+		regions = h.incRegions
+	}
+	if len(regions) == 0 {
+		enabled, _, err := h.scanRegions(ctx, "all")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list regions: %w", err)
+		}
+		regions = enabled
+	}
+
+	rows := make([]bulkIncreaseRow, len(regions))
+	sem := make(chan struct{}, bulkConcurrency)
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		wg.Add(1)
+		go func(row *bulkIncreaseRow, region string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			row.Region, row.ProposedValue = region, body.DesiredValue
+			quota, err := h.fetcher.GetQuota(ctx, region, body.ServiceCode, body.QuotaCode)
+			if err != nil {
+				row.Action, row.Reason = bulkSkip, err.Error()
+				return
+			}
+			row.quota = quota
+			row.QuotaName = quota.QuotaName
+			row.CurrentValue = quota.Value
+			row.Usage = quota.Usage
+			row.UsagePercentage = quota.UsagePercentage
+			row.HasUsage = quota.HasUsageMetrics
+		}(&rows[i], region)
+	}
+	wg.Wait()
+
+	pending := make(map[string]string)
+	for _, req := range h.increases.Undecided() {
+		if req.ServiceCode == body.ServiceCode && req.QuotaCode == body.QuotaCode {
+			pending[req.Region] = req.ID
+		}
+	}
+	globalIn := ""
+	for i := range rows {
+		row := &rows[i]
+		switch {
+		case row.quota == nil:
+			// Skipped with the error reading the quota
+		case !row.quota.Adjustable:
+			row.Action, row.Reason = bulkSkip, "quota is not adjustable"
+		case row.quota.Value >= body.DesiredValue:
+			row.Action, row.Reason = bulkSkip, fmt.Sprintf("current value %g is already at or above the desired value", row.quota.Value)
+		case pending[row.Region] != "":
+			row.Action, row.Reason = bulkSkip, "request "+pending[row.Region]+" is awaiting a decision"
+		case row.quota.Global && globalIn != "":
+			row.Action, row.Reason = bulkSkip, "global quota, requested in "+globalIn
+		default:
+			row.Action = bulkSubmit
+			if row.quota.Global {
+				globalIn = row.Region
+			}
+		}
+	}
+	return rows, nil
+}
+
+// submitBulkRow submits the increase of one region with its justification
+func (h *Handler) submitBulkRow(ctx context.Context, body *bulkIncreaseBody, row *bulkIncreaseRow) (*model.IncreaseRequest, error) {
+	single := increaseRequestBody{
+		Region:        row.Region,
+		ServiceCode:   body.ServiceCode,
+		QuotaCode:     body.QuotaCode,
+		DesiredValue:  body.DesiredValue,
+		Template:      body.Template,
+		GrowthRate:    body.GrowthRate,
+		Variables:     body.Variables,
+		Justification: body.Justification,
+	}
+	justification := single.Justification
+	if justification == "" {
+		var err error
+		if justification, err = h.renderTemplate(row.quota, &single); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := h.fetcher.RequestQuotaIncrease(ctx, row.Region, body.ServiceCode, body.QuotaCode, body.DesiredValue)
+	if err != nil {
+		return nil, err
+	}
+	req.Template = single.Template
+	req.Justification = justification
+	if req.CreatedAt.IsZero() {
+		req.CreatedAt = time.Now()
+	}
+	h.increases.Add(*req)
+	return req, nil
+}
+
+func bulkIncreaseResponse(body *bulkIncreaseBody, rows []bulkIncreaseRow) gin.H {
+	counts := make(map[string]int)
+	for _, row := range rows {
+		counts[row.Action]++
+	}
+	return gin.H{
+		"service_code":  body.ServiceCode,
+		"quota_code":    body.QuotaCode,
+		"desired_value": body.DesiredValue,
+		"regions":       rows,
+		"counts":        counts,
+	}
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/catalog"
+)
+
+// GetCatalog returns the offline quota catalog, refreshed with any live
+// listings made since startup. Use ?service= to return a single service.
+func (h *Handler) GetCatalog(c *gin.Context) {
+	bundle := catalog.Default().Bundle()
+
+	if service := c.Query("service"); service != "" {
+		for _, svc := range bundle.Services {
+			if svc.ServiceCode == service {
+				c.JSON(http.StatusOK, svc)
+				return
+			}
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "service not in catalog: " + service})
+		return
+	}
+
+	c.JSON(http.StatusOK, bundle)
+}
+
+// catalogDiffs keeps the latest comparison of the live Service Quotas catalog
+// against the bundled one
+type catalogDiffs struct {
+	mu      sync.Mutex
+	running bool
+	latest  *catalog.Diff
+	err     string
+}
+
+// RunCatalogDiff lists the live catalog of a region and compares it against
+// the bundled catalog. The listing covers every service, so it takes a few
+// minutes; only one runs at a time.
+func (h *Handler) RunCatalogDiff(ctx context.Context, region string) (*catalog.Diff, error) {
+	if !catalog.Bundled().Generated() {
+		return nil, catalog.ErrNotGenerated
+	}
+
+	h.diffs.mu.Lock()
+	if h.diffs.running {
+		h.diffs.mu.Unlock()
+		return nil, errCatalogDiffRunning
+	}
+	h.diffs.running = true
+	h.diffs.mu.Unlock()
+
+	live, err := h.fetcher.ListCatalog(ctx, region)
+	var diff *catalog.Diff
+	if err == nil {
+		d := catalog.Bundled().Compare(live, region, time.Now())
+		diff = &d
+	}
+
+	h.diffs.mu.Lock()
+	defer h.diffs.mu.Unlock()
+	h.diffs.running = false
+	if err != nil {
+		h.diffs.err = err.Error()
+		return nil, err
+	}
+	h.diffs.latest, h.diffs.err = diff, ""
+	return diff, nil
+}
+
+var errCatalogDiffRunning = errors.New("a catalog comparison is already running")
+
+// StartCatalogDiff starts comparing the live catalog of a region (default
+// us-east-1) against the bundled one; GetCatalogDiff returns the outcome
+func (h *Handler) StartCatalogDiff(c *gin.Context) {
+	region := c.DefaultQuery("region", "us-east-1")
+	if !catalog.Bundled().Generated() {
+		c.JSON(http.StatusConflict, gin.H{"error": catalog.ErrNotGenerated.Error()})
+		return
+	}
+
+	h.diffs.mu.Lock()
+	running := h.diffs.running
+	h.diffs.mu.Unlock()
+	if running {
+		c.JSON(http.StatusConflict, gin.H{"error": errCatalogDiffRunning.Error()})
+		return
+	}
+
+	go func() {
+		diff, err := h.RunCatalogDiff(context.WithoutCancel(c.Request.Context()), region)
+		if err != nil {
+			log.Printf("Failed to compare the quota catalog: %v", err)
+			return
+		}
+		log.Printf("Quota catalog comparison: %d quota codes added and %d removed since the bundled catalog", diff.Added, diff.Removed)
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"status": "running", "region": region})
+}
+
+// GetCatalogDiff returns the latest comparison of the live catalog against
+// the bundled one: the quota codes AWS introduced or retired per service
+func (h *Handler) GetCatalogDiff(c *gin.Context) {
+	h.diffs.mu.Lock()
+	defer h.diffs.mu.Unlock()
+	if h.diffs.latest == nil && h.diffs.err == "" && !h.diffs.running {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no catalog comparison has run yet; start one with POST /api/catalog/diff"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"running": h.diffs.running,
+		"error":   h.diffs.err,
+		"diff":    h.diffs.latest,
+	})
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/format"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/report"
+)
+
+// csvRow is a quota being written to a CSV export
+type csvRow struct {
+	quota    model.Quota
+	opts     format.Options
+	trend    report.Trend
+	hasTrend bool
+}
+
+// csvColumn is a column selectable with the columns parameter of the CSV and
+// XLSX exports; trend columns need the usage history, and numeric ones are
+// number cells in XLSX
+type csvColumn struct {
+	name    string
+	header  string
+	trend   bool
+	numeric bool
+	value   func(r csvRow) string
+}
+
+// csvColumns lists every selectable column
+var csvColumns = []csvColumn{
+	{name: "account", header: "Account", value: func(r csvRow) string { return r.quota.AccountID }},
+	{name: "region", header: "Region", value: func(r csvRow) string { return r.quota.Region }},
+	{name: "service_code", header: "Service Code", value: func(r csvRow) string { return r.quota.ServiceCode }},
+	{name: "service", header: "Service", value: func(r csvRow) string { return r.quota.ServiceName }},
+	{name: "quota_name", header: "Quota Name", value: func(r csvRow) string { return r.quota.QuotaName }},
+	{name: "quota_code", header: "Quota Code", value: func(r csvRow) string { return r.quota.QuotaCode }},
+	{name: "value", header: "Value", numeric: true, value: func(r csvRow) string {
+		if r.quota.LimitUnknown {
+			return model.LimitUnknownLabel
+		}
+		value, _ := r.opts.Quantity(r.quota.Value, r.quota.Unit)
+		return value
+	}},
+	{name: "usage", header: "Usage", numeric: true, value: func(r csvRow) string {
+		if !r.quota.HasUsageMetrics {
+			return ""
+		}
+		usage, _ := r.opts.Quantity(r.quota.Usage, r.quota.Unit)
+		return usage
+	}},
+	{name: "usage_pct", header: "Usage %", numeric: true, value: func(r csvRow) string {
+		if !r.quota.HasUsageMetrics {
+			return ""
+		}
+		return r.opts.Number(math.Round(r.quota.UsagePercentage*10) / 10)
+	}},
+	{name: "unit", header: "Unit", value: func(r csvRow) string {
+		_, unit := r.opts.Quantity(r.quota.Value, r.quota.Unit)
+		return unit
+	}},
+	{name: "adjustable", header: "Adjustable", value: func(r csvRow) string { return strconv.FormatBool(r.quota.Adjustable) }},
+	{name: "global", header: "Global", value: func(r csvRow) string { return strconv.FormatBool(r.quota.Global) }},
+	{name: "usage_details", header: "Usage Details", value: func(r csvRow) string { return r.quota.UsageDetails }},
+	{name: "status", header: "Status", value: func(r csvRow) string { return r.quota.Status }},
+	{name: "peak_usage", header: "Peak Usage", numeric: true, value: func(r csvRow) string {
+		if r.quota.PeakUsageAt == nil {
+			return ""
+		}
+		peak, _ := r.opts.Quantity(r.quota.PeakUsage, r.quota.Unit)
+		return peak
+	}},
+	{name: "peak_usage_at", header: "Peak Usage At", value: func(r csvRow) string {
+		if r.quota.PeakUsageAt == nil {
+			return ""
+		}
+		return r.quota.PeakUsageAt.UTC().Format(time.RFC3339)
+	}},
+	{name: "trend", header: "Trend", trend: true, value: func(r csvRow) string {
+		if !r.hasTrend {
+			return ""
+		}
+		return r.trend.Label(r.opts)
+	}},
+	{name: "previous_usage", header: "Previous Usage", trend: true, numeric: true, value: func(r csvRow) string {
+		if !r.hasTrend {
+			return ""
+		}
+		previous, _ := r.opts.Quantity(r.trend.Previous, r.quota.Unit)
+		return previous
+	}},
+	{name: "owner", header: "Owner", value: func(r csvRow) string { return r.quota.Owner }},
+	{name: "note", header: "Note", value: func(r csvRow) string { return r.quota.Note }},
+	{name: "runbook", header: "Runbook", value: func(r csvRow) string { return r.quota.Runbook }},
+	{name: "alert_threshold", header: "Alert Threshold", numeric: true, value: func(r csvRow) string {
+		if r.quota.AlertThreshold == 0 {
+			return ""
+		}
+		return r.opts.Number(r.quota.AlertThreshold)
+	}},
+}
+
+// defaultCSVColumns are exported when no columns are selected
+var defaultCSVColumns = []string{
+	"region", "service", "quota_name", "quota_code", "value", "usage", "usage_pct", "unit", "adjustable", "peak_usage", "peak_usage_at",
+}
+
+// parseCSVColumns resolves a comma-separated list of column names, in the
+// order given, falling back to the default columns
+func parseCSVColumns(param string) ([]csvColumn, error) {
+	names := defaultCSVColumns
+	if strings.TrimSpace(param) != "" {
+		names = strings.Split(param, ",")
+	}
+	columns := make([]csvColumn, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		col, ok := csvColumnByName(name)
+		if !ok {
+			available := make([]string, len(csvColumns))
+			for i, c := range csvColumns {
+				available[i] = c.name
+			}
+			return nil, fmt.Errorf("unknown column %q, available columns: %s", name, strings.Join(available, ", "))
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+func csvColumnByName(name string) (csvColumn, bool) {
+	for _, col := range csvColumns {
+		if col.name == name {
+			return col, true
+		}
+	}
+	return csvColumn{}, false
+}
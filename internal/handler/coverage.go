@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/catalog"
+	"github.com/yuxishi/aws-quota-dashboard/internal/coverage"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+type coverageRequestBody struct {
+	ServiceCode string `json:"service_code" binding:"required"`
+	QuotaCode   string `json:"quota_code" binding:"required"`
+	QuotaName   string `json:"quota_name"`
+	RequestedBy string `json:"requested_by"`
+	Note        string `json:"note"`
+}
+
+// GetCoverage lists the known quotas, from the catalog, fetches and coverage
+// requests, with whether a direct usage handler counts them and how often one
+// was requested. Filter with service, search and uncovered=true; the most
+// requested quotas come first.
+func (h *Handler) GetCoverage(c *gin.Context) {
+	serviceFilter := c.Query("service")
+	search := c.Query("search")
+	uncoveredOnly := c.Query("uncovered") == "true"
+
+	quotas := make([]model.QuotaCoverage, 0)
+	covered, uncovered := 0, 0
+	for _, entry := range h.knownQuotas() {
+		if serviceFilter != "" && !strings.EqualFold(entry.ServiceCode, serviceFilter) {
+			continue
+		}
+		entry.Covered = aws.HasUsageHandler(entry.ServiceCode, entry.QuotaCode, entry.QuotaName)
+		entry.Requests = h.coverage.Count(entry.ServiceCode, entry.QuotaCode)
+		if entry.Covered {
+			covered++
+		} else {
+			uncovered++
+		}
+		if uncoveredOnly && entry.Covered {
+			continue
+		}
+		if !coverage.Matches(search, entry.ServiceCode, entry.ServiceName, entry.QuotaCode, entry.QuotaName) {
+			continue
+		}
+		quotas = append(quotas, entry)
+	}
+	sort.SliceStable(quotas, func(i, j int) bool { return quotas[i].Requests > quotas[j].Requests })
+
+	c.JSON(http.StatusOK, gin.H{
+		"quotas":    quotas,
+		"total":     len(quotas),
+		"covered":   covered,
+		"uncovered": uncovered,
+	})
+}
+
+// knownQuotas returns every quota the dashboard knows of, ordered by service
+// and quota code. The bundled catalog only lists quotas with usage handlers,
+// so fetched and requested quotas are added to it.
+func (h *Handler) knownQuotas() []model.QuotaCoverage {
+	known := make(map[string]model.QuotaCoverage)
+	add := func(serviceCode, serviceName, quotaCode, quotaName string) {
+		k := serviceCode + "/" + quotaCode
+		entry, ok := known[k]
+		if !ok {
+			entry = model.QuotaCoverage{ServiceCode: serviceCode, QuotaCode: quotaCode}
+		}
+		if entry.ServiceName == "" {
+			entry.ServiceName = serviceName
+		}
+		if entry.QuotaName == "" {
+			entry.QuotaName = quotaName
+		}
+		known[k] = entry
+	}
+
+	cat := catalog.Default()
+	for _, svc := range cat.Services() {
+		for _, q := range cat.Quotas(svc.Code) {
+			add(svc.Code, svc.Name, q.QuotaCode, q.QuotaName)
+		}
+	}
+	h.quotasMu.RLock()
+	for _, q := range h.latest {
+		add(q.ServiceCode, q.ServiceName, q.QuotaCode, q.QuotaName)
+	}
+	h.quotasMu.RUnlock()
+	for _, req := range h.coverage.List("", "") {
+		add(req.ServiceCode, cat.ServiceName(req.ServiceCode), req.QuotaCode, req.QuotaName)
+	}
+
+	list := make([]model.QuotaCoverage, 0, len(known))
+	for _, entry := range known {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].ServiceCode != list[j].ServiceCode {
+			return list[i].ServiceCode < list[j].ServiceCode
+		}
+		return list[i].QuotaCode < list[j].QuotaCode
+	})
+	return list
+}
+
+// GetCoverageRequests lists the requested usage handlers, most requested first
+func (h *Handler) GetCoverageRequests(c *gin.Context) {
+	requests := h.coverage.List(c.Query("service"), c.Query("search"))
+	c.JSON(http.StatusOK, gin.H{
+		"requests": requests,
+		"total":    len(requests),
+	})
+}
+
+// RequestCoverage flags a quota without a usage handler as wanted. Each
+// requester is counted once per quota. The quota name is taken from the
+// catalog or the latest fetch when the request does not carry it.
+func (h *Handler) RequestCoverage(c *gin.Context) {
+	var body coverageRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := body.QuotaName
+	for _, entry := range h.knownQuotas() {
+		if entry.ServiceCode == body.ServiceCode && entry.QuotaCode == body.QuotaCode && entry.QuotaName != "" {
+			name = entry.QuotaName
+			break
+		}
+	}
+	if aws.HasUsageHandler(body.ServiceCode, body.QuotaCode, name) {
+		c.JSON(http.StatusConflict, gin.H{"error": "quota already has a usage handler"})
+		return
+	}
+
+	req := h.coverage.Add(body.ServiceCode, body.QuotaCode, name, body.RequestedBy, body.Note, time.Now())
+	c.JSON(http.StatusOK, req)
+}
@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/format"
+	"github.com/yuxishi/aws-quota-dashboard/internal/increase"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+	"github.com/yuxishi/aws-quota-dashboard/internal/report"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/threshold"
+)
+
+// Periods a digest covers
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+var digestPeriods = map[string]time.Duration{
+	DigestDaily:  24 * time.Hour,
+	DigestWeekly: 7 * 24 * time.Hour,
+}
+
+// maxDigestLines bounds each list of a digest message
+const maxDigestLines = 20
+
+// teamsDigestColor is the card color of digests
+const teamsDigestColor = "0078D7"
+
+// digestReport summarizes the quotas of a period. Breaches are the quotas
+// that moved into warning or critical during it, acknowledged and snoozed
+// ones included.
+type digestReport struct {
+	Event             string                  `json:"event"`
+	Period            string                  `json:"period"`
+	Since             time.Time               `json:"since"`
+	Until             time.Time               `json:"until"`
+	TopUtilization    []model.Quota           `json:"top_utilization"`
+	Breaches          []thresholdEvent        `json:"breaches"`
+	Resolved          int                     `json:"resolved"`
+	ActiveAlerts      int                     `json:"active_alerts"`
+	ApprovedIncreases []model.IncreaseRequest `json:"approved_increases"`
+}
+
+// digestSection is a titled list of a digest message
+type digestSection struct {
+	title string
+	lines []string
+}
+
+// SetDigest configures the scheduled digest
+func (h *Handler) SetDigest(cfg config.DigestConfig) {
+	h.digest = cfg
+}
+
+// GetDigest previews the digest of a period (default: the configured one)
+// without sending it
+func (h *Handler) GetDigest(c *gin.Context) {
+	period := c.DefaultQuery("period", h.digest.Period)
+	if _, ok := digestPeriods[period]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid period %q (daily or weekly)", period)})
+		return
+	}
+	d, err := h.buildDigest(c.Request.Context(), period, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// SendDigest sends the digest of the configured period to every configured
+// notifier: Slack, Teams, all email recipients, the webhooks and the SNS
+// topic
+func (h *Handler) SendDigest(ctx context.Context) error {
+	d, err := h.buildDigest(ctx, h.digest.Period, time.Now())
+	if err != nil {
+		return err
+	}
+	if h.slack != nil {
+		if err := h.slack.Post(ctx, digestMessage(d, h.slackCfg.DashboardURL)); err != nil {
+			log.Printf("Failed to post %s digest to Slack: %v", d.Period, err)
+		}
+	}
+	if h.teams != nil {
+		if err := h.teams.Post(ctx, digestCard(d, h.teamsCfg.DashboardURL)); err != nil {
+			log.Printf("Failed to post %s digest to Teams: %v", d.Period, err)
+		}
+	}
+	if len(h.email.Recipients) > 0 {
+		to := make([]string, len(h.email.Recipients))
+		for i, r := range h.email.Recipients {
+			to[i] = r.Address
+		}
+		subject, html, text := digestEmail(d, h.email.DashboardURL)
+		if err := aws.SendEmail(ctx, h.emailRegion, h.email.From, to, subject, html, text); err != nil {
+			log.Printf("Failed to email %s digest: %v", d.Period, err)
+		}
+	}
+	for _, w := range h.webhooks {
+		if err := w.Post(ctx, d); err != nil {
+			log.Printf("Failed to post %s digest: %v", d.Period, err)
+		}
+	}
+	if h.publishes(d.Event) {
+		h.publish(ctx, d.Event, d.headline(), d, map[string]string{"period": d.Period})
+	}
+	log.Printf("Sent %s", d.headline())
+	return nil
+}
+
+// buildDigest summarizes the period of a length ending at until: the most
+// utilized current quotas, the logged breaches and resolutions, the active
+// alerts and the increase requests approved
+func (h *Handler) buildDigest(ctx context.Context, period string, until time.Time) (digestReport, error) {
+	d := digestReport{
+		Event:  notify.EventDigest,
+		Period: period,
+		Since:  until.Add(-digestPeriods[period]),
+		Until:  until,
+	}
+
+	quotas, _, err := h.currentQuotas(ctx)
+	if err != nil {
+		return d, err
+	}
+	for _, q := range quotas {
+		if q.HasUsageMetrics && !q.LimitUnknown {
+			d.TopUtilization = append(d.TopUtilization, q)
+		}
+	}
+	sort.Slice(d.TopUtilization, func(i, j int) bool {
+		return d.TopUtilization[i].UsagePercentage > d.TopUtilization[j].UsagePercentage
+	})
+	if len(d.TopUtilization) > h.digest.Top {
+		d.TopUtilization = d.TopUtilization[:h.digest.Top]
+	}
+	if h.thresholds != nil {
+		d.TopUtilization = h.thresholds.Apply(h.notes.Apply(d.TopUtilization))
+	}
+
+	transitions, err := h.store.AlertTransitions(ctx, d.Since, until)
+	if err != nil {
+		return d, err
+	}
+	for _, t := range transitions {
+		e := transitionEvent(t)
+		switch {
+		case e.Status == threshold.StatusResolved:
+			d.Resolved++
+		case e.Status != e.PreviousStatus:
+			d.Breaches = append(d.Breaches, e)
+		}
+	}
+
+	states, err := h.store.AlertStates(ctx, nil)
+	if err != nil {
+		return d, err
+	}
+	for _, s := range states {
+		if alerting(s.State) {
+			d.ActiveAlerts++
+		}
+	}
+
+	d.ApprovedIncreases = h.approvedIncreases(ctx, d.Since, until)
+	return d, nil
+}
+
+// approvedIncreases lists the increase requests approved in [since, until]
+// from the request history of every enabled region, so requests made outside
+// the dashboard or before a restart are included. Regions whose history
+// cannot be read are skipped.
+func (h *Handler) approvedIncreases(ctx context.Context, since, until time.Time) []model.IncreaseRequest {
+	regions, _, err := h.scanRegions(ctx, "all")
+	if err != nil {
+		log.Printf("Failed to list regions for approved increases: %v", err)
+		return nil
+	}
+	seen := make(map[string]bool)
+	var approved []model.IncreaseRequest
+	for _, region := range regions {
+		requests, err := h.fetcher.ListIncreaseRequests(ctx, region, increase.StatusApproved)
+		if err != nil {
+			log.Printf("Failed to list approved increases in %s: %v", region, err)
+			continue
+		}
+		for _, req := range requests {
+			if seen[req.ID] || req.ResolvedAt == nil || req.ResolvedAt.Before(since) || req.ResolvedAt.After(until) {
+				continue
+			}
+			seen[req.ID] = true
+			approved = append(approved, req)
+		}
+	}
+	sort.Slice(approved, func(i, j int) bool { return approved[i].ResolvedAt.Before(*approved[j].ResolvedAt) })
+	return approved
+}
+
+// transitionEvent rebuilds the event of a logged alert transition
+func transitionEvent(t store.AlertTransition) thresholdEvent {
+	q := model.Quota{
+		AccountID:       t.Key.AccountID,
+		Region:          t.Key.Region,
+		ServiceCode:     t.Key.ServiceCode,
+		ServiceName:     t.ServiceName,
+		QuotaCode:       t.Key.QuotaCode,
+		QuotaName:       t.QuotaName,
+		Value:           t.Value,
+		Usage:           t.Usage,
+		UsagePercentage: t.UsagePercentage,
+		HasUsageMetrics: true,
+	}
+	event := notify.EventThresholdCrossed
+	if t.State == threshold.StatusResolved {
+		event = notify.EventAlertResolved
+	}
+	return thresholdEvent{
+		Event:           event,
+		Timestamp:       t.At,
+		AlertID:         t.AlertID,
+		AccountID:       q.AccountID,
+		Region:          q.Region,
+		ServiceCode:     q.ServiceCode,
+		ServiceName:     q.ServiceName,
+		QuotaCode:       q.QuotaCode,
+		QuotaName:       q.QuotaName,
+		Value:           q.Value,
+		Usage:           q.Usage,
+		UsagePercentage: q.UsagePercentage,
+		Status:          t.State,
+		PreviousStatus:  t.PreviousState,
+		Threshold:       t.Threshold,
+		quota:           q,
+		silenced:        t.Silenced,
+	}
+}
+
+// headline summarizes a digest in one sentence
+func (d digestReport) headline() string {
+	return fmt.Sprintf("%s%s quota digest: %d new breaches, %d resolved, %d active alerts, %d approved increases",
+		strings.ToUpper(d.Period[:1]), d.Period[1:], len(d.Breaches), d.Resolved, d.ActiveAlerts, len(d.ApprovedIncreases))
+}
+
+// sections lists the top quotas, breaches and approved increases of a digest,
+// each cut to maxDigestLines
+func (d digestReport) sections() []digestSection {
+	top := digestSection{title: "Top utilization"}
+	for _, q := range d.TopUtilization {
+		location := q.Region
+		if q.AccountID != "" {
+			location = q.AccountID + " " + q.Region
+		}
+		top.lines = append(top.lines, fmt.Sprintf("%.1f%% %s (%s) in %s: %g / %g",
+			q.UsagePercentage, q.QuotaName, q.QuotaCode, location, q.Usage, q.Value))
+	}
+	breaches := digestSection{title: "New breaches"}
+	for _, e := range d.Breaches {
+		breaches.lines = append(breaches.lines, e.Timestamp.UTC().Format("Jan 2 15:04")+" "+e.summary())
+	}
+	increases := digestSection{title: "Approved increases"}
+	for _, req := range d.ApprovedIncreases {
+		increases.lines = append(increases.lines, fmt.Sprintf("%s (%s) in %s raised to %g",
+			req.QuotaName, req.QuotaCode, req.Region, req.DesiredValue))
+	}
+
+	sections := []digestSection{top, breaches, increases}
+	for i, s := range sections {
+		if over := len(s.lines) - maxDigestLines; over > 0 {
+			sections[i].lines = append(s.lines[:maxDigestLines], fmt.Sprintf("... and %d more", over))
+		}
+		if len(s.lines) == 0 {
+			sections[i].lines = []string{"None"}
+		}
+	}
+	return sections
+}
+
+// digestMessage renders a digest as a Slack message
+func digestMessage(d digestReport, dashboardURL string) notify.Message {
+	headline := d.headline()
+	blocks := []notify.Block{{Type: "section", Text: notify.Markdown("*" + headline + "*")}}
+	for _, s := range d.sections() {
+		blocks = append(blocks, notify.Block{
+			Type: "section",
+			Text: notify.Markdown("*" + s.title + "*\n• " + strings.Join(s.lines, "\n• ")),
+		})
+	}
+	if dashboardURL != "" {
+		blocks = append(blocks, notify.Block{Type: "section", Text: notify.Markdown("<" + dashboardURL + "|Open dashboard>")})
+	}
+	return notify.Message{Text: headline, Blocks: blocks}
+}
+
+// digestCard renders a digest as a Teams connector card
+func digestCard(d digestReport, dashboardURL string) notify.Card {
+	headline := d.headline()
+	card := notify.NewCard(headline, headline, teamsDigestColor)
+	for _, s := range d.sections() {
+		// Connector card markdown needs blank lines between lines
+		card.Sections = append(card.Sections, notify.CardSection{
+			ActivityTitle: s.title,
+			Text:          strings.Join(s.lines, "\n\n"),
+		})
+	}
+	if dashboardURL != "" {
+		card.PotentialAction = []notify.CardAction{notify.OpenURI("Open dashboard", dashboardURL)}
+	}
+	return card
+}
+
+// digestEmail renders a digest as an email. The HTML body is the quota report
+// of the top and breached quotas with their status; the text body has every
+// section of the digest.
+func digestEmail(d digestReport, dashboardURL string) (subject, html, text string) {
+	quotas := append([]model.Quota(nil), d.TopUtilization...)
+	listed := make(map[store.QuotaKey]bool)
+	for _, q := range quotas {
+		listed[store.KeyOf(q)] = true
+	}
+	for _, e := range d.Breaches {
+		if key := store.KeyOf(e.quota); !listed[key] {
+			listed[key] = true
+			q := e.quota
+			q.Status = e.Status
+			quotas = append(quotas, q)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s to %s\n", d.headline(), d.Since.UTC().Format(time.RFC1123), d.Until.UTC().Format(time.RFC1123))
+	for _, s := range d.sections() {
+		fmt.Fprintf(&b, "\n%s\n", s.title)
+		for _, line := range s.lines {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	if dashboardURL != "" {
+		fmt.Fprintf(&b, "\n%s\n", dashboardURL)
+	}
+
+	subject = "[AWS quotas] " + d.headline()
+	html = report.HTML(quotas, nil, format.Options{Locale: "en", ScaleUnits: true})
+	return subject, html, b.String()
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/alert"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/format"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/report"
+	"github.com/yuxishi/aws-quota-dashboard/internal/threshold"
+)
+
+// SetEmail enables email notifications through SES in region
+func (h *Handler) SetEmail(cfg config.EmailConfig, region string) {
+	h.email = cfg
+	h.emailRegion = region
+}
+
+// emailThresholdCrossings emails each recipient the crossings routed to it,
+// with the HTML report of the crossed quotas
+func (h *Handler) emailThresholdCrossings(ctx context.Context, events []thresholdEvent) {
+	for _, r := range h.email.Recipients {
+		var routed []thresholdEvent
+		for _, e := range events {
+			if routesTo(r, e) {
+				routed = append(routed, e)
+			}
+		}
+		if len(routed) == 0 {
+			continue
+		}
+		render := func(e thresholdEvent) string { return h.renderAlert(alert.NotifierEmail, e) }
+		subject, html, text := thresholdEmail(routed, render, h.email.DashboardURL)
+		if err := aws.SendEmail(ctx, h.emailRegion, h.email.From, []string{r.Address}, subject, html, text); err != nil {
+			log.Printf("Failed to email %d threshold crossings: %v", len(routed), err)
+		}
+	}
+}
+
+// routesTo reports whether a crossing matches the filters of a recipient
+func routesTo(r config.EmailRecipient, e thresholdEvent) bool {
+	return matchesAny(r.Severities, e.severity()) && matchesAny(r.Services, e.ServiceCode) &&
+		matchesAny(r.Regions, e.Region) && matchesAny(r.Accounts, e.AccountID)
+}
+
+// matchesAny reports whether value is in values, or values is empty
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// thresholdEmail renders crossings as an email. The HTML body is the quota
+// report of the crossed quotas; the text body has the message rendered for
+// each, with dashboard links when its URL is configured. A single crossing
+// takes the subject of its message, if it has one.
+func thresholdEmail(events []thresholdEvent, render func(thresholdEvent) string, dashboardURL string) (subject, html, text string) {
+	quotas := make([]model.Quota, len(events))
+	critical, resolved := 0, 0
+	var b strings.Builder
+	for i, e := range events {
+		quotas[i] = e.quota
+		quotas[i].Status = e.Status
+		switch e.Status {
+		case threshold.StatusCritical:
+			critical++
+		case threshold.StatusResolved:
+			resolved++
+		}
+		msgSubject, body := alert.SplitSubject(render(e))
+		if len(events) == 1 {
+			subject = msgSubject
+		} else if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.TrimRight(body, "\n") + "\n")
+		if dashboardURL != "" {
+			fmt.Fprintf(&b, "  %s\n", quotaLink(dashboardURL, e))
+		}
+	}
+
+	if subject == "" && len(events) == 1 {
+		e := events[0]
+		subject = fmt.Sprintf("[AWS quotas] %s is %s in %s", e.QuotaName, e.Status, e.Region)
+	} else if subject == "" {
+		subject = fmt.Sprintf("[AWS quotas] %d quota alerts (%d critical, %d resolved)", len(events), critical, resolved)
+	}
+	html = report.HTML(quotas, nil, format.Options{Locale: "en", ScaleUnits: true})
+	return subject, html, b.String()
+}
@@ -1,19 +1,32 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+	"github.com/yuxishi/aws-quota-dashboard/internal/format"
 	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/report"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/version"
 )
 
 func (h *Handler) ExportJSON(c *gin.Context) {
 	regionParam := c.Query("region")
 	serviceFilter := c.Query("service")
 
-	cacheKey := "quotas:" + regionParam + ":" + serviceFilter
+	cacheKey := h.cacheKey(c.Request.Context(), "quotas", regionParam, serviceFilter)
 	var quotas []model.Quota
 
 	if cached, ok := h.cache.Get(cacheKey); ok {
@@ -29,10 +42,13 @@ func (h *Handler) ExportJSON(c *gin.Context) {
 		})
 		return
 	}
+	quotas = h.notes.Apply(quotas)
 
 	filename := fmt.Sprintf("aws-quotas-%s.json", time.Now().Format("2006-01-02"))
 	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header(versionHeader, version.String())
 	c.JSON(http.StatusOK, model.QuotaResponse{
+		Version:   version.String(),
 		Quotas:    quotas,
 		Total:     len(quotas),
 		FetchedAt: time.Now(),
@@ -44,7 +60,7 @@ func (h *Handler) ExportHTML(c *gin.Context) {
 	regionParam := c.Query("region")
 	serviceFilter := c.Query("service")
 
-	cacheKey := "quotas:" + regionParam + ":" + serviceFilter
+	cacheKey := h.cacheKey(c.Request.Context(), "quotas", regionParam, serviceFilter)
 	var quotas []model.Quota
 
 	if cached, ok := h.cache.Get(cacheKey); ok {
@@ -56,70 +72,431 @@ func (h *Handler) ExportHTML(c *gin.Context) {
 		c.String(http.StatusBadRequest, "No data available. Please fetch quotas first.")
 		return
 	}
+	quotas = h.notes.Apply(quotas)
 
-	html := generateHTMLReport(quotas)
+	opts, err := exportFormat(c, format.Options{Locale: "en", ScaleUnits: true})
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	trends, err := report.Trends(c.Request.Context(), h.store, quotas)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	html := report.HTML(quotas, trends, opts)
 	filename := fmt.Sprintf("aws-quotas-%s.html", time.Now().Format("2006-01-02"))
 	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header(versionHeader, version.String())
 	c.Header("Content-Type", "text/html")
 	c.String(http.StatusOK, html)
 }
 
-func generateHTMLReport(quotas []model.Quota) string {
-	html := `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>AWS Quota Report</title>
-    <style>
-        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; margin: 20px; }
-        h1 { color: #232f3e; }
-        table { border-collapse: collapse; width: 100%; margin-top: 20px; }
-        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
-        th { background-color: #232f3e; color: white; }
-        tr:nth-child(even) { background-color: #f2f2f2; }
-        tr:hover { background-color: #ddd; }
-        .timestamp { color: #666; font-size: 0.9em; }
-    </style>
-</head>
-<body>
-    <h1>AWS Service Quotas Report</h1>
-    <p class="timestamp">Generated: ` + time.Now().Format("2006-01-02 15:04:05") + `</p>
-    <p>Total quotas: ` + fmt.Sprintf("%d", len(quotas)) + `</p>
-    <table>
-        <thead>
-            <tr>
-                <th>Region</th>
-                <th>Service</th>
-                <th>Quota Name</th>
-                <th>Value</th>
-                <th>Unit</th>
-                <th>Adjustable</th>
-            </tr>
-        </thead>
-        <tbody>`
+// ExportPDF exports the cached quotas as a PDF report with the columns,
+// trends and number formatting of the HTML report
+func (h *Handler) ExportPDF(c *gin.Context) {
+	regionParam := c.Query("region")
+	serviceFilter := c.Query("service")
+
+	cacheKey := h.cacheKey(c.Request.Context(), "quotas", regionParam, serviceFilter)
+	var quotas []model.Quota
+
+	if cached, ok := h.cache.Get(cacheKey); ok {
+		if quotas, ok = cached.([]model.Quota); !ok {
+			c.String(http.StatusInternalServerError, "Invalid cache data type")
+			return
+		}
+	} else {
+		c.String(http.StatusBadRequest, "No data available. Please fetch quotas first.")
+		return
+	}
+	quotas = h.notes.Apply(quotas)
+
+	opts, err := exportFormat(c, format.Options{Locale: "en", ScaleUnits: true})
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	trends, err := report.Trends(c.Request.Context(), h.store, quotas)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	pdf, err := report.PDF(quotas, trends, opts)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	filename := fmt.Sprintf("aws-quotas-%s.pdf", time.Now().Format("2006-01-02"))
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header(versionHeader, version.String())
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// exportTable is the quotas of a CSV or XLSX export in the selected columns
+type exportTable struct {
+	columns []csvColumn
+	records [][]string
+}
+
+// loadExportTable reads the cached quotas and lays them out in the columns
+// and number format of the request. On failure the error response is
+// written and false returned.
+func (h *Handler) loadExportTable(c *gin.Context) (exportTable, bool) {
+	regionParam := c.Query("region")
+	serviceFilter := c.Query("service")
+
+	cacheKey := h.cacheKey(c.Request.Context(), "quotas", regionParam, serviceFilter)
+	var quotas []model.Quota
+
+	if cached, ok := h.cache.Get(cacheKey); ok {
+		if quotas, ok = cached.([]model.Quota); !ok {
+			c.String(http.StatusInternalServerError, "Invalid cache data type")
+			return exportTable{}, false
+		}
+	} else {
+		c.String(http.StatusBadRequest, "No data available. Please fetch quotas first.")
+		return exportTable{}, false
+	}
+	quotas = h.notes.Apply(quotas)
+	if h.thresholds != nil {
+		quotas = h.thresholds.Apply(quotas)
+	}
+
+	opts, err := exportFormat(c, format.Options{})
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return exportTable{}, false
+	}
+	columns, err := parseCSVColumns(c.Query("columns"))
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return exportTable{}, false
+	}
 
+	var trends map[store.QuotaKey]report.Trend
+	for _, col := range columns {
+		if col.trend {
+			if trends, err = report.Trends(c.Request.Context(), h.store, quotas); err != nil {
+				c.String(http.StatusInternalServerError, err.Error())
+				return exportTable{}, false
+			}
+			break
+		}
+	}
+
+	table := exportTable{columns: columns, records: make([][]string, 0, len(quotas))}
 	for _, q := range quotas {
-		adjustable := "No"
-		if q.Adjustable {
-			adjustable = "Yes"
+		trend, hasTrend := trends[store.KeyOf(q)]
+		row := csvRow{quota: q, opts: opts, trend: trend, hasTrend: hasTrend}
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = col.value(row)
 		}
-		html += fmt.Sprintf(`
-            <tr>
-                <td>%s</td>
-                <td>%s</td>
-                <td>%s</td>
-                <td>%.0f</td>
-                <td>%s</td>
-                <td>%s</td>
-            </tr>`, q.Region, q.ServiceName, q.QuotaName, q.Value, q.Unit, adjustable)
+		table.records = append(table.records, record)
+	}
+	return table, true
+}
+
+// header returns the column headers of a table
+func (t exportTable) header() []string {
+	header := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		header[i] = col.header
+	}
+	return header
+}
+
+// ExportCSV exports the cached quotas as CSV. Numbers are plain by default;
+// pass locale, compact or units=auto for a human-readable sheet.
+func (h *Handler) ExportCSV(c *gin.Context) {
+	table, ok := h.loadExportTable(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(table.header()); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := w.WriteAll(table.records); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	html += `
-        </tbody>
-    </table>
-</body>
-</html>`
+	filename := fmt.Sprintf("aws-quotas-%s.csv", time.Now().Format("2006-01-02"))
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header(versionHeader, version.String())
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
 
-	return html
+// ExportXLSX exports the cached quotas as an Excel workbook with the columns
+// and number format of the CSV export. Numeric columns are number cells
+// unless a locale or compact numbers make them text.
+func (h *Handler) ExportXLSX(c *gin.Context) {
+	table, ok := h.loadExportTable(c)
+	if !ok {
+		return
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := "Quotas"
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	header := make([]interface{}, len(table.columns))
+	for i, title := range table.header() {
+		header[i] = title
+	}
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	for r, record := range table.records {
+		row := make([]interface{}, len(record))
+		for i, value := range record {
+			row[i] = value
+			if table.columns[i].numeric {
+				if n, err := strconv.ParseFloat(value, 64); err == nil {
+					row[i] = n
+				}
+			}
+		}
+		cell, err := excelize.CoordinatesToCellName(1, r+2)
+		if err == nil {
+			err = f.SetSheetRow(sheet, cell, &row)
+		}
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	filename := fmt.Sprintf("aws-quotas-%s.xlsx", time.Now().Format("2006-01-02"))
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header(versionHeader, version.String())
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+}
+
+// exportFormat reads the number formatting options of an export request:
+// locale (e.g. en, de-DE), compact=true and units=auto|raw
+func exportFormat(c *gin.Context, defaults format.Options) (format.Options, error) {
+	opts := defaults
+	if locale, ok := c.GetQuery("locale"); ok {
+		parsed, err := format.ParseLocale(locale)
+		if err != nil {
+			return opts, err
+		}
+		opts.Locale = parsed
+	}
+	if compact := c.Query("compact"); compact != "" {
+		v, err := strconv.ParseBool(compact)
+		if err != nil {
+			return opts, fmt.Errorf("invalid compact value %q", compact)
+		}
+		opts.Compact = v
+	}
+	switch units := c.Query("units"); units {
+	case "":
+	case "auto":
+		opts.ScaleUnits = true
+	case "raw":
+		opts.ScaleUnits = false
+	default:
+		return opts, fmt.Errorf("units must be auto or raw, got %q", units)
+	}
+	return opts, nil
+}
+
+// quotaCodePattern matches Service Quotas quota codes
+var quotaCodePattern = regexp.MustCompile(`^L-[0-9A-F]{8}$`)
+
+// hostPattern matches a host name or IP address with an optional port
+var hostPattern = regexp.MustCompile(`^(?:[A-Za-z0-9.-]+|\[[0-9A-Fa-f:.]+\])(?::[0-9]{1,5})?$`)
+
+// ExportSnippets generates ready-to-run curl, Python and Go snippets that query
+// this dashboard's API for a single quota. Users copy and run them, so every
+// value is validated and quoted for its target language.
+func (h *Handler) ExportSnippets(c *gin.Context) {
+	quotaCode := c.Query("quota_code")
+	if !quotaCodePattern.MatchString(quotaCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quota_code must be a quota code such as L-1216C47A"})
+		return
+	}
+	base, err := h.requestBaseURL(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := url.Values{}
+	if region := c.Query("region"); region != "" {
+		params.Set("region", region)
+	}
+	if service := c.Query("service"); service != "" {
+		params.Set("service", service)
+	}
+	base.Path = "/api/quotas"
+	base.RawQuery = params.Encode()
+	endpoint := base.String()
+
+	jqFilter := fmt.Sprintf(".quotas[] | select(.quota_code == %s)", jsonQuote(quotaCode))
+	snippets := map[string]string{
+		"curl":   fmt.Sprintf(curlSnippet, shellQuote(endpoint), shellQuote(jqFilter)),
+		"python": fmt.Sprintf(pythonSnippet, jsonQuote(endpoint), jsonQuote(quotaCode)),
+		"go":     fmt.Sprintf(goSnippet, strconv.Quote(endpoint), strconv.Quote(quotaCode)),
+	}
+
+	if lang := c.Query("lang"); lang != "" {
+		snippet, ok := snippets[lang]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lang must be one of curl, python, go"})
+			return
+		}
+		c.String(http.StatusOK, snippet)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quota_code": quotaCode,
+		"endpoint":   endpoint,
+		"snippets":   snippets,
+	})
+}
+
+// requestBaseURL reconstructs the externally visible base URL of the request.
+// X-Forwarded-Proto is only honored from trusted proxies.
+func (h *Handler) requestBaseURL(c *gin.Context) (*url.URL, error) {
+	if !hostPattern.MatchString(c.Request.Host) {
+		return nil, fmt.Errorf("invalid Host header %q", c.Request.Host)
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if h.trustedProxy(c) {
+		proto, _, _ := strings.Cut(c.GetHeader("X-Forwarded-Proto"), ",")
+		if proto = strings.ToLower(strings.TrimSpace(proto)); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+	}
+	return &url.URL{Scheme: scheme, Host: c.Request.Host}, nil
+}
+
+// SetTrustedProxies sets the addresses (IPs or CIDRs) of the reverse proxies
+// whose forwarding headers are trusted
+func (h *Handler) SetTrustedProxies(proxies []string) error {
+	h.proxies = nil
+	for _, p := range proxies {
+		if !strings.Contains(p, "/") {
+			if ip := net.ParseIP(p); ip != nil && ip.To4() != nil {
+				p += "/32"
+			} else {
+				p += "/128"
+			}
+		}
+		_, cidr, err := net.ParseCIDR(p)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+		}
+		h.proxies = append(h.proxies, cidr)
+	}
+	return nil
+}
+
+// trustedProxy reports whether the request came through a trusted proxy
+func (h *Handler) trustedProxy(c *gin.Context) bool {
+	ip := net.ParseIP(c.RemoteIP())
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range h.proxies {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes a value as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// jsonQuote quotes a value as a JSON string, which is also a valid jq and
+// Python string literal
+func jsonQuote(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+const curlSnippet = `curl -s %s \
+  | jq %s
+`
+
+const pythonSnippet = `import requests
+
+resp = requests.get(%s, timeout=300)
+resp.raise_for_status()
+for quota in resp.json()["quotas"]:
+    if quota["quota_code"] == %s:
+        print(f"{quota['region']}: {quota['usage']} / {quota['value']} ({quota['usage_percentage']:.1f}%%)")
+`
+
+const goSnippet = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type quota struct {
+	Region          string  ` + "`json:\"region\"`" + `
+	QuotaCode       string  ` + "`json:\"quota_code\"`" + `
+	Value           float64 ` + "`json:\"value\"`" + `
+	Usage           float64 ` + "`json:\"usage\"`" + `
+	UsagePercentage float64 ` + "`json:\"usage_percentage\"`" + `
+}
+
+func main() {
+	resp, err := http.Get(%s)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Quotas []quota ` + "`json:\"quotas\"`" + `
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		log.Fatal(err)
+	}
+	for _, q := range body.Quotas {
+		if q.QuotaCode == %s {
+			fmt.Printf("%%s: %%.0f / %%.0f (%%.1f%%%%)\n", q.Region, q.Usage, q.Value, q.UsagePercentage)
+		}
+	}
 }
+`
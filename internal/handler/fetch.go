@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// StartFetch loads the quotas of a region/service scope in the background and
+// returns the job to follow. The quotas land in the same cache as GetQuotas,
+// so clients read them from /api/quotas once the job has succeeded.
+func (h *Handler) StartFetch(c *gin.Context) {
+	regionParam := c.Query("region")
+	serviceFilter := c.Query("service")
+
+	job := h.fetchJobs.Create(regionParam, serviceFilter)
+	ctx := aws.WithFetchLog(withRunTrigger(context.WithoutCancel(c.Request.Context()), model.RunTriggerFetchJob), func(line model.FetchLogLine) {
+		h.fetchJobs.Append(job.ID, line)
+	})
+
+	go func() {
+		set, err := h.loadQuotas(ctx, regionParam, serviceFilter)
+		if err != nil {
+			h.fetchJobs.Finish(job.ID, 0, err)
+			return
+		}
+		if set.fromCache {
+			h.fetchJobs.Append(job.ID, model.FetchLogLine{
+				Time:    time.Now(),
+				Level:   model.LogLevelInfo,
+				Message: "Quotas served from cache",
+			})
+		}
+		h.fetchJobs.Finish(job.ID, len(set.quotas), nil)
+	}()
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetFetchJob returns the status of a fetch job
+func (h *Handler) GetFetchJob(c *gin.Context) {
+	job, ok := h.fetchJobs.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fetch job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// StreamFetchLogs streams the log lines of a fetch job as server-sent events.
+// Lines logged before the client connected are replayed first; a final "done"
+// event carries the job once it has finished.
+func (h *Handler) StreamFetchLogs(c *gin.Context) {
+	id := c.Param("id")
+	if _, ok := h.fetchJobs.Get(id); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fetch job not found"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	next := 0
+	for {
+		lines, done, changed, ok := h.fetchJobs.Lines(id, next)
+		if !ok {
+			return
+		}
+		for _, line := range lines {
+			c.SSEvent("log", line)
+		}
+		next += len(lines)
+		if done {
+			if job, ok := h.fetchJobs.Get(id); ok {
+				c.SSEvent("done", job)
+			}
+			c.Writer.Flush()
+			return
+		}
+		c.Writer.Flush()
+
+		select {
+		case <-changed:
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// GetRateStatus reports the adaptive request rate and throttling of every AWS
+// API called so far, most throttled first
+func (h *Handler) GetRateStatus(c *gin.Context) {
+	rates := h.fetcher.RateStats()
+	c.JSON(http.StatusOK, gin.H{
+		"rates": rates,
+		"total": len(rates),
+	})
+}
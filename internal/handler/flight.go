@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// flights shares one call per key between concurrent callers, like
+// singleflight, but cancels the call once every caller waiting on it has gone
+// away: a scan nobody waits for any more is abandoned instead of running on
+// for minutes. The fetch log lines of a call go to the fetch log of every
+// caller waiting on it.
+type flights struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	val     interface{}
+	err     error
+
+	// logs are the fetch logs of the callers, and lines those logged so far,
+	// replayed to callers that join later
+	logMu sync.Mutex
+	logs  []func(model.FetchLogLine)
+	lines []model.FetchLogLine
+}
+
+// follow sends the fetch log lines of the call, past and future, to the
+// fetch log of ctx, if any
+func (f *flight) follow(ctx context.Context) {
+	fn, ok := aws.FetchLog(ctx)
+	if !ok {
+		return
+	}
+	f.logMu.Lock()
+	defer f.logMu.Unlock()
+	for _, line := range f.lines {
+		fn(line)
+	}
+	f.logs = append(f.logs, fn)
+}
+
+// log fans a fetch log line of the call out to every caller following it
+func (f *flight) log(line model.FetchLogLine) {
+	f.logMu.Lock()
+	defer f.logMu.Unlock()
+	f.lines = append(f.lines, line)
+	for _, fn := range f.logs {
+		fn(line)
+	}
+}
+
+// Do runs fn once for all concurrent callers of a key and returns its result,
+// or the caller's context error when the caller goes away first. fn gets a
+// context detached from any single caller, cancelled once the last caller has
+// gone away. Callers whose context is never done keep the call running.
+func (g *flights) Do(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	f, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		callCtx = aws.WithFetchLog(callCtx, f.log)
+		g.calls[key] = f
+		go func() {
+			defer close(f.done)
+			defer cancel()
+			f.val, f.err = fn(callCtx)
+			g.forget(key, f)
+		}()
+	}
+	f.waiters++
+	f.follow(ctx)
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		f.waiters--
+		if f.waiters == 0 {
+			// Later callers must start a new call rather than join the
+			// cancelled one
+			g.remove(key, f)
+			f.cancel()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (g *flights) forget(key string, f *flight) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.remove(key, f)
+}
+
+// remove drops the call of a key unless a newer call replaced it; callers
+// hold mu
+func (g *flights) remove(key string, f *flight) {
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// Heatmap is the max usage percentage per region and service. Cells[i][j]
+// holds the value for Regions[i] and Services[j], or null when none of the
+// quotas in that combination reports usage.
+type Heatmap struct {
+	Regions  []string     `json:"regions"`
+	Services []string     `json:"services"`
+	Cells    [][]*float64 `json:"cells"`
+}
+
+// GetHeatmap returns the region × service utilization matrix of the current snapshot
+func (h *Handler) GetHeatmap(c *gin.Context) {
+	set, err := h.loadQuotas(c.Request.Context(), c.Query("region"), c.Query("service"))
+	if err != nil {
+		loadFailed(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"heatmap":    buildHeatmap(set.quotas),
+		"from_cache": set.fromCache,
+		"warnings":   set.warnings,
+	})
+}
+
+func buildHeatmap(quotas []model.Quota) Heatmap {
+	regionIndex := make(map[string]int)
+	serviceIndex := make(map[string]int)
+	var regions, services []string
+	for _, q := range quotas {
+		if _, ok := regionIndex[q.Region]; !ok {
+			regionIndex[q.Region] = 0
+			regions = append(regions, q.Region)
+		}
+		if _, ok := serviceIndex[q.ServiceCode]; !ok {
+			serviceIndex[q.ServiceCode] = 0
+			services = append(services, q.ServiceCode)
+		}
+	}
+	sort.Strings(regions)
+	sort.Strings(services)
+	for i, r := range regions {
+		regionIndex[r] = i
+	}
+	for j, s := range services {
+		serviceIndex[s] = j
+	}
+
+	cells := make([][]*float64, len(regions))
+	for i := range cells {
+		cells[i] = make([]*float64, len(services))
+	}
+	for _, q := range quotas {
+		if !q.HasUsageMetrics {
+			continue
+		}
+		cell := &cells[regionIndex[q.Region]][serviceIndex[q.ServiceCode]]
+		if *cell == nil || q.UsagePercentage > **cell {
+			pct := q.UsagePercentage
+			*cell = &pct
+		}
+	}
+
+	return Heatmap{Regions: regions, Services: services, Cells: cells}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+// defaultHistoryWindow is used when no since parameter is given
+const defaultHistoryWindow = 7 * 24 * time.Hour
+
+// GetHistory returns the recorded observations of one quota. With
+// resolution=hourly or daily the points are downsampled server-side into
+// buckets carrying both the maximum and the average per bucket.
+func (h *Handler) GetHistory(c *gin.Context) {
+	key := store.QuotaKey{
+		AccountID:   c.Query("account"),
+		Region:      c.Query("region"),
+		ServiceCode: c.Query("service"),
+		QuotaCode:   c.Query("quota_code"),
+	}
+	if key.AccountID == "" {
+		key.AccountID = h.accountID(c.Request.Context())
+	}
+	if key.Region == "" || key.ServiceCode == "" || key.QuotaCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "region, service and quota_code are required"})
+		return
+	}
+
+	now := time.Now()
+	since, err := parseTimeParam(c.Query("since"), now.Add(-defaultHistoryWindow), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since: " + err.Error()})
+		return
+	}
+	until, err := parseTimeParam(c.Query("until"), now, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until: " + err.Error()})
+		return
+	}
+
+	points, err := h.store.History(c.Request.Context(), key, since, until)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	retired, err := h.store.Retired(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var retiredAt *time.Time
+	if at, ok := retired[key]; ok {
+		retiredAt = &at
+	}
+
+	resolution := c.DefaultQuery("resolution", store.ResolutionRaw)
+	if resolution == store.ResolutionRaw {
+		c.JSON(http.StatusOK, gin.H{
+			"quota":      key,
+			"resolution": resolution,
+			"since":      since,
+			"until":      until,
+			"retired_at": retiredAt,
+			"points":     points,
+		})
+		return
+	}
+
+	size, err := store.BucketSize(resolution)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"quota":      key,
+		"resolution": resolution,
+		"since":      since,
+		"until":      until,
+		"retired_at": retiredAt,
+		"points":     store.Downsample(points, size),
+	})
+}
+
+// GetRetiredQuotas lists the quotas whose history has been retired because
+// they vanished from complete fetches
+func (h *Handler) GetRetiredQuotas(c *gin.Context) {
+	retired, err := h.store.Retired(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	type retiredQuota struct {
+		store.QuotaKey
+		RetiredAt time.Time `json:"retired_at"`
+	}
+	quotas := make([]retiredQuota, 0, len(retired))
+	for key, at := range retired {
+		quotas = append(quotas, retiredQuota{QuotaKey: key, RetiredAt: at})
+	}
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].RetiredAt.After(quotas[j].RetiredAt) })
+
+	c.JSON(http.StatusOK, gin.H{
+		"quotas": quotas,
+		"total":  len(quotas),
+	})
+}
+
+// parseTimeParam accepts an RFC 3339 timestamp or a duration relative to now
+// such as "24h" or "30d", returning def when the value is empty
+func parseTimeParam(value string, def, now time.Time) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		if _, err := fmt.Sscanf(days, "%d", &n); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * 24 * time.Hour), nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 timestamp or duration, got %q", value)
+	}
+	return now.Add(-d), nil
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/increase"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+type increaseRequestBody struct {
+	Region        string            `json:"region" binding:"required"`
+	ServiceCode   string            `json:"service_code" binding:"required"`
+	QuotaCode     string            `json:"quota_code" binding:"required"`
+	DesiredValue  float64           `json:"desired_value" binding:"required,gt=0"`
+	Template      string            `json:"template"`
+	GrowthRate    float64           `json:"growth_rate"`
+	Variables     map[string]string `json:"variables"`
+	Justification string            `json:"justification"`
+	RequestedBy   string            `json:"requested_by"`
+}
+
+func (h *Handler) GetJustificationTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"templates": h.templates.List(),
+	})
+}
+
+// RenderJustification previews the justification text for an increase request
+// without submitting it
+func (h *Handler) RenderJustification(c *gin.Context) {
+	var body increaseRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	justification, err := h.renderJustification(c, &body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"template":      body.Template,
+		"justification": justification,
+	})
+}
+
+// largeIncreaseFactor flags requests raising a quota more than tenfold, which
+// AWS tends to question
+const largeIncreaseFactor = 10
+
+// increasePreview is the expected impact of an increase request. Headroom is
+// the limit minus usage; utilization is usage as a percentage of the limit.
+type increasePreview struct {
+	Region               string   `json:"region"`
+	ServiceCode          string   `json:"service_code"`
+	QuotaCode            string   `json:"quota_code"`
+	QuotaName            string   `json:"quota_name"`
+	Adjustable           bool     `json:"adjustable"`
+	Global               bool     `json:"global"`
+	DefaultValue         *float64 `json:"default_value,omitempty"`
+	CurrentValue         float64  `json:"current_value"`
+	DesiredValue         float64  `json:"desired_value"`
+	HasUsageMetrics      bool     `json:"has_usage_metrics"`
+	Usage                float64  `json:"usage"`
+	Utilization          float64  `json:"utilization"`
+	ProjectedUtilization float64  `json:"projected_utilization"`
+	Headroom             float64  `json:"headroom"`
+	ProjectedHeadroom    float64  `json:"projected_headroom"`
+	Justification        string   `json:"justification"`
+	Warnings             []string `json:"warnings"`
+}
+
+// PreviewIncrease is a dry run of an increase request: it reports the
+// current and projected utilization and headroom, whether the quota is
+// adjustable, its default value and the justification, without submitting
+func (h *Handler) PreviewIncrease(c *gin.Context) {
+	var body increaseRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.previewIncrease(c, &body)
+}
+
+func (h *Handler) previewIncrease(c *gin.Context, body *increaseRequestBody) {
+	ctx := c.Request.Context()
+	quota, err := h.fetcher.GetQuota(ctx, body.Region, body.ServiceCode, body.QuotaCode)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	justification := body.Justification
+	if justification == "" {
+		if justification, err = h.renderTemplate(quota, body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	preview := increasePreview{
+		Region:          body.Region,
+		ServiceCode:     body.ServiceCode,
+		QuotaCode:       body.QuotaCode,
+		QuotaName:       quota.QuotaName,
+		Adjustable:      quota.Adjustable,
+		Global:          quota.Global,
+		CurrentValue:    quota.Value,
+		DesiredValue:    body.DesiredValue,
+		HasUsageMetrics: quota.HasUsageMetrics,
+		Justification:   justification,
+		Warnings:        []string{},
+	}
+	if quota.HasUsageMetrics {
+		preview.Usage = quota.Usage
+		preview.Utilization = quota.UsagePercentage
+		preview.ProjectedUtilization = quota.Usage / body.DesiredValue * 100
+		preview.Headroom = quota.Value - quota.Usage
+		preview.ProjectedHeadroom = body.DesiredValue - quota.Usage
+	} else {
+		preview.Warnings = append(preview.Warnings, "no usage metrics; utilization and headroom are unknown")
+	}
+
+	if def, err := h.fetcher.GetDefaultQuotaValue(ctx, body.Region, body.ServiceCode, body.QuotaCode); err != nil {
+		preview.Warnings = append(preview.Warnings, "default value unavailable: "+err.Error())
+	} else {
+		preview.DefaultValue = &def
+	}
+	if !quota.Adjustable {
+		preview.Warnings = append(preview.Warnings, "quota is not adjustable through Service Quotas")
+	}
+	if body.DesiredValue <= quota.Value {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("desired value %g is not above the current value %g", body.DesiredValue, quota.Value))
+	} else if quota.Value > 0 && body.DesiredValue > quota.Value*largeIncreaseFactor {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("desired value is more than %dx the current value and may need a stronger justification", largeIncreaseFactor))
+	}
+	for _, req := range h.increases.Undecided() {
+		if req.Region == body.Region && req.ServiceCode == body.ServiceCode && req.QuotaCode == body.QuotaCode {
+			preview.Warnings = append(preview.Warnings, fmt.Sprintf("request %s for %g is awaiting a decision", req.ID, req.DesiredValue))
+		}
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// SubmitIncreaseRequest submits an increase request, or with dry_run=true
+// returns its preview instead
+func (h *Handler) SubmitIncreaseRequest(c *gin.Context) {
+	var body increaseRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if c.Query("dry_run") == "true" {
+		h.previewIncrease(c, &body)
+		return
+	}
+
+	justification, err := h.renderJustification(c, &body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req, err := h.fetcher.RequestQuotaIncrease(c.Request.Context(), body.Region, body.ServiceCode, body.QuotaCode, body.DesiredValue)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	req.Template = body.Template
+	req.Justification = justification
+	if req.CreatedAt.IsZero() {
+		req.CreatedAt = time.Now()
+	}
+	h.increases.Add(*req)
+
+	c.JSON(http.StatusCreated, req)
+}
+
+func (h *Handler) GetIncreaseRequests(c *gin.Context) {
+	requests := h.increases.List()
+	c.JSON(http.StatusOK, gin.H{
+		"requests": requests,
+		"total":    len(requests),
+	})
+}
+
+// renderJustification returns the explicit justification from the request body
+// or renders the selected template against the quota's current value and usage
+func (h *Handler) renderJustification(c *gin.Context, body *increaseRequestBody) (string, error) {
+	if body.Justification != "" {
+		return body.Justification, nil
+	}
+
+	quota, err := h.fetcher.GetQuota(c.Request.Context(), body.Region, body.ServiceCode, body.QuotaCode)
+	if err != nil {
+		return "", err
+	}
+	return h.renderTemplate(quota, body)
+}
+
+func (h *Handler) renderTemplate(quota *model.Quota, body *increaseRequestBody) (string, error) {
+	if body.Template == "" {
+		body.Template = h.templates.List()[0].Name
+	}
+	data := increase.NewTemplateData(quota, body.DesiredValue, body.GrowthRate, body.Variables)
+	return h.templates.Render(body.Template, data)
+}
+
+// ProposeIncrease records a quota increase for approval instead of submitting
+// it. When Slack is configured the proposal is posted with Approve and Reject
+// buttons; approving it submits the request.
+func (h *Handler) ProposeIncrease(c *gin.Context) {
+	var body increaseRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota, err := h.fetcher.GetQuota(c.Request.Context(), body.Region, body.ServiceCode, body.QuotaCode)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	justification := body.Justification
+	if justification == "" {
+		if justification, err = h.renderTemplate(quota, &body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	proposal := h.proposals.Add(model.IncreaseProposal{
+		Region:        body.Region,
+		ServiceCode:   body.ServiceCode,
+		QuotaCode:     body.QuotaCode,
+		QuotaName:     quota.QuotaName,
+		CurrentValue:  quota.Value,
+		DesiredValue:  body.DesiredValue,
+		Template:      body.Template,
+		Justification: justification,
+		RequestedBy:   body.RequestedBy,
+	})
+
+	if h.slack != nil {
+		if err := h.slack.Post(c.Request.Context(), proposalMessage(proposal)); err != nil {
+			log.Printf("Failed to post increase proposal %s to Slack: %v", proposal.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, proposal)
+}
+
+func (h *Handler) GetIncreaseProposals(c *gin.Context) {
+	proposals := h.proposals.List()
+	c.JSON(http.StatusOK, gin.H{
+		"proposals": proposals,
+		"total":     len(proposals),
+	})
+}
+
+// submitProposal submits an approved proposal and tracks the resulting request
+func (h *Handler) submitProposal(ctx context.Context, proposal model.IncreaseProposal) model.IncreaseProposal {
+	req, err := h.fetcher.RequestQuotaIncrease(ctx, proposal.Region, proposal.ServiceCode, proposal.QuotaCode, proposal.DesiredValue)
+	if err == nil {
+		req.Template = proposal.Template
+		req.Justification = proposal.Justification
+		if req.CreatedAt.IsZero() {
+			req.CreatedAt = time.Now()
+		}
+		h.increases.Add(*req)
+	}
+	return h.proposals.Complete(proposal.ID, req, err)
+}
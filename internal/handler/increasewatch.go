@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/increase"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+)
+
+// increaseDecisionEvent is the payload sent when Service Quotas decides on an
+// increase request
+type increaseDecisionEvent struct {
+	Event        string    `json:"event"`
+	Timestamp    time.Time `json:"timestamp"`
+	Region       string    `json:"region"`
+	ServiceCode  string    `json:"service_code"`
+	QuotaCode    string    `json:"quota_code"`
+	QuotaName    string    `json:"quota_name"`
+	RequestID    string    `json:"request_id"`
+	CaseID       string    `json:"case_id,omitempty"`
+	Status       string    `json:"status"`
+	DesiredValue float64   `json:"desired_value"`
+}
+
+// WatchIncreaseRequests polls the increase requests awaiting a decision: those
+// PENDING or CASE_OPENED in the Service Quotas request history of every
+// enabled region, which also holds requests made in the console or before a
+// restart, and those submitted through the dashboard. When one is approved,
+// denied or closed, the decision is sent to every configured notifier and the
+// cached quotas covering it are dropped, so the dashboard shows the new limit
+// on its next load.
+func (h *Handler) WatchIncreaseRequests(ctx context.Context) {
+	regions, _, err := h.scanRegions(ctx, "all")
+	if err != nil {
+		log.Printf("Failed to list regions for increase requests: %v", err)
+		return
+	}
+	// Requests of regions whose history cannot be read are kept as they
+	// are, as they cannot be told apart from decided ones
+	open := make(map[string]model.IncreaseRequest)
+	unread := make(map[string]bool)
+	for _, region := range regions {
+		for _, status := range []string{increase.StatusPending, increase.StatusCaseOpened} {
+			requests, err := h.fetcher.ListIncreaseRequests(ctx, region, status)
+			if err != nil {
+				log.Printf("Failed to list %s increase requests in %s: %v", status, region, err)
+				unread[region] = true
+				continue
+			}
+			for _, req := range requests {
+				open[req.ID] = req
+			}
+		}
+	}
+
+	h.watchMu.Lock()
+	defer h.watchMu.Unlock()
+	candidates := h.increases.Undecided()
+	for _, req := range h.watched {
+		candidates = append(candidates, req)
+	}
+	watched := make(map[string]model.IncreaseRequest, len(open))
+	for id, req := range open {
+		watched[id] = req
+	}
+	decided := make(map[string]bool)
+	for _, req := range candidates {
+		if _, ok := open[req.ID]; ok || req.ID == "" || decided[req.ID] {
+			continue
+		}
+		if unread[req.Region] {
+			watched[req.ID] = req
+			continue
+		}
+		latest, err := h.fetcher.GetIncreaseRequest(ctx, req.Region, req.ID)
+		if err != nil {
+			log.Printf("Failed to check increase request %s: %v", req.ID, err)
+			watched[req.ID] = req
+			continue
+		}
+		if increase.Undecided(latest.Status) {
+			watched[req.ID] = req
+			continue
+		}
+		decided[req.ID] = true
+		delete(watched, req.ID)
+
+		now := time.Now()
+		updated, tracked := h.increases.SetStatus(req.ID, latest.Status, now)
+		if !tracked {
+			updated = req
+			updated.Status = latest.Status
+			updated.ResolvedAt = &now
+		}
+		removed := h.invalidateQuota(updated.ServiceCode, updated.QuotaCode)
+		log.Printf("Increase request %s is %s, dropped %d cached quota sets", updated.ID, updated.Status, removed)
+		h.notifyIncreaseDecision(ctx, updated)
+	}
+	h.watched = watched
+}
+
+// notifyIncreaseDecision sends the decision on an increase request to Slack
+// (or the log without it), Teams, the email recipients of its service and
+// region, the webhooks and the SNS topic
+func (h *Handler) notifyIncreaseDecision(ctx context.Context, req model.IncreaseRequest) {
+	msg := increaseDecisionMessage(req)
+	if h.slack == nil {
+		log.Printf("Increase request decided: %s", msg.Text)
+	} else if err := h.slack.Post(ctx, msg); err != nil {
+		log.Printf("Failed to notify decision of increase request %s: %v", req.ID, err)
+	}
+	if h.teams != nil {
+		if err := h.teams.Post(ctx, increaseDecisionCard(req, msg.Text)); err != nil {
+			log.Printf("Failed to post decision of increase request %s to Teams: %v", req.ID, err)
+		}
+	}
+	for _, r := range h.email.Recipients {
+		if !matchesAny(r.Services, req.ServiceCode) || !matchesAny(r.Regions, req.Region) {
+			continue
+		}
+		body := msg.Text
+		if h.email.DashboardURL != "" {
+			body += "\n\n" + h.email.DashboardURL
+		}
+		if err := aws.SendEmail(ctx, h.emailRegion, h.email.From, []string{r.Address}, "[AWS quotas] "+msg.Text,
+			"<p>"+html.EscapeString(msg.Text)+"</p>", body); err != nil {
+			log.Printf("Failed to email decision of increase request %s: %v", req.ID, err)
+		}
+	}
+
+	e := increaseDecisionEvent{
+		Event:        notify.EventIncreaseDecided,
+		Timestamp:    time.Now(),
+		Region:       req.Region,
+		ServiceCode:  req.ServiceCode,
+		QuotaCode:    req.QuotaCode,
+		QuotaName:    req.QuotaName,
+		RequestID:    req.ID,
+		CaseID:       req.CaseID,
+		Status:       req.Status,
+		DesiredValue: req.DesiredValue,
+	}
+	if req.ResolvedAt != nil {
+		e.Timestamp = *req.ResolvedAt
+	}
+	for _, w := range h.webhooks {
+		if err := w.Post(ctx, e); err != nil {
+			log.Printf("Failed to post decision of increase request %s: %v", req.ID, err)
+		}
+	}
+	if h.publishes(e.Event) {
+		h.publish(ctx, e.Event, msg.Text, e, map[string]string{
+			"region":       e.Region,
+			"service_code": e.ServiceCode,
+			"quota_code":   e.QuotaCode,
+		})
+	}
+}
+
+// invalidateQuota drops the cached quota sets that may hold a quota: those of
+// its service, of all services and code lists naming it, in every region
+func (h *Handler) invalidateQuota(serviceCode, quotaCode string) int {
+	return h.cache.DeleteFunc(func(key string) bool {
+		_, rest, ok := strings.Cut(key, ":quotas:")
+		if !ok {
+			return false
+		}
+		// rest is region:service[:disabled] or region:codes:svc/code,...
+		parts := strings.SplitN(rest, ":", 3)
+		if len(parts) < 2 {
+			return false
+		}
+		switch parts[1] {
+		case "", serviceCode:
+			return true
+		case "codes":
+			return len(parts) == 3 && strings.Contains(","+parts[2]+",", ","+serviceCode+"/"+quotaCode+",")
+		}
+		return false
+	})
+}
+
+// increaseDecisionMessage renders the decision on an increase request as a
+// Slack message
+func increaseDecisionMessage(req model.IncreaseRequest) notify.Message {
+	var outcome string
+	switch req.Status {
+	case increase.StatusApproved:
+		outcome = fmt.Sprintf("was approved, the new limit is %g", req.DesiredValue)
+	case "CASE_CLOSED":
+		outcome = "was closed"
+	case "INVALID_REQUEST":
+		outcome = "was rejected as invalid"
+	default:
+		outcome = "was " + strings.ToLower(strings.ReplaceAll(req.Status, "_", " "))
+	}
+	summary := fmt.Sprintf("Quota increase request for %s (%s) in %s %s",
+		req.QuotaName, req.QuotaCode, req.Region, outcome)
+
+	fields := []notify.Text{
+		{Type: "mrkdwn", Text: "*Service*\n" + req.ServiceCode},
+		{Type: "mrkdwn", Text: "*Request*\n" + req.ID},
+	}
+	if req.CaseID != "" {
+		fields = append(fields, notify.Text{Type: "mrkdwn", Text: "*Support case*\n" + req.CaseID})
+	}
+
+	return notify.Message{
+		Text: summary,
+		Blocks: []notify.Block{
+			{Type: "section", Text: notify.Markdown("*" + summary + "*")},
+			{Type: "section", Fields: fields},
+		},
+	}
+}
+
+// increaseDecisionCard renders the decision on an increase request as a Teams
+// connector card
+func increaseDecisionCard(req model.IncreaseRequest, summary string) notify.Card {
+	color := teamsCriticalColor
+	if req.Status == increase.StatusApproved {
+		color = teamsResolvedColor
+	}
+	card := notify.NewCard(summary, summary, color)
+	facts := []notify.Fact{
+		{Name: "Service", Value: req.ServiceCode},
+		{Name: "Request", Value: req.ID},
+	}
+	if req.CaseID != "" {
+		facts = append(facts, notify.Fact{Name: "Support case", Value: req.CaseID})
+	}
+	card.Sections = []notify.CardSection{{Facts: facts}}
+	return card
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/alert"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+)
+
+// SetMonitor enables the meta alerts about scheduled scans
+func (h *Handler) SetMonitor(monitor *alert.Monitor) {
+	h.monitor = monitor
+}
+
+// NotifyMetaAlerts posts meta alerts to Slack, or logs them when Slack is not
+// configured
+func (h *Handler) NotifyMetaAlerts(ctx context.Context, alerts []alert.MetaAlert) {
+	for _, a := range alerts {
+		if h.slack == nil {
+			log.Printf("Meta alert [%s]: %s", a.Kind, a.Message)
+			continue
+		}
+		msg := notify.Message{
+			Text:   "Quota dashboard: " + a.Message,
+			Blocks: []notify.Block{{Type: "section", Text: notify.Markdown("*Quota dashboard* `" + a.Kind + "`\n" + a.Message)}},
+		}
+		if err := h.slack.Post(ctx, msg); err != nil {
+			log.Printf("Failed to send meta alert %s: %v", a.Kind, err)
+		}
+	}
+}
+
+// GetMetaAlerts lists the meta alerts raised about scheduled scans, newest
+// first
+func (h *Handler) GetMetaAlerts(c *gin.Context) {
+	if h.monitor == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Org scan mode is not enabled"})
+		return
+	}
+	alerts := h.monitor.Recent()
+	c.JSON(http.StatusOK, gin.H{
+		"alerts": alerts,
+		"total":  len(alerts),
+	})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+)
+
+// unknownIdentity stands in for the account and principal when the caller
+// identity cannot be resolved. Cache keys and history keys both use it, so
+// data fetched without an identity is kept apart from every resolved account
+// but not split in two.
+const unknownIdentity = "unknown"
+
+// identity returns the account and principal of the handler's credentials
+func (h *Handler) identity(ctx context.Context) aws.Identity {
+	if h.fetcher == nil {
+		return aws.Identity{}
+	}
+	id, err := h.fetcher.Identity(ctx)
+	if err != nil {
+		log.Printf("Failed to resolve caller identity: %v", err)
+		return aws.Identity{}
+	}
+	return id
+}
+
+// accountID returns the account the handler's credentials belong to, or
+// unknownIdentity when it cannot be resolved
+func (h *Handler) accountID(ctx context.Context) string {
+	if account := h.identity(ctx).AccountID; account != "" {
+		return account
+	}
+	return unknownIdentity
+}
+
+// cacheKey builds a cache key namespaced by the account and principal of the
+// handler's credentials, so data cached for one account or role is never
+// served to another
+func (h *Handler) cacheKey(ctx context.Context, parts ...string) string {
+	id := h.identity(ctx)
+	account, principal := id.AccountID, id.Principal()
+	if account == "" {
+		account = unknownIdentity
+	}
+	if principal == "" {
+		principal = unknownIdentity
+	}
+	return "acct:" + account + ":" + principal + ":" + strings.Join(parts, ":")
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+func (h *Handler) GetOrgAccounts(c *gin.Context) {
+	if h.orgScanner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Org scan mode is not enabled"})
+		return
+	}
+
+	inventory := h.orgScanner.Inventory()
+	if inventory == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":    "Org inventory is not available yet",
+			"scanning": h.orgScanner.Scanning(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"accounts":     inventory.Accounts,
+		"completed_at": inventory.CompletedAt,
+		"scanning":     h.orgScanner.Scanning(),
+	})
+}
+
+func (h *Handler) GetOrgQuotas(c *gin.Context) {
+	if h.orgScanner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Org scan mode is not enabled"})
+		return
+	}
+
+	// Serve the last completed inventory; before the first scan completes (or
+	// when asked explicitly) serve the partial results of the scan in progress
+	inventory := h.orgScanner.Inventory()
+	partial := false
+	if inventory == nil || c.Query("partial") == "true" {
+		if p := h.orgScanner.Partial(); p != nil {
+			inventory = p
+			partial = true
+		}
+	}
+	if inventory == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":    "Org inventory is not available yet",
+			"scanning": h.orgScanner.Scanning(),
+		})
+		return
+	}
+
+	account := c.Query("account")
+	region := c.Query("region")
+	service := c.Query("service")
+
+	quotas := make([]model.Quota, 0, len(inventory.Quotas))
+	for _, q := range inventory.Quotas {
+		if account != "" && q.AccountID != account {
+			continue
+		}
+		if region != "" && region != "all" && q.Region != region {
+			continue
+		}
+		if service != "" && !strings.EqualFold(q.ServiceCode, service) {
+			continue
+		}
+		quotas = append(quotas, q)
+	}
+
+	if search := c.Query("search"); search != "" {
+		quotas = searchQuotas(quotas, search)
+	}
+	quotas = h.notes.Apply(h.withPeaks(c.Request.Context(), quotas))
+	quotas, err := h.withStatus(quotas, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.QuotaResponse{
+		Quotas:    quotas,
+		Total:     len(quotas),
+		FetchedAt: inventory.CompletedAt,
+		FromCache: true,
+		Partial:   partial,
+		Warnings:  inventory.Warnings,
+	})
+}
+
+func (h *Handler) TriggerOrgScan(c *gin.Context) {
+	if h.orgScanner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Org scan mode is not enabled"})
+		return
+	}
+	if h.orgScanner.Scanning() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Org scan already in progress"})
+		return
+	}
+
+	go func() {
+		if err := h.orgScanner.Scan(context.Background()); err != nil {
+			log.Printf("Org scan failed: %v", err)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Org scan started",
+	})
+}
+
+// GetAccountStatuses reports the per-account fetch status (ok, denied,
+// throttled, error) of the current or last org scan
+func (h *Handler) GetAccountStatuses(c *gin.Context) {
+	if h.orgScanner == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Org scan mode is not enabled"})
+		return
+	}
+
+	statuses := h.orgScanner.AccountStatuses()
+	summary := make(map[string]int)
+	for _, st := range statuses {
+		summary[st.Status]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"accounts": statuses,
+		"summary":  summary,
+		"scanning": h.orgScanner.Scanning(),
+	})
+}
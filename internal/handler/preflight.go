@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/iac"
+)
+
+// maxTerraformUpload bounds the size of uploaded state and plan files
+const maxTerraformUpload = 64 << 20
+
+type terraformImpact struct {
+	iac.Consumption
+	QuotaName                string  `json:"quota_name,omitempty"`
+	Value                    float64 `json:"value"`
+	Usage                    float64 `json:"usage"`
+	HasUsageMetrics          bool    `json:"has_usage_metrics"`
+	Headroom                 float64 `json:"headroom"`
+	ProjectedUsage           float64 `json:"projected_usage"`
+	ProjectedHeadroom        float64 `json:"projected_headroom"`
+	ProjectedUsagePercentage float64 `json:"projected_usage_percentage"`
+	Exceeds                  bool    `json:"exceeds"`
+	Error                    string  `json:"error,omitempty"`
+}
+
+// PreflightTerraform accepts a Terraform state file or plan JSON, either as the
+// raw request body or as a multipart "file" upload, maps its resources to the
+// quotas they consume and reports the headroom of each impacted quota in the
+// target region. For plan JSON the planned creates and deletes are applied on
+// top of current usage; state resources are assumed to already be in usage.
+func (h *Handler) PreflightTerraform(c *gin.Context) {
+	region := c.DefaultQuery("region", "us-east-1")
+
+	var reader io.Reader = http.MaxBytesReader(c.Writer, c.Request.Body, maxTerraformUpload)
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer f.Close()
+		reader = io.LimitReader(f, maxTerraformUpload)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := iac.Analyze(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	impacts := make([]terraformImpact, 0, len(result.Consumption))
+	exceeded := 0
+	for _, consumption := range result.Consumption {
+		impact := terraformImpact{Consumption: consumption}
+		quota, err := h.fetcher.GetQuota(c.Request.Context(), region, consumption.ServiceCode, consumption.QuotaCode)
+		if err != nil {
+			impact.Error = err.Error()
+			impacts = append(impacts, impact)
+			continue
+		}
+
+		impact.QuotaName = quota.QuotaName
+		impact.Value = quota.Value
+		impact.Usage = quota.Usage
+		impact.HasUsageMetrics = quota.HasUsageMetrics
+		impact.Headroom = quota.Value - quota.Usage
+		impact.ProjectedUsage = quota.Usage
+		if result.Kind == "plan" {
+			impact.ProjectedUsage += consumption.Units
+		}
+		impact.ProjectedHeadroom = quota.Value - impact.ProjectedUsage
+		if quota.Value > 0 {
+			impact.ProjectedUsagePercentage = impact.ProjectedUsage / quota.Value * 100
+		}
+		impact.Exceeds = impact.ProjectedHeadroom < 0
+		if impact.Exceeds {
+			exceeded++
+		}
+		impacts = append(impacts, impact)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"region":   region,
+		"kind":     result.Kind,
+		"impacts":  impacts,
+		"exceeded": exceeded,
+		"skipped":  result.Skipped,
+	})
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"golang.org/x/time/rate"
+)
+
+// proxyAuth authenticates proxy callers by bearer token and rate limits each
+// token separately
+type proxyAuth struct {
+	cfg      config.ProxyConfig
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// ProxyMiddleware returns the middleware guarding the Service Quotas proxy
+// endpoints: callers need one of the configured tokens and are limited to
+// requests_per_minute each
+func ProxyMiddleware(cfg config.ProxyConfig) gin.HandlerFunc {
+	auth := &proxyAuth{cfg: cfg, limiters: make(map[string]*rate.Limiter)}
+	return auth.handle
+}
+
+func (a *proxyAuth) handle(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || !a.validToken(token) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "a valid bearer token is required"})
+		return
+	}
+	if !a.limiter(token).Allow() {
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+		return
+	}
+	c.Next()
+}
+
+func (a *proxyAuth) validToken(token string) bool {
+	valid := false
+	for _, t := range a.cfg.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+func (a *proxyAuth) limiter(token string) *rate.Limiter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	l, ok := a.limiters[token]
+	if !ok {
+		perMinute := a.cfg.RequestsPerMinute
+		l = rate.NewLimiter(rate.Limit(float64(perMinute)/60), perMinute)
+		a.limiters[token] = l
+	}
+	return l
+}
+
+// proxyRegion returns the region parameter of a proxy request
+func (h *Handler) proxyRegion(c *gin.Context) string {
+	return c.DefaultQuery("region", h.defaultRegion)
+}
+
+// proxyCached serves a proxied read from the cache, calling fetch on a miss
+func (h *Handler) proxyCached(c *gin.Context, field string, fetch func() (interface{}, error)) {
+	cacheKey := h.cacheKey(c.Request.Context(), "proxy", c.Request.URL.Path, h.proxyRegion(c))
+	if cached, ok := h.cache.Get(cacheKey); ok {
+		c.JSON(http.StatusOK, gin.H{field: cached})
+		return
+	}
+
+	result, err := fetch()
+	if err != nil {
+		status := http.StatusBadGateway
+		switch aws.ClassifyError(err) {
+		case model.FetchStatusThrottled:
+			status = http.StatusTooManyRequests
+		case model.FetchStatusDenied:
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	h.cache.Set(cacheKey, result)
+	c.JSON(http.StatusOK, gin.H{field: result})
+}
+
+// ProxyListServices proxies ListServices
+func (h *Handler) ProxyListServices(c *gin.Context) {
+	h.proxyCached(c, "Services", func() (interface{}, error) {
+		return h.fetcher.ListServiceInfos(c.Request.Context(), h.proxyRegion(c))
+	})
+}
+
+// ProxyListServiceQuotas proxies ListServiceQuotas
+func (h *Handler) ProxyListServiceQuotas(c *gin.Context) {
+	h.proxyCached(c, "Quotas", func() (interface{}, error) {
+		return h.fetcher.ListServiceQuotas(c.Request.Context(), h.proxyRegion(c), c.Param("service"), false)
+	})
+}
+
+// ProxyListDefaultServiceQuotas proxies ListAWSDefaultServiceQuotas
+func (h *Handler) ProxyListDefaultServiceQuotas(c *gin.Context) {
+	h.proxyCached(c, "Quotas", func() (interface{}, error) {
+		return h.fetcher.ListServiceQuotas(c.Request.Context(), h.proxyRegion(c), c.Param("service"), true)
+	})
+}
+
+// ProxyGetServiceQuota proxies GetServiceQuota
+func (h *Handler) ProxyGetServiceQuota(c *gin.Context) {
+	h.proxyCached(c, "Quota", func() (interface{}, error) {
+		return h.fetcher.GetServiceQuota(c.Request.Context(), h.proxyRegion(c), c.Param("service"), c.Param("quota"))
+	})
+}
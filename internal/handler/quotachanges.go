@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/increase"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+// defaultQuotaChangesWindow is used when no since parameter is given
+const defaultQuotaChangesWindow = 30 * 24 * time.Hour
+
+// Causes of a limit change
+const (
+	// ChangeCauseAWS is a change no increase request explains, such as AWS
+	// raising a default limit
+	ChangeCauseAWS = "aws"
+	// ChangeCauseIncrease is a change explained by an increase request
+	// submitted through the dashboard
+	ChangeCauseIncrease = "increase_request"
+)
+
+// TrackLimitChanges compares quotas about to be recorded in the history with
+// their last observation, and records those whose limit moved in the change
+// feed of the store. It must run before the quotas are recorded.
+func (h *Handler) TrackLimitChanges(ctx context.Context, at time.Time, quotas []model.Quota) {
+	detected, err := store.DetectLimitChanges(ctx, h.store, at, quotas)
+	if err != nil {
+		log.Printf("Failed to detect quota limit changes: %v", err)
+		return
+	}
+	if len(detected) == 0 {
+		return
+	}
+
+	requests := h.increases.List()
+	var anomalies []store.LimitChange
+	for i, change := range detected {
+		detected[i].Cause = ChangeCauseAWS
+		if req, ok := explainingRequest(requests, change); ok {
+			detected[i].Cause, detected[i].RequestID = ChangeCauseIncrease, req.ID
+		} else {
+			anomalies = append(anomalies, detected[i])
+		}
+		log.Printf("Quota %s/%s in %s changed from %g to %g (%s)",
+			change.Key.ServiceCode, change.Key.QuotaCode, change.Key.Region, change.PreviousValue, change.Value, detected[i].Cause)
+	}
+	if err := h.store.RecordLimitChanges(ctx, detected); err != nil {
+		log.Printf("Failed to record quota limit changes: %v", err)
+	}
+	if len(anomalies) > 0 && h.publishes(notify.EventLimitAnomaly) {
+		go h.publishLimitAnomalies(context.WithoutCancel(ctx), anomalies)
+	}
+}
+
+// explainingRequest finds the tracked increase request behind a limit change:
+// one for the same quota and region that was resolved between the two
+// observations, approved or for the new value, or that was still open at
+// detection and asked for the new value, as the watcher may not have seen its
+// approval yet. Service Quotas does not report the account of a request, so
+// requests of any account match.
+func explainingRequest(requests []model.IncreaseRequest, change store.LimitChange) (model.IncreaseRequest, bool) {
+	for _, req := range requests {
+		if req.Region != change.Key.Region || req.ServiceCode != change.Key.ServiceCode || req.QuotaCode != change.Key.QuotaCode {
+			continue
+		}
+		if req.ResolvedAt == nil || req.ResolvedAt.After(change.DetectedAt) {
+			if req.CreatedAt.Before(change.DetectedAt) && req.DesiredValue == change.Value {
+				return req, true
+			}
+			continue
+		}
+		if req.ResolvedAt.After(change.PreviousAt) && (req.Status == increase.StatusApproved || req.DesiredValue == change.Value) {
+			return req, true
+		}
+	}
+	return model.IncreaseRequest{}, false
+}
+
+// GetQuotaChanges returns the feed of quota limit changes, newest first.
+// Filters: since, until, cause (aws or increase_request), account, region and
+// service.
+func (h *Handler) GetQuotaChanges(c *gin.Context) {
+	now := time.Now()
+	since, err := parseTimeParam(c.Query("since"), now.Add(-defaultQuotaChangesWindow), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since: " + err.Error()})
+		return
+	}
+	until, err := parseTimeParam(c.Query("until"), now, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until: " + err.Error()})
+		return
+	}
+	cause := c.Query("cause")
+	if cause != "" && cause != ChangeCauseAWS && cause != ChangeCauseIncrease {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cause must be aws or increase_request"})
+		return
+	}
+	account, region, service := c.Query("account"), c.Query("region"), c.Query("service")
+
+	changes, err := h.store.LimitChanges(c.Request.Context(), since, until)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	result := make([]store.LimitChange, 0)
+	for i := len(changes) - 1; i >= 0; i-- {
+		qc := changes[i]
+		switch {
+		case cause != "" && qc.Cause != cause,
+			account != "" && qc.Key.AccountID != account,
+			region != "" && qc.Key.Region != region,
+			service != "" && qc.Key.ServiceCode != service:
+			continue
+		}
+		result = append(result, qc)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":   since,
+		"until":   until,
+		"changes": result,
+		"total":   len(result),
+	})
+}
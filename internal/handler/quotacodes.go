@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// loadQuotasByCode returns only the given quotas of a region parameter, each
+// fetched with a single call, from cache when possible. Targeted scans are
+// recorded in the history but never retire series, as they do not cover
+// whole services.
+func (h *Handler) loadQuotasByCode(ctx context.Context, regionParam string, refs []aws.QuotaRef) (*quotaSet, error) {
+	codes := make([]string, 0, len(refs))
+	wanted := make(map[aws.QuotaRef]bool, len(refs))
+	for _, ref := range refs {
+		codes = append(codes, ref.ServiceCode+"/"+ref.QuotaCode)
+		wanted[ref] = true
+	}
+	sort.Strings(codes)
+	cacheKey := h.cacheKey(ctx, "quotas", regionParam, "codes", strings.Join(codes, ","))
+
+	if set, ok := h.importedQuotas(regionParam, ""); ok {
+		quotas := make([]model.Quota, 0, len(refs))
+		for _, q := range set.quotas {
+			if wanted[aws.QuotaRef{ServiceCode: q.ServiceCode, QuotaCode: q.QuotaCode}] {
+				quotas = append(quotas, q)
+			}
+		}
+		set.quotas = h.notes.Apply(quotas)
+		return set, nil
+	}
+
+	if cached, ok := h.cache.Get(cacheKey); ok {
+		quotas, ok := cached.([]model.Quota)
+		if !ok {
+			return nil, fmt.Errorf("invalid cache data type")
+		}
+		set := &quotaSet{quotas: h.notes.Apply(quotas), fromCache: true}
+		if cached, ok := h.cache.Get(cacheKey + ":disabled"); ok {
+			if disabled, ok := cached.([]string); ok {
+				set.disabledRegions = disabled
+			}
+		}
+		return set, nil
+	}
+
+	regions, disabled, err := h.scanRegions(ctx, regionParam)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := h.inflight.Do(ctx, cacheKey, func(scanCtx context.Context) (interface{}, error) {
+		// The shared fetch outlives the caller that started it: everything
+		// after the scan runs on the flight's own context, never cancelled
+		ctx := context.WithoutCancel(scanCtx)
+		calls := &aws.CallCounter{}
+		startedAt := time.Now()
+		result, err := h.fetcher.GetQuotasByCode(aws.WithCallCounter(scanCtx, calls), regions, refs)
+		if err == nil {
+			err = scanCtx.Err()
+		}
+		h.recordFetchRun(ctx, startedAt, regions, "", calls, result, err)
+		if err != nil {
+			return nil, err
+		}
+		accountID := h.accountID(ctx)
+		for i := range result.Quotas {
+			if result.Quotas[i].AccountID == "" {
+				result.Quotas[i].AccountID = accountID
+			}
+		}
+		result.Quotas = h.withDeltas(ctx, result.Quotas)
+		now := time.Now()
+		h.TrackLimitChanges(ctx, now, result.Quotas)
+		h.NotifyThresholdCrossings(ctx, now, result.Quotas)
+		if err := h.store.Record(ctx, now, result.Quotas); err != nil {
+			log.Printf("Failed to record quota history: %v", err)
+		}
+		result.Quotas = h.withPeaks(ctx, result.Quotas)
+		h.cache.Set(cacheKey, result.Quotas)
+		h.setLatest(result.Quotas)
+		return result, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := v.(*aws.FetchResult)
+	if !ok {
+		return nil, fmt.Errorf("unexpected fetch result type %T", v)
+	}
+
+	disabled = append(disabled, result.DisabledRegions...)
+	sort.Strings(disabled)
+	h.cache.Set(cacheKey+":disabled", disabled)
+	return &quotaSet{quotas: h.notes.Apply(result.Quotas), warnings: result.Warnings, disabledRegions: disabled}, nil
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/review"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+// SetReviewConfig sets the defaults of reviews opened without explicit scope
+// and those opened on schedule
+func (h *Handler) SetReviewConfig(cfg config.ReviewConfig) {
+	h.reviewCfg = cfg
+}
+
+type reviewRequestBody struct {
+	Name      string   `json:"name"`
+	Threshold *float64 `json:"threshold"`
+	Region    string   `json:"region"`
+	Service   string   `json:"service"`
+	Reviewers []string `json:"reviewers"`
+	Org       *bool    `json:"org"`
+}
+
+// OpenReview opens a review of the quotas above the configured threshold in
+// the configured scope. It is used by the review schedule.
+func (h *Handler) OpenReview(ctx context.Context) (model.Review, error) {
+	return h.openReview(ctx, reviewRequestBody{})
+}
+
+// openReview opens a review, filling fields missing from the request from
+// the review configuration
+func (h *Handler) openReview(ctx context.Context, body reviewRequestBody) (model.Review, error) {
+	threshold := h.reviewCfg.Threshold
+	if body.Threshold != nil {
+		threshold = *body.Threshold
+	}
+	region, service := body.Region, body.Service
+	if region == "" && service == "" {
+		region, service = h.reviewCfg.Region, h.reviewCfg.Service
+	}
+	reviewers := body.Reviewers
+	if len(reviewers) == 0 {
+		reviewers = h.reviewCfg.Reviewers
+	}
+	useOrg := h.reviewCfg.Org
+	if body.Org != nil {
+		useOrg = *body.Org
+	}
+	name := body.Name
+	if name == "" {
+		name = "Quota review " + time.Now().Format("2006-01-02")
+	}
+
+	var quotas []model.Quota
+	if useOrg {
+		if h.orgScanner == nil {
+			return model.Review{}, fmt.Errorf("org scan mode is not enabled")
+		}
+		inventory := h.orgScanner.Inventory()
+		if inventory == nil {
+			return model.Review{}, fmt.Errorf("org inventory is not available yet")
+		}
+		quotas = filterQuotas(inventory.Quotas, region, service)
+	} else {
+		set, err := h.loadQuotas(ctx, region, service)
+		if err != nil {
+			return model.Review{}, err
+		}
+		quotas = set.quotas
+	}
+
+	// Retired quotas no longer exist and need no review
+	quotas, err := store.Active(ctx, h.store, quotas)
+	if err != nil {
+		return model.Review{}, err
+	}
+	return h.reviews.Create(name, threshold, reviewers, quotas), nil
+}
+
+// filterQuotas returns the quotas in a region (or "all") and service scope
+func filterQuotas(quotas []model.Quota, region, service string) []model.Quota {
+	filtered := make([]model.Quota, 0, len(quotas))
+	for _, q := range quotas {
+		if region != "" && region != "all" && q.Region != region {
+			continue
+		}
+		if service != "" && !strings.EqualFold(q.ServiceCode, service) {
+			continue
+		}
+		filtered = append(filtered, q)
+	}
+	return filtered
+}
+
+// CreateReview opens a review. Fields left out of the body default to the
+// review configuration.
+func (h *Handler) CreateReview(c *gin.Context) {
+	var body reviewRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rev, err := h.openReview(c.Request.Context(), body)
+	if err != nil {
+		loadFailed(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rev)
+}
+
+// GetReviews lists the reviews with their progress, newest first
+func (h *Handler) GetReviews(c *gin.Context) {
+	reviews := h.reviews.List()
+	c.JSON(http.StatusOK, gin.H{
+		"reviews": reviews,
+		"total":   len(reviews),
+	})
+}
+
+// GetReview returns a review and its items, optionally filtered by reviewer
+// and state
+func (h *Handler) GetReview(c *gin.Context) {
+	rev, ok := h.reviews.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+		return
+	}
+
+	reviewer := c.Query("reviewer")
+	state := c.Query("state")
+	if reviewer != "" || state != "" {
+		items := make([]model.ReviewItem, 0, len(rev.Items))
+		for _, item := range rev.Items {
+			if reviewer != "" && item.Reviewer != reviewer {
+				continue
+			}
+			if state != "" && item.State != state {
+				continue
+			}
+			items = append(items, item)
+		}
+		rev.Items = items
+	}
+	c.JSON(http.StatusOK, rev)
+}
+
+// UpdateReviewItem records the verdict on a quota of an open review
+func (h *Handler) UpdateReviewItem(c *gin.Context) {
+	var body review.ItemUpdate
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := h.reviews.UpdateItem(c.Param("id"), c.Param("item"), body)
+	if errors.Is(err, review.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+type signOffBody struct {
+	SignedOffBy string `json:"signed_off_by" binding:"required"`
+}
+
+// SignOffReview closes a review whose quotas all have a verdict
+func (h *Handler) SignOffReview(c *gin.Context) {
+	var body signOffBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rev, err := h.reviews.SignOff(c.Param("id"), body.SignedOffBy)
+	if errors.Is(err, review.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "progress": rev.Progress})
+		return
+	}
+	c.JSON(http.StatusOK, rev)
+}
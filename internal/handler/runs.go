@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/org"
+)
+
+// defaultRunsWindow is used when no since parameter is given
+const defaultRunsWindow = 7 * 24 * time.Hour
+
+type runTriggerKey struct{}
+
+// withRunTrigger returns a context whose fetches are recorded as started by
+// trigger
+func withRunTrigger(ctx context.Context, trigger string) context.Context {
+	return context.WithValue(ctx, runTriggerKey{}, trigger)
+}
+
+// runTrigger returns what started the fetches of a context; quota requests by
+// default
+func runTrigger(ctx context.Context) string {
+	if trigger, ok := ctx.Value(runTriggerKey{}).(string); ok {
+		return trigger
+	}
+	return model.RunTriggerRequest
+}
+
+// recordFetchRun records a single-account fetch that ran from startedAt
+// until now. result may be nil when the fetch failed.
+func (h *Handler) recordFetchRun(ctx context.Context, startedAt time.Time, regions []string, service string, calls *aws.CallCounter, result *aws.FetchResult, err error) {
+	run := model.Run{
+		Trigger:     runTrigger(ctx),
+		Service:     service,
+		Regions:     regions,
+		Status:      model.FetchJobSucceeded,
+		APICalls:    calls.Count(),
+		StartedAt:   startedAt,
+		CompletedAt: time.Now(),
+	}
+	if err != nil {
+		run.Status, run.Error = model.FetchJobFailed, err.Error()
+	}
+	if result != nil {
+		run.ErrorCount = len(result.Warnings)
+		run.QuotaCount = len(result.Quotas)
+	}
+	h.runs.Record(run)
+}
+
+// RecordOrgScanRun records a completed org scan
+func (h *Handler) RecordOrgScanRun(inv *org.Inventory, regions []string, service string) {
+	h.runs.Record(model.Run{
+		Trigger:     model.RunTriggerOrgScan,
+		Service:     service,
+		Regions:     regions,
+		Accounts:    len(inv.Accounts),
+		Status:      model.FetchJobSucceeded,
+		ErrorCount:  len(inv.Warnings),
+		APICalls:    inv.APICalls,
+		QuotaCount:  len(inv.Quotas),
+		StartedAt:   inv.StartedAt,
+		CompletedAt: inv.CompletedAt,
+	})
+}
+
+// RecordOrgScanFailure records an org scan that failed entirely
+func (h *Handler) RecordOrgScanFailure(startedAt time.Time, regions []string, service string, err error) {
+	h.runs.Record(model.Run{
+		Trigger:     model.RunTriggerOrgScan,
+		Service:     service,
+		Regions:     regions,
+		Status:      model.FetchJobFailed,
+		Error:       err.Error(),
+		StartedAt:   startedAt,
+		CompletedAt: time.Now(),
+	})
+}
+
+// runStats summarizes the runs of one trigger
+type runStats struct {
+	Runs          int     `json:"runs"`
+	Failed        int     `json:"failed"`
+	AvgDurationMS float64 `json:"avg_duration_ms"`
+	MaxDurationMS float64 `json:"max_duration_ms"`
+	AvgAPICalls   float64 `json:"avg_api_calls"`
+}
+
+// GetRuns returns the completed fetches and org scans, most recent first,
+// with duration and API call statistics per trigger. Filters: since, until,
+// trigger and status.
+func (h *Handler) GetRuns(c *gin.Context) {
+	now := time.Now()
+	since, err := parseTimeParam(c.Query("since"), now.Add(-defaultRunsWindow), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since: " + err.Error()})
+		return
+	}
+	until, err := parseTimeParam(c.Query("until"), now, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until: " + err.Error()})
+		return
+	}
+	trigger, status := c.Query("trigger"), c.Query("status")
+
+	runs := make([]model.Run, 0)
+	stats := make(map[string]*runStats)
+	for _, run := range h.runs.List() {
+		if run.CompletedAt.Before(since) || run.CompletedAt.After(until) ||
+			trigger != "" && run.Trigger != trigger || status != "" && run.Status != status {
+			continue
+		}
+		runs = append(runs, run)
+
+		s, ok := stats[run.Trigger]
+		if !ok {
+			s = &runStats{}
+			stats[run.Trigger] = s
+		}
+		s.Runs++
+		if run.Status == model.FetchJobFailed {
+			s.Failed++
+		}
+		s.AvgDurationMS += run.DurationMS
+		s.AvgAPICalls += float64(run.APICalls)
+		if run.DurationMS > s.MaxDurationMS {
+			s.MaxDurationMS = run.DurationMS
+		}
+	}
+	for _, s := range stats {
+		s.AvgDurationMS /= float64(s.Runs)
+		s.AvgAPICalls /= float64(s.Runs)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].CompletedAt.After(runs[j].CompletedAt) })
+
+	c.JSON(http.StatusOK, gin.H{
+		"since": since,
+		"until": until,
+		"runs":  runs,
+		"stats": stats,
+		"total": len(runs),
+	})
+}
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/alert"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+	"github.com/yuxishi/aws-quota-dashboard/internal/threshold"
+)
+
+// Action IDs of the proposal message buttons
+const (
+	actionApproveIncrease = "approve_increase"
+	actionRejectIncrease  = "reject_increase"
+)
+
+// SetSlack enables Slack notifications and interactive approvals
+func (h *Handler) SetSlack(slack *notify.Slack, cfg config.SlackConfig) {
+	h.slack = slack
+	h.slackCfg = cfg
+}
+
+// HandleSlackInteraction receives button clicks on proposal messages. The
+// request signature is verified with the app's signing secret; approving a
+// proposal submits the increase request and replaces the message with the
+// outcome.
+func (h *Handler) HandleSlackInteraction(c *gin.Context) {
+	if h.slack == nil || h.slackCfg.SigningSecret == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Slack interactions are not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := notify.VerifySignature(h.slackCfg.SigningSecret, c.Request.Header, body, time.Now()); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var interaction notify.Interaction
+	if err := json.Unmarshal([]byte(form.Get("payload")), &interaction); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid interaction payload"})
+		return
+	}
+	if interaction.Type != "block_actions" || len(interaction.Actions) == 0 {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	action := interaction.Actions[0]
+	if action.ActionID != actionApproveIncrease && action.ActionID != actionRejectIncrease {
+		c.Status(http.StatusOK)
+		return
+	}
+	if !h.isApprover(interaction.User.ID) {
+		c.JSON(http.StatusOK, notify.Message{
+			Text:         "You are not allowed to approve quota increases.",
+			ResponseType: "ephemeral",
+		})
+		return
+	}
+
+	proposal, err := h.proposals.Decide(action.Value, action.ActionID == actionApproveIncrease, interaction.User.Username)
+	if err != nil {
+		c.JSON(http.StatusOK, notify.Message{Text: err.Error(), ResponseType: "ephemeral"})
+		return
+	}
+
+	// Slack expects an acknowledgement within three seconds, so the request is
+	// submitted in the background and the message is updated when it completes
+	c.Status(http.StatusOK)
+	go h.completeProposal(context.WithoutCancel(c.Request.Context()), proposal, interaction.ResponseURL)
+}
+
+func (h *Handler) completeProposal(ctx context.Context, proposal model.IncreaseProposal, responseURL string) {
+	if proposal.Status == model.ProposalApproved {
+		proposal = h.submitProposal(ctx, proposal)
+	}
+	msg := proposalMessage(proposal)
+	msg.ReplaceOriginal = true
+	if err := h.slack.Respond(ctx, responseURL, msg); err != nil {
+		log.Printf("Failed to update Slack message of proposal %s: %v", proposal.ID, err)
+	}
+}
+
+// isApprover reports whether a Slack user may decide proposals. Nobody may
+// without configured approvers.
+func (h *Handler) isApprover(userID string) bool {
+	for _, approver := range h.slackCfg.Approvers {
+		if approver == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// proposalMessage renders a proposal as a Slack message, with approval
+// buttons while it is pending
+func proposalMessage(p model.IncreaseProposal) notify.Message {
+	summary := fmt.Sprintf("Quota increase proposal: %s (%s) in %s from %g to %g",
+		p.QuotaName, p.QuotaCode, p.Region, p.CurrentValue, p.DesiredValue)
+
+	status := "Pending approval"
+	switch p.Status {
+	case model.ProposalRejected:
+		status = "Rejected by " + p.DecidedBy
+	case model.ProposalSubmitted:
+		status = fmt.Sprintf("Approved by %s and submitted (request %s)", p.DecidedBy, p.Request.ID)
+	case model.ProposalFailed:
+		status = fmt.Sprintf("Approved by %s but submission failed: %s", p.DecidedBy, p.Error)
+	}
+
+	fields := []notify.Text{
+		{Type: "mrkdwn", Text: "*Service*\n" + p.ServiceCode},
+		{Type: "mrkdwn", Text: "*Status*\n" + status},
+	}
+	if p.RequestedBy != "" {
+		fields = append(fields, notify.Text{Type: "mrkdwn", Text: "*Requested by*\n" + p.RequestedBy})
+	}
+
+	blocks := []notify.Block{
+		{Type: "section", Text: notify.Markdown("*" + summary + "*")},
+		{Type: "section", Fields: fields},
+	}
+	if p.Justification != "" {
+		blocks = append(blocks, notify.Block{Type: "section", Text: notify.Markdown(">" + p.Justification)})
+	}
+	if p.Status == model.ProposalPending {
+		blocks = append(blocks, notify.Block{
+			Type:    "actions",
+			BlockID: "proposal_" + p.ID,
+			Elements: []notify.Element{
+				notify.Button("Approve", actionApproveIncrease, p.ID, "primary"),
+				notify.Button("Reject", actionRejectIncrease, p.ID, "danger"),
+			},
+		})
+	}
+
+	return notify.Message{Text: summary, Blocks: blocks}
+}
+
+// slackThresholdsEnabled reports whether threshold crossings are posted to
+// Slack
+func (h *Handler) slackThresholdsEnabled() bool {
+	return h.slack != nil && h.slackCfg.Thresholds.Enabled()
+}
+
+// postThresholdCrossings posts the crossings into the severities enabled for
+// Slack, each to the channel of its severity; resolved alerts go to the
+// channel of the severity they recovered from, without mention
+func (h *Handler) postThresholdCrossings(ctx context.Context, events []thresholdEvent) {
+	for _, e := range events {
+		severity := severityRoute(h.slackCfg.Thresholds, e.severity())
+		if !severity.Enabled {
+			continue
+		}
+		mention := severity.Mention
+		if e.Status == threshold.StatusResolved {
+			mention = ""
+		}
+		msg := thresholdMessage(e, h.renderAlert(alert.NotifierSlack, e), mention, h.slackCfg.DashboardURL)
+		msg.Channel = severity.Channel
+		if err := h.slack.Post(ctx, msg); err != nil {
+			log.Printf("Failed to post %s/%s crossing to Slack: %v", e.ServiceCode, e.QuotaCode, err)
+		}
+	}
+}
+
+// thresholdMessage renders a threshold crossing as a Slack message headed by
+// the text of its template, linking to the quota in the dashboard when its URL
+// is configured
+func thresholdMessage(e thresholdEvent, text, mention, dashboardURL string) notify.Message {
+	icon := ":warning:"
+	switch e.Status {
+	case threshold.StatusCritical:
+		icon = ":rotating_light:"
+	case threshold.StatusResolved:
+		icon = ":white_check_mark:"
+	}
+	summary := text
+	headline := icon + " " + text
+	if mention != "" {
+		summary = mention + " " + summary
+		headline = mention + " " + headline
+	}
+
+	account := e.AccountID
+	if account == "" {
+		account = "current"
+	}
+	previous := "was " + e.PreviousStatus
+	if e.PreviousStatus == "" {
+		previous = "first observation"
+	}
+	blocks := []notify.Block{
+		{Type: "section", Text: notify.Markdown(headline)},
+		{Type: "section", Fields: []notify.Text{
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Quota*\n%s (%s)", e.QuotaName, e.QuotaCode)},
+			{Type: "mrkdwn", Text: "*Service*\n" + e.ServiceName},
+			{Type: "mrkdwn", Text: "*Region*\n" + e.Region},
+			{Type: "mrkdwn", Text: "*Account*\n" + account},
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Usage / limit*\n%g / %g (%.1f%%)", e.Usage, e.Value, e.UsagePercentage)},
+			{Type: "mrkdwn", Text: fmt.Sprintf("*Threshold*\n%g%% (%s)", e.Threshold, previous)},
+		}},
+	}
+	if dashboardURL != "" {
+		blocks = append(blocks, notify.Block{
+			Type: "section",
+			Text: notify.Markdown("<" + quotaLink(dashboardURL, e) + "|Open in dashboard>"),
+		})
+	}
+	return notify.Message{Text: summary, Blocks: blocks}
+}
+
+// quotaLink returns the dashboard URL showing a quota
+func quotaLink(dashboardURL string, e thresholdEvent) string {
+	params := url.Values{}
+	params.Set("region", e.Region)
+	params.Set("service", e.ServiceCode)
+	params.Set("search", e.QuotaName)
+	return strings.TrimRight(dashboardURL, "/") + "/?" + params.Encode()
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/snapshot"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/version"
+)
+
+// maxSnapshotSize bounds the size of an uploaded snapshot archive
+const maxSnapshotSize = 256 << 20
+
+// importedSnapshot is a snapshot served in place of live AWS data
+type importedSnapshot struct {
+	manifest   snapshot.Manifest
+	quotas     []model.Quota
+	importedAt time.Time
+}
+
+// setLatest remembers the most recent value of each fetched quota
+func (h *Handler) setLatest(quotas []model.Quota) {
+	h.quotasMu.Lock()
+	defer h.quotasMu.Unlock()
+	h.setLatestLocked(quotas)
+}
+
+func (h *Handler) setLatestLocked(quotas []model.Quota) {
+	if h.latest == nil {
+		h.latest = make(map[store.QuotaKey]model.Quota)
+	}
+	for _, q := range quotas {
+		h.latest[store.KeyOf(q)] = q
+	}
+}
+
+// importedQuotas serves a region/service scope from the imported snapshot,
+// if any
+func (h *Handler) importedQuotas(regionParam, serviceFilter string) (*quotaSet, bool) {
+	h.quotasMu.RLock()
+	defer h.quotasMu.RUnlock()
+	if h.imported == nil {
+		return nil, false
+	}
+
+	regions := make(map[string]bool)
+	if regionParam != "" && regionParam != "all" {
+		for _, r := range strings.Split(regionParam, ",") {
+			regions[r] = true
+		}
+		regions["global"] = true
+	}
+	quotas := make([]model.Quota, 0)
+	for _, q := range h.imported.quotas {
+		if len(regions) > 0 && !regions[q.Region] {
+			continue
+		}
+		if serviceFilter != "" && !strings.EqualFold(q.ServiceCode, serviceFilter) {
+			continue
+		}
+		quotas = append(quotas, q)
+	}
+	return &quotaSet{
+		quotas:    quotas,
+		fromCache: true,
+		warnings: []string{fmt.Sprintf("Serving snapshot exported at %s",
+			h.imported.manifest.ExportedAt.Format(time.RFC3339))},
+	}, true
+}
+
+// currentQuotas returns the latest value of every active quota, from
+// single-account fetches and the org inventory, and the org accounts
+func (h *Handler) currentQuotas(ctx context.Context) ([]model.Quota, []model.Account, error) {
+	retired, err := h.store.Retired(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	latest := make(map[store.QuotaKey]model.Quota)
+	var accounts []model.Account
+	if h.orgScanner != nil {
+		if inventory := h.orgScanner.Inventory(); inventory != nil {
+			accounts = inventory.Accounts
+			for _, q := range inventory.Quotas {
+				latest[store.KeyOf(q)] = q
+			}
+		}
+	}
+	h.quotasMu.RLock()
+	for key, q := range h.latest {
+		latest[key] = q
+	}
+	h.quotasMu.RUnlock()
+
+	quotas := make([]model.Quota, 0, len(latest))
+	for key, q := range latest {
+		if _, ok := retired[key]; !ok {
+			quotas = append(quotas, q)
+		}
+	}
+	return quotas, accounts, nil
+}
+
+// ExportSnapshot downloads everything the dashboard has collected: the latest
+// value of every active quota, org accounts, history and warnings
+func (h *Handler) ExportSnapshot(c *gin.Context) {
+	ctx := c.Request.Context()
+	dump, err := h.store.Export(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	quotas, accounts, err := h.currentQuotas(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	source, err := os.Hostname()
+	if err != nil {
+		source = ""
+	}
+	snap := &snapshot.Snapshot{
+		Manifest: snapshot.Manifest{ExportedAt: time.Now(), Source: source, DashboardVersion: version.String()},
+		Quotas:   quotas,
+		Accounts: accounts,
+		History:  dump,
+	}
+	var buf bytes.Buffer
+	if err := snapshot.Write(&buf, snap); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("aws-quotas-snapshot-%s.zip", snap.Manifest.ExportedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header(versionHeader, version.String())
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// ImportSnapshot loads a snapshot archive sent as the request body or as the
+// "file" field of a multipart form. Its history and warnings are merged into
+// the store and its quotas are served instead of live AWS data until the
+// import is cleared.
+func (h *Handler) ImportSnapshot(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSnapshotSize)
+	snap, err := readSnapshotUpload(c, "file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.store.Import(c.Request.Context(), snap.History); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.quotasMu.Lock()
+	h.imported = &importedSnapshot{
+		manifest:   snap.Manifest,
+		quotas:     snap.Quotas,
+		importedAt: time.Now(),
+	}
+	h.setLatestLocked(snap.Quotas)
+	h.quotasMu.Unlock()
+	h.cache.Clear()
+
+	c.JSON(http.StatusOK, gin.H{
+		"manifest": snap.Manifest,
+		"accounts": snap.Accounts,
+	})
+}
+
+// readSnapshotUpload reads a snapshot archive sent as the request body or as
+// the given field of a multipart form
+func readSnapshotUpload(c *gin.Context, field string) (*snapshot.Snapshot, error) {
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile(field)
+		if err != nil {
+			return nil, fmt.Errorf("missing snapshot file %q: %w", field, err)
+		}
+		defer file.Close()
+		body = file
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return snapshot.Read(data)
+}
+
+// DiffSnapshot compares the quota limits of an uploaded snapshot archive
+// with those of this dashboard, e.g. an isolated account's against another
+// environment's. With a multipart form, the archive is the "file" field and
+// an optional "base" archive replaces the local quotas, so two exports can be
+// compared offline. Accounts are selected with base_account and
+// other_account when a side holds several.
+func (h *Handler) DiffSnapshot(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 2*maxSnapshotSize)
+	other, err := readSnapshotUpload(c, "file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var baseManifest *snapshot.Manifest
+	var base []model.Quota
+	if c.Request.MultipartForm != nil && len(c.Request.MultipartForm.File["base"]) > 0 {
+		snap, err := readSnapshotUpload(c, "base")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		baseManifest, base = &snap.Manifest, snap.Quotas
+	} else if base, _, err = h.currentQuotas(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	diff, err := snapshot.Compare(quotasOfAccount(base, c.Query("base_account")), quotasOfAccount(other.Quotas, c.Query("other_account")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"base":  baseManifest,
+		"other": other.Manifest,
+		"diff":  diff,
+	})
+}
+
+// quotasOfAccount returns the quotas of one account, or all of them when no
+// account is given
+func quotasOfAccount(quotas []model.Quota, accountID string) []model.Quota {
+	if accountID == "" {
+		return quotas
+	}
+	result := make([]model.Quota, 0)
+	for _, q := range quotas {
+		if q.AccountID == accountID {
+			result = append(result, q)
+		}
+	}
+	return result
+}
+
+// GetImportedSnapshot describes the imported snapshot being served, if any
+func (h *Handler) GetImportedSnapshot(c *gin.Context) {
+	h.quotasMu.RLock()
+	defer h.quotasMu.RUnlock()
+	if h.imported == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no snapshot imported"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"manifest":    h.imported.manifest,
+		"imported_at": h.imported.importedAt,
+	})
+}
+
+// ClearImportedSnapshot goes back to serving live AWS data. Imported history
+// and warnings stay in the store.
+func (h *Handler) ClearImportedSnapshot(c *gin.Context) {
+	h.quotasMu.Lock()
+	h.imported = nil
+	h.quotasMu.Unlock()
+	h.cache.Clear()
+	c.JSON(http.StatusOK, gin.H{"message": "Imported snapshot cleared"})
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+// limitAnomalyEvent is the payload published when a quota limit changes
+// without an increase request explaining it
+type limitAnomalyEvent struct {
+	Event         string    `json:"event"`
+	Timestamp     time.Time `json:"timestamp"`
+	AccountID     string    `json:"account_id,omitempty"`
+	Region        string    `json:"region"`
+	ServiceCode   string    `json:"service_code"`
+	ServiceName   string    `json:"service_name"`
+	QuotaCode     string    `json:"quota_code"`
+	QuotaName     string    `json:"quota_name"`
+	PreviousValue float64   `json:"previous_value"`
+	Value         float64   `json:"value"`
+	PreviousAt    time.Time `json:"previous_at"`
+}
+
+// SetSNS enables publishing quota events to an SNS topic
+func (h *Handler) SetSNS(cfg config.SNSConfig) {
+	h.sns = cfg
+}
+
+// publishes reports whether events of a type are published to SNS
+func (h *Handler) publishes(event string) bool {
+	return h.sns.TopicARN != "" && matchesAny(h.sns.Events, event)
+}
+
+// publishThresholdCrossings publishes each alert transition of a published
+// event type as a message, with the webhook payload as body
+func (h *Handler) publishThresholdCrossings(ctx context.Context, events []thresholdEvent) {
+	for _, e := range events {
+		if !h.publishes(e.Event) {
+			continue
+		}
+		subject := fmt.Sprintf("%s is %s in %s", e.QuotaName, e.Status, e.Region)
+		h.publish(ctx, e.Event, subject, e, map[string]string{
+			"severity":     e.Status,
+			"account_id":   e.AccountID,
+			"region":       e.Region,
+			"service_code": e.ServiceCode,
+			"quota_code":   e.QuotaCode,
+		})
+	}
+}
+
+// publishLimitAnomalies publishes each unexplained limit change as a message
+func (h *Handler) publishLimitAnomalies(ctx context.Context, changes []store.LimitChange) {
+	for _, change := range changes {
+		e := limitAnomalyEvent{
+			Event:         notify.EventLimitAnomaly,
+			Timestamp:     change.DetectedAt,
+			AccountID:     change.Key.AccountID,
+			Region:        change.Key.Region,
+			ServiceCode:   change.Key.ServiceCode,
+			ServiceName:   change.ServiceName,
+			QuotaCode:     change.Key.QuotaCode,
+			QuotaName:     change.QuotaName,
+			PreviousValue: change.PreviousValue,
+			Value:         change.Value,
+			PreviousAt:    change.PreviousAt,
+		}
+		subject := fmt.Sprintf("%s changed from %g to %g in %s", e.QuotaName, e.PreviousValue, e.Value, e.Region)
+		h.publish(ctx, e.Event, subject, e, map[string]string{
+			"account_id":   e.AccountID,
+			"region":       e.Region,
+			"service_code": e.ServiceCode,
+			"quota_code":   e.QuotaCode,
+		})
+	}
+}
+
+// publish sends one event to the topic, with its type as the event_type
+// message attribute
+func (h *Handler) publish(ctx context.Context, event, subject string, payload interface{}, attributes map[string]string) {
+	message, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event, err)
+		return
+	}
+	attributes["event_type"] = event
+	if err := aws.PublishEvent(ctx, h.sns.TopicARN, subject, message, attributes); err != nil {
+		log.Printf("Failed to publish %s event: %v", event, err)
+	}
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+// Sparkline lengths, in buckets
+const (
+	defaultSparklinePoints = 24
+	maxSparklinePoints     = 168
+)
+
+// sparkline is the recent usage of one quota, one value per bucket
+type sparkline struct {
+	Region      string `json:"region"`
+	ServiceCode string `json:"service_code"`
+	QuotaCode   string `json:"quota_code"`
+	// UsagePercentage is the highest usage percentage of each bucket, null
+	// for buckets without observations
+	UsagePercentage []*float64 `json:"usage_percentage"`
+}
+
+// GetSparklines returns the last points buckets of usage of every current
+// quota of an account in one response, so the quota table can draw
+// sparklines without a history request per row. Filters: region
+// (comma-separated; global quotas are always included), service and
+// account. resolution is hourly (default) or daily.
+func (h *Handler) GetSparklines(c *gin.Context) {
+	ctx := c.Request.Context()
+	resolution := c.DefaultQuery("resolution", store.ResolutionHourly)
+	size, err := store.BucketSize(resolution)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	n := defaultSparklinePoints
+	if v := c.Query("points"); v != "" {
+		if n, err = strconv.Atoi(v); err != nil || n < 1 || n > maxSparklinePoints {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "points must be between 1 and " + strconv.Itoa(maxSparklinePoints)})
+			return
+		}
+	}
+	accountID := c.Query("account")
+	if accountID == "" {
+		accountID = h.accountID(ctx)
+	}
+	regions := make(map[string]bool)
+	if param := c.Query("region"); param != "" && param != "all" {
+		for _, r := range strings.Split(param, ",") {
+			regions[r] = true
+		}
+		regions["global"] = true
+	}
+	service := c.Query("service")
+
+	quotas, _, err := h.currentQuotas(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	until := time.Now()
+	since := until.UTC().Truncate(size).Add(-time.Duration(n-1) * size)
+	sparklines := make([]sparkline, 0)
+	for _, q := range quotas {
+		if q.AccountID != accountID || len(regions) > 0 && !regions[q.Region] ||
+			service != "" && !strings.EqualFold(q.ServiceCode, service) {
+			continue
+		}
+		points, err := h.store.History(ctx, store.KeyOf(q), since, until)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		line := sparkline{
+			Region:          q.Region,
+			ServiceCode:     q.ServiceCode,
+			QuotaCode:       q.QuotaCode,
+			UsagePercentage: make([]*float64, n),
+		}
+		hasUsage := false
+		for _, b := range store.Downsample(usagePoints(points), size) {
+			i := int(b.Start.Sub(since) / size)
+			if i < 0 || i >= n {
+				continue
+			}
+			pct := b.MaxUsagePercentage
+			line.UsagePercentage[i], hasUsage = &pct, true
+		}
+		if hasUsage {
+			sparklines = append(sparklines, line)
+		}
+	}
+	sort.Slice(sparklines, func(i, j int) bool {
+		a, b := sparklines[i], sparklines[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.ServiceCode != b.ServiceCode {
+			return a.ServiceCode < b.ServiceCode
+		}
+		return a.QuotaCode < b.QuotaCode
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"account_id": accountID,
+		"resolution": resolution,
+		"points":     n,
+		"since":      since,
+		"until":      until,
+		"sparklines": sparklines,
+	})
+}
+
+// usagePoints drops the observations without usage, so they do not pull
+// bucket maximums down to zero
+func usagePoints(points []store.Point) []store.Point {
+	result := make([]store.Point, 0, len(points))
+	for _, p := range points {
+		if p.HasUsage {
+			result = append(result, p)
+		}
+	}
+	return result
+}
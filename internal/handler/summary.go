@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// SetCostEnabled enables the optional Cost Explorer cost field in service summaries
+func (h *Handler) SetCostEnabled(enabled bool) {
+	h.costEnabled = enabled
+}
+
+// GetServiceSummaries aggregates quotas per service. When cost integration is
+// enabled each summary carries the service's month-to-date cost, so capacity
+// and cost can be discussed from one dataset.
+func (h *Handler) GetServiceSummaries(c *gin.Context) {
+	regionParam := c.Query("region")
+	serviceFilter := c.Query("service")
+
+	set, err := h.loadQuotas(c.Request.Context(), regionParam, serviceFilter)
+	if err != nil {
+		loadFailed(c, err)
+		return
+	}
+
+	summaries := summarizeByService(set.quotas)
+	warnings := set.warnings
+	if h.costEnabled {
+		costs, err := h.monthlyCosts(c, regionParam)
+		if err != nil {
+			log.Printf("Cost Explorer query failed: %v", err)
+			warnings = append(warnings, "Cost data unavailable: "+err.Error())
+		}
+		for i := range summaries {
+			if cost, ok := costs[summaries[i].ServiceCode]; ok {
+				summaries[i].Cost = &cost
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"services":   summaries,
+		"total":      len(summaries),
+		"from_cache": set.fromCache,
+		"warnings":   warnings,
+	})
+}
+
+// monthlyCosts returns the month-to-date cost per service. Cost Explorer
+// charges per request and its data refreshes daily, so results are cached.
+func (h *Handler) monthlyCosts(c *gin.Context, region string) (map[string]model.ServiceCost, error) {
+	cacheKey := h.cacheKey(c.Request.Context(), "cost", region)
+	if cached, ok := h.cache.Get(cacheKey); ok {
+		if costs, ok := cached.(map[string]model.ServiceCost); ok {
+			return costs, nil
+		}
+	}
+
+	costs, err := h.fetcher.GetMonthlyServiceCosts(c.Request.Context(), region)
+	if err != nil {
+		return nil, err
+	}
+	h.cache.Set(cacheKey, costs)
+	return costs, nil
+}
+
+func summarizeByService(quotas []model.Quota) []model.ServiceSummary {
+	byService := make(map[string]*model.ServiceSummary)
+	for _, q := range quotas {
+		summary, ok := byService[q.ServiceCode]
+		if !ok {
+			summary = &model.ServiceSummary{ServiceCode: q.ServiceCode, ServiceName: q.ServiceName}
+			byService[q.ServiceCode] = summary
+		}
+		summary.QuotaCount++
+		if q.HasUsageMetrics {
+			summary.QuotasWithUsage++
+			if q.UsagePercentage > summary.MaxUsagePercentage {
+				summary.MaxUsagePercentage = q.UsagePercentage
+			}
+		}
+	}
+
+	summaries := make([]model.ServiceSummary, 0, len(byService))
+	for _, summary := range byService {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].ServiceCode < summaries[j].ServiceCode })
+	return summaries
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+type supportCaseBody struct {
+	Region       string  `json:"region" binding:"required"`
+	ServiceCode  string  `json:"service_code" binding:"required"`
+	QuotaCode    string  `json:"quota_code" binding:"required"`
+	DesiredValue float64 `json:"desired_value" binding:"required,gt=0"`
+	Details      string  `json:"details"`
+	RequestedBy  string  `json:"requested_by"`
+}
+
+// SetSupportCases enables the AWS Support case endpoints
+func (h *Handler) SetSupportCases(cfg config.SupportCasesConfig) {
+	if cfg.Enabled {
+		h.supportCfg = &cfg
+	}
+}
+
+// GetSupportCases lists the support cases whose subject names a quota code,
+// resolved ones included, so a quota links to the cases already opened
+// about it
+func (h *Handler) GetSupportCases(c *gin.Context) {
+	if h.supportCfg == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Support cases are not enabled"})
+		return
+	}
+	quotaCode := c.Query("quota_code")
+	if quotaCode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quota_code is required"})
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -h.supportCfg.LookbackDays)
+	cases, err := h.fetcher.FindSupportCases(c.Request.Context(), quotaCode, since)
+	if err != nil {
+		c.JSON(supportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quota_code": quotaCode,
+		"since":      since,
+		"cases":      cases,
+		"total":      len(cases),
+	})
+}
+
+// OpenSupportCase opens a service limit increase case pre-filled with the
+// quota's details. Only non-adjustable quotas are accepted; adjustable ones
+// go through increase requests, which Service Quotas tracks.
+func (h *Handler) OpenSupportCase(c *gin.Context) {
+	if h.supportCfg == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Support cases are not enabled"})
+		return
+	}
+	var body supportCaseBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	quota, err := h.fetcher.GetQuota(ctx, body.Region, body.ServiceCode, body.QuotaCode)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	if quota.Adjustable {
+		c.JSON(http.StatusConflict, gin.H{"error": "Quota " + body.QuotaCode + " is adjustable; submit an increase request instead"})
+		return
+	}
+
+	subject := fmt.Sprintf("Quota increase: %s (%s) in %s", quota.QuotaName, quota.QuotaCode, body.Region)
+	message := supportCaseMessage(quota, h.accountID(ctx), &body)
+	id, err := h.fetcher.CreateSupportCase(ctx, subject, message, h.supportCfg.Severity, h.supportCfg.CCEmails)
+	if err != nil {
+		c.JSON(supportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"case_id": id,
+		"subject": subject,
+		"body":    message,
+	})
+}
+
+// supportCaseMessage pre-fills the case body with what AWS Support asks for
+// when raising a limit
+func supportCaseMessage(quota *model.Quota, accountID string, body *supportCaseBody) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Please raise the following quota, which cannot be adjusted through Service Quotas.\n\n")
+	if accountID != "" {
+		fmt.Fprintf(&b, "Account: %s\n", accountID)
+	}
+	fmt.Fprintf(&b, "Region: %s\n", body.Region)
+	fmt.Fprintf(&b, "Service: %s (%s)\n", quota.ServiceName, quota.ServiceCode)
+	fmt.Fprintf(&b, "Quota: %s (%s)\n", quota.QuotaName, quota.QuotaCode)
+	fmt.Fprintf(&b, "Current value: %g\n", quota.Value)
+	if quota.HasUsageMetrics {
+		fmt.Fprintf(&b, "Current usage: %g (%.1f%%)\n", quota.Usage, quota.UsagePercentage)
+	}
+	fmt.Fprintf(&b, "Requested value: %g\n", body.DesiredValue)
+	if body.RequestedBy != "" {
+		fmt.Fprintf(&b, "Requested by: %s\n", body.RequestedBy)
+	}
+	if body.Details != "" {
+		fmt.Fprintf(&b, "\n%s\n", body.Details)
+	}
+	return b.String()
+}
+
+// supportErrorStatus maps a Support API error to the response status
+func supportErrorStatus(err error) int {
+	switch {
+	case aws.IsSupportPlanRequired(err):
+		return http.StatusPaymentRequired
+	case aws.ClassifyError(err) == model.FetchStatusDenied:
+		return http.StatusForbidden
+	default:
+		return http.StatusBadGateway
+	}
+}
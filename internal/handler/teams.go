@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/alert"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+	"github.com/yuxishi/aws-quota-dashboard/internal/threshold"
+)
+
+// Card colors of the threshold notifications
+const (
+	teamsWarningColor  = "FFA500"
+	teamsCriticalColor = "D13438"
+	teamsResolvedColor = "2EB886"
+)
+
+// SetTeams enables Microsoft Teams notifications
+func (h *Handler) SetTeams(teams *notify.Teams, cfg config.TeamsConfig) {
+	h.teams = teams
+	h.teamsCfg = cfg
+}
+
+// teamsThresholdsEnabled reports whether threshold crossings are posted to
+// Teams
+func (h *Handler) teamsThresholdsEnabled() bool {
+	return h.teams != nil && h.teamsCfg.Thresholds.Enabled()
+}
+
+// postTeamsThresholdCrossings posts the crossings into the severities enabled
+// for Teams, each to the channel of its severity; resolved alerts go to the
+// channel of the severity they recovered from, without mention
+func (h *Handler) postTeamsThresholdCrossings(ctx context.Context, events []thresholdEvent) {
+	for _, e := range events {
+		severity := severityRoute(h.teamsCfg.Thresholds, e.severity())
+		if !severity.Enabled {
+			continue
+		}
+		mention := severity.Mention
+		if e.Status == threshold.StatusResolved {
+			mention = ""
+		}
+		card := thresholdCard(e, h.renderAlert(alert.NotifierTeams, e), mention, h.teamsCfg.DashboardURL)
+		var err error
+		if severity.Channel != "" {
+			err = h.teams.PostTo(ctx, severity.Channel, card)
+		} else {
+			err = h.teams.Post(ctx, card)
+		}
+		if err != nil {
+			log.Printf("Failed to post %s/%s crossing to Teams: %v", e.ServiceCode, e.QuotaCode, err)
+		}
+	}
+}
+
+// thresholdCard renders a threshold crossing as a Teams connector card titled
+// by the text of its template, linking to the quota in the dashboard when its
+// URL is configured
+func thresholdCard(e thresholdEvent, text, mention, dashboardURL string) notify.Card {
+	color := teamsWarningColor
+	switch e.Status {
+	case threshold.StatusCritical:
+		color = teamsCriticalColor
+	case threshold.StatusResolved:
+		color = teamsResolvedColor
+	}
+	account := e.AccountID
+	if account == "" {
+		account = "current"
+	}
+	previous := "was " + e.PreviousStatus
+	if e.PreviousStatus == "" {
+		previous = "first observation"
+	}
+	card := notify.NewCard(text, text, color)
+	card.Text = mention
+	card.Sections = []notify.CardSection{{
+		Facts: []notify.Fact{
+			{Name: "Quota", Value: fmt.Sprintf("%s (%s)", e.QuotaName, e.QuotaCode)},
+			{Name: "Service", Value: e.ServiceName},
+			{Name: "Region", Value: e.Region},
+			{Name: "Account", Value: account},
+			{Name: "Usage / limit", Value: fmt.Sprintf("%g / %g (%.1f%%)", e.Usage, e.Value, e.UsagePercentage)},
+			{Name: "Threshold", Value: fmt.Sprintf("%g%% (%s)", e.Threshold, previous)},
+		},
+	}}
+	if dashboardURL != "" {
+		card.PotentialAction = []notify.CardAction{notify.OpenURI("Open in dashboard", quotaLink(dashboardURL, e))}
+	}
+	return card
+}
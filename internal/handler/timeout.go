@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestTimeoutKey holds the request's maximum duration in the gin context
+const requestTimeoutKey = "request_timeout"
+
+// RequestTimeout bounds how long a synchronous request may scan AWS. Past the
+// deadline the scan is cancelled, unless other requests still wait on it, and
+// the request fails with 504. Zero disables the limit.
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(requestTimeoutKey, timeout)
+		c.Next()
+	}
+}
+
+// loadFailed writes the error of a synchronous quota load. Requests over their
+// maximum duration get a 504 pointing to the fetch job API; nothing is written
+// for clients that have disconnected.
+func loadFailed(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && c.Request.Context().Err() != nil:
+		timeout := c.GetDuration(requestTimeoutKey)
+		c.JSON(http.StatusGatewayTimeout, gin.H{
+			"error":           fmt.Sprintf("the request did not complete within %s", timeout),
+			"timeout_seconds": timeout.Seconds(),
+			"hint":            "start the scan with POST /api/fetch, using the same region and service parameters, and follow it with GET /api/fetch/:id",
+		})
+	case errors.Is(err, context.Canceled) && c.Request.Context().Err() != nil:
+		c.Abort()
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
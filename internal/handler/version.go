@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/version"
+)
+
+// versionHeader carries the dashboard build on exports
+const versionHeader = "X-Dashboard-Version"
+
+// SetFeatures sets the optional features reported by /api/version
+func (h *Handler) SetFeatures(features []string) {
+	h.features = features
+}
+
+// GetVersion reports the build version, commit, Go version and the optional
+// features enabled in this deployment
+func (h *Handler) GetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, version.Get(h.features))
+}
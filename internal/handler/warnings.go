@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+// defaultWarningsWindow is used when no since parameter is given
+const defaultWarningsWindow = 24 * time.Hour
+
+// GetWarnings returns the warnings recorded by recent fetches and org scans,
+// newest first, so operators can see why data is missing. Filters: since,
+// until, source (fetch or org_scan) and search.
+func (h *Handler) GetWarnings(c *gin.Context) {
+	now := time.Now()
+	since, err := parseTimeParam(c.Query("since"), now.Add(-defaultWarningsWindow), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since: " + err.Error()})
+		return
+	}
+	until, err := parseTimeParam(c.Query("until"), now, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until: " + err.Error()})
+		return
+	}
+
+	warnings, err := h.store.Warnings(c.Request.Context(), since, until)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	source := c.Query("source")
+	search := strings.ToLower(c.Query("search"))
+	result := make([]store.Warning, 0, len(warnings))
+	for i := len(warnings) - 1; i >= 0; i-- {
+		w := warnings[i]
+		if source != "" && w.Source != source {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(w.Message), search) {
+			continue
+		}
+		result = append(result, w)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":    since,
+		"until":    until,
+		"warnings": result,
+		"total":    len(result),
+	})
+}
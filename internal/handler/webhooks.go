@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/alert"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/notify"
+	"github.com/yuxishi/aws-quota-dashboard/internal/randid"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/threshold"
+	"github.com/yuxishi/aws-quota-dashboard/internal/version"
+)
+
+// thresholdEvent is the payload posted to webhooks when a quota's alert state
+// moves into warning, critical or resolved. PreviousStatus is empty for quotas
+// seen for the first time.
+type thresholdEvent struct {
+	Event           string    `json:"event"`
+	Timestamp       time.Time `json:"timestamp"`
+	AlertID         string    `json:"alert_id"`
+	AccountID       string    `json:"account_id,omitempty"`
+	Region          string    `json:"region"`
+	ServiceCode     string    `json:"service_code"`
+	ServiceName     string    `json:"service_name"`
+	QuotaCode       string    `json:"quota_code"`
+	QuotaName       string    `json:"quota_name"`
+	Value           float64   `json:"value"`
+	Usage           float64   `json:"usage"`
+	UsagePercentage float64   `json:"usage_percentage"`
+	Status          string    `json:"status"`
+	PreviousStatus  string    `json:"previous_status,omitempty"`
+	Threshold       float64   `json:"threshold"`
+
+	quota model.Quota
+	// silenced is set for transitions of acknowledged or snoozed alerts,
+	// which go to the digest only
+	silenced bool
+}
+
+// SetWebhooks sets the endpoints notified of threshold crossings
+func (h *Handler) SetWebhooks(cfg config.WebhooksConfig) {
+	h.webhooks = nil
+	for _, e := range cfg.Endpoints {
+		h.webhooks = append(h.webhooks, notify.NewWebhook(e.URL, e.Secret, cfg.MaxAttempts))
+	}
+}
+
+// NotifyThresholdCrossings moves the alert state of each quota (ok, warning,
+// critical, resolved) according to its status, and notifies the webhooks,
+// Slack, Teams, email recipients and SNS topic of the quotas that moved into
+// warning, critical or resolved. States are kept in the store, so repeated
+// refreshes of a quota in the same state notify once. Deliveries run in the
+// background. Transitions are also logged for the digest, and only go there
+// when it replaces real-time notifications.
+func (h *Handler) NotifyThresholdCrossings(ctx context.Context, at time.Time, quotas []model.Quota) {
+	if h.thresholds == nil {
+		return
+	}
+	// Annotated alert thresholds decide the status, as in the listings
+	transitions := h.alertTransitions(ctx, at, h.notes.Apply(quotas))
+	if len(transitions) == 0 {
+		return
+	}
+	h.logTransitions(ctx, transitions)
+	if h.digest.ReplaceRealtime {
+		log.Printf("%d quota alert state transitions left to the digest", len(transitions))
+		return
+	}
+	var events []thresholdEvent
+	for _, e := range transitions {
+		if !e.silenced {
+			events = append(events, e)
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for _, w := range h.webhooks {
+		go func(w *notify.Webhook) {
+			for _, e := range events {
+				if err := h.postThresholdWebhook(ctx, w, e); err != nil {
+					log.Printf("Failed to notify %s/%s %s: %v", e.ServiceCode, e.QuotaCode, e.Status, err)
+				}
+			}
+		}(w)
+	}
+	if h.slackThresholdsEnabled() {
+		go h.postThresholdCrossings(ctx, events)
+	}
+	if h.teamsThresholdsEnabled() {
+		go h.postTeamsThresholdCrossings(ctx, events)
+	}
+	if len(h.email.Recipients) > 0 {
+		go h.emailThresholdCrossings(ctx, events)
+	}
+	if h.sns.TopicARN != "" {
+		go h.publishThresholdCrossings(ctx, events)
+	}
+	log.Printf("%d quota alert state transitions", len(events))
+}
+
+// logTransitions records alert transitions in the store for the digest
+func (h *Handler) logTransitions(ctx context.Context, events []thresholdEvent) {
+	transitions := make([]store.AlertTransition, 0, len(events))
+	for _, e := range events {
+		transitions = append(transitions, store.AlertTransition{
+			Key:             store.KeyOf(e.quota),
+			At:              e.Timestamp,
+			AlertID:         e.AlertID,
+			ServiceName:     e.ServiceName,
+			QuotaName:       e.QuotaName,
+			State:           e.Status,
+			PreviousState:   e.PreviousStatus,
+			Value:           e.Value,
+			Usage:           e.Usage,
+			UsagePercentage: e.UsagePercentage,
+			Threshold:       e.Threshold,
+			Silenced:        e.silenced,
+		})
+	}
+	if err := h.store.RecordAlertTransitions(ctx, transitions); err != nil {
+		log.Printf("Failed to log quota alert transitions: %v", err)
+	}
+}
+
+// alertTransitions updates the alert states of the quotas and returns the
+// transitions into warning, critical or resolved; those of acknowledged or
+// snoozed alerts are marked silenced. Quotas without a state are treated as
+// first seen.
+func (h *Handler) alertTransitions(ctx context.Context, at time.Time, quotas []model.Quota) []thresholdEvent {
+	keys := make([]store.QuotaKey, 0, len(quotas))
+	for _, q := range quotas {
+		keys = append(keys, store.KeyOf(q))
+	}
+	// Serialized so that overlapping fetches of a quota notify once
+	h.alertMu.Lock()
+	defer h.alertMu.Unlock()
+	states, err := h.store.AlertStates(ctx, keys)
+	if err != nil {
+		log.Printf("Failed to read quota alert states: %v", err)
+		return nil
+	}
+
+	changed := make(map[store.QuotaKey]store.AlertState)
+	var events []thresholdEvent
+	for _, q := range quotas {
+		key := store.KeyOf(q)
+		previous, seen := states[key]
+		next := previous
+		next.State = threshold.NextState(previous.State, h.thresholds.Status(q))
+		if next.State == "" {
+			continue
+		}
+		expired := next.SnoozedUntil != nil && !next.SnoozedUntil.After(at)
+		if expired {
+			next.SnoozedUntil, next.SnoozedBy = nil, ""
+		}
+		transition := !seen || next.State != previous.State
+		if !transition && !expired {
+			continue
+		}
+		if transition {
+			switch {
+			case next.State == threshold.StatusOK:
+				next = store.AlertState{State: threshold.StatusOK}
+			case next.State == threshold.StatusResolved:
+				next = store.AlertState{ID: previous.ID, State: threshold.StatusResolved}
+			case !alerting(previous.State):
+				next = store.AlertState{ID: randid.New(), State: next.State}
+			case next.State == threshold.StatusCritical:
+				// An escalation is not the breach that was acknowledged
+				next.AcknowledgedAt, next.AcknowledgedBy = nil, ""
+			}
+			next.Since = at
+		}
+		changed[key] = next
+		if next.State == threshold.StatusOK {
+			continue
+		}
+
+		event := notify.EventThresholdCrossed
+		warning, critical := h.thresholds.For(q)
+		crossed := warning
+		switch next.State {
+		case threshold.StatusCritical:
+			crossed = critical
+		case threshold.StatusResolved:
+			event = notify.EventAlertResolved
+		}
+		events = append(events, thresholdEvent{
+			Event:           event,
+			Timestamp:       at,
+			AccountID:       q.AccountID,
+			Region:          q.Region,
+			ServiceCode:     q.ServiceCode,
+			ServiceName:     q.ServiceName,
+			QuotaCode:       q.QuotaCode,
+			QuotaName:       q.QuotaName,
+			Value:           q.Value,
+			Usage:           q.Usage,
+			UsagePercentage: q.UsagePercentage,
+			AlertID:         next.ID,
+			Status:          next.State,
+			PreviousStatus:  previous.State,
+			Threshold:       crossed,
+			quota:           q,
+			// A snooze that expired while the alert is still active
+			// notifies it again, unless it is acknowledged. Quota
+			// snoozes of every rule silence it as well.
+			silenced: next.AcknowledgedAt != nil || next.SnoozedUntil != nil ||
+				h.snoozes.Silenced(q.AccountID, q.Region, q.ServiceCode, q.QuotaCode, at),
+		})
+	}
+	if err := h.store.SetAlertStates(ctx, changed); err != nil {
+		// Without stored states the transitions would be notified again
+		log.Printf("Failed to store quota alert states: %v", err)
+		return nil
+	}
+	return events
+}
+
+// alerting reports whether an alert state is warning or critical
+func alerting(state string) bool {
+	return state == threshold.StatusWarning || state == threshold.StatusCritical
+}
+
+// severity returns the severity an event is routed by: its status, or the
+// status it recovered from when resolved
+func (e thresholdEvent) severity() string {
+	if e.Status == threshold.StatusResolved {
+		return e.PreviousStatus
+	}
+	return e.Status
+}
+
+// summary describes an event in one sentence
+func (e thresholdEvent) summary() string {
+	if e.Status == threshold.StatusResolved {
+		return fmt.Sprintf("%s (%s) in %s recovered to %.1f%% of its limit",
+			e.QuotaName, e.QuotaCode, e.Region, e.UsagePercentage)
+	}
+	return fmt.Sprintf("%s (%s) in %s is %s at %.1f%% of its limit",
+		e.QuotaName, e.QuotaCode, e.Region, e.Status, e.UsagePercentage)
+}
+
+// alert returns the event as an alert, the data of the message templates
+func (e thresholdEvent) alert() alert.Alert {
+	return alert.Alert{
+		Severity:        e.severity(),
+		Threshold:       e.Threshold,
+		AccountID:       e.AccountID,
+		Region:          e.Region,
+		ServiceCode:     e.ServiceCode,
+		QuotaCode:       e.QuotaCode,
+		QuotaName:       e.QuotaName,
+		Usage:           e.Usage,
+		Value:           e.Value,
+		UsagePercentage: e.UsagePercentage,
+		Owner:           e.quota.Owner,
+		Resolved:        e.Status == threshold.StatusResolved,
+		Version:         version.String(),
+	}
+}
+
+// renderAlert renders the message of a notifier for an event from the alert
+// templates, falling back to the event summary when the template fails
+func (h *Handler) renderAlert(notifier string, e thresholdEvent) string {
+	msg, err := h.messages.Render(notifier, e.alert())
+	if err != nil {
+		log.Printf("Failed to render %s/%s %s message: %v", e.ServiceCode, e.QuotaCode, notifier, err)
+		return e.summary()
+	}
+	return msg
+}
+
+// postThresholdWebhook delivers an event to a webhook: the payload above, or
+// the webhook message template when one is configured and renders valid JSON
+func (h *Handler) postThresholdWebhook(ctx context.Context, w *notify.Webhook, e thresholdEvent) error {
+	if h.messages.Customized(alert.NotifierWebhook) {
+		body, err := h.messages.Render(alert.NotifierWebhook, e.alert())
+		if err == nil && json.Valid([]byte(body)) {
+			return w.PostBody(ctx, []byte(body))
+		}
+		log.Printf("Webhook template gave no valid JSON for %s/%s, posting the default payload", e.ServiceCode, e.QuotaCode)
+	}
+	return w.Post(ctx, e)
+}
+
+// severityRoute returns the route of an event of a severity
+func severityRoute(routes config.ThresholdRoutes, severity string) config.SeverityRoute {
+	if severity == threshold.StatusCritical {
+		return routes.Critical
+	}
+	return routes.Warning
+}
@@ -0,0 +1,287 @@
+package iac
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Consumption is the number of quota units declared or planned by Terraform
+// for a single quota
+type Consumption struct {
+	ServiceCode string   `json:"service_code"`
+	QuotaCode   string   `json:"quota_code"`
+	Units       float64  `json:"units"`
+	Resources   []string `json:"resources"`
+}
+
+// Result is the quota consumption extracted from a Terraform artifact
+type Result struct {
+	// Kind is "state" for a state file, where declared resources already
+	// exist, or "plan" for plan JSON, where units are the planned change
+	Kind        string        `json:"kind"`
+	Consumption []Consumption `json:"consumption"`
+	Skipped     []string      `json:"skipped,omitempty"`
+}
+
+// quotaTarget identifies the quota a resource type consumes
+type quotaTarget struct {
+	ServiceCode string
+	QuotaCode   string
+}
+
+// weightFunc returns the quota the resource consumes and how many units,
+// based on its attributes; ok is false when it cannot be determined
+type weightFunc func(attrs map[string]interface{}) (target quotaTarget, units float64, ok bool)
+
+func count(serviceCode, quotaCode string) weightFunc {
+	return func(map[string]interface{}) (quotaTarget, float64, bool) {
+		return quotaTarget{serviceCode, quotaCode}, 1, true
+	}
+}
+
+// resourceQuotas maps Terraform AWS provider resource types to the quotas
+// they consume, mirroring the quotas covered by the direct usage handlers
+var resourceQuotas = map[string]weightFunc{
+	// EKS
+	"aws_eks_cluster":         count("eks", "L-1194D53C"),
+	"aws_eks_node_group":      count("eks", "L-6D3F50E6"),
+	"aws_eks_fargate_profile": count("eks", "L-23414FF3"),
+	"aws_eks_addon":           count("eks", "L-6E77F4DE"),
+
+	// EC2
+	"aws_instance":         instanceVCPUs,
+	"aws_eip":              count("ec2", "L-0263D0A3"),
+	"aws_key_pair":         count("ec2", "L-0E3CBAB9"),
+	"aws_ami":              count("ec2", "L-0DA580E9"),
+	"aws_ami_copy":         count("ec2", "L-0DA580E9"),
+	"aws_ebs_snapshot":     count("ec2", "L-309BACF6"),
+	"aws_internet_gateway": count("ec2", "L-407747CB"),
+	"aws_nat_gateway":      count("ec2", "L-FE5A380F"),
+
+	// EBS
+	"aws_ebs_volume": ebsVolumeTiB,
+
+	// VPC
+	"aws_vpc":               count("vpc", "L-F678F1CE"),
+	"aws_network_interface": count("vpc", "L-DF5E4CA3"),
+	"aws_security_group":    count("vpc", "L-E79EC296"),
+
+	// ELB
+	"aws_lb":               loadBalancer,
+	"aws_alb":              loadBalancer,
+	"aws_lb_target_group":  count("elasticloadbalancing", "L-B22855CB"),
+	"aws_alb_target_group": count("elasticloadbalancing", "L-B22855CB"),
+
+	// Auto Scaling
+	"aws_autoscaling_group": count("autoscaling", "L-CDE20ADC"),
+
+	// S3
+	"aws_s3_bucket": count("s3", "L-DC2B2D3D"),
+
+	// Lambda
+	"aws_lambda_function": count("lambda", "L-9FEE3D26"),
+
+	// RDS
+	"aws_db_instance": count("rds", "L-7B6409FD"),
+	"aws_rds_cluster": count("rds", "L-952B80B8"),
+
+	// DynamoDB
+	"aws_dynamodb_table": count("dynamodb", "L-F98FE922"),
+
+	// CloudFront
+	"aws_cloudfront_distribution": count("cloudfront", "L-5B2E3F44"),
+
+	// Route53
+	"aws_route53_zone": publicHostedZone,
+
+	// IAM
+	"aws_iam_user":   count("iam", "L-4019AD8D"),
+	"aws_iam_role":   count("iam", "L-FE177D64"),
+	"aws_iam_group":  count("iam", "L-0DA4ABF3"),
+	"aws_iam_policy": count("iam", "L-D0B7243C"),
+
+	// SNS / SQS / ECR
+	"aws_sns_topic":      count("sns", "L-61103206"),
+	"aws_sqs_queue":      count("sqs", "L-75826ACE"),
+	"aws_ecr_repository": count("ecr", "L-CFEB8E8D"),
+}
+
+// instanceVCPUs counts the vCPUs of an instance against the standard On-Demand
+// vCPU quota, when the state records the CPU options
+func instanceVCPUs(attrs map[string]interface{}) (quotaTarget, float64, bool) {
+	cores, okCores := attrs["cpu_core_count"].(float64)
+	threads, okThreads := attrs["cpu_threads_per_core"].(float64)
+	if !okCores || !okThreads || cores == 0 || threads == 0 {
+		return quotaTarget{}, 0, false
+	}
+	return quotaTarget{"ec2", "L-1216C47A"}, cores * threads, true
+}
+
+var ebsVolumeQuotas = map[string]string{
+	"gp2": "L-D18FCD1D",
+	"gp3": "L-7A658B76",
+	"io1": "L-FD252861",
+	"io2": "L-09BD8365",
+}
+
+// ebsVolumeTiB counts volume size in TiB against the per-type storage quota
+func ebsVolumeTiB(attrs map[string]interface{}) (quotaTarget, float64, bool) {
+	volumeType, _ := attrs["type"].(string)
+	if volumeType == "" {
+		volumeType = "gp3"
+	}
+	quotaCode, ok := ebsVolumeQuotas[volumeType]
+	size, hasSize := attrs["size"].(float64)
+	if !ok || !hasSize {
+		return quotaTarget{}, 0, false
+	}
+	return quotaTarget{"ebs", quotaCode}, size / 1024.0, true
+}
+
+func loadBalancer(attrs map[string]interface{}) (quotaTarget, float64, bool) {
+	lbType, _ := attrs["load_balancer_type"].(string)
+	switch lbType {
+	case "", "application":
+		return quotaTarget{"elasticloadbalancing", "L-53DA6B97"}, 1, true
+	case "network":
+		return quotaTarget{"elasticloadbalancing", "L-69A177A2"}, 1, true
+	default:
+		return quotaTarget{}, 0, false
+	}
+}
+
+// publicHostedZone counts only public zones, matching the hosted zone handler
+func publicHostedZone(attrs map[string]interface{}) (quotaTarget, float64, bool) {
+	if vpcs, ok := attrs["vpc"].([]interface{}); ok && len(vpcs) > 0 {
+		return quotaTarget{}, 0, false
+	}
+	return quotaTarget{"route53", "route53:hosted-zones"}, 1, true
+}
+
+// terraformDoc covers the parts of state (format 4) and plan JSON we read
+type terraformDoc struct {
+	Version         int              `json:"version"`
+	FormatVersion   string           `json:"format_version"`
+	Resources       []stateResource  `json:"resources"`
+	ResourceChanges []resourceChange `json:"resource_changes"`
+}
+
+type stateResource struct {
+	Mode      string `json:"mode"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Module    string `json:"module"`
+	Instances []struct {
+		IndexKey   interface{}            `json:"index_key"`
+		Attributes map[string]interface{} `json:"attributes"`
+	} `json:"instances"`
+}
+
+type resourceChange struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Change  struct {
+		Actions []string               `json:"actions"`
+		Before  map[string]interface{} `json:"before"`
+		After   map[string]interface{} `json:"after"`
+	} `json:"change"`
+}
+
+// Analyze parses a Terraform state file or `terraform show -json` plan output
+// and returns the quota units its resources consume
+func Analyze(data []byte) (*Result, error) {
+	var doc terraformDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("not a Terraform state or plan JSON document: %w", err)
+	}
+
+	acc := newAccumulator()
+	switch {
+	case doc.FormatVersion != "" && doc.ResourceChanges != nil:
+		acc.kind = "plan"
+		for _, rc := range doc.ResourceChanges {
+			if rc.Mode != "managed" {
+				continue
+			}
+			for _, action := range rc.Change.Actions {
+				switch action {
+				case "create":
+					acc.add(rc.Type, rc.Address, rc.Change.After, 1)
+				case "delete":
+					acc.add(rc.Type, rc.Address, rc.Change.Before, -1)
+				}
+			}
+		}
+	case doc.Version > 0:
+		acc.kind = "state"
+		for _, r := range doc.Resources {
+			if r.Mode != "managed" {
+				continue
+			}
+			address := r.Type + "." + r.Name
+			if r.Module != "" {
+				address = r.Module + "." + address
+			}
+			for _, inst := range r.Instances {
+				addr := address
+				if inst.IndexKey != nil {
+					addr = fmt.Sprintf("%s[%v]", address, inst.IndexKey)
+				}
+				acc.add(r.Type, addr, inst.Attributes, 1)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized document: expected Terraform state (version 4) or plan JSON")
+	}
+
+	return acc.result(), nil
+}
+
+type accumulator struct {
+	kind    string
+	byQuota map[quotaTarget]*Consumption
+	skipped []string
+}
+
+func newAccumulator() *accumulator {
+	return &accumulator{byQuota: make(map[quotaTarget]*Consumption)}
+}
+
+// add records the consumption of one resource; sign is -1 for planned deletes
+func (a *accumulator) add(resourceType, address string, attrs map[string]interface{}, sign float64) {
+	weight, ok := resourceQuotas[resourceType]
+	if !ok {
+		return
+	}
+	target, units, ok := weight(attrs)
+	if !ok {
+		a.skipped = append(a.skipped, address)
+		return
+	}
+	c, exists := a.byQuota[target]
+	if !exists {
+		c = &Consumption{ServiceCode: target.ServiceCode, QuotaCode: target.QuotaCode}
+		a.byQuota[target] = c
+	}
+	c.Units += sign * units
+	// Replacements show up as a delete and a create of the same address
+	if n := len(c.Resources); n == 0 || c.Resources[n-1] != address {
+		c.Resources = append(c.Resources, address)
+	}
+}
+
+func (a *accumulator) result() *Result {
+	consumption := make([]Consumption, 0, len(a.byQuota))
+	for _, c := range a.byQuota {
+		consumption = append(consumption, *c)
+	}
+	sort.Slice(consumption, func(i, j int) bool {
+		if consumption[i].ServiceCode != consumption[j].ServiceCode {
+			return consumption[i].ServiceCode < consumption[j].ServiceCode
+		}
+		return consumption[i].QuotaCode < consumption[j].QuotaCode
+	})
+	return &Result{Kind: a.kind, Consumption: consumption, Skipped: a.skipped}
+}
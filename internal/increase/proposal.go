@@ -0,0 +1,113 @@
+package increase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/persist"
+	"github.com/yuxishi/aws-quota-dashboard/internal/randid"
+)
+
+// Proposals keeps quota increase proposals and enforces their approval
+// workflow: pending → approved → submitted or failed, or pending → rejected
+type Proposals struct {
+	mu        sync.RWMutex
+	proposals map[string]*model.IncreaseProposal
+	doc       *persist.Doc
+}
+
+func NewProposals() *Proposals {
+	return &Proposals{
+		proposals: make(map[string]*model.IncreaseProposal),
+	}
+}
+
+// Persist loads the proposals saved to a document and saves every later
+// change to it
+func (p *Proposals) Persist(ctx context.Context, doc *persist.Doc) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := doc.Load(ctx, &p.proposals); err != nil {
+		return err
+	}
+	p.doc = doc
+	return nil
+}
+
+// Add stores a new pending proposal and returns it with its ID assigned
+func (p *Proposals) Add(proposal model.IncreaseProposal) model.IncreaseProposal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	proposal.ID = randid.New()
+	proposal.Status = model.ProposalPending
+	proposal.CreatedAt = time.Now()
+	p.proposals[proposal.ID] = &proposal
+	p.doc.Save(p.proposals)
+	return proposal
+}
+
+// Get returns a proposal by ID
+func (p *Proposals) Get(id string) (model.IncreaseProposal, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	proposal, ok := p.proposals[id]
+	if !ok {
+		return model.IncreaseProposal{}, false
+	}
+	return *proposal, true
+}
+
+// Decide approves or rejects a pending proposal. Deciding a proposal that is
+// no longer pending fails, so a proposal is submitted at most once.
+func (p *Proposals) Decide(id string, approve bool, decidedBy string) (model.IncreaseProposal, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	proposal, ok := p.proposals[id]
+	if !ok {
+		return model.IncreaseProposal{}, fmt.Errorf("proposal %s not found", id)
+	}
+	if proposal.Status != model.ProposalPending {
+		return *proposal, fmt.Errorf("proposal %s is already %s", id, proposal.Status)
+	}
+	now := time.Now()
+	proposal.Status = model.ProposalRejected
+	if approve {
+		proposal.Status = model.ProposalApproved
+	}
+	proposal.DecidedBy = decidedBy
+	proposal.DecidedAt = &now
+	p.doc.Save(p.proposals)
+	return *proposal, nil
+}
+
+// Complete records the outcome of submitting an approved proposal
+func (p *Proposals) Complete(id string, req *model.IncreaseRequest, err error) model.IncreaseProposal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	proposal := p.proposals[id]
+	if err != nil {
+		proposal.Status = model.ProposalFailed
+		proposal.Error = err.Error()
+	} else {
+		proposal.Status = model.ProposalSubmitted
+		proposal.Request = req
+	}
+	p.doc.Save(p.proposals)
+	return *proposal
+}
+
+// List returns the proposals, newest first
+func (p *Proposals) List() []model.IncreaseProposal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	list := make([]model.IncreaseProposal, 0, len(p.proposals))
+	for _, proposal := range p.proposals {
+		list = append(list, *proposal)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
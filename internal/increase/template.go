@@ -0,0 +1,108 @@
+package increase
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// defaultTemplate is used when no justification templates are configured
+var defaultTemplate = config.JustificationTemplate{
+	Name:        "default",
+	Description: "Usage-based justification",
+	Text: "We are requesting an increase of {{.QuotaName}} ({{.QuotaCode}}) in {{.Region}} " +
+		"from {{.CurrentValue}} to {{.DesiredValue}}. Current usage is {{.CurrentUsage}} " +
+		"({{printf \"%.1f\" .UsagePercentage}}% of the limit)" +
+		"{{if gt .GrowthRate 0.0}} and is growing by {{printf \"%.1f\" .GrowthRate}}% per month{{end}}. " +
+		"The additional headroom is needed to support planned workload growth.",
+}
+
+// TemplateData is the set of variables available to justification templates
+type TemplateData struct {
+	QuotaName       string
+	QuotaCode       string
+	ServiceCode     string
+	ServiceName     string
+	Region          string
+	CurrentValue    float64
+	CurrentUsage    float64
+	UsagePercentage float64
+	DesiredValue    float64
+	GrowthRate      float64
+	Vars            map[string]string
+}
+
+// NewTemplateData builds template variables from a quota and the requested value
+func NewTemplateData(q *model.Quota, desiredValue, growthRate float64, vars map[string]string) TemplateData {
+	return TemplateData{
+		QuotaName:       q.QuotaName,
+		QuotaCode:       q.QuotaCode,
+		ServiceCode:     q.ServiceCode,
+		ServiceName:     q.ServiceName,
+		Region:          q.Region,
+		CurrentValue:    q.Value,
+		CurrentUsage:    q.Usage,
+		UsagePercentage: q.UsagePercentage,
+		DesiredValue:    desiredValue,
+		GrowthRate:      growthRate,
+		Vars:            vars,
+	}
+}
+
+// Templates holds the parsed justification templates by name
+type Templates struct {
+	defs   map[string]config.JustificationTemplate
+	parsed map[string]*template.Template
+}
+
+// NewTemplates parses the configured justification templates
+func NewTemplates(defs []config.JustificationTemplate) (*Templates, error) {
+	if len(defs) == 0 {
+		defs = []config.JustificationTemplate{defaultTemplate}
+	}
+
+	t := &Templates{
+		defs:   make(map[string]config.JustificationTemplate, len(defs)),
+		parsed: make(map[string]*template.Template, len(defs)),
+	}
+	for _, def := range defs {
+		if def.Name == "" {
+			return nil, fmt.Errorf("justification template without a name")
+		}
+		tmpl, err := template.New(def.Name).Option("missingkey=zero").Parse(def.Text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid justification template %q: %w", def.Name, err)
+		}
+		t.defs[def.Name] = def
+		t.parsed[def.Name] = tmpl
+	}
+	return t, nil
+}
+
+// List returns the template definitions sorted by name
+func (t *Templates) List() []config.JustificationTemplate {
+	list := make([]config.JustificationTemplate, 0, len(t.defs))
+	for _, def := range t.defs {
+		list = append(list, def)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Render executes the named template with the given data
+func (t *Templates) Render(name string, data TemplateData) (string, error) {
+	tmpl, ok := t.parsed[name]
+	if !ok {
+		return "", fmt.Errorf("unknown justification template %q", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render justification template %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
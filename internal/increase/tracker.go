@@ -0,0 +1,87 @@
+package increase
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// Request statuses under which Service Quotas has not decided yet
+const (
+	StatusPending    = "PENDING"
+	StatusCaseOpened = "CASE_OPENED"
+	StatusApproved   = "APPROVED"
+)
+
+// Undecided reports whether a request with the status may still be approved
+// or denied
+func Undecided(status string) bool {
+	return status == StatusPending || status == StatusCaseOpened
+}
+
+// Tracker keeps the increase requests submitted through the dashboard together
+// with the justification text that was attached to them
+type Tracker struct {
+	mu       sync.RWMutex
+	requests []model.IncreaseRequest
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+func (t *Tracker) Add(req model.IncreaseRequest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests = append(t.requests, req)
+}
+
+// List returns the tracked requests, newest first
+func (t *Tracker) List() []model.IncreaseRequest {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	list := make([]model.IncreaseRequest, len(t.requests))
+	copy(list, t.requests)
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// Undecided returns the tracked requests still awaiting a decision
+func (t *Tracker) Undecided() []model.IncreaseRequest {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var list []model.IncreaseRequest
+	for _, req := range t.requests {
+		if Undecided(req.Status) {
+			list = append(list, req)
+		}
+	}
+	return list
+}
+
+// SetStatus records the latest status of a request, and when it was decided.
+// It returns the updated request and whether the status changed.
+func (t *Tracker) SetStatus(id, status string, at time.Time) (model.IncreaseRequest, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.requests {
+		req := &t.requests[i]
+		if req.ID != id {
+			continue
+		}
+		if req.Status == status {
+			return *req, false
+		}
+		req.Status = status
+		if !Undecided(status) {
+			req.ResolvedAt = &at
+		}
+		return *req, true
+	}
+	return model.IncreaseRequest{}, false
+}
@@ -3,6 +3,7 @@ package model
 import "time"
 
 type Quota struct {
+	AccountID       string  `json:"account_id,omitempty"`
 	Region          string  `json:"region"`
 	ServiceCode     string  `json:"service_code"`
 	ServiceName     string  `json:"service_name"`
@@ -15,22 +16,360 @@ type Quota struct {
 	Unit            string  `json:"unit"`
 	Adjustable      bool    `json:"adjustable"`
 	Global          bool    `json:"global"`
+	Owner           string  `json:"owner,omitempty"`
+	Composite       bool    `json:"composite,omitempty"`
+	// LimitUnknown marks quotas of regions where Service Quotas is
+	// unavailable; Value is not known and only usage is reported
+	LimitUnknown bool `json:"limit_unknown,omitempty"`
+	// Status classifies usage against the configured thresholds: ok,
+	// warning, critical or unknown
+	Status string `json:"status,omitempty"`
+	// PeakUsage is the highest usage recorded in the history store and
+	// PeakUsageAt when it was observed; unset without recorded usage
+	PeakUsage   float64    `json:"peak_usage,omitempty"`
+	PeakUsageAt *time.Time `json:"peak_usage_at,omitempty"`
+	// UsageDelta and ValueDelta are the changes of usage and limit since the
+	// previous fetch recorded at PreviousFetchAt; unset without a previous
+	// observation to compare with
+	UsageDelta      *float64   `json:"usage_delta,omitempty"`
+	ValueDelta      *float64   `json:"value_delta,omitempty"`
+	PreviousFetchAt *time.Time `json:"previous_fetch_at,omitempty"`
+	// UsageDetails names the resource behind the usage of a per-resource
+	// quota, such as the VPC with the most subnets
+	UsageDetails string `json:"usage_details,omitempty"`
+	// Note, Runbook and AlertThreshold come from the quota's annotation;
+	// AlertThreshold replaces the threshold of every alert rule and the
+	// critical utilization threshold
+	Note           string  `json:"note,omitempty"`
+	Runbook        string  `json:"runbook,omitempty"`
+	AlertThreshold float64 `json:"alert_threshold,omitempty"`
 }
 
+// LimitUnknownLabel is displayed instead of the value of a quota whose limit
+// could not be read from Service Quotas
+const LimitUnknownLabel = "limit unknown (SQ unavailable)"
+
 type QuotaResponse struct {
+	// Version is the dashboard build that produced an export
+	Version   string    `json:"version,omitempty"`
 	Quotas    []Quota   `json:"quotas"`
 	Total     int       `json:"total"`
 	FetchedAt time.Time `json:"fetched_at"`
 	FromCache bool      `json:"from_cache"`
-	Warnings  []string  `json:"warnings,omitempty"`
+	// Stale marks quotas persisted before the server restarted, served
+	// while a background fetch refreshes them
+	Stale    bool     `json:"stale,omitempty"`
+	Partial  bool     `json:"partial,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+
+	// DisabledRegions were skipped because they are not enabled for the
+	// account or are disabled by a service control policy
+	DisabledRegions []string `json:"disabled_regions,omitempty"`
 }
 
 type Region struct {
 	Code string `json:"code"`
 	Name string `json:"name"`
+	// OptInStatus is the opt-in status of the region for the account;
+	// regions not opted into are not enabled
+	OptInStatus string `json:"opt_in_status,omitempty"`
+	Enabled     bool   `json:"enabled"`
 }
 
 type Service struct {
 	Code string `json:"code"`
 	Name string `json:"name"`
 }
+
+type Account struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email,omitempty"`
+	Status string `json:"status"`
+}
+
+// IncreaseRequest is a quota increase request submitted through the dashboard
+type IncreaseRequest struct {
+	ID            string    `json:"id"`
+	CaseID        string    `json:"case_id,omitempty"`
+	Region        string    `json:"region"`
+	ServiceCode   string    `json:"service_code"`
+	QuotaCode     string    `json:"quota_code"`
+	QuotaName     string    `json:"quota_name"`
+	DesiredValue  float64   `json:"desired_value"`
+	Status        string    `json:"status"`
+	Template      string    `json:"template,omitempty"`
+	Justification string    `json:"justification,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	// ResolvedAt is when the watcher saw the request approved, denied or
+	// closed
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// SupportCase is an AWS Support case about a quota, the route to raise
+// quotas Service Quotas cannot adjust
+type SupportCase struct {
+	ID          string `json:"id"`
+	DisplayID   string `json:"display_id"`
+	Subject     string `json:"subject"`
+	Status      string `json:"status"`
+	Severity    string `json:"severity"`
+	SubmittedBy string `json:"submitted_by,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// Increase proposal statuses
+const (
+	ProposalPending   = "pending"
+	ProposalApproved  = "approved"
+	ProposalRejected  = "rejected"
+	ProposalSubmitted = "submitted"
+	ProposalFailed    = "failed"
+)
+
+// IncreaseProposal is a quota increase awaiting approval before it is
+// submitted to Service Quotas
+type IncreaseProposal struct {
+	ID            string           `json:"id"`
+	Region        string           `json:"region"`
+	ServiceCode   string           `json:"service_code"`
+	QuotaCode     string           `json:"quota_code"`
+	QuotaName     string           `json:"quota_name"`
+	CurrentValue  float64          `json:"current_value"`
+	DesiredValue  float64          `json:"desired_value"`
+	Template      string           `json:"template,omitempty"`
+	Justification string           `json:"justification,omitempty"`
+	Status        string           `json:"status"`
+	RequestedBy   string           `json:"requested_by,omitempty"`
+	DecidedBy     string           `json:"decided_by,omitempty"`
+	Error         string           `json:"error,omitempty"`
+	Request       *IncreaseRequest `json:"request,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	DecidedAt     *time.Time       `json:"decided_at,omitempty"`
+}
+
+// Attribution reports which principals created the resources counted by a quota
+type Attribution struct {
+	Region      string              `json:"region"`
+	QuotaCode   string              `json:"quota_code"`
+	EventNames  []string            `json:"event_names"`
+	StartTime   time.Time           `json:"start_time"`
+	EndTime     time.Time           `json:"end_time"`
+	TotalEvents int                 `json:"total_events"`
+	Principals  []PrincipalActivity `json:"principals"`
+}
+
+type PrincipalActivity struct {
+	Principal   string    `json:"principal"`
+	Count       int       `json:"count"`
+	LastEventAt time.Time `json:"last_event_at"`
+}
+
+// Fetch status of an account or region scan
+const (
+	FetchStatusPending   = "pending"
+	FetchStatusOK        = "ok"
+	FetchStatusDenied    = "denied"
+	FetchStatusThrottled = "throttled"
+	FetchStatusError     = "error"
+)
+
+// AccountStatus is the outcome of the last scan of an account
+type AccountStatus struct {
+	AccountID     string `json:"account_id"`
+	AccountName   string `json:"account_name"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	RegionsOK     int    `json:"regions_ok"`
+	RegionsFailed int    `json:"regions_failed"`
+	QuotaCount    int    `json:"quota_count"`
+	// CredentialPath records which credentials were used for the account
+	CredentialPath string    `json:"credential_path,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// RegionsDisabled counts the regions skipped because they are disabled
+	// for the account; they are not failures
+	RegionsDisabled int `json:"regions_disabled,omitempty"`
+}
+
+// Credential paths used to access an account during an org scan
+const (
+	CredentialPathSelf     = "self"
+	CredentialPathPrimary  = "primary"
+	CredentialPathFallback = "fallback"
+)
+
+// ServiceCost is the month-to-date cost of a service from Cost Explorer
+type ServiceCost struct {
+	Amount    float64 `json:"amount"`
+	Unit      string  `json:"unit"`
+	Period    string  `json:"period"`
+	Estimated bool    `json:"estimated"`
+}
+
+// ServiceSummary aggregates the quotas of one service
+type ServiceSummary struct {
+	ServiceCode        string       `json:"service_code"`
+	ServiceName        string       `json:"service_name"`
+	QuotaCount         int          `json:"quota_count"`
+	QuotasWithUsage    int          `json:"quotas_with_usage"`
+	MaxUsagePercentage float64      `json:"max_usage_percentage"`
+	Cost               *ServiceCost `json:"cost,omitempty"`
+}
+
+// Fetch job statuses
+const (
+	FetchJobRunning   = "running"
+	FetchJobSucceeded = "succeeded"
+	FetchJobFailed    = "failed"
+)
+
+// FetchJob is a quota fetch started in the background whose progress can be
+// followed while it runs
+type FetchJob struct {
+	ID          string     `json:"id"`
+	Region      string     `json:"region"`
+	Service     string     `json:"service,omitempty"`
+	Status      string     `json:"status"`
+	QuotaCount  int        `json:"quota_count"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Run triggers
+const (
+	// RunTriggerRequest is a quota request that missed the cache
+	RunTriggerRequest = "request"
+	// RunTriggerFetchJob is a background fetch started with POST /api/fetch
+	RunTriggerFetchJob = "fetch_job"
+	// RunTriggerOrgScan is an organization scan
+	RunTriggerOrgScan = "org_scan"
+	// RunTriggerRefresh is a background refresh of quotas persisted before
+	// the server restarted
+	RunTriggerRefresh = "refresh"
+)
+
+// Run is a completed fetch or org scan. Status is FetchJobSucceeded or
+// FetchJobFailed; ErrorCount counts the regions, or for org scans the
+// accounts and regions, that failed within a run that completed.
+type Run struct {
+	ID          string    `json:"id"`
+	Trigger     string    `json:"trigger"`
+	Service     string    `json:"service,omitempty"`
+	Regions     []string  `json:"regions"`
+	Accounts    int       `json:"accounts,omitempty"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	ErrorCount  int       `json:"error_count"`
+	APICalls    int64     `json:"api_calls"`
+	QuotaCount  int       `json:"quota_count"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	DurationMS  float64   `json:"duration_ms"`
+}
+
+// Fetch log levels
+const (
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// FetchLogLine is a structured progress line emitted during a quota fetch
+type FetchLogLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Region  string    `json:"region,omitempty"`
+	Service string    `json:"service,omitempty"`
+	Message string    `json:"message"`
+}
+
+// Quota review item states
+const (
+	ReviewPending      = "pending"
+	ReviewAcknowledged = "acknowledged"
+	ReviewJustified    = "justified"
+	ReviewActionNeeded = "action_needed"
+)
+
+// Quota review statuses
+const (
+	ReviewOpen      = "open"
+	ReviewSignedOff = "signed_off"
+)
+
+// ReviewItem is one quota under review and its reviewer's verdict
+type ReviewItem struct {
+	ID              string     `json:"id"`
+	AccountID       string     `json:"account_id,omitempty"`
+	Region          string     `json:"region"`
+	ServiceCode     string     `json:"service_code"`
+	QuotaCode       string     `json:"quota_code"`
+	QuotaName       string     `json:"quota_name"`
+	Value           float64    `json:"value"`
+	Usage           float64    `json:"usage"`
+	UsagePercentage float64    `json:"usage_percentage"`
+	Reviewer        string     `json:"reviewer,omitempty"`
+	State           string     `json:"state"`
+	Note            string     `json:"note,omitempty"`
+	UpdatedBy       string     `json:"updated_by,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+// ReviewProgress counts the items of a review per state
+type ReviewProgress struct {
+	Total    int            `json:"total"`
+	Reviewed int            `json:"reviewed"`
+	Percent  float64        `json:"percent"`
+	ByState  map[string]int `json:"by_state"`
+	// ByReviewer counts the items still pending per reviewer
+	ByReviewer map[string]int `json:"pending_by_reviewer"`
+}
+
+// Review is a periodic capacity review of the quotas above a usage threshold
+type Review struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Threshold   float64        `json:"threshold"`
+	Reviewers   []string       `json:"reviewers,omitempty"`
+	Status      string         `json:"status"`
+	Items       []ReviewItem   `json:"items,omitempty"`
+	Progress    ReviewProgress `json:"progress"`
+	CreatedAt   time.Time      `json:"created_at"`
+	SignedOffBy string         `json:"signed_off_by,omitempty"`
+	SignedOffAt *time.Time     `json:"signed_off_at,omitempty"`
+}
+
+// CoverageRequest aggregates the requests for a usage handler of a quota
+// that has none
+type CoverageRequest struct {
+	ServiceCode      string    `json:"service_code"`
+	QuotaCode        string    `json:"quota_code"`
+	QuotaName        string    `json:"quota_name,omitempty"`
+	Count            int       `json:"count"`
+	Requesters       []string  `json:"requesters,omitempty"`
+	Notes            []string  `json:"notes,omitempty"`
+	FirstRequestedAt time.Time `json:"first_requested_at"`
+	LastRequestedAt  time.Time `json:"last_requested_at"`
+}
+
+// QuotaCoverage tells whether the usage of a quota is counted by a direct
+// usage handler, and how often a handler was requested if not
+type QuotaCoverage struct {
+	ServiceCode string `json:"service_code"`
+	ServiceName string `json:"service_name,omitempty"`
+	QuotaCode   string `json:"quota_code"`
+	QuotaName   string `json:"quota_name"`
+	Covered     bool   `json:"covered"`
+	Requests    int    `json:"requests"`
+}
+
+// APIRate is the adaptive request rate of one AWS API in a region
+type APIRate struct {
+	Region             string  `json:"region"`
+	API                string  `json:"api"`
+	Rate               float64 `json:"rate"`
+	Requests           int     `json:"requests"`
+	Throttled          int     `json:"throttled"`
+	ThrottlePercentage float64 `json:"throttle_percentage"`
+}
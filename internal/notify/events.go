@@ -0,0 +1,20 @@
+package notify
+
+// Types of the quota events sent to webhooks and SNS
+const (
+	// EventThresholdCrossed is sent when a quota moves into warning or
+	// critical
+	EventThresholdCrossed = "quota.threshold_crossed"
+	// EventAlertResolved is sent when a quota in warning or critical
+	// recovers below its thresholds
+	EventAlertResolved = "quota.alert_resolved"
+	// EventLimitAnomaly is sent when a quota limit changes without an
+	// increase request explaining it
+	EventLimitAnomaly = "quota.limit_anomaly"
+	// EventIncreaseDecided is sent when Service Quotas approves, denies or
+	// closes an increase request
+	EventIncreaseDecided = "quota.increase_decided"
+	// EventDigest is sent on the digest schedule with a summary of the
+	// period
+	EventDigest = "quota.digest"
+)
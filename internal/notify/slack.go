@@ -0,0 +1,199 @@
+// Package notify delivers dashboard notifications to chat systems (Slack,
+// Microsoft Teams) and webhooks
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxSignatureAge rejects replayed Slack requests
+const maxSignatureAge = 5 * time.Minute
+
+// postMessageURL is the Web API method bots post messages with
+const postMessageURL = "https://slack.com/api/chat.postMessage"
+
+// Message is a Slack message in Block Kit format. Text is the notification
+// fallback when blocks are present.
+type Message struct {
+	// Channel is only honored when posting with a bot token; incoming
+	// webhooks always post to their own channel
+	Channel         string  `json:"channel,omitempty"`
+	Text            string  `json:"text"`
+	Blocks          []Block `json:"blocks,omitempty"`
+	ResponseType    string  `json:"response_type,omitempty"`
+	ReplaceOriginal bool    `json:"replace_original,omitempty"`
+}
+
+// Block is a Block Kit layout block
+type Block struct {
+	Type     string    `json:"type"`
+	BlockID  string    `json:"block_id,omitempty"`
+	Text     *Text     `json:"text,omitempty"`
+	Fields   []Text    `json:"fields,omitempty"`
+	Elements []Element `json:"elements,omitempty"`
+}
+
+// Text is a Block Kit text object
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Element is an interactive Block Kit element
+type Element struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	ActionID string `json:"action_id,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Style    string `json:"style,omitempty"`
+}
+
+// Markdown returns a mrkdwn text object
+func Markdown(text string) *Text {
+	return &Text{Type: "mrkdwn", Text: text}
+}
+
+// Button returns a button element
+func Button(label, actionID, value, style string) Element {
+	return Element{
+		Type:     "button",
+		Text:     &Text{Type: "plain_text", Text: label},
+		ActionID: actionID,
+		Value:    value,
+		Style:    style,
+	}
+}
+
+// Slack posts messages to an incoming webhook, or with a bot token
+type Slack struct {
+	webhookURL string
+	token      string
+	channel    string
+	client     *http.Client
+}
+
+func NewSlack(webhookURL string) *Slack {
+	return &Slack{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewSlackBot creates a client posting with a bot token to the channel of
+// each message, or to channel when a message has none
+func NewSlackBot(token, channel string) *Slack {
+	return &Slack{
+		token:   token,
+		channel: channel,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Post sends a message to the webhook's channel, or with a bot token to the
+// message's channel
+func (s *Slack) Post(ctx context.Context, msg Message) error {
+	if s.token == "" {
+		return s.post(ctx, s.webhookURL, "", msg)
+	}
+	if msg.Channel == "" {
+		msg.Channel = s.channel
+	}
+	return s.post(ctx, postMessageURL, s.token, msg)
+}
+
+// Respond sends a message to the response URL of an interaction, e.g. to
+// replace the message whose button was clicked
+func (s *Slack) Respond(ctx context.Context, responseURL string, msg Message) error {
+	return s.post(ctx, responseURL, "", msg)
+}
+
+// post sends a message to url, authenticated with token when it is a Web API
+// call
+func (s *Slack) post(ctx context.Context, url, token string, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, err := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if err != nil {
+			return fmt.Errorf("slack returned %s", resp.Status)
+		}
+		return fmt.Errorf("slack returned %s: %s", resp.Status, detail)
+	}
+	if token == "" {
+		return nil
+	}
+	// The Web API reports failures in the body of a 200 response
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&result); err != nil {
+		return fmt.Errorf("invalid slack response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack returned error: %s", result.Error)
+	}
+	return nil
+}
+
+// VerifySignature checks the X-Slack-Signature of a request against the app's
+// signing secret, rejecting requests older than five minutes
+func VerifySignature(signingSecret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid request timestamp")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return fmt.Errorf("request timestamp too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// Interaction is the payload Slack posts when a user clicks a message button
+type Interaction struct {
+	Type        string `json:"type"`
+	ResponseURL string `json:"response_url"`
+	User        struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
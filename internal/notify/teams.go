@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Card is a Microsoft Teams connector card (legacy MessageCard format)
+type Card struct {
+	Type            string        `json:"@type"`
+	Context         string        `json:"@context"`
+	Summary         string        `json:"summary"`
+	ThemeColor      string        `json:"themeColor,omitempty"`
+	Title           string        `json:"title,omitempty"`
+	Text            string        `json:"text,omitempty"`
+	Sections        []CardSection `json:"sections,omitempty"`
+	PotentialAction []CardAction  `json:"potentialAction,omitempty"`
+}
+
+// CardSection is a section of a connector card
+type CardSection struct {
+	ActivityTitle string `json:"activityTitle,omitempty"`
+	Text          string `json:"text,omitempty"`
+	Facts         []Fact `json:"facts,omitempty"`
+}
+
+// Fact is a name/value pair of a card section
+type Fact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CardAction is a button of a connector card
+type CardAction struct {
+	Type    string       `json:"@type"`
+	Name    string       `json:"name"`
+	Targets []CardTarget `json:"targets,omitempty"`
+}
+
+// CardTarget is the URI a card action opens
+type CardTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+// NewCard returns a connector card with a summary and title
+func NewCard(summary, title, themeColor string) Card {
+	return Card{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    summary,
+		ThemeColor: themeColor,
+		Title:      title,
+	}
+}
+
+// OpenURI returns a card action opening a URL
+func OpenURI(name, uri string) CardAction {
+	return CardAction{
+		Type:    "OpenUri",
+		Name:    name,
+		Targets: []CardTarget{{OS: "default", URI: uri}},
+	}
+}
+
+// Teams posts connector cards to Microsoft Teams incoming webhooks
+type Teams struct {
+	webhookURL string
+	client     *http.Client
+}
+
+func NewTeams(webhookURL string) *Teams {
+	return &Teams{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Post sends a card to the webhook's channel
+func (t *Teams) Post(ctx context.Context, card Card) error {
+	return t.PostTo(ctx, t.webhookURL, card)
+}
+
+// PostTo sends a card to the channel of another incoming webhook
+func (t *Teams) PostTo(ctx context.Context, webhookURL string, card Card) error {
+	body, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, err := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if err != nil {
+			return fmt.Errorf("teams returned %s", resp.Status)
+		}
+		return fmt.Errorf("teams returned %s: %s", resp.Status, detail)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of a webhook delivery. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" with the endpoint's secret, prefixed with "sha256=".
+const (
+	WebhookSignatureHeader = "X-Quota-Dashboard-Signature"
+	WebhookTimestampHeader = "X-Quota-Dashboard-Timestamp"
+)
+
+// DefaultWebhookAttempts is how often a delivery is tried when no attempt
+// count is configured
+const DefaultWebhookAttempts = 3
+
+// webhookBackoff is the delay before the first retry; it doubles with each
+// further retry
+const webhookBackoff = time.Second
+
+// Webhook POSTs JSON payloads to an endpoint, signing them when a secret is
+// set and retrying failed deliveries
+type Webhook struct {
+	url      string
+	secret   string
+	attempts int
+	client   *http.Client
+}
+
+// NewWebhook creates a webhook for an endpoint. A non-positive attempt count
+// uses DefaultWebhookAttempts.
+func NewWebhook(url, secret string, attempts int) *Webhook {
+	if attempts <= 0 {
+		attempts = DefaultWebhookAttempts
+	}
+	return &Webhook{
+		url:      url,
+		secret:   secret,
+		attempts: attempts,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// URL returns the endpoint of the webhook
+func (w *Webhook) URL() string {
+	return w.url
+}
+
+// Post delivers a payload. Network errors, 429 and 5xx responses are retried
+// with exponential backoff; other responses fail the delivery at once.
+func (w *Webhook) Post(ctx context.Context, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return w.PostBody(ctx, body)
+}
+
+// PostBody delivers an encoded JSON body, retried like Post
+func (w *Webhook) PostBody(ctx context.Context, body []byte) error {
+	delay := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.deliver(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.attempts {
+			return fmt.Errorf("webhook %s failed after %d attempts: %w", w.url, attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// deliver makes one delivery attempt and reports whether a failure is worth
+// retrying
+func (w *Webhook) deliver(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(w.secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	detail, err := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if err != nil {
+		detail = nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("endpoint returned %s: %s", resp.Status, detail)
+}
+
+// SignWebhook returns the signature header value of a webhook body
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,209 @@
+package org
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// DefaultDemoAccounts is the number of simulated accounts when none is
+// configured
+const DefaultDemoAccounts = 6
+
+// demoAccountProfiles name the simulated accounts and scale their usage:
+// production accounts run close to their limits, sandboxes barely use them
+var demoAccountProfiles = []struct {
+	name  string
+	scale float64
+}{
+	{"prod-payments", 1.0},
+	{"prod-search", 0.9},
+	{"data-platform", 0.75},
+	{"staging", 0.45},
+	{"shared-services", 0.35},
+	{"dev-sandbox", 0.2},
+	{"ml-training", 0.8},
+	{"security-audit", 0.1},
+}
+
+// demoServiceNames are the display names of the simulated services
+var demoServiceNames = map[string]string{
+	"dynamodb":             "Amazon DynamoDB",
+	"ebs":                  "Amazon Elastic Block Store (Amazon EBS)",
+	"ec2":                  "Amazon Elastic Compute Cloud (Amazon EC2)",
+	"eks":                  "Amazon Elastic Kubernetes Service (Amazon EKS)",
+	"elasticloadbalancing": "Elastic Load Balancing (ELB)",
+	"iam":                  "AWS Identity and Access Management (IAM)",
+	"rds":                  "Amazon Relational Database Service (Amazon RDS)",
+	"s3":                   "Amazon Simple Storage Service (Amazon S3)",
+	"vpc":                  "Amazon Virtual Private Cloud (Amazon VPC)",
+}
+
+// demoQuotas are the simulated quotas. base is the share of the limit a
+// full-scale account uses in its primary region; growth is the share added
+// per scan, so history and trends move; integer quotas count resources.
+var demoQuotas = []struct {
+	service string
+	code    string
+	name    string
+	global  bool
+	limit   float64
+	base    float64
+	growth  float64
+	integer bool
+}{
+	{"ec2", "L-1216C47A", "Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances", false, 1152, 0.78, 0.004, true},
+	{"ec2", "L-DB2E81BA", "Running On-Demand G and VT instances", false, 256, 0.55, 0.006, true},
+	{"ec2", "L-0263D0A3", "EC2-VPC Elastic IPs", false, 5, 0.7, 0, true},
+	{"vpc", "L-F678F1CE", "VPCs per Region", false, 5, 0.6, 0, true},
+	{"ebs", "L-D18FCD1D", "Storage for General Purpose SSD (gp2) volumes, in TiB", false, 50, 0.5, 0.003, false},
+	{"ebs", "L-7A658B76", "Storage for General Purpose SSD (gp3) volumes, in TiB", false, 50, 0.65, 0.005, false},
+	{"rds", "L-7B6409FD", "DB instances", false, 40, 0.5, 0.002, true},
+	{"eks", "L-1194D53C", "Clusters", false, 100, 0.15, 0.001, true},
+	{"elasticloadbalancing", "L-53DA6B97", "Application Load Balancers per Region", false, 50, 0.45, 0.002, true},
+	{"dynamodb", "L-F98FE922", "Maximum number of tables", false, 2500, 0.2, 0.001, true},
+	{"iam", "L-FE177D64", "Roles per account", true, 1000, 0.6, 0.002, true},
+	{"s3", "L-DC2B2D3D", "General purpose buckets", true, 100, 0.55, 0.002, true},
+}
+
+// Demo simulates an organization, so the multi-account inventory, its
+// comparisons and alerts can be shown and tested without AWS. Usage is
+// deterministic for a seed and correlated: an account's scale and a
+// per-scan deployment wave move all its quotas together, usage falls off
+// from the primary region to the others, and grows slowly from scan to scan.
+type Demo struct {
+	seed     int64
+	accounts []model.Account
+	regions  []string
+
+	mu   sync.Mutex
+	scan int
+}
+
+// NewDemo creates the simulated organization
+func NewDemo(cfg config.DemoConfig, regions []string) *Demo {
+	n := cfg.Accounts
+	if n <= 0 {
+		n = DefaultDemoAccounts
+	}
+	d := &Demo{seed: cfg.Seed, regions: regions}
+	for i := 0; i < n; i++ {
+		profile := demoAccountProfiles[i%len(demoAccountProfiles)]
+		name := profile.name
+		if i >= len(demoAccountProfiles) {
+			name = fmt.Sprintf("%s-%d", name, i/len(demoAccountProfiles)+1)
+		}
+		d.accounts = append(d.accounts, model.Account{
+			ID:     fmt.Sprintf("1000000000%02d", i+1),
+			Name:   name,
+			Email:  name + "@demo.example.com",
+			Status: "ACTIVE",
+		})
+	}
+	return d
+}
+
+// NewDemoScanner creates a scanner walking the simulated organization
+func NewDemoScanner(demo *Demo, cfg config.OrgScanConfig, concurrency int) *Scanner {
+	s := NewScanner(nil, cfg, demo.regions, concurrency)
+	s.demo = demo
+	return s
+}
+
+// listAccounts returns the simulated accounts and starts a new simulated
+// scan
+func (d *Demo) listAccounts() []model.Account {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.scan++
+	return d.accounts
+}
+
+// fetcher returns the simulated quota source of an account
+func (d *Demo) fetcher(account model.Account) regionFetcher {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return &demoFetcher{demo: d, account: account, scan: d.scan}
+}
+
+type demoFetcher struct {
+	demo    *Demo
+	account model.Account
+	scan    int
+}
+
+// GetQuotasForRegion returns the simulated quotas of the account in a region
+func (f *demoFetcher) GetQuotasForRegion(_ context.Context, region, serviceFilter string) ([]model.Quota, error) {
+	d := f.demo
+	scale := demoAccountScale(f.account.Name)
+	// The deployment wave of this account and scan, shared by all its quotas
+	wave := 0.9 + 0.2*d.noise(f.account.ID, "wave", "", f.scan)
+
+	var quotas []model.Quota
+	for _, dq := range demoQuotas {
+		if serviceFilter != "" && dq.service != serviceFilter {
+			continue
+		}
+		weight := 1.0
+		if !dq.global {
+			weight = d.regionWeight(region)
+		}
+		share := dq.base * scale * weight * wave * (1 + dq.growth*float64(f.scan))
+		share *= 0.9 + 0.2*d.noise(f.account.ID, region, dq.code, f.scan)
+		usage := math.Min(share, 1) * dq.limit
+		if dq.integer {
+			usage = math.Round(usage)
+		} else {
+			usage = math.Round(usage*10) / 10
+		}
+
+		quotas = append(quotas, model.Quota{
+			AccountID:       f.account.ID,
+			Region:          region,
+			ServiceCode:     dq.service,
+			ServiceName:     demoServiceNames[dq.service],
+			QuotaName:       dq.name,
+			QuotaCode:       dq.code,
+			Value:           dq.limit,
+			Usage:           usage,
+			UsagePercentage: usage / dq.limit * 100,
+			HasUsageMetrics: true,
+			Unit:            "None",
+			Adjustable:      true,
+			Global:          dq.global,
+		})
+	}
+	return quotas, nil
+}
+
+// regionWeight falls off from the first (primary) region to the others
+func (d *Demo) regionWeight(region string) float64 {
+	for i, r := range d.regions {
+		if r == region {
+			return 1 / (1 + 0.8*float64(i))
+		}
+	}
+	return 0.3
+}
+
+// noise returns a deterministic value in [0, 1) for the seed and its inputs
+func (d *Demo) noise(account, region, code string, scan int) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%d/%d/%s/%s/%s", d.seed, scan, account, region, code)))
+	return float64(h.Sum64()>>11) / float64(1<<53)
+}
+
+func demoAccountScale(name string) float64 {
+	for _, p := range demoAccountProfiles {
+		if strings.HasPrefix(name, p.name) {
+			return p.scale
+		}
+	}
+	return 0.5
+}
@@ -0,0 +1,432 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/yuxishi/aws-quota-dashboard/internal/aws"
+	"github.com/yuxishi/aws-quota-dashboard/internal/composite"
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// ErrScanInProgress is returned when a scan is started while another runs
+var ErrScanInProgress = errors.New("org scan already in progress")
+
+// Inventory is the consolidated quota snapshot of all organization accounts
+type Inventory struct {
+	Accounts    []model.Account `json:"accounts"`
+	Quotas      []model.Quota   `json:"quotas"`
+	Warnings    []string        `json:"warnings,omitempty"`
+	StartedAt   time.Time       `json:"started_at"`
+	CompletedAt time.Time       `json:"completed_at"`
+	// APICalls counts the AWS call attempts of the scan, retries included
+	APICalls int64 `json:"api_calls,omitempty"`
+}
+
+// Scanner periodically walks all organization accounts and keeps the
+// consolidated quota inventory warm
+type Scanner struct {
+	fetcher     *aws.QuotaFetcher
+	cfg         config.OrgScanConfig
+	regions     []string
+	concurrency int
+	cron        *cron.Cron
+
+	mu        sync.RWMutex
+	inventory *Inventory
+	partial   *Inventory
+	scanning  bool
+	statuses  map[string]*model.AccountStatus
+	hooks     []func(*Inventory)
+	failHooks []func(error)
+	startedAt time.Time
+
+	composites []composite.Quota
+	// demo replaces the organization and its accounts with a simulation
+	demo *Demo
+}
+
+func NewScanner(fetcher *aws.QuotaFetcher, cfg config.OrgScanConfig, regions []string, concurrency int) *Scanner {
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+	return &Scanner{
+		fetcher:     fetcher,
+		cfg:         cfg,
+		regions:     regions,
+		concurrency: concurrency,
+		statuses:    make(map[string]*model.AccountStatus),
+	}
+}
+
+// Start schedules the scan on the configured cron expression and, if enabled,
+// runs an initial scan in the background
+func (s *Scanner) Start(ctx context.Context) error {
+	s.cron = cron.New()
+	if _, err := s.cron.AddFunc(s.cfg.Schedule, func() { s.runScheduled(ctx) }); err != nil {
+		return fmt.Errorf("invalid org scan schedule %q: %w", s.cfg.Schedule, err)
+	}
+	s.cron.Start()
+
+	if s.cfg.ScanOnStart {
+		go s.runScheduled(ctx)
+	}
+	return nil
+}
+
+// Stop stops the scheduler; a scan in progress is not interrupted
+func (s *Scanner) Stop() {
+	if s.cron != nil {
+		s.cron.Stop()
+	}
+}
+
+func (s *Scanner) runScheduled(ctx context.Context) {
+	if err := s.Scan(ctx); err != nil {
+		log.Printf("Org scan failed: %v", err)
+	}
+}
+
+// SetComposites sets the composite quotas evaluated per account and region
+// of each completed scan
+func (s *Scanner) SetComposites(composites []composite.Quota) {
+	s.composites = composites
+}
+
+// OnComplete registers a hook called with the new inventory after each
+// completed scan. Hooks must be registered before Start.
+func (s *Scanner) OnComplete(hook func(*Inventory)) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// OnFailure registers a hook called with the error of each scan that fails
+// entirely. Hooks must be registered before Start.
+func (s *Scanner) OnFailure(hook func(error)) {
+	s.failHooks = append(s.failHooks, hook)
+}
+
+// Inventory returns the latest completed inventory, or nil if no scan has
+// completed yet
+func (s *Scanner) Inventory() *Inventory {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.inventory
+}
+
+// Scanning reports whether a scan is currently in progress
+func (s *Scanner) Scanning() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.scanning
+}
+
+// RunningSince returns the start time of the scan in progress, or of the
+// last scan when none is running
+func (s *Scanner) RunningSince() (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.startedAt, s.scanning
+}
+
+// Scan walks every active account in the organization. Region scans are
+// interleaved fairly across accounts; results accumulate in a partial
+// inventory that replaces the completed one once all accounts have been visited.
+// The failure hooks are called when the scan fails.
+func (s *Scanner) Scan(ctx context.Context) error {
+	err := s.scan(ctx)
+	if err != nil && !errors.Is(err, ErrScanInProgress) {
+		for _, hook := range s.failHooks {
+			hook(err)
+		}
+	}
+	return err
+}
+
+func (s *Scanner) scan(ctx context.Context) error {
+	startedAt := time.Now()
+	s.mu.Lock()
+	if s.scanning {
+		s.mu.Unlock()
+		return ErrScanInProgress
+	}
+	s.scanning = true
+	s.startedAt = startedAt
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.scanning = false
+		s.partial = nil
+		s.mu.Unlock()
+	}()
+
+	calls := &aws.CallCounter{}
+	ctx = aws.WithCallCounter(ctx, calls)
+	accounts, selfID, err := s.listAccounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Org scan started: %d accounts, %d regions", len(accounts), len(s.regions))
+
+	partial := &Inventory{
+		Accounts:  accounts,
+		StartedAt: startedAt,
+	}
+	s.mu.Lock()
+	s.partial = partial
+	s.mu.Unlock()
+
+	s.resetStatuses(accounts)
+
+	queue := newFairQueue()
+	for _, account := range accounts {
+		if account.Status != "ACTIVE" {
+			continue
+		}
+		fetcher, path, err := s.accountFetcher(ctx, account, selfID)
+		s.setCredentialPath(account.ID, path)
+		if err != nil {
+			status := aws.ClassifyError(err)
+			s.setAccountFailed(account.ID, status, err)
+			s.addResults(nil, []string{fmt.Sprintf("[%s] %s: failed to access account: %v", account.ID, status, err)})
+			continue
+		}
+		for _, region := range s.regions {
+			queue.push(task{account: account, fetcher: fetcher, region: region})
+		}
+	}
+
+	queue.run(s.concurrency, func(t task) {
+		quotas, err := t.fetcher.GetQuotasForRegion(ctx, t.region, s.cfg.Service)
+		if err != nil && aws.IsRegionDisabled(err) {
+			// Regions the account has not enabled are expected, not failures
+			s.recordRegionDisabled(t.account.ID)
+			return
+		}
+		s.recordRegion(t.account.ID, len(quotas), err)
+		if err != nil {
+			status := aws.ClassifyError(err)
+			s.addResults(nil, []string{fmt.Sprintf("[%s] %s: failed to fetch quotas for region %s: %v", t.account.ID, status, t.region, err)})
+			return
+		}
+		for i := range quotas {
+			quotas[i].AccountID = t.account.ID
+		}
+		s.addResults(quotas, nil)
+	})
+
+	s.mu.Lock()
+	quotas := aws.DeduplicateGlobalQuotas(partial.Quotas)
+	if s.cfg.Service == "" {
+		quotas = composite.Append(s.composites, quotas)
+	}
+	inventory := &Inventory{
+		Accounts:    accounts,
+		Quotas:      quotas,
+		Warnings:    partial.Warnings,
+		StartedAt:   startedAt,
+		CompletedAt: time.Now(),
+		APICalls:    calls.Count(),
+	}
+	s.inventory = inventory
+	s.mu.Unlock()
+
+	log.Printf("Org scan completed in %s: %d quotas, %d warnings",
+		inventory.CompletedAt.Sub(startedAt).Round(time.Second), len(inventory.Quotas), len(inventory.Warnings))
+
+	for _, hook := range s.hooks {
+		hook(inventory)
+	}
+	return nil
+}
+
+// addResults appends the results of one task to the partial inventory
+func (s *Scanner) addResults(quotas []model.Quota, warnings []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.partial == nil {
+		return
+	}
+	s.partial.Quotas = append(s.partial.Quotas, quotas...)
+	s.partial.Warnings = append(s.partial.Warnings, warnings...)
+}
+
+// Partial returns a copy of the inventory of the scan in progress, or nil when
+// no scan is running
+func (s *Scanner) Partial() *Inventory {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.partial == nil {
+		return nil
+	}
+	quotas := make([]model.Quota, len(s.partial.Quotas))
+	copy(quotas, s.partial.Quotas)
+	return &Inventory{
+		Accounts:  s.partial.Accounts,
+		Quotas:    aws.DeduplicateGlobalQuotas(quotas),
+		Warnings:  append([]string(nil), s.partial.Warnings...),
+		StartedAt: s.partial.StartedAt,
+	}
+}
+
+// listAccounts lists the organization accounts and the account the server
+// runs in
+func (s *Scanner) listAccounts(ctx context.Context) ([]model.Account, string, error) {
+	if s.demo != nil {
+		return s.demo.listAccounts(), "", nil
+	}
+	accounts, err := aws.ListOrgAccounts(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list organization accounts: %w", err)
+	}
+	selfID, err := aws.GetCallerAccountID(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve caller account: %w", err)
+	}
+	return accounts, selfID, nil
+}
+
+// accountFetcher returns a fetcher using the member account role, or the
+// server's own credentials for the account it runs in. When the primary role
+// cannot be assumed the fallback role is tried. The credential path used is
+// returned along with the fetcher.
+func (s *Scanner) accountFetcher(ctx context.Context, account model.Account, selfID string) (regionFetcher, string, error) {
+	if s.demo != nil {
+		return s.demo.fetcher(account), model.CredentialPathSelf, nil
+	}
+	if account.ID == selfID {
+		return s.fetcher, model.CredentialPathSelf, nil
+	}
+
+	primary, fallback := s.cfg.AccountRoles(account.ID)
+	fetcher, err := s.assumeRole(ctx, aws.RoleARN(account.ID, primary))
+	if err == nil {
+		return fetcher, model.CredentialPathPrimary, nil
+	}
+	if fallback == "" {
+		return nil, model.CredentialPathPrimary, err
+	}
+
+	log.Printf("Org scan: primary role failed for account %s, trying fallback: %v", account.ID, err)
+	fetcher, fallbackErr := s.assumeRole(ctx, aws.RoleARN(account.ID, fallback))
+	if fallbackErr != nil {
+		return nil, model.CredentialPathFallback, fmt.Errorf("primary role: %v; fallback role: %w", err, fallbackErr)
+	}
+	return fetcher, model.CredentialPathFallback, nil
+}
+
+func (s *Scanner) assumeRole(ctx context.Context, roleARN string) (*aws.QuotaFetcher, error) {
+	provider, err := aws.AssumeRoleCredentials(ctx, roleARN)
+	if err != nil {
+		return nil, err
+	}
+	// Assume the role up front so a missing or untrusted role fails the
+	// account once instead of every region scan
+	if _, err := provider.Retrieve(ctx); err != nil {
+		return nil, err
+	}
+	return s.fetcher.WithCredentials(provider), nil
+}
+
+// statusSeverity orders fetch statuses so an account reports its worst outcome
+var statusSeverity = map[string]int{
+	model.FetchStatusPending:   0,
+	model.FetchStatusOK:        1,
+	model.FetchStatusThrottled: 2,
+	model.FetchStatusError:     3,
+	model.FetchStatusDenied:    4,
+}
+
+// AccountStatuses returns the fetch status of every active account from the
+// current or last scan, ordered by account ID
+func (s *Scanner) AccountStatuses() []model.AccountStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	statuses := make([]model.AccountStatus, 0, len(s.statuses))
+	for _, st := range s.statuses {
+		statuses = append(statuses, *st)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].AccountID < statuses[j].AccountID })
+	return statuses
+}
+
+func (s *Scanner) resetStatuses(accounts []model.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses = make(map[string]*model.AccountStatus, len(accounts))
+	now := time.Now()
+	for _, account := range accounts {
+		if account.Status != "ACTIVE" {
+			continue
+		}
+		s.statuses[account.ID] = &model.AccountStatus{
+			AccountID:   account.ID,
+			AccountName: account.Name,
+			Status:      model.FetchStatusPending,
+			UpdatedAt:   now,
+		}
+	}
+}
+
+func (s *Scanner) setCredentialPath(accountID, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.statuses[accountID]; ok {
+		st.CredentialPath = path
+	}
+}
+
+func (s *Scanner) setAccountFailed(accountID, status string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.statuses[accountID]; ok {
+		st.Status = status
+		st.Error = err.Error()
+		st.RegionsFailed = len(s.regions)
+		st.UpdatedAt = time.Now()
+	}
+}
+
+// recordRegionDisabled counts a region skipped because it is disabled for the
+// account
+func (s *Scanner) recordRegionDisabled(accountID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.statuses[accountID]; ok {
+		st.RegionsDisabled++
+		if st.Status == model.FetchStatusPending {
+			st.Status = model.FetchStatusOK
+		}
+		st.UpdatedAt = time.Now()
+	}
+}
+
+// recordRegion folds the outcome of one region scan into the account status
+func (s *Scanner) recordRegion(accountID string, quotaCount int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.statuses[accountID]
+	if !ok {
+		return
+	}
+	status := aws.ClassifyError(err)
+	if err != nil {
+		st.RegionsFailed++
+		st.Error = err.Error()
+	} else {
+		st.RegionsOK++
+		st.QuotaCount += quotaCount
+	}
+	if statusSeverity[status] > statusSeverity[st.Status] {
+		st.Status = status
+	}
+	st.UpdatedAt = time.Now()
+}
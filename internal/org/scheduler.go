@@ -0,0 +1,78 @@
+package org
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// regionFetcher fetches the quotas of one account in a region
+type regionFetcher interface {
+	GetQuotasForRegion(ctx context.Context, region, serviceFilter string) ([]model.Quota, error)
+}
+
+// task is a single region scan within one account
+type task struct {
+	account model.Account
+	fetcher regionFetcher
+	region  string
+}
+
+// fairQueue hands out tasks round-robin across accounts, so an account with
+// many regions or services cannot starve the others and every account gets
+// partial results early in the scan
+type fairQueue struct {
+	mu     sync.Mutex
+	queues map[string][]task
+	order  []string
+	next   int
+}
+
+func newFairQueue() *fairQueue {
+	return &fairQueue{queues: make(map[string][]task)}
+}
+
+func (q *fairQueue) push(t task) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, exists := q.queues[t.account.ID]; !exists {
+		q.order = append(q.order, t.account.ID)
+	}
+	q.queues[t.account.ID] = append(q.queues[t.account.ID], t)
+}
+
+// pop returns the next task from the account after the one served last,
+// skipping accounts whose queues are drained
+func (q *fairQueue) pop() (task, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for range q.order {
+		accountID := q.order[q.next%len(q.order)]
+		q.next++
+		if pending := q.queues[accountID]; len(pending) > 0 {
+			q.queues[accountID] = pending[1:]
+			return pending[0], true
+		}
+	}
+	return task{}, false
+}
+
+// run drains the queue with the given number of workers, calling fn for each task
+func (q *fairQueue) run(workers int, fn func(task)) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				t, ok := q.pop()
+				if !ok {
+					return
+				}
+				fn(t)
+			}
+		}()
+	}
+	wg.Wait()
+}
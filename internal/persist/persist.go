@@ -0,0 +1,56 @@
+// Package persist keeps in-memory collections (reviews, snoozes, proposals,
+// ...) across restarts as JSON documents in the history store.
+package persist
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+)
+
+// State stores named documents
+type State interface {
+	// SaveState creates or replaces a document
+	SaveState(ctx context.Context, name string, data []byte) error
+	// LoadState returns a document, or nil when it was never saved
+	LoadState(ctx context.Context, name string) ([]byte, error)
+}
+
+// Doc is the document a collection is saved to. A nil Doc saves nothing, so
+// collections work unpersisted until they are given one.
+type Doc struct {
+	state State
+	name  string
+}
+
+func NewDoc(state State, name string) *Doc {
+	return &Doc{state: state, name: name}
+}
+
+// Load decodes the saved document into v and reports whether there was one
+func (d *Doc) Load(ctx context.Context, v interface{}) (bool, error) {
+	data, err := d.state.LoadState(ctx, d.name)
+	if err != nil || data == nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Save encodes v as the document. Collections save while holding their lock,
+// so saves land in the order of the changes; a failure is logged and the
+// change is kept in memory.
+func (d *Doc) Save(v interface{}) {
+	if d == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = d.state.SaveState(context.Background(), d.name, data)
+	}
+	if err != nil {
+		log.Printf("Failed to save %s: %v", d.name, err)
+	}
+}
@@ -0,0 +1,20 @@
+// Package randid generates the IDs of reviews, snoozes, proposals, fetch
+// jobs, runs and alerts.
+package randid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// New returns a random 16 hex digit ID, or the current time in hex should the
+// system's random source fail
+func New() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
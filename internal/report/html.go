@@ -0,0 +1,152 @@
+// Package report renders self-contained quota reports
+package report
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/format"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/version"
+)
+
+// HTML renders the quotas as a standalone HTML page with inlined styles, so it
+// can be downloaded or hosted as a static file. An account column is added
+// when the quotas span organization accounts, a status column when they have
+// a threshold status, and a trend column when usage trends from the history
+// are given.
+func HTML(quotas []model.Quota, trends map[store.QuotaKey]Trend, opts format.Options) string {
+	withAccount, withStatus := false, false
+	for _, q := range quotas {
+		withAccount = withAccount || q.AccountID != ""
+		withStatus = withStatus || q.Status != ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>AWS Quota Report</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; margin: 20px; }
+        h1 { color: #232f3e; }
+        table { border-collapse: collapse; width: 100%; margin-top: 20px; }
+        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
+        th { background-color: #232f3e; color: white; }
+        tr:nth-child(even) { background-color: #f2f2f2; }
+        tr:hover { background-color: #ddd; }
+        .timestamp { color: #666; font-size: 0.9em; }
+        .up { color: #c0392b; }
+        .down { color: #27ae60; }
+        .flat { color: #666; }
+        .warning { color: #d68910; font-weight: bold; }
+        .critical { color: #c0392b; font-weight: bold; }
+        .resolved { color: #27ae60; }
+    </style>
+</head>
+<body>
+    <h1>AWS Service Quotas Report</h1>
+    <p class="timestamp">Generated: ` + time.Now().Format("2006-01-02 15:04:05") + ` by aws-quota-dashboard ` + html.EscapeString(version.String()) + `</p>
+    <p>Total quotas: ` + opts.Number(float64(len(quotas))) + `</p>
+    <table>
+        <thead>
+            <tr>`)
+	if withAccount {
+		b.WriteString(`
+                <th>Account</th>`)
+	}
+	b.WriteString(`
+                <th>Region</th>
+                <th>Service</th>
+                <th>Quota Name</th>
+                <th>Value</th>
+                <th>Usage</th>
+                <th>Unit</th>
+                <th>Adjustable</th>`)
+	if withStatus {
+		b.WriteString(`
+                <th>Status</th>`)
+	}
+	if len(trends) > 0 {
+		b.WriteString(`
+                <th>Trend</th>`)
+	}
+	b.WriteString(`
+            </tr>
+        </thead>
+        <tbody>`)
+
+	for _, q := range quotas {
+		adjustable := "No"
+		if q.Adjustable {
+			adjustable = "Yes"
+		}
+		value, unit := opts.Quantity(q.Value, q.Unit)
+		if q.LimitUnknown {
+			value = model.LimitUnknownLabel
+		}
+		usage := "N/A"
+		if q.HasUsageMetrics {
+			usage, _ = opts.Quantity(q.Usage, q.Unit)
+			if !q.LimitUnknown {
+				usage += " (" + opts.Number(math.Round(q.UsagePercentage*10)/10) + "%)"
+			}
+		}
+		b.WriteString(`
+            <tr>`)
+		if withAccount {
+			fmt.Fprintf(&b, `
+                <td>%s</td>`, html.EscapeString(q.AccountID))
+		}
+		fmt.Fprintf(&b, `
+                <td>%s</td>
+                <td>%s</td>
+                <td>%s</td>
+                <td>%s</td>
+                <td>%s</td>
+                <td>%s</td>
+                <td>%s</td>`, html.EscapeString(q.Region), html.EscapeString(q.ServiceName), html.EscapeString(q.QuotaName),
+			value, usage, html.EscapeString(unit), adjustable)
+		if withStatus {
+			fmt.Fprintf(&b, `
+                <td class="%[1]s">%[1]s</td>`, html.EscapeString(q.Status))
+		}
+		if len(trends) > 0 {
+			b.WriteString(trendCell(trends, q, opts))
+		}
+		b.WriteString(`
+            </tr>`)
+	}
+
+	b.WriteString(`
+        </tbody>
+    </table>
+</body>
+</html>`)
+
+	return b.String()
+}
+
+// trendCell renders the trend of a quota, empty without enough history
+func trendCell(trends map[store.QuotaKey]Trend, q model.Quota, opts format.Options) string {
+	t, ok := trends[store.KeyOf(q)]
+	if !ok {
+		return `
+                <td></td>`
+	}
+	class := "flat"
+	switch {
+	case t.Current > t.Previous:
+		class = "up"
+	case t.Current < t.Previous:
+		class = "down"
+	}
+	return fmt.Sprintf(`
+                <td class="%s" title="vs %s">%s</td>`, class, t.PreviousAt.UTC().Format(time.RFC3339), html.EscapeString(t.Label(opts)))
+}
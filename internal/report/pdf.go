@@ -0,0 +1,159 @@
+package report
+
+import (
+	"bytes"
+	"math"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/yuxishi/aws-quota-dashboard/internal/format"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+	"github.com/yuxishi/aws-quota-dashboard/internal/version"
+)
+
+// pdfColumn is a column of the PDF report with its width in millimeters
+type pdfColumn struct {
+	title string
+	width float64
+	value func(q model.Quota) string
+}
+
+// PDF renders the quotas as an A4 landscape table with the columns of the
+// HTML report. Text outside the Windows-1252 character set is replaced, and
+// trends are written as the signed change, as the built-in fonts have no
+// arrows.
+func PDF(quotas []model.Quota, trends map[store.QuotaKey]Trend, opts format.Options) ([]byte, error) {
+	withAccount, withStatus := false, false
+	for _, q := range quotas {
+		withAccount = withAccount || q.AccountID != ""
+		withStatus = withStatus || q.Status != ""
+	}
+
+	var columns []pdfColumn
+	if withAccount {
+		columns = append(columns, pdfColumn{"Account", 27, func(q model.Quota) string { return q.AccountID }})
+	}
+	columns = append(columns,
+		pdfColumn{"Region", 24, func(q model.Quota) string { return q.Region }},
+		pdfColumn{"Service", 40, func(q model.Quota) string { return q.ServiceName }},
+		pdfColumn{"Quota Name", 0, func(q model.Quota) string { return q.QuotaName }},
+		pdfColumn{"Value", 24, func(q model.Quota) string {
+			if q.LimitUnknown {
+				return model.LimitUnknownLabel
+			}
+			value, _ := opts.Quantity(q.Value, q.Unit)
+			return value
+		}},
+		pdfColumn{"Usage", 32, func(q model.Quota) string {
+			if !q.HasUsageMetrics {
+				return "N/A"
+			}
+			usage, _ := opts.Quantity(q.Usage, q.Unit)
+			if !q.LimitUnknown {
+				usage += " (" + opts.Number(math.Round(q.UsagePercentage*10)/10) + "%)"
+			}
+			return usage
+		}},
+		pdfColumn{"Unit", 20, func(q model.Quota) string {
+			_, unit := opts.Quantity(q.Value, q.Unit)
+			return unit
+		}},
+		pdfColumn{"Adjustable", 18, func(q model.Quota) string {
+			if q.Adjustable {
+				return "Yes"
+			}
+			return "No"
+		}},
+	)
+	if withStatus {
+		columns = append(columns, pdfColumn{"Status", 18, func(q model.Quota) string { return q.Status }})
+	}
+	if len(trends) > 0 {
+		columns = append(columns, pdfColumn{"Trend", 18, func(q model.Quota) string {
+			t, ok := trends[store.KeyOf(q)]
+			if !ok {
+				return ""
+			}
+			if change := t.ChangeLabel(opts); change != "" {
+				return change
+			}
+			if t.Current > t.Previous {
+				return "up"
+			}
+			return "flat"
+		}})
+	}
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle("AWS Service Quotas Report", true)
+	pdf.SetCreator("aws-quota-dashboard "+version.String(), true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	left, _, right, bottom := pdf.GetMargins()
+
+	// The quota name takes the width the other columns leave
+	fixed := 0.0
+	for _, col := range columns {
+		fixed += col.width
+	}
+	for i := range columns {
+		if columns[i].width == 0 {
+			columns[i].width = pageWidth - left - right - fixed
+		}
+	}
+
+	const rowHeight = 6.0
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.SetFillColor(0x23, 0x2f, 0x3e)
+		pdf.SetTextColor(255, 255, 255)
+		for _, col := range columns {
+			pdf.CellFormat(col.width, rowHeight, col.title, "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.SetTextColor(0x23, 0x2f, 0x3e)
+	pdf.CellFormat(0, 10, "AWS Service Quotas Report", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(0x66, 0x66, 0x66)
+	pdf.CellFormat(0, 5, tr("Generated: "+time.Now().Format("2006-01-02 15:04:05")+" by aws-quota-dashboard "+version.String()), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 5, "Total quotas: "+opts.Number(float64(len(quotas))), "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+	header()
+
+	for i, q := range quotas {
+		if pdf.GetY()+rowHeight > pageHeight-bottom {
+			pdf.AddPage()
+			header()
+		}
+		fill := i%2 == 1
+		pdf.SetFillColor(0xf2, 0xf2, 0xf2)
+		for _, col := range columns {
+			pdf.CellFormat(col.width, rowHeight, fitText(pdf, tr(col.value(q)), col.width-2), "1", 0, "L", fill, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fitText shortens text with an ellipsis to fit a width at the current font
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
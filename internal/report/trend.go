@@ -0,0 +1,90 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/format"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+// Trend is the change of a quota's usage between the previous snapshot and
+// the latest one
+type Trend struct {
+	Previous   float64
+	Current    float64
+	PreviousAt time.Time
+}
+
+// Trends returns the usage trend of every quota with at least two recorded
+// snapshots with usage
+func Trends(ctx context.Context, s store.Store, quotas []model.Quota) (map[store.QuotaKey]Trend, error) {
+	keys := make([]store.QuotaKey, 0, len(quotas))
+	for _, q := range quotas {
+		keys = append(keys, store.KeyOf(q))
+	}
+	recent, err := s.Recent(ctx, keys, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	trends := make(map[store.QuotaKey]Trend, len(recent))
+	for key, points := range recent {
+		if len(points) < 2 {
+			continue
+		}
+		trends[key] = Trend{
+			Previous:   points[0].Usage,
+			Current:    points[1].Usage,
+			PreviousAt: points[0].Timestamp,
+		}
+	}
+	return trends, nil
+}
+
+// Arrow returns ▲, ▼ or → for rising, falling and flat usage
+func (t Trend) Arrow() string {
+	switch {
+	case t.Current > t.Previous:
+		return "▲"
+	case t.Current < t.Previous:
+		return "▼"
+	default:
+		return "→"
+	}
+}
+
+// Change returns the change in percent of the previous usage; false when the
+// previous usage was zero
+func (t Trend) Change() (float64, bool) {
+	if t.Previous == 0 {
+		return 0, false
+	}
+	return (t.Current - t.Previous) / t.Previous * 100, true
+}
+
+// Label renders the arrow and the signed percent change, e.g. "▲ +12.5%"
+func (t Trend) Label(opts format.Options) string {
+	change := t.ChangeLabel(opts)
+	if change == "" {
+		return t.Arrow()
+	}
+	return t.Arrow() + " " + change
+}
+
+// ChangeLabel renders the signed percent change, e.g. "+12.5%"; empty when
+// the previous usage was zero
+func (t Trend) ChangeLabel(opts format.Options) string {
+	change, ok := t.Change()
+	if !ok {
+		return ""
+	}
+	sign := ""
+	if change > 0 {
+		sign = "+"
+	}
+	return fmt.Sprintf("%s%s%%", sign, opts.Number(math.Round(change*10)/10))
+}
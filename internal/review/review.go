@@ -0,0 +1,232 @@
+// Package review runs capacity reviews: a set of quotas above a usage
+// threshold is assigned to reviewers, each quota is given a verdict, and the
+// review is signed off once every quota has one.
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/persist"
+	"github.com/yuxishi/aws-quota-dashboard/internal/randid"
+)
+
+// ErrNotFound is returned for unknown reviews and items
+var ErrNotFound = errors.New("not found")
+
+var validStates = map[string]bool{
+	model.ReviewPending:      true,
+	model.ReviewAcknowledged: true,
+	model.ReviewJustified:    true,
+	model.ReviewActionNeeded: true,
+}
+
+// Reviews keeps the quota reviews
+type Reviews struct {
+	mu      sync.RWMutex
+	reviews map[string]*model.Review
+	doc     *persist.Doc
+}
+
+func NewReviews() *Reviews {
+	return &Reviews{
+		reviews: make(map[string]*model.Review),
+	}
+}
+
+// Persist loads the reviews saved to a document and saves every later change
+// to it
+func (r *Reviews) Persist(ctx context.Context, doc *persist.Doc) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := doc.Load(ctx, &r.reviews); err != nil {
+		return err
+	}
+	r.doc = doc
+	return nil
+}
+
+// Create opens a review of the quotas whose usage is at or above the
+// threshold (in percent). Items are ordered by usage, highest first, and
+// assigned to the reviewers round-robin.
+func (r *Reviews) Create(name string, threshold float64, reviewers []string, quotas []model.Quota) model.Review {
+	var selected []model.Quota
+	for _, q := range quotas {
+		if q.HasUsageMetrics && q.UsagePercentage >= threshold {
+			selected = append(selected, q)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].UsagePercentage > selected[j].UsagePercentage })
+
+	review := &model.Review{
+		ID:        randid.New(),
+		Name:      name,
+		Threshold: threshold,
+		Reviewers: reviewers,
+		Status:    model.ReviewOpen,
+		Items:     make([]model.ReviewItem, 0, len(selected)),
+		CreatedAt: time.Now(),
+	}
+	for i, q := range selected {
+		item := model.ReviewItem{
+			ID:              fmt.Sprintf("%d", i+1),
+			AccountID:       q.AccountID,
+			Region:          q.Region,
+			ServiceCode:     q.ServiceCode,
+			QuotaCode:       q.QuotaCode,
+			QuotaName:       q.QuotaName,
+			Value:           q.Value,
+			Usage:           q.Usage,
+			UsagePercentage: q.UsagePercentage,
+			State:           model.ReviewPending,
+		}
+		if len(reviewers) > 0 {
+			item.Reviewer = reviewers[i%len(reviewers)]
+		}
+		review.Items = append(review.Items, item)
+	}
+	review.Progress = progress(review.Items)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reviews[review.ID] = review
+	r.doc.Save(r.reviews)
+	return copyReview(review)
+}
+
+// Get returns a review by ID
+func (r *Reviews) Get(id string) (model.Review, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	review, ok := r.reviews[id]
+	if !ok {
+		return model.Review{}, false
+	}
+	return copyReview(review), true
+}
+
+// List returns the reviews without their items, newest first
+func (r *Reviews) List() []model.Review {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]model.Review, 0, len(r.reviews))
+	for _, review := range r.reviews {
+		summary := copyReview(review)
+		summary.Items = nil
+		list = append(list, summary)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// ItemUpdate changes an item of a review. Empty fields are left unchanged.
+type ItemUpdate struct {
+	State     string `json:"state"`
+	Note      string `json:"note"`
+	Reviewer  string `json:"reviewer"`
+	UpdatedBy string `json:"updated_by"`
+}
+
+// UpdateItem records a verdict, note or reassignment on an item of an open
+// review
+func (r *Reviews) UpdateItem(reviewID, itemID string, update ItemUpdate) (model.ReviewItem, error) {
+	if update.State != "" && !validStates[update.State] {
+		return model.ReviewItem{}, fmt.Errorf("invalid state %q", update.State)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	review, ok := r.reviews[reviewID]
+	if !ok {
+		return model.ReviewItem{}, fmt.Errorf("review %s: %w", reviewID, ErrNotFound)
+	}
+	if review.Status != model.ReviewOpen {
+		return model.ReviewItem{}, fmt.Errorf("review %s is already %s", reviewID, review.Status)
+	}
+	for i := range review.Items {
+		item := &review.Items[i]
+		if item.ID != itemID {
+			continue
+		}
+		if update.State != "" {
+			item.State = update.State
+		}
+		if update.Note != "" {
+			item.Note = update.Note
+		}
+		if update.Reviewer != "" {
+			item.Reviewer = update.Reviewer
+		}
+		now := time.Now()
+		item.UpdatedBy = update.UpdatedBy
+		item.UpdatedAt = &now
+		review.Progress = progress(review.Items)
+		r.doc.Save(r.reviews)
+		return *item, nil
+	}
+	return model.ReviewItem{}, fmt.Errorf("item %s of review %s: %w", itemID, reviewID, ErrNotFound)
+}
+
+// SignOff closes a review once every item has a verdict
+func (r *Reviews) SignOff(id, signedOffBy string) (model.Review, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	review, ok := r.reviews[id]
+	if !ok {
+		return model.Review{}, fmt.Errorf("review %s: %w", id, ErrNotFound)
+	}
+	if review.Status != model.ReviewOpen {
+		return copyReview(review), fmt.Errorf("review %s is already %s", id, review.Status)
+	}
+	if pending := review.Progress.ByState[model.ReviewPending]; pending > 0 {
+		return copyReview(review), fmt.Errorf("review %s has %d quotas pending", id, pending)
+	}
+	now := time.Now()
+	review.Status = model.ReviewSignedOff
+	review.SignedOffBy = signedOffBy
+	review.SignedOffAt = &now
+	r.doc.Save(r.reviews)
+	return copyReview(review), nil
+}
+
+func progress(items []model.ReviewItem) model.ReviewProgress {
+	p := model.ReviewProgress{
+		Total:      len(items),
+		ByState:    make(map[string]int),
+		ByReviewer: make(map[string]int),
+	}
+	for _, item := range items {
+		p.ByState[item.State]++
+		if item.State == model.ReviewPending {
+			p.ByReviewer[item.Reviewer]++
+		} else {
+			p.Reviewed++
+		}
+	}
+	if p.Total > 0 {
+		p.Percent = float64(p.Reviewed) / float64(p.Total) * 100
+	} else {
+		p.Percent = 100
+	}
+	return p
+}
+
+func copyReview(review *model.Review) model.Review {
+	c := *review
+	c.Items = append([]model.ReviewItem(nil), review.Items...)
+	c.Reviewers = append([]string(nil), review.Reviewers...)
+	c.Progress.ByState = make(map[string]int, len(review.Progress.ByState))
+	for k, v := range review.Progress.ByState {
+		c.Progress.ByState[k] = v
+	}
+	c.Progress.ByReviewer = make(map[string]int, len(review.Progress.ByReviewer))
+	for k, v := range review.Progress.ByReviewer {
+		c.Progress.ByReviewer[k] = v
+	}
+	return c
+}
@@ -0,0 +1,236 @@
+// Package sink mirrors quota snapshots into external systems
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yuxishi/aws-quota-dashboard/internal/accesslog"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// Snapshot sources
+const (
+	SourceFetch   = "fetch"
+	SourceOrgScan = "org_scan"
+)
+
+// DefaultSchema is used when no schema is configured
+const DefaultSchema = "quota_dashboard"
+
+var schemaName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Sink receives every complete quota snapshot
+type Sink interface {
+	WriteSnapshot(ctx context.Context, at time.Time, source string, quotas []model.Quota, warnings []string) error
+}
+
+// Postgres mirrors snapshots into a PostgreSQL schema for BI tools. Every
+// snapshot gets a row in snapshots; its quotas, usage and warnings reference
+// it, and the latest_quotas view joins the most recent snapshot of each
+// source. Only complete snapshots may be written, as a partial one would
+// replace the whole source in the view.
+type Postgres struct {
+	db        *sql.DB
+	schema    string
+	retention time.Duration
+}
+
+// NewPostgres connects to the database and creates the schema and its tables
+// when they do not exist. Snapshots and access log entries older than the
+// retention are deleted as new ones are written; 0 keeps them forever.
+func NewPostgres(ctx context.Context, dsn, schema string, retention time.Duration) (*Postgres, error) {
+	if schema == "" {
+		schema = DefaultSchema
+	}
+	if !schemaName.MatchString(schema) {
+		return nil, fmt.Errorf("invalid postgres schema name %q", schema)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	p := &Postgres{db: db, schema: pq.QuoteIdentifier(schema), retention: retention}
+	if err := p.migrate(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create postgres schema: %w", err)
+	}
+	return p, nil
+}
+
+func (p *Postgres) migrate(ctx context.Context) error {
+	statements := []string{
+		`CREATE SCHEMA IF NOT EXISTS %[1]s`,
+		`CREATE TABLE IF NOT EXISTS %[1]s.snapshots (
+			id BIGSERIAL PRIMARY KEY,
+			taken_at TIMESTAMPTZ NOT NULL,
+			source TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS snapshots_taken_at ON %[1]s.snapshots (taken_at)`,
+		`CREATE TABLE IF NOT EXISTS %[1]s.quotas (
+			snapshot_id BIGINT NOT NULL REFERENCES %[1]s.snapshots (id) ON DELETE CASCADE,
+			account_id TEXT NOT NULL,
+			region TEXT NOT NULL,
+			service_code TEXT NOT NULL,
+			service_name TEXT NOT NULL,
+			quota_code TEXT NOT NULL,
+			quota_name TEXT NOT NULL,
+			value DOUBLE PRECISION,
+			unit TEXT NOT NULL,
+			adjustable BOOLEAN NOT NULL,
+			global BOOLEAN NOT NULL,
+			owner TEXT NOT NULL,
+			PRIMARY KEY (snapshot_id, account_id, region, service_code, quota_code)
+		)`,
+		`CREATE TABLE IF NOT EXISTS %[1]s.usage (
+			snapshot_id BIGINT NOT NULL REFERENCES %[1]s.snapshots (id) ON DELETE CASCADE,
+			account_id TEXT NOT NULL,
+			region TEXT NOT NULL,
+			service_code TEXT NOT NULL,
+			quota_code TEXT NOT NULL,
+			usage DOUBLE PRECISION NOT NULL,
+			usage_percentage DOUBLE PRECISION,
+			PRIMARY KEY (snapshot_id, account_id, region, service_code, quota_code)
+		)`,
+		`CREATE TABLE IF NOT EXISTS %[1]s.warnings (
+			snapshot_id BIGINT NOT NULL REFERENCES %[1]s.snapshots (id) ON DELETE CASCADE,
+			message TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS %[1]s.access_log (
+			requested_at TIMESTAMPTZ NOT NULL,
+			method TEXT NOT NULL,
+			route TEXT NOT NULL,
+			status INTEGER NOT NULL,
+			user_name TEXT NOT NULL,
+			client_ip TEXT NOT NULL,
+			duration_ms DOUBLE PRECISION NOT NULL,
+			bytes INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS access_log_requested_at ON %[1]s.access_log (requested_at)`,
+		`CREATE OR REPLACE VIEW %[1]s.latest_quotas AS
+			SELECT s.taken_at, s.source, q.account_id, q.region, q.service_code, q.service_name,
+				q.quota_code, q.quota_name, q.value, q.unit, q.adjustable, q.global, q.owner,
+				u.usage, u.usage_percentage
+			FROM %[1]s.snapshots s
+			JOIN %[1]s.quotas q ON q.snapshot_id = s.id
+			LEFT JOIN %[1]s.usage u ON u.snapshot_id = q.snapshot_id AND u.account_id = q.account_id
+				AND u.region = q.region AND u.service_code = q.service_code AND u.quota_code = q.quota_code
+			WHERE s.id IN (SELECT MAX(id) FROM %[1]s.snapshots GROUP BY source)`,
+	}
+	for _, stmt := range statements {
+		if _, err := p.db.ExecContext(ctx, fmt.Sprintf(stmt, p.schema)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSnapshot stores a snapshot in a single transaction, so BI queries
+// never see a partial one, and deletes the snapshots past the retention.
+// Limits of quotas whose limit is unknown and usage percentages of quotas
+// without a limit are stored as NULL.
+func (p *Postgres) WriteSnapshot(ctx context.Context, at time.Time, source string, quotas []model.Quota, warnings []string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	var id int64
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`INSERT INTO %s.snapshots (taken_at, source) VALUES ($1, $2) RETURNING id`, p.schema),
+		at, source).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	insertQuota, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s.quotas
+		(snapshot_id, account_id, region, service_code, service_name, quota_code, quota_name, value, unit, adjustable, global, owner)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT DO NOTHING`, p.schema))
+	if err != nil {
+		return err
+	}
+	defer insertQuota.Close()
+	insertUsage, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s.usage
+		(snapshot_id, account_id, region, service_code, quota_code, usage, usage_percentage)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING`, p.schema))
+	if err != nil {
+		return err
+	}
+	defer insertUsage.Close()
+
+	for _, q := range quotas {
+		value := sql.NullFloat64{Float64: q.Value, Valid: !q.LimitUnknown}
+		if _, err := insertQuota.ExecContext(ctx, id, q.AccountID, q.Region, q.ServiceCode, q.ServiceName,
+			q.QuotaCode, q.QuotaName, value, q.Unit, q.Adjustable, q.Global, q.Owner); err != nil {
+			return err
+		}
+		if !q.HasUsageMetrics {
+			continue
+		}
+		pct := sql.NullFloat64{Float64: q.UsagePercentage, Valid: q.Value > 0 && !q.LimitUnknown}
+		if _, err := insertUsage.ExecContext(ctx, id, q.AccountID, q.Region, q.ServiceCode, q.QuotaCode, q.Usage, pct); err != nil {
+			return err
+		}
+	}
+
+	for _, w := range warnings {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s.warnings (snapshot_id, message) VALUES ($1, $2)`, p.schema), id, w); err != nil {
+			return err
+		}
+	}
+
+	if p.retention > 0 {
+		// Quotas, usage and warnings cascade; the latest snapshot of each
+		// source stays, so the view never empties
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %[1]s.snapshots WHERE taken_at < $1
+			AND id NOT IN (SELECT MAX(id) FROM %[1]s.snapshots GROUP BY source)`, p.schema), at.Add(-p.retention)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// WriteAccessLog stores a batch of API requests in a single transaction and
+// deletes the entries past the retention
+func (p *Postgres) WriteAccessLog(ctx context.Context, entries []accesslog.Entry) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	insert, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s.access_log
+		(requested_at, method, route, status, user_name, client_ip, duration_ms, bytes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, p.schema))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	for _, e := range entries {
+		if _, err := insert.ExecContext(ctx, e.Time, e.Method, e.Route, e.Status, e.User, e.ClientIP, e.DurationMS, e.Bytes); err != nil {
+			return err
+		}
+	}
+	if p.retention > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s.access_log WHERE requested_at < $1`, p.schema),
+			time.Now().Add(-p.retention)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close closes the database connection
+func (p *Postgres) Close() error {
+	return p.db.Close()
+}
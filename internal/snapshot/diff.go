@@ -0,0 +1,129 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// Diff statuses of a quota
+const (
+	DiffChanged   = "changed"
+	DiffOnlyBase  = "only_base"
+	DiffOnlyOther = "only_other"
+)
+
+// DiffKey identifies a quota across environments. Accounts differ between
+// environments, so they are not part of the key.
+type DiffKey struct {
+	Region      string `json:"region"`
+	ServiceCode string `json:"service_code"`
+	QuotaCode   string `json:"quota_code"`
+}
+
+// DiffEntry is a quota whose limit differs between two sets of quotas, or
+// that only one of them has
+type DiffEntry struct {
+	DiffKey
+	ServiceName string   `json:"service_name"`
+	QuotaName   string   `json:"quota_name"`
+	Status      string   `json:"status"`
+	BaseValue   *float64 `json:"base_value,omitempty"`
+	OtherValue  *float64 `json:"other_value,omitempty"`
+	BaseUsage   *float64 `json:"base_usage,omitempty"`
+	OtherUsage  *float64 `json:"other_usage,omitempty"`
+}
+
+// Diff compares two sets of quotas
+type Diff struct {
+	Entries   []DiffEntry `json:"entries"`
+	Changed   int         `json:"changed"`
+	OnlyBase  int         `json:"only_base"`
+	OnlyOther int         `json:"only_other"`
+	Unchanged int         `json:"unchanged"`
+}
+
+// Compare lists the quotas whose limit differs between base and other, and
+// those only one of them has. Each side must hold a single account's quotas:
+// a quota present in several accounts cannot be matched.
+func Compare(base, other []model.Quota) (*Diff, error) {
+	baseByKey, err := byDiffKey(base, "base")
+	if err != nil {
+		return nil, err
+	}
+	otherByKey, err := byDiffKey(other, "other")
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{Entries: make([]DiffEntry, 0)}
+	for key, b := range baseByKey {
+		o, ok := otherByKey[key]
+		switch {
+		case !ok:
+			diff.OnlyBase++
+			diff.Entries = append(diff.Entries, diffEntry(key, DiffOnlyBase, &b, nil))
+		case b.LimitUnknown != o.LimitUnknown || b.Value != o.Value:
+			diff.Changed++
+			diff.Entries = append(diff.Entries, diffEntry(key, DiffChanged, &b, &o))
+		default:
+			diff.Unchanged++
+		}
+	}
+	for key, o := range otherByKey {
+		if _, ok := baseByKey[key]; !ok {
+			diff.OnlyOther++
+			diff.Entries = append(diff.Entries, diffEntry(key, DiffOnlyOther, nil, &o))
+		}
+	}
+
+	sort.Slice(diff.Entries, func(i, j int) bool {
+		a, b := diff.Entries[i], diff.Entries[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.ServiceCode != b.ServiceCode {
+			return a.ServiceCode < b.ServiceCode
+		}
+		return a.QuotaCode < b.QuotaCode
+	})
+	return diff, nil
+}
+
+func byDiffKey(quotas []model.Quota, side string) (map[DiffKey]model.Quota, error) {
+	result := make(map[DiffKey]model.Quota, len(quotas))
+	for _, q := range quotas {
+		key := DiffKey{Region: q.Region, ServiceCode: q.ServiceCode, QuotaCode: q.QuotaCode}
+		if prev, ok := result[key]; ok && prev.AccountID != q.AccountID {
+			return nil, fmt.Errorf("%s quotas span accounts %s and %s; select one account", side, prev.AccountID, q.AccountID)
+		}
+		result[key] = q
+	}
+	return result, nil
+}
+
+func diffEntry(key DiffKey, status string, base, other *model.Quota) DiffEntry {
+	entry := DiffEntry{DiffKey: key, Status: status}
+	for _, side := range []struct {
+		q            *model.Quota
+		value, usage **float64
+	}{
+		{base, &entry.BaseValue, &entry.BaseUsage},
+		{other, &entry.OtherValue, &entry.OtherUsage},
+	} {
+		if side.q == nil {
+			continue
+		}
+		entry.ServiceName, entry.QuotaName = side.q.ServiceName, side.q.QuotaName
+		if !side.q.LimitUnknown {
+			value := side.q.Value
+			*side.value = &value
+		}
+		if side.q.HasUsageMetrics {
+			usage := side.q.Usage
+			*side.usage = &usage
+		}
+	}
+	return entry
+}
@@ -0,0 +1,162 @@
+// Package snapshot reads and writes the archive used to move the data
+// collected by one dashboard instance into another, e.g. for audits in
+// environments without AWS access.
+package snapshot
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	"github.com/yuxishi/aws-quota-dashboard/internal/store"
+)
+
+// Version is the archive format version written by this build
+const Version = 1
+
+// maxEntrySize bounds the decompressed size of each archive entry
+const maxEntrySize = 512 << 20
+
+const (
+	manifestFile = "manifest.json"
+	quotasFile   = "quotas.json"
+	accountsFile = "accounts.json"
+	historyFile  = "history.json"
+)
+
+// Manifest describes an archive
+type Manifest struct {
+	Version     int       `json:"version"`
+	ExportedAt  time.Time `json:"exported_at"`
+	Source      string    `json:"source,omitempty"`
+	QuotaCount  int       `json:"quota_count"`
+	SeriesCount int       `json:"series_count"`
+	Warnings    int       `json:"warning_count"`
+
+	// AccountIDs and Regions are those of the archived quotas, so an
+	// archive can be matched with the environment to compare it with
+	AccountIDs []string `json:"account_ids,omitempty"`
+	Regions    []string `json:"regions,omitempty"`
+
+	// DashboardVersion is the build that wrote the archive
+	DashboardVersion string `json:"dashboard_version,omitempty"`
+}
+
+// Snapshot is the content of an archive: the latest quotas, the organization
+// accounts they belong to, and the complete history and warnings
+type Snapshot struct {
+	Manifest Manifest
+	Quotas   []model.Quota
+	Accounts []model.Account
+	History  *store.Dump
+}
+
+// Write writes the snapshot as a zip archive, filling in its manifest counts
+func Write(w io.Writer, s *Snapshot) error {
+	if s.History == nil {
+		s.History = &store.Dump{}
+	}
+	s.Manifest.Version = Version
+	s.Manifest.QuotaCount = len(s.Quotas)
+	s.Manifest.SeriesCount = len(s.History.Series)
+	s.Manifest.Warnings = len(s.History.Warnings)
+	s.Manifest.AccountIDs, s.Manifest.Regions = scopeOf(s.Quotas)
+
+	zw := zip.NewWriter(w)
+	entries := []struct {
+		name string
+		v    interface{}
+	}{
+		{manifestFile, s.Manifest},
+		{quotasFile, s.Quotas},
+		{accountsFile, s.Accounts},
+		{historyFile, s.History},
+	}
+	for _, e := range entries {
+		f, err := zw.Create(e.name)
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(f).Encode(e.v); err != nil {
+			return fmt.Errorf("failed to write %s: %w", e.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// Read parses an archive written by Write
+func Read(data []byte) (*Snapshot, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot archive: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	s := &Snapshot{History: &store.Dump{}}
+	if err := readEntry(files, manifestFile, &s.Manifest); err != nil {
+		return nil, err
+	}
+	if s.Manifest.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d", s.Manifest.Version)
+	}
+	if err := readEntry(files, quotasFile, &s.Quotas); err != nil {
+		return nil, err
+	}
+	if err := readEntry(files, accountsFile, &s.Accounts); err != nil {
+		return nil, err
+	}
+	if err := readEntry(files, historyFile, s.History); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// scopeOf returns the sorted accounts and regions of quotas
+func scopeOf(quotas []model.Quota) (accountIDs, regions []string) {
+	accounts, seen := make(map[string]bool), make(map[string]bool)
+	for _, q := range quotas {
+		if q.AccountID != "" && !accounts[q.AccountID] {
+			accounts[q.AccountID] = true
+			accountIDs = append(accountIDs, q.AccountID)
+		}
+		if !seen[q.Region] {
+			seen[q.Region] = true
+			regions = append(regions, q.Region)
+		}
+	}
+	sort.Strings(accountIDs)
+	sort.Strings(regions)
+	return accountIDs, regions
+}
+
+func readEntry(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("snapshot archive is missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxEntrySize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(data) > maxEntrySize {
+		return fmt.Errorf("%s exceeds %d bytes", name, maxEntrySize)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// LimitChange is a quota whose limit differs from its last recorded
+// observation, and its likely cause once attributed
+type LimitChange struct {
+	Key           QuotaKey  `json:"key"`
+	ServiceName   string    `json:"service_name"`
+	QuotaName     string    `json:"quota_name"`
+	PreviousValue float64   `json:"previous_value"`
+	Value         float64   `json:"value"`
+	PreviousAt    time.Time `json:"previous_at"`
+	DetectedAt    time.Time `json:"detected_at"`
+	Cause         string    `json:"cause"`
+	RequestID     string    `json:"request_id,omitempty"`
+}
+
+// DetectLimitChanges compares quotas about to be recorded at the given time
+// with the last observation of their series. Quotas seen for the first time
+// and quotas whose limit is unknown, now or before, are not reported.
+func DetectLimitChanges(ctx context.Context, s Store, at time.Time, quotas []model.Quota) ([]LimitChange, error) {
+	keys := make([]QuotaKey, 0, len(quotas))
+	for _, q := range quotas {
+		keys = append(keys, KeyOf(q))
+	}
+	latest, err := s.Latest(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []LimitChange
+	for _, q := range quotas {
+		prev, ok := latest[KeyOf(q)]
+		if !ok || q.LimitUnknown || prev.LimitUnknown || prev.Value == q.Value || !prev.Timestamp.Before(at) {
+			continue
+		}
+		changes = append(changes, LimitChange{
+			Key:           KeyOf(q),
+			ServiceName:   q.ServiceName,
+			QuotaName:     q.QuotaName,
+			PreviousValue: prev.Value,
+			Value:         q.Value,
+			PreviousAt:    prev.Timestamp,
+			DetectedAt:    at,
+		})
+	}
+	return changes, nil
+}
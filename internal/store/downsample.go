@@ -0,0 +1,75 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// History resolutions
+const (
+	ResolutionRaw    = "raw"
+	ResolutionHourly = "hourly"
+	ResolutionDaily  = "daily"
+)
+
+// Bucket aggregates the points of one time bucket. Maximums are kept next to
+// averages because the peaks are what matter when sizing against a limit.
+type Bucket struct {
+	Start              time.Time `json:"start"`
+	Value              float64   `json:"value"`
+	MaxUsage           float64   `json:"max_usage"`
+	AvgUsage           float64   `json:"avg_usage"`
+	MaxUsagePercentage float64   `json:"max_usage_percentage"`
+	AvgUsagePercentage float64   `json:"avg_usage_percentage"`
+	Samples            int       `json:"samples"`
+}
+
+// BucketSize returns the bucket width of a downsampled resolution
+func BucketSize(resolution string) (time.Duration, error) {
+	switch resolution {
+	case ResolutionHourly:
+		return time.Hour, nil
+	case ResolutionDaily:
+		return 24 * time.Hour, nil
+	default:
+		return 0, fmt.Errorf("unknown resolution %q (expected raw, hourly or daily)", resolution)
+	}
+}
+
+// Downsample groups time-ordered points into buckets of the given width,
+// aligned to UTC. The limit value reported is the last one seen in the bucket.
+func Downsample(points []Point, size time.Duration) []Bucket {
+	var buckets []Bucket
+	var usageSum, pctSum float64
+	for _, p := range points {
+		start := p.Timestamp.UTC().Truncate(size)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].Start.Equal(start) {
+			finishBucket(buckets, usageSum, pctSum)
+			buckets = append(buckets, Bucket{Start: start})
+			usageSum, pctSum = 0, 0
+		}
+		b := &buckets[len(buckets)-1]
+		b.Value = p.Value
+		b.Samples++
+		usageSum += p.Usage
+		pctSum += p.UsagePercentage
+		if b.Samples == 1 || p.Usage > b.MaxUsage {
+			b.MaxUsage = p.Usage
+		}
+		if b.Samples == 1 || p.UsagePercentage > b.MaxUsagePercentage {
+			b.MaxUsagePercentage = p.UsagePercentage
+		}
+	}
+	finishBucket(buckets, usageSum, pctSum)
+	return buckets
+}
+
+// finishBucket computes the averages of the last bucket
+func finishBucket(buckets []Bucket, usageSum, pctSum float64) {
+	if len(buckets) == 0 {
+		return
+	}
+	b := &buckets[len(buckets)-1]
+	b.AvgUsage = usageSum / float64(b.Samples)
+	b.AvgUsagePercentage = pctSum / float64(b.Samples)
+}
@@ -0,0 +1,370 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// maxPointsPerSeries bounds the memory used by each series; the oldest points
+// are dropped first
+const maxPointsPerSeries = 10000
+
+// maxWarnings bounds the number of warnings kept; the oldest are dropped first
+const maxWarnings = 10000
+
+// maxAlertTransitions bounds the number of alert transitions kept; the oldest
+// are dropped first
+const maxAlertTransitions = 10000
+
+// maxLimitChanges bounds the number of limit changes kept; the oldest are
+// dropped first
+const maxLimitChanges = 1000
+
+// MemoryStore keeps history in memory. It is lost on restart.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	series   map[QuotaKey][]Point
+	retired  map[QuotaKey]time.Time
+	alerts   map[QuotaKey]AlertState
+	warnings []Warning
+	changes  []LimitChange
+	logged   []AlertTransition
+	state    map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		series:  make(map[QuotaKey][]Point),
+		retired: make(map[QuotaKey]time.Time),
+		alerts:  make(map[QuotaKey]AlertState),
+		state:   make(map[string][]byte),
+	}
+}
+
+func (s *MemoryStore) Record(_ context.Context, at time.Time, quotas []model.Quota) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, q := range quotas {
+		key := KeyOf(q)
+		points := append(s.series[key], Point{
+			Timestamp:       at,
+			Value:           q.Value,
+			Usage:           q.Usage,
+			UsagePercentage: q.UsagePercentage,
+			HasUsage:        q.HasUsageMetrics,
+			LimitUnknown:    q.LimitUnknown,
+		})
+		if len(points) > maxPointsPerSeries {
+			points = points[len(points)-maxPointsPerSeries:]
+		}
+		s.series[key] = points
+		delete(s.retired, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) History(_ context.Context, key QuotaKey, since, until time.Time) ([]Point, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	points := s.series[key]
+	start := sort.Search(len(points), func(i int) bool { return !points[i].Timestamp.Before(since) })
+	var result []Point
+	for _, p := range points[start:] {
+		if p.Timestamp.After(until) {
+			break
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) Retire(_ context.Context, at time.Time, match func(key QuotaKey, lastSeen time.Time) bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for key, points := range s.series {
+		if _, ok := s.retired[key]; ok || len(points) == 0 {
+			continue
+		}
+		if match(key, points[len(points)-1].Timestamp) {
+			s.retired[key] = at
+			delete(s.alerts, key)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *MemoryStore) Retired(_ context.Context) (map[QuotaKey]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	retired := make(map[QuotaKey]time.Time, len(s.retired))
+	for key, at := range s.retired {
+		retired[key] = at
+	}
+	return retired, nil
+}
+
+func (s *MemoryStore) Peaks(_ context.Context, keys []QuotaKey) (map[QuotaKey]Point, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	peaks := make(map[QuotaKey]Point)
+	for _, key := range keys {
+		for _, p := range s.series[key] {
+			if !p.HasUsage {
+				continue
+			}
+			if peak, ok := peaks[key]; !ok || p.Usage > peak.Usage {
+				peaks[key] = p
+			}
+		}
+	}
+	return peaks, nil
+}
+
+func (s *MemoryStore) Latest(_ context.Context, keys []QuotaKey) (map[QuotaKey]Point, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	latest := make(map[QuotaKey]Point)
+	for _, key := range keys {
+		if points := s.series[key]; len(points) > 0 {
+			latest[key] = points[len(points)-1]
+		}
+	}
+	return latest, nil
+}
+
+func (s *MemoryStore) Recent(_ context.Context, keys []QuotaKey, n int) (map[QuotaKey][]Point, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	recent := make(map[QuotaKey][]Point)
+	for _, key := range keys {
+		points := s.series[key]
+		var result []Point
+		for i := len(points) - 1; i >= 0 && len(result) < n; i-- {
+			if points[i].HasUsage {
+				result = append(result, points[i])
+			}
+		}
+		if len(result) == 0 {
+			continue
+		}
+		for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+			result[i], result[j] = result[j], result[i]
+		}
+		recent[key] = result
+	}
+	return recent, nil
+}
+
+func (s *MemoryStore) AlertStates(_ context.Context, keys []QuotaKey) (map[QuotaKey]AlertState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	states := make(map[QuotaKey]AlertState)
+	if keys == nil {
+		for key, state := range s.alerts {
+			states[key] = state
+		}
+		return states, nil
+	}
+	for _, key := range keys {
+		if state, ok := s.alerts[key]; ok {
+			states[key] = state
+		}
+	}
+	return states, nil
+}
+
+func (s *MemoryStore) SetAlertStates(_ context.Context, states map[QuotaKey]AlertState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, state := range states {
+		s.alerts[key] = state
+	}
+	return nil
+}
+
+func (s *MemoryStore) RecordAlertTransitions(_ context.Context, transitions []AlertTransition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logged = append(s.logged, transitions...)
+	if len(s.logged) > maxAlertTransitions {
+		s.logged = append([]AlertTransition(nil), s.logged[len(s.logged)-maxAlertTransitions:]...)
+	}
+	return nil
+}
+
+func (s *MemoryStore) AlertTransitions(_ context.Context, since, until time.Time) ([]AlertTransition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []AlertTransition
+	for _, t := range s.logged {
+		if !t.At.Before(since) && !t.At.After(until) {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) SaveState(_ context.Context, name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *MemoryStore) LoadState(_ context.Context, name string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.state[name]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (s *MemoryStore) RecordWarnings(_ context.Context, at time.Time, source string, warnings []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, message := range warnings {
+		s.warnings = append(s.warnings, Warning{Timestamp: at, Source: source, Message: message})
+	}
+	if len(s.warnings) > maxWarnings {
+		s.warnings = s.warnings[len(s.warnings)-maxWarnings:]
+	}
+	return nil
+}
+
+func (s *MemoryStore) Warnings(_ context.Context, since, until time.Time) ([]Warning, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	start := sort.Search(len(s.warnings), func(i int) bool { return !s.warnings[i].Timestamp.Before(since) })
+	var result []Warning
+	for _, w := range s.warnings[start:] {
+		if w.Timestamp.After(until) {
+			break
+		}
+		result = append(result, w)
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) RecordLimitChanges(_ context.Context, changes []LimitChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes = append(s.changes, changes...)
+	if len(s.changes) > maxLimitChanges {
+		s.changes = append([]LimitChange(nil), s.changes[len(s.changes)-maxLimitChanges:]...)
+	}
+	return nil
+}
+
+func (s *MemoryStore) LimitChanges(_ context.Context, since, until time.Time) ([]LimitChange, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []LimitChange
+	for _, c := range s.changes {
+		if !c.DetectedAt.Before(since) && !c.DetectedAt.After(until) {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) Compact(_ context.Context, at time.Time, r Retention) (int, error) {
+	compactBefore, deleteBefore := r.cutoffs(at)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for key, points := range s.series {
+		compacted := compactPoints(points, compactBefore, deleteBefore, r.Bucket)
+		removed += len(points) - len(compacted)
+		if len(compacted) == 0 {
+			delete(s.series, key)
+			delete(s.retired, key)
+			continue
+		}
+		s.series[key] = compacted
+	}
+	if !deleteBefore.IsZero() {
+		start := sort.Search(len(s.warnings), func(i int) bool { return !s.warnings[i].Timestamp.Before(deleteBefore) })
+		s.warnings = append([]Warning(nil), s.warnings[start:]...)
+		start = sort.Search(len(s.changes), func(i int) bool { return !s.changes[i].DetectedAt.Before(deleteBefore) })
+		s.changes = append([]LimitChange(nil), s.changes[start:]...)
+		start = sort.Search(len(s.logged), func(i int) bool { return !s.logged[i].At.Before(deleteBefore) })
+		s.logged = append([]AlertTransition(nil), s.logged[start:]...)
+	}
+	return removed, nil
+}
+
+func (s *MemoryStore) Export(_ context.Context) (*Dump, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dump := &Dump{
+		Series:   make([]Series, 0, len(s.series)),
+		Warnings: append([]Warning(nil), s.warnings...),
+	}
+	for key, points := range s.series {
+		series := Series{Key: key, Points: append([]Point(nil), points...)}
+		if at, ok := s.retired[key]; ok {
+			series.RetiredAt = &at
+		}
+		dump.Series = append(dump.Series, series)
+	}
+	return dump, nil
+}
+
+func (s *MemoryStore) Import(_ context.Context, dump *Dump) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, series := range dump.Series {
+		byTime := make(map[int64]Point, len(s.series[series.Key])+len(series.Points))
+		for _, p := range s.series[series.Key] {
+			byTime[p.Timestamp.UnixNano()] = p
+		}
+		for _, p := range series.Points {
+			byTime[p.Timestamp.UnixNano()] = p
+		}
+		points := make([]Point, 0, len(byTime))
+		for _, p := range byTime {
+			points = append(points, p)
+		}
+		sort.Slice(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+		if len(points) > maxPointsPerSeries {
+			points = points[len(points)-maxPointsPerSeries:]
+		}
+		s.series[series.Key] = points
+		if series.RetiredAt != nil {
+			s.retired[series.Key] = *series.RetiredAt
+		}
+	}
+
+	// Importing the same dump twice must not duplicate its warnings
+	type warningKey struct {
+		at      int64
+		source  string
+		message string
+	}
+	seen := make(map[warningKey]bool, len(s.warnings))
+	for _, w := range s.warnings {
+		seen[warningKey{w.Timestamp.UnixNano(), w.Source, w.Message}] = true
+	}
+	for _, w := range dump.Warnings {
+		if !seen[warningKey{w.Timestamp.UnixNano(), w.Source, w.Message}] {
+			s.warnings = append(s.warnings, w)
+		}
+	}
+	sort.SliceStable(s.warnings, func(i, j int) bool { return s.warnings[i].Timestamp.Before(s.warnings[j].Timestamp) })
+	if len(s.warnings) > maxWarnings {
+		s.warnings = s.warnings[len(s.warnings)-maxWarnings:]
+	}
+	return nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
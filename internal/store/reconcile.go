@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// Scope describes which series a complete snapshot covers. Nil account and
+// region lists and an empty service code match everything.
+type Scope struct {
+	AccountIDs  []string
+	Regions     []string
+	ServiceCode string
+}
+
+// Contains reports whether a series is covered by the scope
+func (s Scope) Contains(key QuotaKey) bool {
+	if s.AccountIDs != nil && !contains(s.AccountIDs, key.AccountID) {
+		return false
+	}
+	if s.Regions != nil && !contains(s.Regions, key.Region) {
+		return false
+	}
+	return s.ServiceCode == "" || strings.EqualFold(s.ServiceCode, key.ServiceCode)
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Reconcile retires the series in scope that are missing from a snapshot taken
+// at the given time, such as quotas of deprecated codes or disabled regions.
+// A series is only retired once it has not been recorded for longer than
+// grace, so a transient fetch failure does not retire it.
+func Reconcile(ctx context.Context, s Store, at time.Time, scope Scope, quotas []model.Quota, grace time.Duration) (int, error) {
+	present := make(map[QuotaKey]bool, len(quotas))
+	for _, q := range quotas {
+		present[KeyOf(q)] = true
+	}
+	return s.Retire(ctx, at, func(key QuotaKey, lastSeen time.Time) bool {
+		return scope.Contains(key) && !present[key] && at.Sub(lastSeen) > grace
+	})
+}
+
+// Active returns the quotas whose series have not been retired
+func Active(ctx context.Context, s Store, quotas []model.Quota) ([]model.Quota, error) {
+	retired, err := s.Retired(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(retired) == 0 {
+		return quotas, nil
+	}
+	active := make([]model.Quota, 0, len(quotas))
+	for _, q := range quotas {
+		if _, ok := retired[KeyOf(q)]; !ok {
+			active = append(active, q)
+		}
+	}
+	return active, nil
+}
+
+// WithPeaks returns a copy of quotas with the peak usage recorded in the
+// store's history filled in. Quotas without recorded usage are left unchanged.
+func WithPeaks(ctx context.Context, s Store, quotas []model.Quota) ([]model.Quota, error) {
+	keys := make([]QuotaKey, 0, len(quotas))
+	for _, q := range quotas {
+		keys = append(keys, KeyOf(q))
+	}
+	peaks, err := s.Peaks(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model.Quota, len(quotas))
+	for i, q := range quotas {
+		if peak, ok := peaks[KeyOf(q)]; ok {
+			at := peak.Timestamp
+			q.PeakUsage, q.PeakUsageAt = peak.Usage, &at
+		}
+		result[i] = q
+	}
+	return result, nil
+}
+
+// WithDeltas fills in the change of usage and limit of each quota since its
+// last recorded observation. It must run before the quotas are recorded.
+// Deltas are left unset where either side lacks usage or a known limit.
+func WithDeltas(ctx context.Context, s Store, quotas []model.Quota) ([]model.Quota, error) {
+	keys := make([]QuotaKey, 0, len(quotas))
+	for _, q := range quotas {
+		keys = append(keys, KeyOf(q))
+	}
+	latest, err := s.Latest(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model.Quota, len(quotas))
+	for i, q := range quotas {
+		if prev, ok := latest[KeyOf(q)]; ok {
+			at := prev.Timestamp
+			q.PreviousFetchAt = &at
+			if prev.HasUsage && q.HasUsageMetrics {
+				delta := q.Usage - prev.Usage
+				q.UsageDelta = &delta
+			}
+			if !prev.LimitUnknown && !q.LimitUnknown {
+				delta := q.Value - prev.Value
+				q.ValueDelta = &delta
+			}
+		}
+		result[i] = q
+	}
+	return result, nil
+}
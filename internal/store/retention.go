@@ -0,0 +1,54 @@
+package store
+
+import "time"
+
+// Retention bounds the history. Observations older than Raw are compacted to
+// one per Bucket, and observations and warnings older than Rollups are
+// deleted. A zero Raw disables compaction; a zero Rollups keeps rollups
+// forever.
+type Retention struct {
+	Raw     time.Duration
+	Rollups time.Duration
+	Bucket  time.Duration
+}
+
+// cutoffs returns the times before which observations are compacted and
+// deleted; a zero time disables the step
+func (r Retention) cutoffs(at time.Time) (compactBefore, deleteBefore time.Time) {
+	if r.Raw > 0 && r.Bucket > 0 {
+		compactBefore = at.Add(-r.Raw)
+	}
+	if r.Rollups > 0 {
+		deleteBefore = at.Add(-r.Rollups)
+	}
+	return compactBefore, deleteBefore
+}
+
+// compactPoints applies the retention cutoffs to time-ordered points. Each
+// bucket before compactBefore keeps a single observation: the one with the
+// highest usage, so peaks and their timestamps survive, or the last one when
+// none has usage. Compacting compacted points changes nothing.
+func compactPoints(points []Point, compactBefore, deleteBefore time.Time, size time.Duration) []Point {
+	result := make([]Point, 0, len(points))
+	var bucket time.Time
+	for _, p := range points {
+		if !deleteBefore.IsZero() && p.Timestamp.Before(deleteBefore) {
+			continue
+		}
+		if compactBefore.IsZero() || !p.Timestamp.Before(compactBefore) {
+			result = append(result, p)
+			continue
+		}
+		start := p.Timestamp.UTC().Truncate(size)
+		if len(result) == 0 || !start.Equal(bucket) {
+			bucket = start
+			result = append(result, p)
+			continue
+		}
+		kept := &result[len(result)-1]
+		if p.HasUsage && (!kept.HasUsage || p.Usage > kept.Usage) || !p.HasUsage && !kept.HasUsage {
+			*kept = p
+		}
+	}
+	return result
+}
@@ -0,0 +1,748 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+	// Registers the pure Go "sqlite" driver, so no cgo toolchain is needed
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore keeps history in a SQLite database file, so it survives
+// restarts and grows beyond what fits in memory. Every series is a row of
+// series; its observations are rows of points keyed by series and time.
+// Timestamps are stored as Unix nanoseconds.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens or creates the database at path and its tables
+func NewSQLiteStore(ctx context.Context, path string) (*SQLiteStore, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer; one connection serializes writes
+	// instead of failing them with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: db}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create sqlite history tables in %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *SQLiteStore) migrate(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS series (
+			id INTEGER PRIMARY KEY,
+			account_id TEXT NOT NULL,
+			region TEXT NOT NULL,
+			service_code TEXT NOT NULL,
+			quota_code TEXT NOT NULL,
+			retired_at INTEGER,
+			UNIQUE (account_id, region, service_code, quota_code)
+		)`,
+		`CREATE TABLE IF NOT EXISTS points (
+			series_id INTEGER NOT NULL REFERENCES series (id) ON DELETE CASCADE,
+			taken_at INTEGER NOT NULL,
+			value REAL NOT NULL,
+			usage REAL NOT NULL,
+			usage_percentage REAL NOT NULL,
+			has_usage INTEGER NOT NULL,
+			PRIMARY KEY (series_id, taken_at)
+		) WITHOUT ROWID`,
+		`CREATE TABLE IF NOT EXISTS warnings (
+			taken_at INTEGER NOT NULL,
+			source TEXT NOT NULL,
+			message TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS warnings_taken_at ON warnings (taken_at)`,
+		`CREATE TABLE IF NOT EXISTS state (
+			name TEXT PRIMARY KEY,
+			data BLOB NOT NULL,
+			updated_at INTEGER NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS alert_transitions (
+			account_id TEXT NOT NULL,
+			region TEXT NOT NULL,
+			service_code TEXT NOT NULL,
+			quota_code TEXT NOT NULL,
+			taken_at INTEGER NOT NULL,
+			alert_id TEXT NOT NULL,
+			service_name TEXT NOT NULL,
+			quota_name TEXT NOT NULL,
+			state TEXT NOT NULL,
+			previous_state TEXT NOT NULL,
+			value REAL NOT NULL,
+			usage REAL NOT NULL,
+			usage_percentage REAL NOT NULL,
+			threshold REAL NOT NULL,
+			silenced INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS alert_transitions_taken_at ON alert_transitions (taken_at)`,
+		`CREATE TABLE IF NOT EXISTS limit_changes (
+			account_id TEXT NOT NULL,
+			region TEXT NOT NULL,
+			service_code TEXT NOT NULL,
+			quota_code TEXT NOT NULL,
+			service_name TEXT NOT NULL,
+			quota_name TEXT NOT NULL,
+			previous_value REAL NOT NULL,
+			value REAL NOT NULL,
+			previous_at INTEGER NOT NULL,
+			detected_at INTEGER NOT NULL,
+			cause TEXT NOT NULL,
+			request_id TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS limit_changes_detected_at ON limit_changes (detected_at)`,
+		`CREATE TABLE IF NOT EXISTS alert_states (
+			account_id TEXT NOT NULL,
+			region TEXT NOT NULL,
+			service_code TEXT NOT NULL,
+			quota_code TEXT NOT NULL,
+			state TEXT NOT NULL,
+			since INTEGER NOT NULL,
+			PRIMARY KEY (account_id, region, service_code, quota_code)
+		) WITHOUT ROWID`,
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if err := s.addColumns(ctx, "points", pointColumns); err != nil {
+		return err
+	}
+	return s.addColumns(ctx, "alert_states", alertStateColumns)
+}
+
+// pointColumns are the points columns added after the table was created
+var pointColumns = [][2]string{
+	{"limit_unknown", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// alertStateColumns are the alert_states columns beyond the state itself. They
+// are added by addColumns, so databases created before them are upgraded.
+var alertStateColumns = [][2]string{
+	{"id", "TEXT NOT NULL DEFAULT ''"},
+	{"acknowledged_at", "INTEGER"},
+	{"acknowledged_by", "TEXT NOT NULL DEFAULT ''"},
+	{"snoozed_until", "INTEGER"},
+	{"snoozed_by", "TEXT NOT NULL DEFAULT ''"},
+	{"note", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// addColumns adds the columns a table of an older database lacks
+func (s *SQLiteStore) addColumns(ctx context.Context, table string, columns [][2]string) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		if existing[column[0]] {
+			continue
+		}
+		if _, err := s.db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column[0], column[1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertSeries returns the ID of a series, creating it when new. A recorded
+// series is active again, so active clears its retirement.
+func upsertSeries(ctx context.Context, tx *sql.Tx, key QuotaKey, active bool) (int64, error) {
+	query := `INSERT INTO series (account_id, region, service_code, quota_code) VALUES (?, ?, ?, ?)
+		ON CONFLICT (account_id, region, service_code, quota_code) DO UPDATE SET retired_at = retired_at
+		RETURNING id`
+	if active {
+		query = `INSERT INTO series (account_id, region, service_code, quota_code) VALUES (?, ?, ?, ?)
+			ON CONFLICT (account_id, region, service_code, quota_code) DO UPDATE SET retired_at = NULL
+			RETURNING id`
+	}
+	var id int64
+	err := tx.QueryRowContext(ctx, query, key.AccountID, key.Region, key.ServiceCode, key.QuotaCode).Scan(&id)
+	return id, err
+}
+
+const insertPoint = `INSERT OR REPLACE INTO points (series_id, taken_at, value, usage, usage_percentage, has_usage, limit_unknown)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+func (s *SQLiteStore) Record(ctx context.Context, at time.Time, quotas []model.Quota) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	for _, q := range quotas {
+		id, err := upsertSeries(ctx, tx, KeyOf(q), true)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, insertPoint, id, at.UnixNano(), q.Value, q.Usage, q.UsagePercentage, q.HasUsageMetrics, q.LimitUnknown); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const selectSeriesPoints = `SELECT p.taken_at, p.value, p.usage, p.usage_percentage, p.has_usage, p.limit_unknown
+	FROM points p JOIN series s ON s.id = p.series_id
+	WHERE s.account_id = ? AND s.region = ? AND s.service_code = ? AND s.quota_code = ?`
+
+func (s *SQLiteStore) History(ctx context.Context, key QuotaKey, since, until time.Time) ([]Point, error) {
+	return s.queryPoints(ctx, selectSeriesPoints+` AND p.taken_at BETWEEN ? AND ? ORDER BY p.taken_at`,
+		key.AccountID, key.Region, key.ServiceCode, key.QuotaCode, since.UnixNano(), until.UnixNano())
+}
+
+func (s *SQLiteStore) Retire(ctx context.Context, at time.Time, match func(key QuotaKey, lastSeen time.Time) bool) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	rows, err := tx.QueryContext(ctx, `SELECT s.id, s.account_id, s.region, s.service_code, s.quota_code, MAX(p.taken_at)
+		FROM series s JOIN points p ON p.series_id = s.id
+		WHERE s.retired_at IS NULL
+		GROUP BY s.id`)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id, lastSeen int64
+		var key QuotaKey
+		if err := rows.Scan(&id, &key.AccountID, &key.Region, &key.ServiceCode, &key.QuotaCode, &lastSeen); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if match(key, time.Unix(0, lastSeen)) {
+			ids = append(ids, id)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, `UPDATE series SET retired_at = ? WHERE id = ?`, at.UnixNano(), id); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM alert_states WHERE (account_id, region, service_code, quota_code) IN (
+			SELECT account_id, region, service_code, quota_code FROM series WHERE id = ?)`, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), tx.Commit()
+}
+
+func (s *SQLiteStore) Retired(ctx context.Context) (map[QuotaKey]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT account_id, region, service_code, quota_code, retired_at
+		FROM series WHERE retired_at IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	retired := make(map[QuotaKey]time.Time)
+	for rows.Next() {
+		var key QuotaKey
+		var at int64
+		if err := rows.Scan(&key.AccountID, &key.Region, &key.ServiceCode, &key.QuotaCode, &at); err != nil {
+			return nil, err
+		}
+		retired[key] = time.Unix(0, at)
+	}
+	return retired, rows.Err()
+}
+
+func (s *SQLiteStore) Peaks(ctx context.Context, keys []QuotaKey) (map[QuotaKey]Point, error) {
+	peaks := make(map[QuotaKey]Point)
+	for _, key := range keys {
+		// The earliest of equal peaks, as in the memory store
+		points, err := s.queryPoints(ctx, selectSeriesPoints+` AND p.has_usage ORDER BY p.usage DESC, p.taken_at LIMIT 1`,
+			key.AccountID, key.Region, key.ServiceCode, key.QuotaCode)
+		if err != nil {
+			return nil, err
+		}
+		if len(points) > 0 {
+			peaks[key] = points[0]
+		}
+	}
+	return peaks, nil
+}
+
+func (s *SQLiteStore) Latest(ctx context.Context, keys []QuotaKey) (map[QuotaKey]Point, error) {
+	latest := make(map[QuotaKey]Point)
+	for _, key := range keys {
+		points, err := s.queryPoints(ctx, selectSeriesPoints+` ORDER BY p.taken_at DESC LIMIT 1`,
+			key.AccountID, key.Region, key.ServiceCode, key.QuotaCode)
+		if err != nil {
+			return nil, err
+		}
+		if len(points) > 0 {
+			latest[key] = points[0]
+		}
+	}
+	return latest, nil
+}
+
+func (s *SQLiteStore) Recent(ctx context.Context, keys []QuotaKey, n int) (map[QuotaKey][]Point, error) {
+	recent := make(map[QuotaKey][]Point)
+	for _, key := range keys {
+		points, err := s.queryPoints(ctx, selectSeriesPoints+` AND p.has_usage ORDER BY p.taken_at DESC LIMIT ?`,
+			key.AccountID, key.Region, key.ServiceCode, key.QuotaCode, n)
+		if err != nil {
+			return nil, err
+		}
+		if len(points) == 0 {
+			continue
+		}
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+		recent[key] = points
+	}
+	return recent, nil
+}
+
+// alertStateBatch is the number of keys looked up per AlertStates query, well
+// under SQLite's limit of bound parameters
+const alertStateBatch = 500
+
+const selectAlertStates = `SELECT account_id, region, service_code, quota_code, id, state, since,
+	acknowledged_at, acknowledged_by, snoozed_until, snoozed_by, note FROM alert_states`
+
+// AlertStates looks the given keys up in batches, or reads every state when
+// keys is nil
+func (s *SQLiteStore) AlertStates(ctx context.Context, keys []QuotaKey) (map[QuotaKey]AlertState, error) {
+	states := make(map[QuotaKey]AlertState)
+	if keys == nil {
+		return states, s.queryAlertStates(ctx, states, selectAlertStates)
+	}
+	for start := 0; start < len(keys); start += alertStateBatch {
+		batch := keys[start:min(start+alertStateBatch, len(keys))]
+		args := make([]interface{}, 0, 4*len(batch))
+		for _, key := range batch {
+			args = append(args, key.AccountID, key.Region, key.ServiceCode, key.QuotaCode)
+		}
+		values := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?), ", len(batch)), ", ")
+		query := selectAlertStates + ` WHERE (account_id, region, service_code, quota_code) IN (VALUES ` + values + `)`
+		if err := s.queryAlertStates(ctx, states, query, args...); err != nil {
+			return nil, err
+		}
+	}
+	return states, nil
+}
+
+// queryAlertStates adds the alert states selected by query to states
+func (s *SQLiteStore) queryAlertStates(ctx context.Context, states map[QuotaKey]AlertState, query string, args ...interface{}) error {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key QuotaKey
+		var state AlertState
+		var since int64
+		var acknowledgedAt, snoozedUntil sql.NullInt64
+		if err := rows.Scan(&key.AccountID, &key.Region, &key.ServiceCode, &key.QuotaCode, &state.ID, &state.State, &since,
+			&acknowledgedAt, &state.AcknowledgedBy, &snoozedUntil, &state.SnoozedBy, &state.Note); err != nil {
+			return err
+		}
+		state.Since = time.Unix(0, since)
+		state.AcknowledgedAt = nullTime(acknowledgedAt)
+		state.SnoozedUntil = nullTime(snoozedUntil)
+		states[key] = state
+	}
+	return rows.Err()
+}
+
+// nullTime converts a nullable Unix nanosecond column
+func nullTime(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.Unix(0, v.Int64)
+	return &t
+}
+
+// nullUnixNano converts an optional time to a nullable column value
+func nullUnixNano(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func (s *SQLiteStore) SetAlertStates(ctx context.Context, states map[QuotaKey]AlertState) error {
+	if len(states) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	for key, state := range states {
+		if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO alert_states
+			(account_id, region, service_code, quota_code, id, state, since,
+			acknowledged_at, acknowledged_by, snoozed_until, snoozed_by, note)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			key.AccountID, key.Region, key.ServiceCode, key.QuotaCode, state.ID, state.State, state.Since.UnixNano(),
+			nullUnixNano(state.AcknowledgedAt), state.AcknowledgedBy, nullUnixNano(state.SnoozedUntil), state.SnoozedBy, state.Note); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SaveState(ctx context.Context, name string, data []byte) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO state (name, data, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		name, data, time.Now().UnixNano())
+	return err
+}
+
+func (s *SQLiteStore) LoadState(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM state WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s *SQLiteStore) RecordAlertTransitions(ctx context.Context, transitions []AlertTransition) error {
+	if len(transitions) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	for _, t := range transitions {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO alert_transitions
+			(account_id, region, service_code, quota_code, taken_at, alert_id, service_name, quota_name,
+			state, previous_state, value, usage, usage_percentage, threshold, silenced)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			t.Key.AccountID, t.Key.Region, t.Key.ServiceCode, t.Key.QuotaCode, t.At.UnixNano(), t.AlertID, t.ServiceName, t.QuotaName,
+			t.State, t.PreviousState, t.Value, t.Usage, t.UsagePercentage, t.Threshold, t.Silenced); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) AlertTransitions(ctx context.Context, since, until time.Time) ([]AlertTransition, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT account_id, region, service_code, quota_code, taken_at, alert_id, service_name, quota_name,
+		state, previous_state, value, usage, usage_percentage, threshold, silenced
+		FROM alert_transitions WHERE taken_at BETWEEN ? AND ? ORDER BY taken_at, rowid`, since.UnixNano(), until.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []AlertTransition
+	for rows.Next() {
+		var t AlertTransition
+		var at int64
+		if err := rows.Scan(&t.Key.AccountID, &t.Key.Region, &t.Key.ServiceCode, &t.Key.QuotaCode, &at, &t.AlertID, &t.ServiceName, &t.QuotaName,
+			&t.State, &t.PreviousState, &t.Value, &t.Usage, &t.UsagePercentage, &t.Threshold, &t.Silenced); err != nil {
+			return nil, err
+		}
+		t.At = time.Unix(0, at)
+		transitions = append(transitions, t)
+	}
+	return transitions, rows.Err()
+}
+
+func (s *SQLiteStore) RecordWarnings(ctx context.Context, at time.Time, source string, warnings []string) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	for _, message := range warnings {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO warnings (taken_at, source, message) VALUES (?, ?, ?)`,
+			at.UnixNano(), source, message); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Warnings(ctx context.Context, since, until time.Time) ([]Warning, error) {
+	return s.queryWarnings(ctx, `SELECT taken_at, source, message FROM warnings
+		WHERE taken_at BETWEEN ? AND ? ORDER BY taken_at, rowid`, since.UnixNano(), until.UnixNano())
+}
+
+func (s *SQLiteStore) RecordLimitChanges(ctx context.Context, changes []LimitChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	for _, c := range changes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO limit_changes
+			(account_id, region, service_code, quota_code, service_name, quota_name,
+			previous_value, value, previous_at, detected_at, cause, request_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			c.Key.AccountID, c.Key.Region, c.Key.ServiceCode, c.Key.QuotaCode, c.ServiceName, c.QuotaName,
+			c.PreviousValue, c.Value, c.PreviousAt.UnixNano(), c.DetectedAt.UnixNano(), c.Cause, c.RequestID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) LimitChanges(ctx context.Context, since, until time.Time) ([]LimitChange, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT account_id, region, service_code, quota_code, service_name, quota_name,
+		previous_value, value, previous_at, detected_at, cause, request_id
+		FROM limit_changes WHERE detected_at BETWEEN ? AND ? ORDER BY detected_at, rowid`, since.UnixNano(), until.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []LimitChange
+	for rows.Next() {
+		var c LimitChange
+		var previousAt, detectedAt int64
+		if err := rows.Scan(&c.Key.AccountID, &c.Key.Region, &c.Key.ServiceCode, &c.Key.QuotaCode, &c.ServiceName, &c.QuotaName,
+			&c.PreviousValue, &c.Value, &previousAt, &detectedAt, &c.Cause, &c.RequestID); err != nil {
+			return nil, err
+		}
+		c.PreviousAt, c.DetectedAt = time.Unix(0, previousAt), time.Unix(0, detectedAt)
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// Compact deletes the observations of each bucket before the raw cutoff
+// but the one compactPoints keeps, ranking them with a window function over
+// buckets of Unix time, which are aligned to UTC like Truncate
+func (s *SQLiteStore) Compact(ctx context.Context, at time.Time, r Retention) (int, error) {
+	compactBefore, deleteBefore := r.cutoffs(at)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	var removed int64
+	if !deleteBefore.IsZero() {
+		res, err := tx.ExecContext(ctx, `DELETE FROM points WHERE taken_at < ?`, deleteBefore.UnixNano())
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		removed += n
+		if _, err := tx.ExecContext(ctx, `DELETE FROM warnings WHERE taken_at < ?`, deleteBefore.UnixNano()); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM limit_changes WHERE detected_at < ?`, deleteBefore.UnixNano()); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM alert_transitions WHERE taken_at < ?`, deleteBefore.UnixNano()); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM series WHERE NOT EXISTS (SELECT 1 FROM points WHERE series_id = series.id)`); err != nil {
+			return 0, err
+		}
+	}
+	if !compactBefore.IsZero() {
+		// Keep the highest usage, earliest first, or the last observation of
+		// a bucket without usage
+		res, err := tx.ExecContext(ctx, `DELETE FROM points WHERE (series_id, taken_at) IN (
+			SELECT series_id, taken_at FROM (
+				SELECT series_id, taken_at, ROW_NUMBER() OVER (
+					PARTITION BY series_id, taken_at / ?
+					ORDER BY has_usage DESC,
+						CASE WHEN has_usage THEN usage END DESC,
+						CASE WHEN has_usage THEN taken_at ELSE -taken_at END
+				) AS rank
+				FROM points WHERE taken_at < ?
+			) WHERE rank > 1
+		)`, r.Bucket.Nanoseconds(), compactBefore.UnixNano())
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		removed += n
+	}
+	return int(removed), tx.Commit()
+}
+
+func (s *SQLiteStore) Export(ctx context.Context) (*Dump, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, account_id, region, service_code, quota_code, retired_at FROM series ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	var series []Series
+	for rows.Next() {
+		var id int64
+		var retiredAt sql.NullInt64
+		var sr Series
+		if err := rows.Scan(&id, &sr.Key.AccountID, &sr.Key.Region, &sr.Key.ServiceCode, &sr.Key.QuotaCode, &retiredAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if retiredAt.Valid {
+			at := time.Unix(0, retiredAt.Int64)
+			sr.RetiredAt = &at
+		}
+		ids = append(ids, id)
+		series = append(series, sr)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	dump := &Dump{Series: make([]Series, 0, len(series))}
+	for i, sr := range series {
+		points, err := s.queryPoints(ctx, `SELECT taken_at, value, usage, usage_percentage, has_usage, limit_unknown
+			FROM points WHERE series_id = ? ORDER BY taken_at`, ids[i])
+		if err != nil {
+			return nil, err
+		}
+		sr.Points = points
+		dump.Series = append(dump.Series, sr)
+	}
+	dump.Warnings, err = s.queryWarnings(ctx, `SELECT taken_at, source, message FROM warnings ORDER BY taken_at, rowid`)
+	if err != nil {
+		return nil, err
+	}
+	return dump, nil
+}
+
+func (s *SQLiteStore) Import(ctx context.Context, dump *Dump) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	for _, series := range dump.Series {
+		id, err := upsertSeries(ctx, tx, series.Key, false)
+		if err != nil {
+			return err
+		}
+		for _, p := range series.Points {
+			if _, err := tx.ExecContext(ctx, insertPoint, id, p.Timestamp.UnixNano(), p.Value, p.Usage, p.UsagePercentage, p.HasUsage, p.LimitUnknown); err != nil {
+				return err
+			}
+		}
+		if series.RetiredAt != nil {
+			if _, err := tx.ExecContext(ctx, `UPDATE series SET retired_at = ? WHERE id = ?`, series.RetiredAt.UnixNano(), id); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Importing the same dump twice must not duplicate its warnings
+	for _, w := range dump.Warnings {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO warnings (taken_at, source, message)
+			SELECT ?, ?, ? WHERE NOT EXISTS (
+				SELECT 1 FROM warnings WHERE taken_at = ? AND source = ? AND message = ?
+			)`, w.Timestamp.UnixNano(), w.Source, w.Message, w.Timestamp.UnixNano(), w.Source, w.Message); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) queryPoints(ctx context.Context, query string, args ...interface{}) ([]Point, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []Point
+	for rows.Next() {
+		var p Point
+		var at int64
+		if err := rows.Scan(&at, &p.Value, &p.Usage, &p.UsagePercentage, &p.HasUsage, &p.LimitUnknown); err != nil {
+			return nil, err
+		}
+		p.Timestamp = time.Unix(0, at)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+func (s *SQLiteStore) queryWarnings(ctx context.Context, query string, args ...interface{}) ([]Warning, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warnings []Warning
+	for rows.Next() {
+		var w Warning
+		var at int64
+		if err := rows.Scan(&at, &w.Source, &w.Message); err != nil {
+			return nil, err
+		}
+		w.Timestamp = time.Unix(0, at)
+		warnings = append(warnings, w)
+	}
+	return warnings, rows.Err()
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// QuotaKey identifies one quota time series
+type QuotaKey struct {
+	AccountID   string `json:"account_id,omitempty"`
+	Region      string `json:"region"`
+	ServiceCode string `json:"service_code"`
+	QuotaCode   string `json:"quota_code"`
+}
+
+// KeyOf returns the series key of a quota
+func KeyOf(q model.Quota) QuotaKey {
+	return QuotaKey{
+		AccountID:   q.AccountID,
+		Region:      q.Region,
+		ServiceCode: q.ServiceCode,
+		QuotaCode:   q.QuotaCode,
+	}
+}
+
+// Point is a single recorded observation of a quota
+type Point struct {
+	Timestamp       time.Time `json:"timestamp"`
+	Value           float64   `json:"value"`
+	Usage           float64   `json:"usage"`
+	UsagePercentage float64   `json:"usage_percentage"`
+	HasUsage        bool      `json:"has_usage"`
+	// LimitUnknown is set when the limit could not be read, so Value is 0
+	// rather than the limit
+	LimitUnknown bool `json:"limit_unknown,omitempty"`
+}
+
+// Warning sources
+const (
+	WarningSourceFetch   = "fetch"
+	WarningSourceOrgScan = "org_scan"
+)
+
+// Warning is a problem reported by a fetch, such as a failed region or a
+// denied account
+type Warning struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	Message   string    `json:"message"`
+}
+
+// AlertState is the alert state of a quota (ok, warning, critical or
+// resolved) and when it was entered. An alert keeps its ID from entering
+// warning or critical until it is resolved; while it is acknowledged or
+// snoozed its transitions are not notified.
+type AlertState struct {
+	ID             string     `json:"id,omitempty"`
+	State          string     `json:"state"`
+	Since          time.Time  `json:"since"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	SnoozedUntil   *time.Time `json:"snoozed_until,omitempty"`
+	SnoozedBy      string     `json:"snoozed_by,omitempty"`
+	Note           string     `json:"note,omitempty"`
+}
+
+// AlertTransition is a logged move of the alert state of a quota into
+// warning, critical or resolved. Silenced transitions were not notified, as
+// the alert was acknowledged or snoozed.
+type AlertTransition struct {
+	Key             QuotaKey  `json:"key"`
+	At              time.Time `json:"at"`
+	AlertID         string    `json:"alert_id"`
+	ServiceName     string    `json:"service_name"`
+	QuotaName       string    `json:"quota_name"`
+	State           string    `json:"state"`
+	PreviousState   string    `json:"previous_state,omitempty"`
+	Value           float64   `json:"value"`
+	Usage           float64   `json:"usage"`
+	UsagePercentage float64   `json:"usage_percentage"`
+	Threshold       float64   `json:"threshold"`
+	Silenced        bool      `json:"silenced,omitempty"`
+}
+
+// Series is the complete recorded history of one quota
+type Series struct {
+	Key       QuotaKey   `json:"key"`
+	Points    []Point    `json:"points"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+// Dump is the complete content of a store
+type Dump struct {
+	Series   []Series  `json:"series"`
+	Warnings []Warning `json:"warnings"`
+}
+
+// Store records fetched quotas so the dashboard can answer questions about
+// how usage changes over time
+type Store interface {
+	// Record stores one observation per quota taken at the given time
+	Record(ctx context.Context, at time.Time, quotas []model.Quota) error
+	// History returns the observations of a quota in [since, until], oldest first
+	History(ctx context.Context, key QuotaKey, since, until time.Time) ([]Point, error)
+	// Retire marks the active series for which match returns true as retired
+	// at the given time, passing each series' last observation time to match,
+	// and drops their alert states. Recording a retired series again makes it
+	// active. It returns the number of series retired.
+	Retire(ctx context.Context, at time.Time, match func(key QuotaKey, lastSeen time.Time) bool) (int, error)
+	// Retired returns the retirement time of every retired series
+	Retired(ctx context.Context) (map[QuotaKey]time.Time, error)
+	// Peaks returns the observation with the highest usage recorded for each
+	// of the given series. Series without usage observations are omitted.
+	Peaks(ctx context.Context, keys []QuotaKey) (map[QuotaKey]Point, error)
+	// Latest returns the last observation of each of the given series, with
+	// or without usage. Series never recorded are omitted.
+	Latest(ctx context.Context, keys []QuotaKey) (map[QuotaKey]Point, error)
+	// Recent returns the last n observations with usage of each of the given
+	// series, oldest first. Series without usage observations are omitted.
+	Recent(ctx context.Context, keys []QuotaKey, n int) (map[QuotaKey][]Point, error)
+	// AlertStates returns the alert state of each of the given series, or of
+	// every series when keys is nil. Series without a state are omitted.
+	AlertStates(ctx context.Context, keys []QuotaKey) (map[QuotaKey]AlertState, error)
+	// SetAlertStates stores the alert states of series, replacing their
+	// previous ones
+	SetAlertStates(ctx context.Context, states map[QuotaKey]AlertState) error
+	// RecordAlertTransitions logs alert state transitions
+	RecordAlertTransitions(ctx context.Context, transitions []AlertTransition) error
+	// AlertTransitions returns the transitions logged in [since, until],
+	// oldest first
+	AlertTransitions(ctx context.Context, since, until time.Time) ([]AlertTransition, error)
+	// SaveState and LoadState keep the documents of the in-memory
+	// collections, see package persist
+	SaveState(ctx context.Context, name string, data []byte) error
+	LoadState(ctx context.Context, name string) ([]byte, error)
+	// RecordWarnings stores the warnings raised by a fetch from the given source
+	RecordWarnings(ctx context.Context, at time.Time, source string, warnings []string) error
+	// Warnings returns the warnings recorded in [since, until], oldest first
+	Warnings(ctx context.Context, since, until time.Time) ([]Warning, error)
+	// RecordLimitChanges stores detected limit changes
+	RecordLimitChanges(ctx context.Context, changes []LimitChange) error
+	// LimitChanges returns the limit changes detected in [since, until],
+	// oldest first
+	LimitChanges(ctx context.Context, since, until time.Time) ([]LimitChange, error)
+	// Export returns everything the store holds
+	Export(ctx context.Context) (*Dump, error)
+	// Compact applies the retention to the history at the given time and
+	// returns the number of observations removed
+	Compact(ctx context.Context, at time.Time, r Retention) (int, error)
+	// Import merges a dump into the store. Points and warnings are merged in
+	// timestamp order; a point at a timestamp already recorded for its series
+	// replaces it.
+	Import(ctx context.Context, dump *Dump) error
+	Close() error
+}
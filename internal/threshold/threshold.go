@@ -0,0 +1,169 @@
+// Package threshold classifies quotas by utilization into ok, warning and
+// critical, with thresholds overridable per service and per quota.
+package threshold
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+// Quota statuses
+const (
+	StatusOK       = "ok"
+	StatusWarning  = "warning"
+	StatusCritical = "critical"
+	// StatusUnknown is the status of quotas without usage metrics or a known
+	// limit
+	StatusUnknown = "unknown"
+	// StatusResolved is the alert state of a quota back below its thresholds
+	// after a warning or critical state
+	StatusResolved = "resolved"
+)
+
+// NextState returns the alert state of a quota observed with a status, given
+// its current state: ok, warning, critical, or resolved when it recovers from
+// warning or critical, until the next ok observation. An unknown status keeps
+// the state.
+func NextState(state, status string) string {
+	switch status {
+	case StatusUnknown:
+		return state
+	case StatusOK:
+		if state == StatusWarning || state == StatusCritical {
+			return StatusResolved
+		}
+		return StatusOK
+	default:
+		return status
+	}
+}
+
+// Thresholds holds the configured thresholds, in usage percent
+type Thresholds struct {
+	warning, critical float64
+	overrides         []config.ThresholdOverride
+}
+
+// New returns the thresholds of the config
+func New(cfg config.ThresholdsConfig) *Thresholds {
+	return &Thresholds{warning: cfg.Warning, critical: cfg.Critical, overrides: cfg.Overrides}
+}
+
+// Validate checks that every threshold is a percentage and that warning stays
+// below critical wherever an override applies
+func Validate(cfg config.ThresholdsConfig) error {
+	if err := checkPair("thresholds", cfg.Warning, cfg.Critical); err != nil {
+		return err
+	}
+	for i, o := range cfg.Overrides {
+		if o.Service == "" && o.QuotaCode == "" {
+			return fmt.Errorf("thresholds.overrides[%d]: service or quota_code is required", i)
+		}
+		warning, critical := cfg.Warning, cfg.Critical
+		if o.Warning > 0 {
+			warning = o.Warning
+		}
+		if o.Critical > 0 {
+			critical = o.Critical
+		}
+		if err := checkPair(fmt.Sprintf("thresholds.overrides[%d]", i), warning, critical); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkPair(name string, warning, critical float64) error {
+	if warning <= 0 || warning > 100 || critical <= 0 || critical > 100 {
+		return fmt.Errorf("%s: warning and critical must be between 0 and 100, got %v and %v", name, warning, critical)
+	}
+	if warning >= critical {
+		return fmt.Errorf("%s: warning (%v) must be below critical (%v)", name, warning, critical)
+	}
+	return nil
+}
+
+// For returns the warning and critical thresholds of a quota. An override for
+// its quota code wins over one for its service, which wins over the defaults.
+// The alert threshold of the quota's annotation, if any, wins over all of
+// them as the critical threshold, capping the warning one.
+func (t *Thresholds) For(q model.Quota) (warning, critical float64) {
+	warning, critical = t.configured(q)
+	if q.AlertThreshold > 0 {
+		critical = q.AlertThreshold
+		warning = min(warning, critical)
+	}
+	return warning, critical
+}
+
+// configured returns the thresholds of a quota set in the config
+func (t *Thresholds) configured(q model.Quota) (warning, critical float64) {
+	warning, critical = t.warning, t.critical
+	best := 0
+	for _, o := range t.overrides {
+		rank := 0
+		switch {
+		case o.QuotaCode != "" && o.QuotaCode == q.QuotaCode && (o.Service == "" || strings.EqualFold(o.Service, q.ServiceCode)):
+			rank = 2
+		case o.QuotaCode == "" && strings.EqualFold(o.Service, q.ServiceCode):
+			rank = 1
+		}
+		if rank <= best {
+			continue
+		}
+		best = rank
+		warning, critical = t.warning, t.critical
+		if o.Warning > 0 {
+			warning = o.Warning
+		}
+		if o.Critical > 0 {
+			critical = o.Critical
+		}
+	}
+	return warning, critical
+}
+
+// Status returns the status of a quota
+func (t *Thresholds) Status(q model.Quota) string {
+	if !q.HasUsageMetrics || q.LimitUnknown {
+		return StatusUnknown
+	}
+	warning, critical := t.For(q)
+	switch {
+	case q.UsagePercentage >= critical:
+		return StatusCritical
+	case q.UsagePercentage >= warning:
+		return StatusWarning
+	default:
+		return StatusOK
+	}
+}
+
+// Apply returns copies of the quotas with their status set
+func (t *Thresholds) Apply(quotas []model.Quota) []model.Quota {
+	result := make([]model.Quota, len(quotas))
+	for i, q := range quotas {
+		q.Status = t.Status(q)
+		result[i] = q
+	}
+	return result
+}
+
+// Filter returns the quotas whose status is one of the comma-separated
+// statuses
+func Filter(quotas []model.Quota, statuses string) []model.Quota {
+	want := make(map[string]bool)
+	for _, s := range strings.Split(statuses, ",") {
+		want[strings.TrimSpace(strings.ToLower(s))] = true
+	}
+	result := make([]model.Quota, 0, len(quotas))
+	for _, q := range quotas {
+		if want[q.Status] {
+			result = append(result, q)
+		}
+	}
+	return result
+}
@@ -0,0 +1,165 @@
+package threshold
+
+import (
+	"testing"
+
+	"github.com/yuxishi/aws-quota-dashboard/internal/config"
+	"github.com/yuxishi/aws-quota-dashboard/internal/model"
+)
+
+var testConfig = config.ThresholdsConfig{
+	Warning:  70,
+	Critical: 90,
+	Overrides: []config.ThresholdOverride{
+		{Service: "ec2", Warning: 60},
+		{Service: "ec2", QuotaCode: "L-1216C47A", Warning: 50, Critical: 80},
+		{QuotaCode: "L-0263D0A3", Critical: 95},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ThresholdsConfig
+		wantErr bool
+	}{
+		{name: "valid", cfg: testConfig},
+		{name: "zero warning", cfg: config.ThresholdsConfig{Critical: 90}, wantErr: true},
+		{name: "critical above 100", cfg: config.ThresholdsConfig{Warning: 70, Critical: 101}, wantErr: true},
+		{name: "warning equals critical", cfg: config.ThresholdsConfig{Warning: 90, Critical: 90}, wantErr: true},
+		{name: "warning above critical", cfg: config.ThresholdsConfig{Warning: 95, Critical: 90}, wantErr: true},
+		{
+			name:    "override without target",
+			cfg:     config.ThresholdsConfig{Warning: 70, Critical: 90, Overrides: []config.ThresholdOverride{{Warning: 50}}},
+			wantErr: true,
+		},
+		{
+			name:    "override warning above default critical",
+			cfg:     config.ThresholdsConfig{Warning: 70, Critical: 90, Overrides: []config.ThresholdOverride{{Service: "ec2", Warning: 95}}},
+			wantErr: true,
+		},
+		{
+			name: "override raising both",
+			cfg:  config.ThresholdsConfig{Warning: 70, Critical: 90, Overrides: []config.ThresholdOverride{{Service: "ec2", Warning: 95, Critical: 99}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("Validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	thresholds := New(testConfig)
+	tests := []struct {
+		name              string
+		quota             model.Quota
+		warning, critical float64
+	}{
+		{name: "defaults", quota: model.Quota{ServiceCode: "lambda", QuotaCode: "L-B99A9384"}, warning: 70, critical: 90},
+		{name: "service override", quota: model.Quota{ServiceCode: "ec2", QuotaCode: "L-34B43A08"}, warning: 60, critical: 90},
+		{name: "service override ignores case", quota: model.Quota{ServiceCode: "EC2", QuotaCode: "L-34B43A08"}, warning: 60, critical: 90},
+		{name: "quota override wins over service", quota: model.Quota{ServiceCode: "ec2", QuotaCode: "L-1216C47A"}, warning: 50, critical: 80},
+		{name: "quota override in another service", quota: model.Quota{ServiceCode: "lambda", QuotaCode: "L-1216C47A"}, warning: 70, critical: 90},
+		{name: "quota override of any service", quota: model.Quota{ServiceCode: "vpc", QuotaCode: "L-0263D0A3"}, warning: 70, critical: 95},
+		{name: "annotation threshold", quota: model.Quota{ServiceCode: "lambda", QuotaCode: "L-B99A9384", AlertThreshold: 85}, warning: 70, critical: 85},
+		{name: "annotation threshold caps warning", quota: model.Quota{ServiceCode: "lambda", QuotaCode: "L-B99A9384", AlertThreshold: 60}, warning: 60, critical: 60},
+		{name: "annotation threshold over override", quota: model.Quota{ServiceCode: "ec2", QuotaCode: "L-1216C47A", AlertThreshold: 95}, warning: 50, critical: 95},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning, critical := thresholds.For(tt.quota)
+			if warning != tt.warning || critical != tt.critical {
+				t.Errorf("For = %v, %v, want %v, %v", warning, critical, tt.warning, tt.critical)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	thresholds := New(testConfig)
+	quota := func(service string, percentage float64) model.Quota {
+		return model.Quota{ServiceCode: service, UsagePercentage: percentage, HasUsageMetrics: true}
+	}
+	tests := []struct {
+		name  string
+		quota model.Quota
+		want  string
+	}{
+		{name: "ok", quota: quota("lambda", 69.9), want: StatusOK},
+		{name: "warning at threshold", quota: quota("lambda", 70), want: StatusWarning},
+		{name: "critical at threshold", quota: quota("lambda", 90), want: StatusCritical},
+		{name: "over the limit", quota: quota("lambda", 120), want: StatusCritical},
+		{name: "service override", quota: quota("ec2", 65), want: StatusWarning},
+		{name: "no usage metrics", quota: model.Quota{ServiceCode: "lambda", UsagePercentage: 95}, want: StatusUnknown},
+		{name: "unknown limit", quota: model.Quota{ServiceCode: "lambda", UsagePercentage: 95, HasUsageMetrics: true, LimitUnknown: true}, want: StatusUnknown},
+		{name: "annotation threshold", quota: model.Quota{ServiceCode: "lambda", UsagePercentage: 50, HasUsageMetrics: true, AlertThreshold: 50}, want: StatusCritical},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thresholds.Status(tt.quota); got != tt.want {
+				t.Errorf("Status = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	quotas := []model.Quota{
+		{QuotaCode: "a", Status: StatusOK},
+		{QuotaCode: "b", Status: StatusWarning},
+		{QuotaCode: "c", Status: StatusCritical},
+		{QuotaCode: "d", Status: StatusUnknown},
+	}
+	tests := []struct {
+		statuses string
+		want     []string
+	}{
+		{statuses: "critical", want: []string{"c"}},
+		{statuses: "Warning, CRITICAL", want: []string{"b", "c"}},
+		{statuses: "resolved"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.statuses, func(t *testing.T) {
+			got := Filter(quotas, tt.statuses)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Filter returned %d quotas, want %d", len(got), len(tt.want))
+			}
+			for i, q := range got {
+				if q.QuotaCode != tt.want[i] {
+					t.Errorf("quota %d = %q, want %q", i, q.QuotaCode, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNextState(t *testing.T) {
+	tests := []struct {
+		state, status, want string
+	}{
+		{state: "", status: StatusOK, want: StatusOK},
+		{state: "", status: StatusWarning, want: StatusWarning},
+		{state: "", status: StatusUnknown, want: ""},
+		{state: StatusOK, status: StatusOK, want: StatusOK},
+		{state: StatusOK, status: StatusCritical, want: StatusCritical},
+		{state: StatusWarning, status: StatusCritical, want: StatusCritical},
+		{state: StatusCritical, status: StatusWarning, want: StatusWarning},
+		{state: StatusWarning, status: StatusOK, want: StatusResolved},
+		{state: StatusCritical, status: StatusOK, want: StatusResolved},
+		{state: StatusCritical, status: StatusUnknown, want: StatusCritical},
+		{state: StatusResolved, status: StatusOK, want: StatusOK},
+		{state: StatusResolved, status: StatusWarning, want: StatusWarning},
+		{state: StatusResolved, status: StatusUnknown, want: StatusResolved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state+"/"+tt.status, func(t *testing.T) {
+			if got := NextState(tt.state, tt.status); got != tt.want {
+				t.Errorf("NextState(%q, %q) = %q, want %q", tt.state, tt.status, got, tt.want)
+			}
+		})
+	}
+}
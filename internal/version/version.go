@@ -0,0 +1,70 @@
+// Package version reports the build of the running binary. Version, Commit
+// and BuildDate are set at link time:
+//
+//	go build -ldflags "-X github.com/yuxishi/aws-quota-dashboard/internal/version.Version=1.2.0 ..."
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set via -ldflags -X
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit,omitempty"`
+	BuildDate string   `json:"build_date,omitempty"`
+	GoVersion string   `json:"go_version"`
+	Platform  string   `json:"platform"`
+	Features  []string `json:"features"`
+}
+
+// Get returns the build information with the given enabled features
+func Get(features []string) Info {
+	if features == nil {
+		features = []string{}
+	}
+	return Info{
+		Version:   Version,
+		Commit:    commit(),
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Features:  features,
+	}
+}
+
+// String returns the version and short commit, e.g. "1.2.0 (3f2a9c1)"
+func String() string {
+	c := commit()
+	if len(c) > 7 {
+		c = c[:7]
+	}
+	if c == "" {
+		return Version
+	}
+	return Version + " (" + c + ")"
+}
+
+// commit falls back to the VCS revision stamped by the Go toolchain when the
+// binary was built without ldflags
+func commit() string {
+	if Commit != "" {
+		return Commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				return s.Value
+			}
+		}
+	}
+	return ""
+}